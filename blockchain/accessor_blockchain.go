@@ -290,6 +290,9 @@ func (blockchain *BlockChain) GetShardBlockForBridge(from uint64, to common.Hash
 			shardBlksForBridge[sid] = append(shardBlocks, shardBlksForBridge[sid]...)
 			shardBlksForBridgeAgg[beaconHeight][sid] = shardBlocks
 		}
+		if beaconHeight == 1 { //genesis block has no previous block
+			break
+		}
 		beaconBlk, _, _ = blockchain.GetBeaconBlockByHash(beaconBlk.GetPrevHash())
 	}
 
@@ -502,6 +505,30 @@ func (blockchain *BlockChain) GetBeaconRootsHashFromBlockHeight(height uint64) (
 	return GetBeaconRootsHashByBlockHash(blockchain.GetBeaconChainDatabase(), *h)
 }
 
+// CheckStateAvailable returns a StatePrunedError when the state of chain cid
+// (common.BeaconChainID for beacon) at height was dropped by the state pruner,
+// i.e. height is outside the retention window recorded in the prune status
+func (blockchain *BlockChain) CheckStateAvailable(cid int, height uint64) error {
+	db := blockchain.GetBeaconChainDatabase()
+	if cid != common.BeaconChainID {
+		db = blockchain.GetShardChainDatabase(byte(cid))
+	}
+	data, err := rawdbv2.GetPruneStatus(db)
+	if err != nil { //never pruned
+		return nil
+	}
+	status := struct {
+		PrunedHeight uint64
+	}{}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil
+	}
+	if height <= status.PrunedHeight {
+		return NewBlockChainError(StatePrunedError, fmt.Errorf("state of chain %v at height %v is pruned, lowest available height is %v", cid, height, status.PrunedHeight+1))
+	}
+	return nil
+}
+
 func GetBeaconRootsHashByBlockHash(db incdb.Database, hash common.Hash) (*BeaconRootHash, error) {
 	data, e := rawdbv2.GetBeaconRootsHash(db, hash)
 	if e != nil {
@@ -133,6 +133,9 @@ func (blockchain *BlockChain) GetAllBridgeTokensByHeight(height uint64) ([]commo
 	if height > blockchain.GetBeaconBestState().BeaconHeight {
 		return bridgeTokenIDs, allBridgeTokens, fmt.Errorf("height too large")
 	}
+	if err := blockchain.CheckStateAvailable(common.BeaconChainID, height); err != nil {
+		return bridgeTokenIDs, allBridgeTokens, err
+	}
 
 	bridgeStateDB, err := blockchain.GetBestStateBeaconFeatureStateDBByHeight(height, blockchain.GetBeaconChainDatabase())
 	if err != nil {
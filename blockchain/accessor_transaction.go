@@ -320,14 +320,19 @@ func (blockchain *BlockChain) BuildResponseTransactionFromTxsWithMetadata(view *
 //
 // If there is a ReadonlyKey, return decrypted coins; otherwise, just return raw coins
 func (blockchain *BlockChain) getOutputCoins(keyset *incognitokey.KeySet, shardID byte, tokenID *common.Hash, upToHeight uint64, versionsIncluded map[int]bool) ([]privacy.PlainCoin, []privacy.Coin, uint64, error) {
+	bss := blockchain.GetBestStateShard(shardID)
+	return blockchain.getOutputCoinsFromStateDB(blockchain.GetBestStateTransactionStateDB(shardID), bss.ShardHeight, keyset, shardID, tokenID, upToHeight, versionsIncluded)
+}
+
+// getOutputCoinsFromStateDB is getOutputCoins on the transaction state
+// database after the shard block of height latest
+func (blockchain *BlockChain) getOutputCoinsFromStateDB(transactionStateDB *statedb.StateDB, latest uint64, keyset *incognitokey.KeySet, shardID byte, tokenID *common.Hash, upToHeight uint64, versionsIncluded map[int]bool) ([]privacy.PlainCoin, []privacy.Coin, uint64, error) {
 	var outCoins []privacy.Coin
 	var lowestHeightForV2 uint64 = config.Param().CoinVersion2LowestHeight
 	var fromHeight uint64
 	if keyset == nil {
 		return nil, nil, 0, NewBlockChainError(GetListDecryptedOutputCoinsByKeysetError, fmt.Errorf("invalid key set, got keyset %+v", keyset))
 	}
-	bss := blockchain.GetBestStateShard(shardID)
-	transactionStateDB := blockchain.GetBestStateTransactionStateDB(shardID)
 
 	if versionsIncluded[1] {
 		results, err := coinIndexer.QueryDbCoinVer1(keyset.PaymentAddress.Pk, tokenID, transactionStateDB)
@@ -343,7 +348,6 @@ func (blockchain *BlockChain) getOutputCoins(keyset *incognitokey.KeySet, shardI
 		if keyset.PaymentAddress.GetOTAPublicKey() == nil {
 			return nil, nil, 0, errors.New("OTA publicKey is needed when retrieving coinV2")
 		}
-		latest := bss.ShardHeight
 		if upToHeight > latest || upToHeight == 0 {
 			upToHeight = latest
 		}
@@ -435,6 +439,18 @@ func (blockchain *BlockChain) GetListDecryptedOutputCoinsByKeyset(keyset *incogn
 	return blockchain.getOutputCoins(keyset, shardID, tokenID, shardHeight, map[int]bool{1: true, 2: true})
 }
 
+// GetListDecryptedOutputCoinsByKeysetAt is GetListDecryptedOutputCoinsByKeyset
+// on the transaction state of a past shard block, see GetStateDBAt. Coins v2
+// are scanned up to the height of that block and coins are checked unspent
+// against its serial numbers.
+func (blockchain *BlockChain) GetListDecryptedOutputCoinsByKeysetAt(keyset *incognitokey.KeySet, shardID byte, tokenID *common.Hash, at *StateAt) ([]privacy.PlainCoin, []privacy.Coin, uint64, error) {
+	versionsIncluded := map[int]bool{1: true, 2: true}
+	if keyset.OTAKey.GetPublicSpend() == nil || keyset.OTAKey.GetOTASecretKey() == nil || keyset.PaymentAddress.GetOTAPublicKey() == nil {
+		versionsIncluded = map[int]bool{1: true}
+	}
+	return blockchain.getOutputCoinsFromStateDB(at.StateDB, at.Height, keyset, shardID, tokenID, at.Height, versionsIncluded)
+}
+
 func (blockchain *BlockChain) SubmitOTAKey(otaKey privacy.OTAKey, accessToken string, isReset bool, heightToSyncFrom uint64) error {
 	if !EnableIndexingCoinByOTAKey {
 		return fmt.Errorf("OTA key submission not supported by this node configuration")
@@ -0,0 +1,370 @@
+package blockchain
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain/types"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/pkg/errors"
+)
+
+// Chain archive, a portable export of the finalized blocks of one chain.
+//
+// The archive is a JSON-lines stream, gzip compressed or not. The first line is
+// the ArchiveHeader, every next line is an ArchiveBlock, in increasing height
+// without gap. Block holds the complete block JSON as stored by the node and is
+// the only field read on import; the other fields are decoded from it for
+// readers which do not know the block format. A reader must refuse an archive
+// whose Format is unknown or whose Version is greater than its own.
+const (
+	ArchiveFormat  = "incognito-chain-archive"
+	ArchiveVersion = 1
+)
+
+// ArchiveHeader is the first line of a chain archive
+type ArchiveHeader struct {
+	Format     string
+	Version    int
+	Network    string //config param name, the archive is only imported on the same network
+	ChainID    int    //-1 for beacon, shard ID otherwise
+	FromHeight uint64
+	ToHeight   uint64 //0 when unknown at creation, the last line gives the last height
+	CreatedAt  int64  //unix time
+}
+
+// ArchiveBlock is one block of a chain archive
+type ArchiveBlock struct {
+	Height       uint64
+	Hash         string
+	PrevHash     string
+	Epoch        uint64
+	Round        int
+	BeaconHeight uint64
+	ProduceTime  int64
+	Proposer     string
+	Producer     string
+	Block        json.RawMessage
+	Transactions []ArchiveTx          `json:",omitempty"`
+	Instructions []ArchiveInstruction `json:",omitempty"`
+}
+
+// ArchiveTx is the decoded summary of a shard block transaction
+type ArchiveTx struct {
+	Hash         string
+	Type         string
+	Version      int8
+	LockTime     int64
+	Fee          uint64
+	FeeToken     uint64
+	TokenID      string
+	IsPrivacy    bool
+	MetadataType int
+	Metadata     json.RawMessage `json:",omitempty"`
+}
+
+// ArchiveInstruction is a block instruction with its action decoded, Action is
+// the first field of the instruction, MetadataType is set when it is a number
+type ArchiveInstruction struct {
+	Action       string
+	MetadataType int `json:",omitempty"`
+	Args         []string
+}
+
+func newArchiveBlock(blk types.BlockInterface) (*ArchiveBlock, error) {
+	data, err := json.Marshal(blk)
+	if err != nil {
+		return nil, err
+	}
+	rec := &ArchiveBlock{
+		Height:       blk.GetHeight(),
+		Hash:         blk.Hash().String(),
+		PrevHash:     blk.GetPrevHash().String(),
+		Epoch:        blk.GetCurrentEpoch(),
+		Round:        blk.GetRound(),
+		BeaconHeight: blk.GetBeaconHeight(),
+		ProduceTime:  blk.GetProduceTime(),
+		Proposer:     blk.GetProposer(),
+		Producer:     blk.GetProducer(),
+		Block:        data,
+	}
+	for _, inst := range blk.GetInstructions() {
+		if len(inst) == 0 {
+			continue
+		}
+		decoded := ArchiveInstruction{Action: inst[0], Args: inst[1:]}
+		if metaType, err := strconv.Atoi(inst[0]); err == nil {
+			decoded.MetadataType = metaType
+		}
+		rec.Instructions = append(rec.Instructions, decoded)
+	}
+	if shardBlock, ok := blk.(*types.ShardBlock); ok {
+		for _, tx := range shardBlock.Body.Transactions {
+			decoded := ArchiveTx{
+				Hash:         tx.Hash().String(),
+				Type:         tx.GetType(),
+				Version:      tx.GetVersion(),
+				LockTime:     tx.GetLockTime(),
+				Fee:          tx.GetTxFee(),
+				FeeToken:     tx.GetTxFeeToken(),
+				TokenID:      tx.GetTokenID().String(),
+				IsPrivacy:    tx.IsPrivacy(),
+				MetadataType: tx.GetMetadataType(),
+			}
+			if meta := tx.GetMetadata(); meta != nil {
+				if decoded.Metadata, err = json.Marshal(meta); err != nil {
+					return nil, err
+				}
+			}
+			rec.Transactions = append(rec.Transactions, decoded)
+		}
+	}
+	return rec, nil
+}
+
+// DecodeBlock returns the block of the record, checking its hash and height
+func (rec *ArchiveBlock) DecodeBlock(chainID int) (types.BlockInterface, error) {
+	var blk types.BlockInterface
+	if chainID == common.BeaconChainID {
+		blk = types.NewBeaconBlock()
+	} else {
+		blk = types.NewShardBlock()
+	}
+	if err := json.Unmarshal(rec.Block, blk); err != nil {
+		return nil, err
+	}
+	if blk.Hash().String() != rec.Hash || blk.GetHeight() != rec.Height {
+		return nil, fmt.Errorf("block at height %v is %v, archive expects %v at height %v", blk.GetHeight(), blk.Hash().String(), rec.Hash, rec.Height)
+	}
+	return blk, nil
+}
+
+// ArchiveWriter writes a chain archive
+type ArchiveWriter struct {
+	Header ArchiveHeader
+	w      *bufio.Writer
+	gz     *gzip.Writer
+	last   uint64
+}
+
+// NewArchiveWriter writes the header of the archive of chainID, gzip
+// compressed when compress is set
+func NewArchiveWriter(w io.Writer, chainID int, fromHeight, toHeight uint64, compress bool) (*ArchiveWriter, error) {
+	aw := &ArchiveWriter{
+		Header: ArchiveHeader{
+			Format:     ArchiveFormat,
+			Version:    ArchiveVersion,
+			ChainID:    chainID,
+			FromHeight: fromHeight,
+			ToHeight:   toHeight,
+			CreatedAt:  time.Now().Unix(),
+		},
+	}
+	if param := config.Param(); param != nil {
+		aw.Header.Network = param.Name
+	}
+	if compress {
+		aw.gz = gzip.NewWriter(w)
+		w = aw.gz
+	}
+	aw.w = bufio.NewWriter(w)
+	if err := aw.writeLine(aw.Header); err != nil {
+		return nil, err
+	}
+	return aw, nil
+}
+
+func (aw *ArchiveWriter) writeLine(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := aw.w.Write(data); err != nil {
+		return err
+	}
+	return aw.w.WriteByte('\n')
+}
+
+// Append writes the next block of the archive
+func (aw *ArchiveWriter) Append(blk types.BlockInterface) error {
+	if blk.GetShardID() != aw.Header.ChainID {
+		return fmt.Errorf("block of chain %v in archive of chain %v", blk.GetShardID(), aw.Header.ChainID)
+	}
+	if aw.last != 0 && blk.GetHeight() != aw.last+1 {
+		return fmt.Errorf("block at height %v follows height %v", blk.GetHeight(), aw.last)
+	}
+	rec, err := newArchiveBlock(blk)
+	if err != nil {
+		return err
+	}
+	aw.last = blk.GetHeight()
+	return aw.writeLine(rec)
+}
+
+// Close flushes the archive, the underlying writer is not closed
+func (aw *ArchiveWriter) Close() error {
+	if err := aw.w.Flush(); err != nil {
+		return err
+	}
+	if aw.gz != nil {
+		return aw.gz.Close()
+	}
+	return nil
+}
+
+// ArchiveReader reads a chain archive, compressed or not
+type ArchiveReader struct {
+	Header ArchiveHeader
+	r      *bufio.Reader
+}
+
+// NewArchiveReader reads and checks the header of the archive
+func NewArchiveReader(r io.Reader) (*ArchiveReader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		br = bufio.NewReader(gz)
+	}
+	ar := &ArchiveReader{r: br}
+	line, err := ar.readLine()
+	if err != nil {
+		return nil, errors.Wrap(err, "read archive header")
+	}
+	if err := json.Unmarshal(line, &ar.Header); err != nil {
+		return nil, errors.Wrap(err, "decode archive header")
+	}
+	if ar.Header.Format != ArchiveFormat {
+		return nil, fmt.Errorf("unknown archive format %q", ar.Header.Format)
+	}
+	if ar.Header.Version > ArchiveVersion {
+		return nil, fmt.Errorf("archive version %v is not supported, max version is %v", ar.Header.Version, ArchiveVersion)
+	}
+	return ar, nil
+}
+
+func (ar *ArchiveReader) readLine() ([]byte, error) {
+	line, err := ar.r.ReadBytes('\n')
+	if err == io.EOF && len(line) > 0 {
+		return nil, io.ErrUnexpectedEOF //the last line of an archive ends with a new line
+	}
+	return line, err
+}
+
+// Next returns the next block record, io.EOF at the end of the archive
+func (ar *ArchiveReader) Next() (*ArchiveBlock, error) {
+	line, err := ar.readLine()
+	if err != nil {
+		return nil, err
+	}
+	rec := &ArchiveBlock{}
+	if err := json.Unmarshal(line, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// ExportArchive writes the finalized blocks of heights [from, to] to the
+// archive, to = 0 exports up to the last finalized block. It returns the
+// number of exported blocks.
+func (s *BlockStorage) ExportArchive(aw *ArchiveWriter, from, to uint64) (uint64, error) {
+	if from == 0 {
+		from = 1
+	}
+	count := uint64(0)
+	for height := from; to == 0 || height <= to; height++ {
+		var hash *common.Hash
+		var err error
+		if s.cid == common.BeaconChainID {
+			hash, err = s.GetFinalizedBeaconBlock(height)
+		} else {
+			hash, err = s.GetFinalizedShardBlockHashByIndex(height)
+		}
+		if err != nil {
+			if to == 0 && height > from {
+				break
+			}
+			return count, errors.Wrapf(err, "no finalized block at height %v", height)
+		}
+		blk, _, err := s.GetBlock(*hash)
+		if err != nil {
+			return count, errors.Wrapf(err, "read block %v at height %v", hash.String(), height)
+		}
+		if err := aw.Append(blk); err != nil {
+			return count, err
+		}
+		count++
+		if height%1000 == 0 {
+			Logger.log.Infof("Export chain %v block %v", s.cid, height)
+		}
+	}
+	return count, nil
+}
+
+// ImportArchive replays the blocks of heights [from, to] of the archive
+// through the normal insert path, with full validation. Blocks not above the
+// best view are skipped, to = 0 imports up to the end of the archive. The
+// import stops before the next block once stop is closed. It returns the
+// number of inserted blocks.
+func (blockchain *BlockChain) ImportArchive(ar *ArchiveReader, from, to uint64, stop <-chan struct{}) (uint64, error) {
+	cid := ar.Header.ChainID
+	if param := config.Param(); param != nil && ar.Header.Network != "" && ar.Header.Network != param.Name {
+		return 0, fmt.Errorf("archive of network %v cannot be imported on %v", ar.Header.Network, param.Name)
+	}
+	if cid != common.BeaconChainID && (cid < 0 || cid >= len(blockchain.ShardChain)) {
+		return 0, fmt.Errorf("archive of chain %v is not an active chain", cid)
+	}
+	count := uint64(0)
+	for {
+		select {
+		case <-stop:
+			return count, nil
+		default:
+		}
+		rec, err := ar.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		if rec.Height < from {
+			continue
+		}
+		if to != 0 && rec.Height > to {
+			return count, nil
+		}
+		blk, err := rec.DecodeBlock(cid)
+		if err != nil {
+			return count, err
+		}
+		switch b := blk.(type) {
+		case *types.BeaconBlock:
+			if b.Header.Height <= blockchain.BeaconChain.GetBestViewHeight() {
+				continue
+			}
+			err = blockchain.InsertBeaconBlock(b, true)
+		case *types.ShardBlock:
+			if b.Header.Height <= blockchain.ShardChain[cid].GetBestViewHeight() {
+				continue
+			}
+			err = blockchain.InsertShardBlock(b, true)
+		}
+		if err != nil {
+			return count, errors.Wrapf(err, "insert block %v at height %v", rec.Hash, rec.Height)
+		}
+		count++
+		if rec.Height%1000 == 0 {
+			Logger.log.Infof("Import chain %v block %v", cid, rec.Height)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package blockchain
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain/types"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func testArchiveBeaconBlocks(from, to uint64) []*types.BeaconBlock {
+	blocks := []*types.BeaconBlock{}
+	prevHash := common.Hash{}
+	for height := from; height <= to; height++ {
+		blk := types.NewBeaconBlock()
+		blk.Header.Height = height
+		blk.Header.PreviousBlockHash = prevHash
+		blocks = append(blocks, blk)
+		prevHash = *blk.Hash()
+	}
+	return blocks
+}
+
+func TestArchiveWriterReader(t *testing.T) {
+	config.AbortParam()
+	blocks := testArchiveBeaconBlocks(1, 3)
+	for _, compress := range []bool{true, false} {
+		buf := &bytes.Buffer{}
+		aw, err := NewArchiveWriter(buf, common.BeaconChainID, 1, 3, compress)
+		assert.Nil(t, err)
+		for _, blk := range blocks {
+			assert.Nil(t, aw.Append(blk))
+		}
+		assert.Nil(t, aw.Close())
+
+		ar, err := NewArchiveReader(bytes.NewReader(buf.Bytes()))
+		assert.Nil(t, err)
+		assert.Equal(t, ArchiveFormat, ar.Header.Format)
+		assert.Equal(t, common.BeaconChainID, ar.Header.ChainID)
+		assert.Equal(t, uint64(1), ar.Header.FromHeight)
+		assert.Equal(t, uint64(3), ar.Header.ToHeight)
+		for _, blk := range blocks {
+			rec, err := ar.Next()
+			assert.Nil(t, err)
+			assert.Equal(t, blk.GetHeight(), rec.Height)
+			assert.Equal(t, blk.GetPrevHash().String(), rec.PrevHash)
+			decoded, err := rec.DecodeBlock(common.BeaconChainID)
+			assert.Nil(t, err)
+			assert.Equal(t, *blk.Hash(), *decoded.Hash())
+		}
+		_, err = ar.Next()
+		assert.Equal(t, io.EOF, err)
+	}
+}
+
+func TestArchiveWriterReaderErrors(t *testing.T) {
+	config.AbortParam()
+	blocks := testArchiveBeaconBlocks(1, 3)
+	buf := &bytes.Buffer{}
+	aw, err := NewArchiveWriter(buf, common.BeaconChainID, 1, 0, false)
+	assert.Nil(t, err)
+	assert.Nil(t, aw.Append(blocks[0]))
+	//a gap, or a block of another chain
+	assert.NotNil(t, aw.Append(blocks[2]))
+	assert.NotNil(t, aw.Append(types.NewShardBlock()))
+	assert.Nil(t, aw.Append(blocks[1]))
+	assert.Nil(t, aw.Close())
+	data := buf.Bytes()
+
+	//a record forged after its hash was taken
+	rec := &ArchiveBlock{Height: 2, Hash: blocks[2].Hash().String(), Block: []byte(`{}`)}
+	_, err = rec.DecodeBlock(common.BeaconChainID)
+	assert.NotNil(t, err)
+
+	//an archive cut in the middle of its last line
+	ar, err := NewArchiveReader(bytes.NewReader(data[:len(data)-5]))
+	assert.Nil(t, err)
+	_, err = ar.Next()
+	assert.Nil(t, err)
+	_, err = ar.Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	//unknown format and newer version
+	_, err = NewArchiveReader(bytes.NewReader([]byte("{\"Format\":\"other\",\"Version\":1}\n")))
+	assert.NotNil(t, err)
+	_, err = NewArchiveReader(bytes.NewReader([]byte("{\"Format\":\"" + ArchiveFormat + "\",\"Version\":99}\n")))
+	assert.NotNil(t, err)
+}
+
+func TestExportArchiveReadOnly(t *testing.T) {
+	config.AbortParam()
+	dir, err := ioutil.TempDir("", "exportarchive")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	hashes := storeTestBeaconBlocks(t, dir, 1, 3)
+
+	//a torn record at the tail is left as is by the export
+	segment := path.Join(dir, "blockstorage", "0")
+	fd, err := os.OpenFile(segment, os.O_APPEND|os.O_WRONLY, 0666)
+	assert.Nil(t, err)
+	_, err = fd.Write([]byte{1, 2, 3})
+	assert.Nil(t, err)
+	fd.Close()
+	before, err := ioutil.ReadFile(segment)
+	assert.Nil(t, err)
+
+	blockStorage, err := OpenBlockStorageReadOnly(path.Join(dir, "blockstorage"), "", common.BeaconChainID)
+	assert.Nil(t, err)
+	buf := &bytes.Buffer{}
+	aw, err := NewArchiveWriter(buf, common.BeaconChainID, 0, 0, true)
+	assert.Nil(t, err)
+	count, err := blockStorage.ExportArchive(aw, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), count)
+	assert.Nil(t, aw.Close())
+	assert.NotNil(t, blockStorage.StoreBlock(types.NewBeaconBlock()))
+	assert.Nil(t, blockStorage.Close())
+
+	after, err := ioutil.ReadFile(segment)
+	assert.Nil(t, err)
+	assert.Equal(t, before, after)
+
+	ar, err := NewArchiveReader(bytes.NewReader(buf.Bytes()))
+	assert.Nil(t, err)
+	for i, hash := range hashes {
+		rec, err := ar.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(i+1), rec.Height)
+		assert.Equal(t, hash.String(), rec.Hash)
+	}
+	_, err = ar.Next()
+	assert.Equal(t, io.EOF, err)
+}
@@ -26,6 +26,18 @@ type BackupProcessInfo struct {
 	BeaconView      *BeaconBestState
 	ShardView       map[int]*ShardBestState
 	MinBeaconHeight uint64
+	Parent          string         //checkpoint this incremental backup is taken on top of, empty for a full backup
+	Chain           []string       //checkpoints to apply in order to restore this one, from the full backup
+	BaseShardHeight map[int]uint64 //shard heights of the full backup of Chain
+}
+
+// GetChain returns the checkpoints to apply to restore the backup, backups
+// taken before incremental backups existed are full backups
+func (s BackupProcessInfo) GetChain() []string {
+	if len(s.Chain) == 0 {
+		return []string{s.CheckpointName}
+	}
+	return s.Chain
 }
 
 type BackupManager struct {
@@ -98,7 +110,7 @@ func (s *BackupManager) Backup(backupHeight uint64) {
 				log.Println("remove unfinished backup folder", dirPath, time.Since(t1).Seconds())
 			} else {
 				s.donwloadingLock.Lock()
-				if s.lastBackup.CheckpointName != info.Name() && s.downloading[info.Name()] == 0 {
+				if !s.isInLastBackupChain(info.Name()) && s.downloading[info.Name()] == 0 {
 					log.Println("remove old backup folder", dirPath)
 					os.RemoveAll(dirPath)
 				}
@@ -142,6 +154,21 @@ func (s *BackupManager) Backup(backupHeight uint64) {
 		BeaconView:     beaconFinalView,
 		ShardView:      map[int]*ShardBestState{},
 	}
+	parent := s.getDeltaParent()
+	if parent != nil {
+		backupInfo.Parent = parent.CheckpointName
+		backupInfo.Chain = append(append([]string{}, parent.GetChain()...), checkPoint)
+		backupInfo.BaseShardHeight = parent.BaseShardHeight
+		if parent.Parent == "" {
+			backupInfo.BaseShardHeight = map[int]uint64{}
+			for sid, view := range parent.ShardView {
+				backupInfo.BaseShardHeight[sid] = view.ShardHeight
+			}
+		}
+		log.Println("incremental backup on top of", parent.CheckpointName)
+	} else {
+		backupInfo.Chain = []string{checkPoint}
+	}
 	s.runningBackup = backupInfo
 	defer func() {
 		s.runningBackup = nil
@@ -150,7 +177,11 @@ func (s *BackupManager) Backup(backupHeight uint64) {
 	//backup beacon then shard
 	log.Println("backup beacon")
 	backUpPath := path.Join(cfg.DataDir, cfg.DatabaseDir, checkPoint)
-	s.backupBeacon(backUpPath, beaconFinalView)
+	var parentBeaconView *BeaconBestState
+	if parent != nil {
+		parentBeaconView = parent.BeaconView
+	}
+	s.backupBeacon(backUpPath, beaconFinalView, parentBeaconView)
 	beaconFinalView.BestBlock = types.BeaconBlock{}
 
 	//backup shard
@@ -174,7 +205,11 @@ func (s *BackupManager) Backup(backupHeight uint64) {
 		shardWG.Add(1)
 		sem.Acquire(context.Background(), 1)
 		go func(sid int) {
-			s.backupShard(backUpPath, shardFinalView[sid])
+			var parentShardView *ShardBestState
+			if parent != nil {
+				parentShardView = parent.ShardView[sid]
+			}
+			s.backupShard(backUpPath, shardFinalView[sid], parentShardView)
 			backupInfo.ShardView[sid] = shardFinalView[sid]
 			shardWG.Done()
 			sem.Release(1)
@@ -199,8 +234,16 @@ func (s *BackupManager) Backup(backupHeight uint64) {
 	}
 	backupInfo.MinBeaconHeight-- //for get previous block
 
-	//backup beacon block
-	s.backupBeaconBlock(backUpPath, backupInfo.MinBeaconHeight, beaconFinalView)
+	//backup beacon block, an incremental backup only holds the blocks after its parent
+	fromBeaconBlock := backupInfo.MinBeaconHeight
+	if parent != nil {
+		fromBeaconBlock = parent.BeaconView.BeaconHeight + 1
+	}
+	s.backupBeaconBlock(backUpPath, fromBeaconBlock, beaconFinalView)
+
+	if err := writeBackupManifest(backUpPath, backupInfo); err != nil {
+		panic(err)
+	}
 
 	//create done file
 	fd, err := os.OpenFile(path.Join(backUpPath, "done"), os.O_CREATE|os.O_RDWR, 0666)
@@ -237,21 +280,32 @@ func (s *BackupManager) GetFileID(cid int, blockheight uint64) uint64 {
 	}
 }
 
-func (s *BackupManager) backupShard(name string, finalView *ShardBestState) {
+func (s *BackupManager) backupShard(name string, finalView *ShardBestState, parentView *ShardBestState) {
 	consensusDB := finalView.GetCopiedConsensusStateDB()
 	txDB := finalView.GetCopiedTransactionStateDB()
 	featureDB := finalView.GetCopiedFeatureStateDB()
 	rewardDB := finalView.GetShardRewardStateDB()
 
-	shardKeyValueDB, _ := incdb.Open("leveldb", path.Join(name, fmt.Sprintf("shard%v", finalView.ShardID)))
+	shardKeyValueDB, _ := incdb.Open(config.Config().DatabaseDriver, path.Join(name, fmt.Sprintf("shard%v", finalView.ShardID)))
 
 	wg := sync.WaitGroup{}
+	parentRoot := ShardRootHash{}
+	fromBlock := uint64(1)
+	if parentView != nil {
+		parentRoot = ShardRootHash{
+			ConsensusStateDBRootHash:   parentView.ConsensusStateDBRootHash,
+			TransactionStateDBRootHash: parentView.TransactionStateDBRootHash,
+			FeatureStateDBRootHash:     parentView.FeatureStateDBRootHash,
+			RewardStateDBRootHash:      parentView.RewardStateDBRootHash,
+		}
+		fromBlock = parentView.ShardHeight + 1
+	}
 	wg.Add(5)
-	go s.backupShardBlock(name, finalView, &wg)
-	go backupStateDB(consensusDB, shardKeyValueDB, &wg)
-	go backupStateDB(featureDB, shardKeyValueDB, &wg)
-	go backupStateDB(txDB, shardKeyValueDB, &wg)
-	go backupStateDB(rewardDB, shardKeyValueDB, &wg)
+	go s.backupShardBlock(name, fromBlock, finalView, &wg)
+	go backupStateDB(consensusDB, parentRoot.ConsensusStateDBRootHash, shardKeyValueDB, &wg)
+	go backupStateDB(featureDB, parentRoot.FeatureStateDBRootHash, shardKeyValueDB, &wg)
+	go backupStateDB(txDB, parentRoot.TransactionStateDBRootHash, shardKeyValueDB, &wg)
+	go backupStateDB(rewardDB, parentRoot.RewardStateDBRootHash, shardKeyValueDB, &wg)
 	wg.Wait()
 }
 
@@ -267,11 +321,14 @@ func (s *BackupManager) GetBackupReader(checkpoint string, cid int) string {
 	return dbLoc
 }
 
-func (s *BackupManager) backupShardBlock(name string, finalView *ShardBestState, wg *sync.WaitGroup) {
+// backupShardBlock copies the finalized shard blocks from fromBlock into the
+// flat file of the checkpoint. Blocks are read by height through the block
+// storage of the chain, so the blocks moved to the ancient store are copied too.
+func (s *BackupManager) backupShardBlock(name string, fromBlock uint64, finalView *ShardBestState, wg *sync.WaitGroup) {
 	defer wg.Done()
 	sid := finalView.GetShardID()
 	blockStorage := NewBlockStorage(nil, path.Join(name, fmt.Sprintf("shard%v", sid), "blockstorage"), int(sid), true)
-	for blkHeight := uint64(1); blkHeight <= finalView.ShardHeight; blkHeight++ {
+	for blkHeight := fromBlock; blkHeight <= finalView.ShardHeight; blkHeight++ {
 		shardBlock, err := s.blockchain.GetShardBlockByHeightV1(blkHeight, sid)
 		if err != nil {
 			panic(err)
@@ -293,21 +350,30 @@ func (s *BackupManager) backupShardBlock(name string, finalView *ShardBestState,
 	}
 }
 
-func (s *BackupManager) backupBeacon(name string, finalView *BeaconBestState) {
+func (s *BackupManager) backupBeacon(name string, finalView *BeaconBestState, parentView *BeaconBestState) {
 	consensusDB := finalView.GetBeaconConsensusStateDB()
 	featureDB := finalView.GetBeaconFeatureStateDB()
 	rewardDB := finalView.GetBeaconRewardStateDB()
 	slashDB := finalView.GetBeaconSlashStateDB()
 
-	beaconStateDB, _ := incdb.Open("leveldb", path.Join(name, "beacon"))
+	beaconStateDB, _ := incdb.Open(config.Config().DatabaseDriver, path.Join(name, "beacon"))
 
 	wg := sync.WaitGroup{}
 	wg.Add(4)
 
-	go backupStateDB(consensusDB, beaconStateDB, &wg)
-	go backupStateDB(featureDB, beaconStateDB, &wg)
-	go backupStateDB(rewardDB, beaconStateDB, &wg)
-	go backupStateDB(slashDB, beaconStateDB, &wg)
+	parentRoot := BeaconRootHash{}
+	if parentView != nil {
+		parentRoot = BeaconRootHash{
+			ConsensusStateDBRootHash: parentView.ConsensusStateDBRootHash,
+			FeatureStateDBRootHash:   parentView.FeatureStateDBRootHash,
+			RewardStateDBRootHash:    parentView.RewardStateDBRootHash,
+			SlashStateDBRootHash:     parentView.SlashStateDBRootHash,
+		}
+	}
+	go backupStateDB(consensusDB, parentRoot.ConsensusStateDBRootHash, beaconStateDB, &wg)
+	go backupStateDB(featureDB, parentRoot.FeatureStateDBRootHash, beaconStateDB, &wg)
+	go backupStateDB(rewardDB, parentRoot.RewardStateDBRootHash, beaconStateDB, &wg)
+	go backupStateDB(slashDB, parentRoot.SlashStateDBRootHash, beaconStateDB, &wg)
 
 	//store beacon finalview
 	allViews := []*BeaconBestState{finalView}
@@ -321,6 +387,8 @@ func (s *BackupManager) backupBeacon(name string, finalView *BeaconBestState) {
 	wg.Wait()
 }
 
+// backupBeaconBlock copies the finalized beacon blocks from fromBlock into the
+// flat file of the checkpoint, the frozen ones included, see backupShardBlock
 func (s *BackupManager) backupBeaconBlock(name string, fromBlock uint64, finalView *BeaconBestState) {
 	blockStorage := NewBlockStorage(nil, path.Join(name, "beacon", "blockstorage"), -1, true)
 	committeeFromBlock := map[byte]map[common.Hash]bool{}
@@ -383,13 +451,23 @@ func recheck(stateDB incdb.Database, roothash common.Hash) {
 	}
 }
 
-func backupStateDB(stateDB *statedb.StateDB, kvDB incdb.Database, wg *sync.WaitGroup) {
+// backupStateDB copies the trie nodes of stateDB into kvDB. With a non empty
+// parentRoot, only the nodes which are not part of the parent trie are copied
+// (incremental backup), the copy cannot be rechecked on its own then.
+func backupStateDB(stateDB *statedb.StateDB, parentRoot common.Hash, kvDB incdb.Database, wg *sync.WaitGroup) {
 	defer wg.Done()
-	it := stateDB.GetIterator()
-	batchData := kvDB.NewBatch()
 	if stateDB == nil {
 		return
 	}
+	it := stateDB.GetIterator()
+	if !parentRoot.IsEqual(&common.Hash{}) {
+		diffIt, err := stateDB.GetDiffIterator(parentRoot)
+		if err != nil {
+			panic(err)
+		}
+		it = diffIt
+	}
+	batchData := kvDB.NewBatch()
 	for it.Next(false, true, true) {
 		diskvalue, err := stateDB.Database().TrieDB().DiskDB().Get(it.Key)
 		if err != nil {
@@ -417,5 +495,7 @@ func backupStateDB(stateDB *statedb.StateDB, kvDB incdb.Database, wg *sync.WaitG
 	if err != nil {
 		panic(err)
 	}
-	recheck(kvDB, rootHash)
+	if parentRoot.IsEqual(&common.Hash{}) {
+		recheck(kvDB, rootHash)
+	}
 }
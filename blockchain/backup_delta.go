@@ -0,0 +1,321 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/flatfile"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
+)
+
+const backupManifestFile = "manifest"
+
+// BackupManifest is stored in every checkpoint folder. A full backup has no
+// parent, an incremental backup only holds the trie nodes and blocks added
+// since its parent and must be applied on top of every checkpoint of Chain.
+type BackupManifest struct {
+	CheckpointName  string
+	Parent          string
+	Chain           []string
+	MinBeaconHeight uint64
+
+	BeaconHeight    uint64
+	BeaconBlockHash common.Hash
+	BeaconRoot      BeaconRootHash
+	ShardHeight     map[int]uint64
+	ShardBlockHash  map[int]common.Hash
+	ShardRoot       map[int]ShardRootHash
+}
+
+// NewBackupManifest returns the manifest of a finished backup
+func NewBackupManifest(info *BackupProcessInfo) *BackupManifest {
+	manifest := &BackupManifest{
+		CheckpointName:  info.CheckpointName,
+		Parent:          info.Parent,
+		Chain:           info.GetChain(),
+		MinBeaconHeight: info.MinBeaconHeight,
+		BeaconHeight:    info.BeaconView.BeaconHeight,
+		BeaconBlockHash: info.BeaconView.BestBlockHash,
+		BeaconRoot: BeaconRootHash{
+			ConsensusStateDBRootHash: info.BeaconView.ConsensusStateDBRootHash,
+			FeatureStateDBRootHash:   info.BeaconView.FeatureStateDBRootHash,
+			RewardStateDBRootHash:    info.BeaconView.RewardStateDBRootHash,
+			SlashStateDBRootHash:     info.BeaconView.SlashStateDBRootHash,
+		},
+		ShardHeight:    map[int]uint64{},
+		ShardBlockHash: map[int]common.Hash{},
+		ShardRoot:      map[int]ShardRootHash{},
+	}
+	for sid, view := range info.ShardView {
+		manifest.ShardHeight[sid] = view.ShardHeight
+		manifest.ShardBlockHash[sid] = view.BestBlockHash
+		manifest.ShardRoot[sid] = ShardRootHash{
+			ConsensusStateDBRootHash:   view.ConsensusStateDBRootHash,
+			TransactionStateDBRootHash: view.TransactionStateDBRootHash,
+			FeatureStateDBRootHash:     view.FeatureStateDBRootHash,
+			RewardStateDBRootHash:      view.RewardStateDBRootHash,
+			SlashStateDBRootHash:       view.SlashStateDBRootHash,
+		}
+	}
+	return manifest
+}
+
+func writeBackupManifest(backupPath string, info *BackupProcessInfo) error {
+	b, err := json.Marshal(NewBackupManifest(info))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path.Join(backupPath, backupManifestFile), b, 0666)
+}
+
+// ReadBackupManifest reads the manifest of a checkpoint folder
+func ReadBackupManifest(backupPath string) (*BackupManifest, error) {
+	b, err := ioutil.ReadFile(path.Join(backupPath, backupManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &BackupManifest{}
+	if err := json.Unmarshal(b, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// isInLastBackupChain reports whether the checkpoint is needed to restore the last backup
+func (s *BackupManager) isInLastBackupChain(checkpoint string) bool {
+	if s.lastBackup == nil {
+		return false
+	}
+	for _, name := range s.lastBackup.GetChain() {
+		if name == checkpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// getDeltaParent returns the backup the next backup can be taken on top of, or
+// nil when a full backup is needed: incremental backup is disabled, the chain
+// of deltas is too long, or the state of the last backup is not in the
+// database anymore (pruned)
+func (s *BackupManager) getDeltaParent() *BackupProcessInfo {
+	cfg := config.Config()
+	parent := s.lastBackup
+	if cfg.BackupMaxDelta <= 0 || parent == nil || parent.BeaconView == nil {
+		return nil
+	}
+	if int64(len(parent.GetChain())) > cfg.BackupMaxDelta {
+		return nil
+	}
+	if len(parent.ShardView) != s.blockchain.GetActiveShardNumber() {
+		return nil
+	}
+	for _, checkpoint := range parent.GetChain() {
+		if _, err := os.Stat(path.Join(cfg.DataDir, cfg.DatabaseDir, checkpoint, "done")); err != nil {
+			return nil
+		}
+	}
+
+	roots := []common.Hash{
+		parent.BeaconView.ConsensusStateDBRootHash,
+		parent.BeaconView.FeatureStateDBRootHash,
+		parent.BeaconView.RewardStateDBRootHash,
+		parent.BeaconView.SlashStateDBRootHash,
+	}
+	if !hasStateRoots(s.blockchain.GetBeaconChainDatabase(), roots) {
+		Logger.log.Info("Parent backup beacon state is not available, take full backup")
+		return nil
+	}
+	for sid, view := range parent.ShardView {
+		roots := []common.Hash{
+			view.ConsensusStateDBRootHash,
+			view.TransactionStateDBRootHash,
+			view.FeatureStateDBRootHash,
+			view.RewardStateDBRootHash,
+		}
+		if !hasStateRoots(s.blockchain.GetShardChainDatabase(byte(sid)), roots) {
+			Logger.log.Infof("Parent backup shard %v state is not available, take full backup", sid)
+			return nil
+		}
+	}
+	return parent
+}
+
+func hasStateRoots(db incdb.Database, roots []common.Hash) bool {
+	for _, root := range roots {
+		if root.IsEqual(&common.Hash{}) {
+			continue
+		}
+		if _, err := statedb.NewWithPrefixTrie(root, statedb.NewDatabaseAccessWarper(db)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// backupFetcher downloads one database (state, blockKV or block) of a checkpoint
+// into dir, it is remoteRPCClient.SyncDB for a remote backup node
+type backupFetcher func(checkpoint string, cid int, dbType string, offset uint64, dir string) error
+
+// syncBackupChain downloads the full backup chain[0] into the chain folder dir,
+// its blocks from the flat file segment holding fromBlock, then applies the
+// incremental backups of chain in order
+func syncBackupChain(fetch backupFetcher, chain []string, cid int, fromBlock uint64, dir string) error {
+	if len(chain) == 0 {
+		return fmt.Errorf("empty backup chain")
+	}
+	if err := os.MkdirAll(path.Join(dir, "blockstorage", "blockKV"), 0776); err != nil {
+		return err
+	}
+	if err := fetch(chain[0], cid, "state", 0, dir); err != nil {
+		return err
+	}
+	if err := fetch(chain[0], cid, "blockKV", 0, path.Join(dir, "blockstorage", "blockKV")); err != nil {
+		return err
+	}
+	if err := fetch(chain[0], cid, "block", fromBlock, path.Join(dir, "blockstorage")); err != nil {
+		return err
+	}
+	return syncBackupDeltas(fetch, chain, cid, dir)
+}
+
+// syncBackupDeltas downloads the incremental backups of chain (the full backup
+// excluded) one by one and applies them on top of the chain folder tmpDir
+func syncBackupDeltas(fetch backupFetcher, chain []string, cid int, tmpDir string) error {
+	deltaDir := tmpDir + ".delta"
+	defer os.RemoveAll(deltaDir)
+	for _, checkpoint := range chain[1:] {
+		if err := os.RemoveAll(deltaDir); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(path.Join(deltaDir, "blockstorage", "blockKV"), 0776); err != nil {
+			return err
+		}
+		Logger.log.Infof("Apply incremental backup %v on chain %v", checkpoint, cid)
+		if err := fetch(checkpoint, cid, "state", 0, deltaDir); err != nil {
+			return err
+		}
+		if err := fetch(checkpoint, cid, "blockKV", 0, path.Join(deltaDir, "blockstorage", "blockKV")); err != nil {
+			return err
+		}
+		if err := fetch(checkpoint, cid, "block", 0, path.Join(deltaDir, "blockstorage")); err != nil {
+			return err
+		}
+		if err := ApplyBackupDelta(tmpDir, deltaDir, cid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyBackupDelta merges the chain folder of an incremental backup (state
+// database, blockstorage/blockKV and flat file) into the restored chain folder
+// of its parent. Trie nodes are content addressed so the state keys are simply
+// copied, blocks are appended to the flat file and indexed again.
+func ApplyBackupDelta(chainDir, deltaDir string, cid int) error {
+	if err := mergeDatabase(chainDir, deltaDir, nil); err != nil {
+		return fmt.Errorf("merge state: %v", err)
+	}
+
+	dstFF, err := flatfile.NewFlatFileWithCompression(path.Join(chainDir, "blockstorage"), BlockFlatFileSize, ffCompression())
+	if err != nil {
+		return err
+	}
+	srcFF, err := flatfile.NewFlatFile(path.Join(deltaDir, "blockstorage"), BlockFlatFileSize)
+	if err != nil {
+		return err
+	}
+	dstDB, err := openBackupDB(path.Join(chainDir, "blockstorage", "blockKV"))
+	if err != nil {
+		return err
+	}
+	defer dstDB.Close()
+	srcDB, err := openBackupDB(path.Join(deltaDir, "blockstorage", "blockKV"))
+	if err != nil {
+		return err
+	}
+	defer srcDB.Close()
+	if err := repairFlatFileTail(dstFF, dstDB, cid); err != nil {
+		return err
+	}
+
+	blocks, err := rawdbv2.GetAllFlatFileIndex(srcDB)
+	if err != nil {
+		return err
+	}
+	indexes := []uint64{}
+	for index := range blocks {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+	for _, index := range indexes {
+		data, err := srcFF.Read(index)
+		if err != nil {
+			return err
+		}
+		ffIndex, err := dstFF.Append(data)
+		if err != nil {
+			return err
+		}
+		if err := rawdbv2.StoreFlatFileIndexByBlockHash(dstDB, blocks[index], ffIndex); err != nil {
+			return err
+		}
+	}
+
+	return copyDatabase(dstDB, srcDB, rawdbv2.IsFlatFileIndexKey)
+}
+
+// openBackupDB opens a database of a checkpoint folder with the driver it was
+// written with, which may differ from the configured one (offline tools)
+func openBackupDB(dbPath string) (incdb.Database, error) {
+	return incdb.Open(pebbledb.DetectDriver(dbPath, dbDriver()), dbPath)
+}
+
+// mergeDatabase copies every key of the database at srcPath into the database
+// at dstPath, except the keys matched by skip
+func mergeDatabase(dstPath, srcPath string, skip func([]byte) bool) error {
+	dstDB, err := openBackupDB(dstPath)
+	if err != nil {
+		return err
+	}
+	defer dstDB.Close()
+	srcDB, err := openBackupDB(srcPath)
+	if err != nil {
+		return err
+	}
+	defer srcDB.Close()
+	return copyDatabase(dstDB, srcDB, skip)
+}
+
+func copyDatabase(dstDB, srcDB incdb.Database, skip func([]byte) bool) error {
+	it := srcDB.NewIterator()
+	defer it.Release()
+	batch := dstDB.NewBatch()
+	for it.Next() {
+		if skip != nil && skip(it.Key()) {
+			continue
+		}
+		if err := batch.Put(common.CopyBytes(it.Key()), common.CopyBytes(it.Value())); err != nil {
+			return err
+		}
+		if batch.ValueSize() > 5*1024*1024 {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
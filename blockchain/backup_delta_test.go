@@ -0,0 +1,150 @@
+package blockchain
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain/types"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	_ "github.com/levietcuong2602/incognito-chain/incdb/lvdb"
+	"github.com/stretchr/testify/assert"
+)
+
+// localBackupFetcher serves the checkpoint folders of a backup node from disk,
+// the way handleGetBootstrapStateDB streams them
+func localBackupFetcher(backupDir string) backupFetcher {
+	return func(checkpoint string, cid int, dbType string, offset uint64, dir string) error {
+		folder := path.Join(backupDir, checkpoint, "beacon")
+		switch dbType {
+		case "blockKV":
+			folder = path.Join(folder, "blockstorage", "blockKV")
+		case "block":
+			folder = path.Join(folder, "blockstorage")
+		}
+		files, err := ioutil.ReadDir(folder)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+			if dbType == "block" {
+				if id, err := strconv.Atoi(file.Name()); err == nil && uint64(id) < offset/BlockFlatFileSize {
+					continue
+				}
+			}
+			if err := copyFileTo(path.Join(folder, file.Name()), path.Join(dir, file.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func copyFileTo(src, dst string) error {
+	b, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dst, b, 0666)
+}
+
+func storeTestBeaconBlocks(t *testing.T, dir string, from, to uint64) []common.Hash {
+	blockStorage := NewBlockStorage(nil, path.Join(dir, "blockstorage"), -1, true)
+	defer blockStorage.blockStorageDB.Close()
+	hashes := []common.Hash{}
+	for height := from; height <= to; height++ {
+		blk := types.NewBeaconBlock()
+		blk.Header.Height = height
+		assert.Nil(t, blockStorage.StoreBlock(blk))
+		assert.Nil(t, blockStorage.StoreFinalizedBeaconBlock(height, *blk.Hash()))
+		hashes = append(hashes, *blk.Hash())
+	}
+	return hashes
+}
+
+func backupTestState(t *testing.T, stateDB *statedb.StateDB, parentRoot common.Hash, dir string) {
+	kvDB, err := incdb.Open("leveldb", dir)
+	assert.Nil(t, err)
+	defer kvDB.Close()
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	backupStateDB(stateDB, parentRoot, kvDB, &wg)
+	wg.Wait()
+}
+
+func TestSyncBackupChain(t *testing.T) {
+	config.AbortParam()
+	dir, err := ioutil.TempDir("", "backupchain")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	liveDB, err := incdb.Open("leveldb", path.Join(dir, "live"))
+	assert.Nil(t, err)
+	defer liveDB.Close()
+	stateDB, err := statedb.NewWithPrefixTrie(common.EmptyRoot, statedb.NewDatabaseAccessWarper(liveDB))
+	assert.Nil(t, err)
+
+	//full backup
+	assert.Nil(t, statedb.StoreSerialNumbers(stateDB, common.PRVCoinID, [][]byte{[]byte("sn-base")}, 0))
+	baseRoot, err := stateDB.Commit(true)
+	assert.Nil(t, err)
+	assert.Nil(t, stateDB.Database().TrieDB().Commit(baseRoot, false))
+	baseDir := path.Join(dir, "backup", "base", "beacon")
+	backupTestState(t, stateDB, common.Hash{}, baseDir)
+	blocks := storeTestBeaconBlocks(t, baseDir, 1, 3)
+
+	//two incremental backups on top of it
+	parentRoot := baseRoot
+	for i, checkpoint := range []string{"delta1", "delta2"} {
+		sn := []byte("sn-" + checkpoint)
+		assert.Nil(t, statedb.StoreSerialNumbers(stateDB, common.PRVCoinID, [][]byte{sn}, 0))
+		root, err := stateDB.Commit(true)
+		assert.Nil(t, err)
+		assert.Nil(t, stateDB.Database().TrieDB().Commit(root, false))
+		deltaDir := path.Join(dir, "backup", checkpoint, "beacon")
+		backupTestState(t, stateDB, parentRoot, deltaDir)
+		from := uint64(4 + 2*i)
+		blocks = append(blocks, storeTestBeaconBlocks(t, deltaDir, from, from+1)...)
+		parentRoot = root
+	}
+	finalRoot := parentRoot
+
+	restoreDir := path.Join(dir, "restore")
+	chain := BackupProcessInfo{CheckpointName: "delta2", Parent: "delta1", Chain: []string{"base", "delta1", "delta2"}}.GetChain()
+	assert.Nil(t, syncBackupChain(localBackupFetcher(path.Join(dir, "backup")), chain, -1, 0, restoreDir))
+
+	restoredDB, err := incdb.Open("leveldb", restoreDir)
+	assert.Nil(t, err)
+	restoredState, err := statedb.NewWithPrefixTrie(finalRoot, statedb.NewDatabaseAccessWarper(restoredDB))
+	assert.Nil(t, err)
+	assert.Nil(t, restoredState.Recheck())
+	for _, sn := range []string{"sn-base", "sn-delta1", "sn-delta2"} {
+		has, err := statedb.HasSerialNumber(restoredState, common.PRVCoinID, []byte(sn), 0)
+		assert.Nil(t, err)
+		assert.True(t, has, sn)
+	}
+	restoredDB.Close()
+
+	blockStorage := NewBlockStorage(nil, path.Join(restoreDir, "blockstorage"), -1, true)
+	defer blockStorage.blockStorageDB.Close()
+	for i, hash := range blocks {
+		blk, _, err := blockStorage.GetBlock(hash)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(i+1), blk.GetHeight())
+		finalized, err := blockStorage.GetFinalizedBeaconBlock(uint64(i + 1))
+		assert.Nil(t, err)
+		assert.Equal(t, hash, *finalized)
+	}
+
+	//a missing checkpoint of the chain fails the restore
+	assert.NotNil(t, syncBackupChain(localBackupFetcher(path.Join(dir, "backup")), []string{"base", "missing"}, -1, 0, path.Join(dir, "restore2")))
+}
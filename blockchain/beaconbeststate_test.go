@@ -841,6 +841,7 @@ func TestBeaconBestState_GetNonSlashingCommittee(t *testing.T) {
 func TestBeaconBestState_GetFinishSyncingValidators(t *testing.T) {
 
 	beaconCommitteeStateMocks1 := &externalmocks.BeaconCommitteeState{}
+	beaconCommitteeStateMocks1.On("Version").Return(committeestate.STAKING_FLOW_V3)
 	beaconCommitteeStateMocks1.On("GetSyncingValidators").Return(
 		map[byte][]incognitokey.CommitteePublicKey{
 			0: []incognitokey.CommitteePublicKey{},
@@ -848,6 +849,7 @@ func TestBeaconBestState_GetFinishSyncingValidators(t *testing.T) {
 	)
 
 	beaconCommitteeStateMocks2 := &externalmocks.BeaconCommitteeState{}
+	beaconCommitteeStateMocks2.On("Version").Return(committeestate.STAKING_FLOW_V3)
 	beaconCommitteeStateMocks2.On("GetSyncingValidators").Return(
 		map[byte][]incognitokey.CommitteePublicKey{
 			0: incognitoKeys[:5],
@@ -855,6 +857,7 @@ func TestBeaconBestState_GetFinishSyncingValidators(t *testing.T) {
 	)
 
 	beaconCommitteeStateMocks3 := &externalmocks.BeaconCommitteeState{}
+	beaconCommitteeStateMocks3.On("Version").Return(committeestate.STAKING_FLOW_V3)
 	beaconCommitteeStateMocks3.On("GetSyncingValidators").Return(
 		map[byte][]incognitokey.CommitteePublicKey{
 			0: incognitoKeys[3:5],
@@ -862,6 +865,7 @@ func TestBeaconBestState_GetFinishSyncingValidators(t *testing.T) {
 	)
 
 	beaconCommitteeStateMocks4 := &externalmocks.BeaconCommitteeState{}
+	beaconCommitteeStateMocks4.On("Version").Return(committeestate.STAKING_FLOW_V3)
 	beaconCommitteeStateMocks4.On("GetSyncingValidators").Return(
 		map[byte][]incognitokey.CommitteePublicKey{
 			0: incognitoKeys,
@@ -869,6 +873,7 @@ func TestBeaconBestState_GetFinishSyncingValidators(t *testing.T) {
 	)
 
 	beaconCommitteeStateMocks5 := &externalmocks.BeaconCommitteeState{}
+	beaconCommitteeStateMocks5.On("Version").Return(committeestate.STAKING_FLOW_V3)
 	beaconCommitteeStateMocks5.On("GetSyncingValidators").Return(
 		map[byte][]incognitokey.CommitteePublicKey{
 			0: incognitoKeys,
@@ -41,6 +41,7 @@ func NewBeaconChain(multiView multiview.MultiView, blockGen *BlockGenerator, blo
 	cfg := config.Config()
 	ffPath := path.Join(cfg.DataDir, cfg.DatabaseDir, "beacon", "blockstorage")
 	bs := NewBlockStorage(blockchain.GetBeaconChainDatabase(), ffPath, -1, false)
+	bs.openAncientStore("beacon")
 	chain := &BeaconChain{
 		multiView:           multiView,
 		BlockGen:            blockGen,
@@ -75,6 +76,10 @@ func (chain *BeaconChain) SetMultiView(multiView multiview.MultiView) {
 	chain.multiView = multiView
 }
 
+func (chain *BeaconChain) GetInsertLock() *sync.Mutex {
+	return &chain.insertLock
+}
+
 func (chain *BeaconChain) GetBestView() multiview.View {
 	return chain.multiView.GetBestView()
 }
@@ -178,6 +178,7 @@ func (blockchain *BlockChain) InsertBeaconBlock(beaconBlock *types.BeaconBlock,
 	if err2 := blockchain.processStoreBeaconBlock(curView, newBestState, beaconBlock, committeeChange); err2 != nil {
 		return err2
 	}
+	blockchain.config.Server.InsertNewBeaconView(newBestState)
 
 	Logger.log.Infof("BEACON | Finish Insert new Beacon Block %+v, with hash %+v", beaconBlock.Header.Height, *beaconBlock.Hash())
 
@@ -348,6 +349,12 @@ func (blockchain *BlockChain) verifyPreProcessingBeaconBlockForSigning(curView *
 	enableFeatureInstructions := filterEnableFeatureInstruction(beaconBlock.Body.Instructions)
 	instructions = append(instructions, enableFeatureInstructions...)
 
+	equivocationInstructions, err := curView.filterAndVerifyEquivocationInstructions(beaconBlock.Body.Instructions)
+	if err != nil {
+		return NewBlockChainError(EquivocationInstructionError, err)
+	}
+	instructions = append(instructions, equivocationInstructions...)
+
 	if len(incurredInstructions) != 0 {
 		instructions = append(instructions, incurredInstructions...)
 	}
@@ -773,6 +780,7 @@ func (beaconBestState *BeaconBestState) initBeaconBestState(genesisBeaconBlock *
 	if err != nil {
 		return err
 	}
+	beaconBestState.attachStateSnapshots(blockchain)
 	beaconBestState.ConsensusStateDBRootHash = common.EmptyRoot
 	beaconBestState.SlashStateDBRootHash = common.EmptyRoot
 	beaconBestState.RewardStateDBRootHash = common.EmptyRoot
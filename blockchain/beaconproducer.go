@@ -124,6 +124,9 @@ func (blockchain *BlockChain) NewBlockBeacon(
 	enableFeatureInstructions, _ := copiedCurView.generateEnableFeatureInstructions()
 	instructions = append(instructions, enableFeatureInstructions...)
 
+	equivocationInstructions := copiedCurView.generateEquivocationInstructions()
+	instructions = append(instructions, equivocationInstructions...)
+
 	newBeaconBlock.Body = types.NewBeaconBody(shardStates, instructions)
 
 	// Process new block with new view
@@ -122,6 +122,7 @@ func TestBeaconBestState_preProcessInstructionsFromShardBlock(t *testing.T) {
 
 	initPublicKey()
 	initLog()
+	sDB, _ := statedb.NewWithPrefixTrie(emptyRoot, warperDBStatedbTest)
 
 	tx, _ := common.Hash{}.NewHashFromStr("123")
 	paymentAddress0, err := wallet.Base58CheckDeserialize(paymentAddreessKey0)
@@ -573,6 +574,9 @@ func TestBeaconBestState_preProcessInstructionsFromShardBlock(t *testing.T) {
 				slashStateDB:             tt.fields.slashStateDB,
 				SlashStateDBRootHash:     tt.fields.SlashStateDBRootHash,
 			}
+			if beaconBestState.consensusStateDB == nil {
+				beaconBestState.consensusStateDB = sDB
+			}
 			got := beaconBestState.preProcessInstructionsFromShardBlock(tt.args.instructions, tt.args.shardID)
 			for i, v := range got.shardStakeInstructions {
 				if !reflect.DeepEqual(v, tt.want.shardStakeInstructions[i]) {
@@ -42,7 +42,7 @@ func TestBlockChain_buildStatefulInstructions(t *testing.T) {
 	config.Param().EthContractAddressStr = "0x7bebc8445c6223b41b7bb4b0ae9742e2fd2f47f3"
 	config.AbortUnifiedToken()
 	common.MaxShardNumber = 8
-	view := multiview.NewMultiView()
+	view := multiview.NewShardMultiView()
 	view.AddView(&BeaconBestState{
 		BestBlock: types.BeaconBlock{
 			Header: types.BeaconHeader{
@@ -50,13 +50,15 @@ func TestBlockChain_buildStatefulInstructions(t *testing.T) {
 			},
 		},
 	})
-	temp := map[uint64]map[common.Hash]map[uint]config.Vault{
+	unifiedTokenID, _ := common.Hash{}.NewHashFromStr("0000000000000000000000000000000000000000000000000000000000000100")
+	incTokenID, _ := common.Hash{}.NewHashFromStr("375825bf838527610102c6943282642826901937679d8df5b5634d43d54a5769")
+	temp := map[uint64]map[common.Hash]map[common.Hash]config.Vault{
 		10: {
-			common.PRVCoinID: map[uint]config.Vault{
-				1: {
+			*unifiedTokenID: map[common.Hash]config.Vault{
+				*incTokenID: {
 					ExternalDecimal: 9,
 					ExternalTokenID: "0x0000000000000000000000000000000000000001",
-					IncTokenID:      "375825bf838527610102c6943282642826901937679d8df5b5634d43d54a5769",
+					NetworkID:       1,
 				},
 			},
 		},
@@ -80,7 +82,7 @@ func TestBlockChain_buildStatefulInstructions(t *testing.T) {
 		rewardForCustodianByEpoch map[common.Hash]uint64
 		portalParams              portal.PortalParams
 		shardStates               map[byte][]types.ShardState
-		allPdexv3Txs              map[byte][]metadata.Transaction
+		allPdexv3Txs              map[uint]map[byte][]metadata.Transaction
 		pdexReward                uint64
 	}
 	tests := []struct {
@@ -123,11 +125,14 @@ func TestBlockChain_buildStatefulInstructions(t *testing.T) {
 			args: args{
 				beaconHeight: 10,
 				beaconBestState: &BeaconBestState{
-					pdeStates:      map[uint]pdex.State{},
-					featureStateDB: sDB,
-					portalStateV3:  &portalprocessv3.CurrentPortalState{},
-					portalStateV4:  &portalprocessv4.CurrentPortalStateV4{},
-					bridgeAggState: bridgeagg.NewState(),
+					pdeStates:        map[uint]pdex.State{},
+					featureStateDB:   sDB,
+					portalStateV3:    &portalprocessv3.CurrentPortalState{},
+					portalStateV4:    &portalprocessv4.CurrentPortalStateV4{},
+					bridgeAggManager: bridgeagg.NewManager(),
+					TriggeredFeature: map[string]uint64{
+						"unified_token_10": 9,
+					},
 				},
 				portalParams: portal.PortalParams{
 					RelayingParam:  portalrelaying.RelayingParams{},
@@ -147,8 +152,8 @@ func TestBlockChain_buildStatefulInstructions(t *testing.T) {
 			},
 			want: [][]string{
 				{
-					"348",
-					"eyJOZXdMaXN0VG9rZW5zIjp7IjAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDQiOnsiMSI6eyJFeHRlcm5hbERlY2ltYWwiOjksIkV4dGVybmFsVG9rZW5JRCI6IjB4MDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMSIsIkluY1Rva2VuSUQiOiIzNzU4MjViZjgzODUyNzYxMDEwMmM2OTQzMjgyNjQyODI2OTAxOTM3Njc5ZDhkZjViNTYzNGQ0M2Q1NGE1NzY5In19fX0=",
+					"347",
+					"eyJOZXdMaXN0VG9rZW5zIjp7IjAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAxMDAiOnsiMzc1ODI1YmY4Mzg1Mjc2MTAxMDJjNjk0MzI4MjY0MjgyNjkwMTkzNzY3OWQ4ZGY1YjU2MzRkNDNkNTRhNTc2OSI6eyJFeHRlcm5hbERlY2ltYWwiOjksIkV4dGVybmFsVG9rZW5JRCI6IjB4MDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMSIsIk5ldHdvcmtJRCI6MX19fX0=",
 				},
 				{
 					"80", "0", "rejected", "13e56a4572aa7eeb5e7f658a8af036ed3ff8a1285452f30c741f4733bc8c7f9c",
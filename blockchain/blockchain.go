@@ -53,6 +53,7 @@ type BlockChain struct {
 	beaconViewCache             *lru.Cache
 	committeeByEpochCache       *lru.Cache
 	committeeByEpochProcessLock sync.Mutex
+	stateSnapshots              stateSnapshots
 }
 
 // Config is a descriptor which specifies the blockchain instblockchain/beaconstatefulinsts.goance configuration.
@@ -220,6 +221,7 @@ func (blockchain *BlockChain) InitChainState() error {
 			nil,
 		)
 		tp.UpdateTxVerifier(tv)
+		tp.UpdateViewGetter(blockchain.txPoolViewGetter(shardID))
 		blockchain.ShardChain[shardID] = NewShardChain(int(shardID), multiview.NewShardMultiView(), blockchain.config.BlockGen, blockchain, common.GetShardChainKey(shardID), tp, tv)
 		blockchain.ShardChain[shardID].hashHistory, err = lru.New(1000)
 		if err != nil {
@@ -256,6 +258,9 @@ func (blockchain *BlockChain) InitChainState() error {
 		Logger.log.Infof("Init Shard View shardID %+v, height %+v", shardID, blockchain.ShardChain[shardID].GetFinalViewHeight())
 	}
 
+	if config.Config().AncientBlockDepth > 0 {
+		go blockchain.freezeAncientBlocks()
+	}
 	return nil
 }
 
@@ -673,6 +678,7 @@ func (blockchain *BlockChain) RestoreBeaconViews() error {
 		if err := v.RestoreBeaconViewStateFromHash(blockchain, true, includePdexv3, true); err != nil {
 			return NewBlockChainError(BeaconError, err)
 		}
+		v.attachStateSnapshots(blockchain)
 		if v.NumberOfFixedShardBlockValidator == 0 {
 			v.NumberOfFixedShardBlockValidator = config.Param().CommitteeSize.NumberOfFixedShardBlockValidator
 		}
@@ -759,6 +765,7 @@ func (blockchain *BlockChain) RestoreShardViews(shardID byte) error {
 		if err != nil {
 			panic(err)
 		}
+		v.attachStateSnapshots(blockchain)
 
 		version := committeestate.VersionByBeaconHeight(v.BeaconHeight,
 			config.Param().ConsensusParam.StakingFlowV2Height,
@@ -1073,6 +1080,10 @@ func GetFirstBeaconHeightInEpoch(epoch uint64) uint64 {
 }
 
 func (bc *BlockChain) GetLastBeaconHeightInEpoch(epoch uint64) uint64 {
+	return GetLastBeaconHeightInEpoch(epoch)
+}
+
+func GetLastBeaconHeightInEpoch(epoch uint64) uint64 {
 	params := config.Param()
 	if epoch < params.EpochParam.EpochV2BreakPoint {
 		return epoch * params.EpochParam.NumberOfBlockInEpoch
@@ -1230,6 +1241,19 @@ func (bc *BlockChain) GetAllCommitteeStakeInfoSlashingVersion(epoch uint64) (map
 	return statedb.GetAllCommitteeStakeInfoSlashingVersion(beaconConsensusStateDB, allCommitteeState), nil
 }
 
+// txPoolViewGetter returns the best views of shardID the txs of its pool are
+// validated with
+func (blockchain *BlockChain) txPoolViewGetter(shardID byte) txpool.ViewGetter {
+	return func() (metadata.ChainRetriever, metadata.ShardViewRetriever, metadata.BeaconViewRetriever, error) {
+		sBestView := blockchain.ShardChain[shardID].GetBestState()
+		bcView, err := blockchain.GetBeaconViewStateDataFromBlockHash(sBestView.GetBeaconHash(), true, false, false)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return blockchain, sBestView, bcView, nil
+	}
+}
+
 func (blockchain *BlockChain) GetPoolManager() *txpool.PoolManager {
 	return blockchain.config.PoolManager
 }
@@ -1280,14 +1304,14 @@ func (blockChain *BlockChain) ReloadDatabase(cid int) (err error) {
 	switch cid {
 	case -1:
 		blockChain.config.DataBase[cid].Close()
-		blockChain.config.DataBase[cid], err = incdb.Open("leveldb", path.Join(cfg.DataDir, cfg.DatabaseDir, "beacon"))
+		blockChain.config.DataBase[cid], err = incdb.Open(cfg.DatabaseDriver, path.Join(cfg.DataDir, cfg.DatabaseDir, "beacon"))
 		if err != nil {
 			return err
 		}
 		blockChain.BeaconChain.BlockStorage.rootDB = blockChain.GetBeaconChainDatabase()
 	default:
 		blockChain.config.DataBase[cid].Close()
-		blockChain.config.DataBase[cid], err = incdb.Open("leveldb", path.Join(cfg.DataDir, cfg.DatabaseDir, fmt.Sprintf("shard%v", cid)))
+		blockChain.config.DataBase[cid], err = incdb.Open(cfg.DatabaseDriver, path.Join(cfg.DataDir, cfg.DatabaseDir, fmt.Sprintf("shard%v", cid)))
 		if err != nil {
 			return err
 		}
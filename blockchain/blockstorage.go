@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"github.com/levietcuong2602/incognito-chain/blockchain/types"
 	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
 	"github.com/levietcuong2602/incognito-chain/dataaccessobject/flatfile"
 	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
 	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
 	"github.com/levietcuong2602/incognito-chain/metadata"
 	"github.com/levietcuong2602/incognito-chain/transaction"
 	"github.com/pkg/errors"
@@ -15,33 +17,138 @@ import (
 	"path"
 )
 
+// BlockFlatFileSize is the number of blocks per flat file segment
+const BlockFlatFileSize = 5000
+
 type BlockStorage struct {
 	rootDB         incdb.Database
 	blockStorageDB incdb.Database
 	flatfile       flatfile.FlatFile
 	cid            int
 	useFF          bool
+	ancient        *flatfile.AncientStore //finalized blocks older than the ancient depth, nil if never enabled
 }
 
 func NewBlockStorage(db incdb.Database, ffPath string, cid int, useFF bool) *BlockStorage {
 	var ff *flatfile.FlatFileManager
 	var blockStorageDB incdb.Database
 
-	ff, _ = flatfile.NewFlatFile(ffPath, 5000)
-	blockStorageDB, _ = incdb.Open("leveldb", path.Join(ffPath, "blockKV"))
+	ff, _ = flatfile.NewFlatFileWithCompression(ffPath, BlockFlatFileSize, ffCompression())
+	blockStorageDB, _ = incdb.Open(dbDriver(), path.Join(ffPath, "blockKV"))
+	if ff != nil && blockStorageDB != nil {
+		if err := repairFlatFileTail(ff, blockStorageDB, cid); err != nil {
+			Logger.log.Errorf("Repair flatfile %v error: %v", ffPath, err)
+		}
+	}
 
 	return &BlockStorage{
-		db, blockStorageDB, ff, cid, useFF,
+		db, blockStorageDB, ff, cid, useFF, nil,
+	}
+}
+
+// OpenBlockStorageReadOnly opens the flat file block storage at ffPath, and the
+// ancient store at ancientDir when there is one, for the offline tools. Nothing
+// is written: the flat file tail is not repaired and the block index database
+// is opened read only, with the driver it was written with
+func OpenBlockStorageReadOnly(ffPath, ancientDir string, cid int) (*BlockStorage, error) {
+	ff, err := flatfile.OpenFlatFileReadOnly(ffPath, BlockFlatFileSize)
+	if err != nil {
+		return nil, err
+	}
+	kvPath := path.Join(ffPath, "blockKV")
+	blockStorageDB, err := incdb.OpenReadOnly(pebbledb.DetectDriver(kvPath, dbDriver()), kvPath)
+	if err != nil {
+		return nil, err
+	}
+	s := &BlockStorage{
+		nil, blockStorageDB, ff, cid, true, nil,
+	}
+	if _, err := os.Stat(ancientDir); ancientDir != "" && err == nil {
+		if s.ancient, err = flatfile.OpenAncientStoreReadOnly(ancientDir); err != nil {
+			blockStorageDB.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Close closes the block index database and the ancient store
+func (s *BlockStorage) Close() error {
+	if s.ancient != nil {
+		if err := s.ancient.Close(); err != nil {
+			return err
+		}
 	}
+	return s.blockStorageDB.Close()
+}
+
+// repairFlatFileTail drops a half written record left at the end of the flat
+// file by a crash, and the block index entries pointing at dropped records so
+// that they are not shadowed by the next appended blocks (they are synced again).
+// Complete records failing their checksum are only reported, they may hold
+// finalized blocks that are never synced again, use checkflatfile to inspect them
+func repairFlatFileTail(ff *flatfile.FlatFileManager, blockStorageDB incdb.Database, cid int) error {
+	report, err := ff.RepairTail()
+	if err != nil {
+		return err
+	}
+	if verify, err := ff.VerifyTail(); err != nil {
+		return err
+	} else if !verify.Healthy() {
+		Logger.log.Errorf("Chain %v flatfile segment %v is corrupted: %v, run checkflatfile",
+			cid, verify.FileID, verify.Error)
+	}
+	if report == nil {
+		return nil
+	}
+	Logger.log.Warnf("Chain %v flatfile segment %v: dropped %v bytes from index %v (%v)",
+		cid, report.FileID, report.DroppedBytes, report.FirstDroppedIndex, report.Reason)
+	removed, err := rawdbv2.DeleteFlatFileIndexFrom(blockStorageDB, report.FirstDroppedIndex)
+	if err != nil {
+		return err
+	}
+	for _, hash := range removed {
+		Logger.log.Warnf("Chain %v block %v dropped from flatfile", cid, hash.String())
+	}
+	return nil
+}
+
+// dbDriver returns the configured chain database driver, falling back to the
+// incdb default when no config is loaded (unit tests)
+func dbDriver() string {
+	if cfg := config.Config(); cfg != nil {
+		return cfg.DatabaseDriver
+	}
+	return incdb.DefaultDriver
+}
+
+// ffCompression returns the configured codec for new flat file segments
+func ffCompression() flatfile.Compression {
+	cfg := config.Config()
+	if cfg == nil {
+		return flatfile.CompressionNone
+	}
+	compression, err := flatfile.ParseCompression(cfg.FlatFileCompression)
+	if err != nil {
+		Logger.log.Errorf("%v, fallback to no compression", err)
+		return flatfile.CompressionNone
+	}
+	return compression
 }
 
 func (s *BlockStorage) ChangeMainDir(tmpDir, mainDir string) error {
 	os.Rename(mainDir, mainDir+".bk")
 	os.Rename(tmpDir, mainDir)
-	blockStorageDB, _ := incdb.Open("leveldb", path.Join(mainDir, "blockstorage", "blockKV"))
+	blockStorageDB, _ := incdb.Open(dbDriver(), path.Join(mainDir, "blockstorage", "blockKV"))
 	s.blockStorageDB = blockStorageDB
-	s.flatfile, _ = flatfile.NewFlatFile(path.Join(mainDir, "blockstorage"), 5000)
+	s.flatfile, _ = flatfile.NewFlatFileWithCompression(path.Join(mainDir, "blockstorage"), BlockFlatFileSize, ffCompression())
 	os.RemoveAll(mainDir + ".bk")
+	//frozen blocks belong to the replaced chain data
+	if s.ancient != nil {
+		if err := s.ancient.Reset(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -195,6 +302,9 @@ func (s *BlockStorage) StoreFinalizedBeaconBlock(index uint64, hash common.Hash)
 
 func (s *BlockStorage) GetFinalizedBeaconBlock(index uint64) (*common.Hash, error) {
 	if s.useFF {
+		if s.ancient != nil && s.ancient.Has(index) {
+			return s.ancient.HashByHeight(index)
+		}
 		return rawdbv2.GetFinalizedBeaconBlockHashByIndex(s.blockStorageDB, index)
 	} else {
 		return rawdbv2.GetFinalizedBeaconBlockHashByIndex(s.rootDB, index)
@@ -216,6 +326,9 @@ func (s *BlockStorage) StoreFinalizedShardBlock(index uint64, hash common.Hash)
 
 func (s *BlockStorage) GetFinalizedShardBlockHashByIndex(index uint64) (*common.Hash, error) {
 	if s.useFF {
+		if s.ancient != nil && s.ancient.Has(index) {
+			return s.ancient.HashByHeight(index)
+		}
 		return rawdbv2.GetFinalizedShardBlockHashByIndex(s.blockStorageDB, byte(s.cid), index)
 	} else {
 		return rawdbv2.GetFinalizedShardBlockHashByIndex(s.rootDB, byte(s.cid), index)
@@ -225,7 +338,10 @@ func (s *BlockStorage) GetFinalizedShardBlockHashByIndex(index uint64) (*common.
 func (s *BlockStorage) IsExisted(blkHash common.Hash) bool {
 	if s.useFF {
 		if _, err := rawdbv2.GetFlatFileIndexByBlockHash(s.blockStorageDB, blkHash); err != nil {
-			return false
+			if s.ancient != nil {
+				_, err = rawdbv2.GetAncientHeightByBlockHash(s.blockStorageDB, blkHash)
+			}
+			return err == nil
 		}
 		return true
 	} else {
@@ -266,7 +382,11 @@ func (s *BlockStorage) decode(data []byte) (types.BlockInterface, error) {
 			panic(err)
 		}
 	}
+	return s.decodeRaw(rawData)
+}
 
+// decodeRaw decodes the json of a block
+func (s *BlockStorage) decodeRaw(rawData []byte) (types.BlockInterface, error) {
 	switch s.cid {
 	case -1:
 		beaconBlock := types.NewBeaconBlock()
@@ -300,10 +420,19 @@ func (s *BlockStorage) storeBlockUsingFF(blk types.BlockInterface) error {
 
 func (s *BlockStorage) getBlockUsingFF(blkHash common.Hash) (types.BlockInterface, int, error) {
 	if ffIndex, err := rawdbv2.GetFlatFileIndexByBlockHash(s.blockStorageDB, blkHash); err != nil {
+		if s.ancient != nil {
+			return s.getAncientBlock(blkHash)
+		}
 		return nil, 0, err
 	} else {
 		data, err := s.flatfile.Read(ffIndex)
 		if err != nil {
+			//the segment may have been dropped by the freezer meanwhile
+			if s.ancient != nil {
+				if blk, size, ancientErr := s.getAncientBlock(blkHash); ancientErr == nil {
+					return blk, size, nil
+				}
+			}
 			return nil, 0, err
 		}
 		blk, err := s.decode(data)
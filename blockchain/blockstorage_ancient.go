@@ -0,0 +1,231 @@
+package blockchain
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain/types"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/flatfile"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/pkg/errors"
+)
+
+// ancientFreezeBatch bounds the number of blocks moved by one Freeze call
+const ancientFreezeBatch = 10000
+
+// ancientStoreDir returns the ancient store folder of a chain (beacon, shard0...)
+func ancientStoreDir(chainDir string) string {
+	cfg := config.Config()
+	if cfg.AncientDir != "" {
+		return path.Join(cfg.AncientDir, chainDir)
+	}
+	return path.Join(cfg.DataDir, cfg.DatabaseDir, chainDir, "ancient")
+}
+
+// openAncientStore opens the ancient store of the chain when the freezer is
+// enabled, or when blocks were frozen before it was disabled
+func (s *BlockStorage) openAncientStore(chainDir string) {
+	dir := ancientStoreDir(chainDir)
+	if config.Config().AncientBlockDepth == 0 {
+		if _, err := os.Stat(dir); err != nil {
+			return
+		}
+	}
+	ancient, err := flatfile.OpenAncientStore(dir)
+	if err != nil {
+		Logger.log.Errorf("Open ancient store %v error: %v", dir, err)
+		return
+	}
+	s.ancient = ancient
+}
+
+// getAncientBlock reads a block moved to the ancient store
+func (s *BlockStorage) getAncientBlock(blkHash common.Hash) (types.BlockInterface, int, error) {
+	height, err := rawdbv2.GetAncientHeightByBlockHash(s.blockStorageDB, blkHash)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := s.ancient.ReadByHeight(height)
+	if err != nil {
+		return nil, 0, err
+	}
+	blk, err := s.decodeRaw(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	return blk, len(data), nil
+}
+
+// Freeze moves the finalized blocks deeper than depth below finalHeight to the
+// ancient store. The block is appended to the ancient segments and its hash to
+// the height table, then its flat file index and finalized index are replaced
+// by its ancient height. Flat file segments left with stale blocks only are
+// removed afterward.
+func (s *BlockStorage) Freeze(finalHeight, depth uint64) error {
+	if !s.useFF || s.ancient == nil || finalHeight <= depth {
+		return nil
+	}
+	_, next := s.ancient.Range()
+	if next == 0 {
+		var err error
+		if next, err = s.lowestFinalizedHeight(finalHeight - depth); err != nil {
+			return err
+		}
+	}
+	moved := uint64(0)
+	for height := next; height <= finalHeight-depth && moved < ancientFreezeBatch; height++ {
+		if err := s.freezeBlock(height); err != nil {
+			return errors.Wrapf(err, "freeze block at height %v", height)
+		}
+		moved++
+	}
+	if moved == 0 {
+		return nil
+	}
+	Logger.log.Infof("Chain %v moved %v blocks to ancient store, up to height %v", s.cid, moved, next+moved-1)
+	return s.dropFrozenSegments(next + moved)
+}
+
+func (s *BlockStorage) freezeBlock(height uint64) error {
+	hash, err := s.getFinalizedHashFromDB(height)
+	if err != nil {
+		return err
+	}
+	ffIndex, err := rawdbv2.GetFlatFileIndexByBlockHash(s.blockStorageDB, *hash)
+	if err != nil {
+		return err
+	}
+	data, err := s.flatfile.Read(ffIndex)
+	if err != nil {
+		return err
+	}
+	rawData, err := common.GZipToBytes(data)
+	if err != nil {
+		return err
+	}
+	if err := s.ancient.Append(height, *hash, rawData); err != nil {
+		return err
+	}
+
+	batch := s.blockStorageDB.NewBatch()
+	if err := rawdbv2.StoreAncientHeightByBlockHash(batch, *hash, height); err != nil {
+		return err
+	}
+	if err := rawdbv2.DeleteFlatFileIndexByBlockHash(batch, *hash); err != nil {
+		return err
+	}
+	if err := rawdbv2.DeleteFinalizedBlockHashByIndex(batch, s.cid, height); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func (s *BlockStorage) getFinalizedHashFromDB(height uint64) (*common.Hash, error) {
+	if s.cid == common.BeaconChainID {
+		return rawdbv2.GetFinalizedBeaconBlockHashByIndex(s.blockStorageDB, height)
+	}
+	return rawdbv2.GetFinalizedShardBlockHashByIndex(s.blockStorageDB, byte(s.cid), height)
+}
+
+// lowestFinalizedHeight returns the first height of the finalized index, which
+// is not 1 for a node bootstrapped from a backup. The index has no gap.
+func (s *BlockStorage) lowestFinalizedHeight(top uint64) (uint64, error) {
+	if _, err := s.getFinalizedHashFromDB(top); err != nil {
+		return 0, fmt.Errorf("no finalized block at height %v", top)
+	}
+	low, high := uint64(1), top
+	for low < high {
+		mid := low + (high-low)/2
+		if _, err := s.getFinalizedHashFromDB(mid); err != nil {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+	return low, nil
+}
+
+// dropFrozenSegments removes the flat file segments holding only blocks below
+// the next height to freeze, i.e. frozen blocks and stale fork blocks. The
+// index of the stale blocks is removed. A frozen block still indexed in the
+// flat file (crash before the index update) gets its ancient height instead.
+func (s *BlockStorage) dropFrozenSegments(next uint64) error {
+	blocks, err := rawdbv2.GetAllFlatFileIndex(s.blockStorageDB)
+	if err != nil {
+		return err
+	}
+	indexes := []uint64{}
+	for index := range blocks {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	type staleBlock struct {
+		seg    uint64
+		hash   common.Hash
+		height uint64
+	}
+	fileSize := s.flatfile.FileSize()
+	cut := s.flatfile.Size() / fileSize //the current segment is never dropped
+	stale := []staleBlock{}
+	for _, index := range indexes {
+		seg := index / fileSize
+		if seg >= cut {
+			break
+		}
+		var blk types.BlockInterface
+		data, err := s.flatfile.Read(index)
+		if err == nil {
+			data, err = common.GZipToBytes(data)
+		}
+		if err == nil {
+			blk, err = s.decodeRaw(data)
+		}
+		if err != nil || blk.GetHeight() >= next {
+			cut = seg
+			break
+		}
+		stale = append(stale, staleBlock{seg, blocks[index], blk.GetHeight()})
+	}
+
+	batch := s.blockStorageDB.NewBatch()
+	for _, b := range stale {
+		if b.seg >= cut {
+			break
+		}
+		if frozen, err := s.ancient.HashByHeight(b.height); err == nil && frozen.IsEqual(&b.hash) {
+			if err := rawdbv2.StoreAncientHeightByBlockHash(batch, b.hash, b.height); err != nil {
+				return err
+			}
+		}
+		if err := rawdbv2.DeleteFlatFileIndexByBlockHash(batch, b.hash); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	return s.flatfile.Truncate(cut * fileSize)
+}
+
+// freezeAncientBlocks periodically moves the finalized blocks deeper than the
+// configured depth of every chain to their ancient store
+func (blockchain *BlockChain) freezeAncientBlocks() {
+	depth := config.Config().AncientBlockDepth
+	for {
+		time.Sleep(10 * time.Minute)
+		if err := blockchain.BeaconChain.BlockStorage.Freeze(blockchain.BeaconChain.GetFinalViewHeight(), depth); err != nil {
+			Logger.log.Errorf("Freeze beacon blocks error: %v", err)
+		}
+		for sid, chain := range blockchain.ShardChain {
+			if err := chain.BlockStorage.Freeze(chain.GetFinalViewHeight(), depth); err != nil {
+				Logger.log.Errorf("Freeze shard %v blocks error: %v", sid, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package blockchain
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain/types"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/flatfile"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFreezeBlocksStayReadable checks that the frozen blocks are still served by
+// the height and hash lookups the backup and the offline tools read blocks with
+func TestFreezeBlocksStayReadable(t *testing.T) {
+	config.AbortParam()
+	dir, err := ioutil.TempDir("", "freeze")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	blockStorage := NewBlockStorage(nil, path.Join(dir, "blockstorage"), common.BeaconChainID, true)
+	hashes := []common.Hash{}
+	for height := uint64(1); height <= 10; height++ {
+		blk := types.NewBeaconBlock()
+		blk.Header.Height = height
+		assert.Nil(t, blockStorage.StoreBlock(blk))
+		assert.Nil(t, blockStorage.StoreFinalizedBeaconBlock(height, *blk.Hash()))
+		hashes = append(hashes, *blk.Hash())
+	}
+	ancientDir := path.Join(dir, "ancient")
+	blockStorage.ancient, err = flatfile.OpenAncientStore(ancientDir)
+	assert.Nil(t, err)
+	assert.Nil(t, blockStorage.Freeze(10, 3))
+	first, next := blockStorage.ancient.Range()
+	assert.Equal(t, uint64(1), first)
+	assert.Equal(t, uint64(8), next)
+
+	for i, hash := range hashes {
+		finalized, err := blockStorage.GetFinalizedBeaconBlock(uint64(i + 1))
+		assert.Nil(t, err)
+		assert.Equal(t, hash, *finalized)
+		blk, _, err := blockStorage.GetBlock(hash)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(i+1), blk.GetHeight())
+	}
+	assert.Nil(t, blockStorage.Close())
+
+	//the offline tools read the frozen blocks too
+	readOnly, err := OpenBlockStorageReadOnly(path.Join(dir, "blockstorage"), ancientDir, common.BeaconChainID)
+	assert.Nil(t, err)
+	defer readOnly.Close()
+	aw, err := NewArchiveWriter(&bytes.Buffer{}, common.BeaconChainID, 0, 0, false)
+	assert.Nil(t, err)
+	count, err := readOnly.ExportArchive(aw, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), count)
+}
@@ -430,24 +430,13 @@ func (s *BootstrapManager) BootstrapBeacon() {
 			panic(err)
 		}
 		mainDir := path.Join(cfg.DataDir, cfg.DatabaseDir, "beacon")
-		err = os.MkdirAll(path.Join(tmpDir, "blockstorage", "blockKV"), 0776)
-		if err != nil {
-			panic(err)
-		}
 		bestView := latestBackup.BeaconView
 
 		Logger.log.Info("Start bootstrap beacon from host", host)
 		//Logger.log.Infof("Stream block beacon from %v", latestBackup.MinBeaconHeight)
-		err = rpcClient.SyncDB(latestBackup.CheckpointName, -1, "state", 0, tmpDir)
-		if err != nil {
-			continue
-		}
-		err = rpcClient.SyncDB(latestBackup.CheckpointName, -1, "blockKV", 0, path.Join(tmpDir, "blockstorage", "blockKV"))
-		if err != nil {
-			continue
-		}
-		err = rpcClient.SyncDB(latestBackup.CheckpointName, -1, "block", 0, path.Join(tmpDir, "blockstorage"))
+		err = syncBackupChain(rpcClient.SyncDB, latestBackup.GetChain(), -1, 0, tmpDir)
 		if err != nil {
+			Logger.log.Error(err)
 			continue
 		}
 		Logger.log.Info("Finish sync ... post processing ...")
@@ -519,26 +508,20 @@ func (s *BootstrapManager) BootstrapShard(sid int, force bool) {
 		if err != nil {
 			panic(err)
 		}
-		err = os.MkdirAll(path.Join(tmpDir, "blockstorage", "blockKV"), 0776)
-		if err != nil {
-			panic(err)
-		}
 		mainDir := path.Join(cfg.DataDir, cfg.DatabaseDir, fmt.Sprintf("shard%v", sid))
 
 		//retrieve beacon block -> backup height
 		bestView := latestBackup.ShardView[sid]
 
 		Logger.log.Infof("Start bootstrap shard %v from host %v", sid, host)
-		err = rpcClient.SyncDB(latestBackup.CheckpointName, sid, "state", 0, tmpDir)
-		if err != nil {
-			continue
-		}
-		err = rpcClient.SyncDB(latestBackup.CheckpointName, sid, "blockKV", 0, path.Join(tmpDir, "blockstorage", "blockKV"))
-		if err != nil {
-			continue
+		chain := latestBackup.GetChain()
+		baseShardHeight := bestView.ShardHeight
+		if len(chain) > 1 {
+			baseShardHeight = latestBackup.BaseShardHeight[sid]
 		}
-		err = rpcClient.SyncDB(latestBackup.CheckpointName, sid, "block", bestView.ShardHeight-500, path.Join(tmpDir, "blockstorage"))
+		err = syncBackupChain(rpcClient.SyncDB, chain, sid, baseShardHeight-500, tmpDir)
 		if err != nil {
+			Logger.log.Error(err)
 			continue
 		}
 		Logger.log.Info("Finish sync ... post processing ...")
@@ -495,3 +495,15 @@ func (b *beaconCommitteeStateSlashingBase) processUnstakeInstruction(
 
 	return committeeChange, returnStakingInstruction, nil
 }
+
+//processEquivocationInstruction : force unstake the validator proven to vote for two blocks in one timeslot
+// candidates are removed and returned their staking amount, validators stop auto staking and leave at their next swap
+func (b *beaconCommitteeStateSlashingBase) processEquivocationInstruction(
+	equivocationInstruction *instruction.EquivocationInstruction,
+	env *BeaconCommitteeStateEnvironment,
+	committeeChange *CommitteeChange,
+	returnStakingInstruction *instruction.ReturnStakeInstruction,
+) (*CommitteeChange, *instruction.ReturnStakeInstruction, error) {
+	unstakeInstruction := instruction.NewUnstakeInstructionWithValue([]string{equivocationInstruction.CommitteePublicKey})
+	return b.processUnstakeInstruction(unstakeInstruction, env, committeeChange, returnStakingInstruction)
+}
@@ -205,6 +205,16 @@ func (b *BeaconCommitteeStateV3) UpdateCommitteeState(env *BeaconCommitteeStateE
 			if err != nil {
 				return nil, nil, nil, NewCommitteeStateError(ErrUpdateCommitteeState, err)
 			}
+		case instruction.EQUIVOCATION_ACTION:
+			equivocationInstruction, err := instruction.ValidateAndImportEquivocationInstructionFromString(inst)
+			if err != nil {
+				return nil, nil, nil, NewCommitteeStateError(ErrUpdateCommitteeState, err)
+			}
+			committeeChange, returnStakingInstruction, err = b.processEquivocationInstruction(
+				equivocationInstruction, env, committeeChange, returnStakingInstruction)
+			if err != nil {
+				return nil, nil, nil, NewCommitteeStateError(ErrUpdateCommitteeState, err)
+			}
 			//case instruction.DEQUEUE:
 			//	dequeueInstruction, err := instruction.ValidateAndImportDequeueInstructionFromString(inst)
 			//	if err != nil {
@@ -412,3 +412,197 @@ func (_m *BeaconCommitteeState) Version() int {
 
 	return r0
 }
+
+// GetAllStaker provides a mock function with no fields
+func (_m *BeaconCommitteeState) GetAllStaker() (map[byte][]incognitokey.CommitteePublicKey, map[byte][]incognitokey.CommitteePublicKey, map[byte][]incognitokey.CommitteePublicKey, []incognitokey.CommitteePublicKey, []incognitokey.CommitteePublicKey, []incognitokey.CommitteePublicKey, []incognitokey.CommitteePublicKey, []incognitokey.CommitteePublicKey, []incognitokey.CommitteePublicKey) {
+	ret := _m.Called()
+
+	var r0 map[byte][]incognitokey.CommitteePublicKey
+	var r1 map[byte][]incognitokey.CommitteePublicKey
+	var r2 map[byte][]incognitokey.CommitteePublicKey
+	var r3 []incognitokey.CommitteePublicKey
+	var r4 []incognitokey.CommitteePublicKey
+	var r5 []incognitokey.CommitteePublicKey
+	var r6 []incognitokey.CommitteePublicKey
+	var r7 []incognitokey.CommitteePublicKey
+	var r8 []incognitokey.CommitteePublicKey
+	if rf, ok := ret.Get(0).(func() map[byte][]incognitokey.CommitteePublicKey); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[byte][]incognitokey.CommitteePublicKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() map[byte][]incognitokey.CommitteePublicKey); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(map[byte][]incognitokey.CommitteePublicKey)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func() map[byte][]incognitokey.CommitteePublicKey); ok {
+		r2 = rf()
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(map[byte][]incognitokey.CommitteePublicKey)
+		}
+	}
+
+	if rf, ok := ret.Get(3).(func() []incognitokey.CommitteePublicKey); ok {
+		r3 = rf()
+	} else {
+		if ret.Get(3) != nil {
+			r3 = ret.Get(3).([]incognitokey.CommitteePublicKey)
+		}
+	}
+
+	if rf, ok := ret.Get(4).(func() []incognitokey.CommitteePublicKey); ok {
+		r4 = rf()
+	} else {
+		if ret.Get(4) != nil {
+			r4 = ret.Get(4).([]incognitokey.CommitteePublicKey)
+		}
+	}
+
+	if rf, ok := ret.Get(5).(func() []incognitokey.CommitteePublicKey); ok {
+		r5 = rf()
+	} else {
+		if ret.Get(5) != nil {
+			r5 = ret.Get(5).([]incognitokey.CommitteePublicKey)
+		}
+	}
+
+	if rf, ok := ret.Get(6).(func() []incognitokey.CommitteePublicKey); ok {
+		r6 = rf()
+	} else {
+		if ret.Get(6) != nil {
+			r6 = ret.Get(6).([]incognitokey.CommitteePublicKey)
+		}
+	}
+
+	if rf, ok := ret.Get(7).(func() []incognitokey.CommitteePublicKey); ok {
+		r7 = rf()
+	} else {
+		if ret.Get(7) != nil {
+			r7 = ret.Get(7).([]incognitokey.CommitteePublicKey)
+		}
+	}
+
+	if rf, ok := ret.Get(8).(func() []incognitokey.CommitteePublicKey); ok {
+		r8 = rf()
+	} else {
+		if ret.Get(8) != nil {
+			r8 = ret.Get(8).([]incognitokey.CommitteePublicKey)
+		}
+	}
+
+	return r0, r1, r2, r3, r4, r5, r6, r7, r8
+}
+
+// GetBeaconCandidateUID provides a mock function with given fields: candidatePK
+func (_m *BeaconCommitteeState) GetBeaconCandidateUID(candidatePK string) (string, error) {
+	ret := _m.Called(candidatePK)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(candidatePK)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(candidatePK)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBeaconLocking provides a mock function with no fields
+func (_m *BeaconCommitteeState) GetBeaconLocking() []incognitokey.CommitteePublicKey {
+	ret := _m.Called()
+
+	var r0 []incognitokey.CommitteePublicKey
+	if rf, ok := ret.Get(0).(func() []incognitokey.CommitteePublicKey); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]incognitokey.CommitteePublicKey)
+		}
+	}
+
+	return r0
+}
+
+// GetBeaconWaiting provides a mock function with no fields
+func (_m *BeaconCommitteeState) GetBeaconWaiting() []incognitokey.CommitteePublicKey {
+	ret := _m.Called()
+
+	var r0 []incognitokey.CommitteePublicKey
+	if rf, ok := ret.Get(0).(func() []incognitokey.CommitteePublicKey); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]incognitokey.CommitteePublicKey)
+		}
+	}
+
+	return r0
+}
+
+// GetNonSlashingRewardReceiver provides a mock function with given fields: staker
+func (_m *BeaconCommitteeState) GetNonSlashingRewardReceiver(staker []incognitokey.CommitteePublicKey) ([]key.PaymentAddress, error) {
+	ret := _m.Called(staker)
+
+	var r0 []key.PaymentAddress
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]incognitokey.CommitteePublicKey) []key.PaymentAddress); ok {
+		r0 = rf(staker)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]key.PaymentAddress)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]incognitokey.CommitteePublicKey) error); ok {
+		r1 = rf(staker)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUnsyncBeaconValidator provides a mock function with no fields
+func (_m *BeaconCommitteeState) GetUnsyncBeaconValidator() []incognitokey.CommitteePublicKey {
+	ret := _m.Called()
+
+	var r0 []incognitokey.CommitteePublicKey
+	if rf, ok := ret.Get(0).(func() []incognitokey.CommitteePublicKey); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]incognitokey.CommitteePublicKey)
+		}
+	}
+
+	return r0
+}
+
+// IsFinishSync provides a mock function with given fields: _a0
+func (_m *BeaconCommitteeState) IsFinishSync(_a0 string) bool {
+	ret := _m.Called(_a0)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
@@ -0,0 +1,158 @@
+package blockchain
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain/committeestate"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/consensus_v2/consensustypes"
+	"github.com/levietcuong2602/incognito-chain/instruction"
+)
+
+// maxEquivocationInstructions bounds the validators a beacon block slashes
+const maxEquivocationInstructions = 10
+
+var DefaultEquivocationPool = NewEquivocationPool()
+
+// EquivocationPool keeps the verified evidences of validators voting for two
+// blocks in one timeslot until a beacon block slashes them
+type EquivocationPool struct {
+	evidences map[string]*consensustypes.EquivocationEvidence //offender committee public key => evidence
+	mu        *sync.RWMutex
+}
+
+func NewEquivocationPool() *EquivocationPool {
+	return &EquivocationPool{
+		evidences: make(map[string]*consensustypes.EquivocationEvidence),
+		mu:        &sync.RWMutex{},
+	}
+}
+
+// Add keeps the first evidence received for offender
+func (p *EquivocationPool) Add(offender string, evidence *consensustypes.EquivocationEvidence) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.evidences[offender]; ok {
+		return false
+	}
+	p.evidences[offender] = evidence
+	return true
+}
+
+func (p *EquivocationPool) Remove(offender string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.evidences, offender)
+}
+
+// GetEvidences returns the evidences by offender
+func (p *EquivocationPool) GetEvidences() map[string]*consensustypes.EquivocationEvidence {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	res := make(map[string]*consensustypes.EquivocationEvidence)
+	for offender, evidence := range p.evidences {
+		res[offender] = evidence
+	}
+	return res
+}
+
+// AddEquivocationEvidence verifies an evidence gossiped by a shard validator
+// and keeps it for the next beacon blocks
+func (blockchain *BlockChain) AddEquivocationEvidence(data string) error {
+	evidence, err := consensustypes.DecodeEquivocationEvidence(data)
+	if err != nil {
+		return err
+	}
+	offender, err := evidence.Verify()
+	if err != nil {
+		return err
+	}
+	if !blockchain.GetBeaconBestState().isSlashable(offender) {
+		return fmt.Errorf("validator %v is not staking", offender)
+	}
+	if DefaultEquivocationPool.Add(offender, evidence) {
+		Logger.log.Infof("Receive equivocation evidence of validator %v in chain %v", offender, evidence.ChainID())
+	}
+	return nil
+}
+
+// isSlashable returns whether the validator is still staking and not
+// slashed yet, equivocation force unstakes it
+func (curView *BeaconBestState) isSlashable(committeePublicKey string) bool {
+	if curView.CommitteeStateVersion() < committeestate.STAKING_FLOW_V2 {
+		return false
+	}
+	return curView.beaconCommitteeState.GetAutoStaking()[committeePublicKey]
+}
+
+// generateEquivocationInstructions slashes the validators of the equivocation
+// pool still staking
+func (curView *BeaconBestState) generateEquivocationInstructions() [][]string {
+	instructions := [][]string{}
+	if curView.BeaconHeight+1 < config.Param().ConsensusParam.EquivocationSlashHeight {
+		return instructions
+	}
+	evidences := DefaultEquivocationPool.GetEvidences()
+	offenders := []string{}
+	for offender := range evidences {
+		if !curView.isSlashable(offender) {
+			DefaultEquivocationPool.Remove(offender)
+			continue
+		}
+		offenders = append(offenders, offender)
+	}
+	sort.Strings(offenders)
+	for _, offender := range offenders {
+		if len(instructions) == maxEquivocationInstructions {
+			break
+		}
+		data, err := consensustypes.EncodeEquivocationEvidence(*evidences[offender])
+		if err != nil {
+			Logger.log.Error(err)
+			continue
+		}
+		instructions = append(instructions, instruction.NewEquivocationInstructionWithValue(offender, data).ToString())
+	}
+	return instructions
+}
+
+// filterAndVerifyEquivocationInstructions verifies the evidence of the
+// equivocation instructions of a beacon block from the signatures of the votes
+func (curView *BeaconBestState) filterAndVerifyEquivocationInstructions(instructions [][]string) ([][]string, error) {
+	equivocationInstructions := [][]string{}
+	offenders := make(map[string]bool)
+	for _, v := range instructions {
+		if v[0] != instruction.EQUIVOCATION_ACTION {
+			continue
+		}
+		if curView.BeaconHeight+1 < config.Param().ConsensusParam.EquivocationSlashHeight {
+			return nil, fmt.Errorf("equivocation instruction before height %v", config.Param().ConsensusParam.EquivocationSlashHeight)
+		}
+		inst, err := instruction.ValidateAndImportEquivocationInstructionFromString(v)
+		if err != nil {
+			return nil, err
+		}
+		evidence, err := consensustypes.DecodeEquivocationEvidence(inst.Evidence)
+		if err != nil {
+			return nil, err
+		}
+		offender, err := evidence.Verify()
+		if err != nil {
+			return nil, fmt.Errorf("invalid equivocation evidence of %v, %v", inst.CommitteePublicKey, err)
+		}
+		if offender != inst.CommitteePublicKey {
+			return nil, fmt.Errorf("equivocation evidence is of %v, not %v", offender, inst.CommitteePublicKey)
+		}
+		if offenders[offender] || !curView.isSlashable(offender) {
+			return nil, fmt.Errorf("validator %v cannot be slashed", offender)
+		}
+		offenders[offender] = true
+		equivocationInstructions = append(equivocationInstructions, v)
+	}
+	if len(equivocationInstructions) > maxEquivocationInstructions {
+		return nil, fmt.Errorf("too many equivocation instructions %v", len(equivocationInstructions))
+	}
+	return equivocationInstructions, nil
+}
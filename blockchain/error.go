@@ -101,6 +101,7 @@ const (
 	BuildRewardInstructionError
 	BuildBridgeError
 	BuildBridgeAggError
+	EquivocationInstructionError
 	GenerateBeaconCommitteeAndValidatorRootError
 	GenerateShardCommitteeAndValidatorRootError
 	GenerateBeaconCandidateRootError
@@ -197,6 +198,9 @@ const (
 	ShardBlockAlreadyExist
 	PDEStateDBError
 	UpdateBFTV3StatsError
+	StatePrunedError
+	StateDiffError
+	StateSnapshotError
 	FinishSyncInstructionError
 	OutdatedCodeError
 )
@@ -375,6 +379,7 @@ var ErrCodeMessage = map[int]struct {
 	FinishSyncInstructionError:                        {-1165, "Checking finish sync instruction error"},
 	BuildBridgeError:                                  {-1166, "Build bridge unshield instruction error"},
 	BuildBridgeAggError:                               {-1167, "Build bridge agg unshield instruction error"},
+	EquivocationInstructionError:                      {-1168, "Checking equivocation instruction error"},
 
 	GetListOutputCoinsByKeysetError:                 {-2000, "Get List Output Coins By Keyset Error"},
 	GetTotalLockedCollateralError:                   {-3000, "Get Total Locked Collateral Error"},
@@ -385,6 +390,9 @@ var ErrCodeMessage = map[int]struct {
 	UpgradeBeaconCommitteeStateError:                {-4000, "Upgrade Beacon Committee State Error"},
 	UpgradeShardCommitteeStateError:                 {-4001, "Upgrade Shard Committee State Error"},
 	UpdateBFTV3StatsError:                           {-4002, "Update BFT V3 Stats Error, This Error Won't effect Store Shard Block"},
+	StatePrunedError:                                {-4003, "State Pruned Error"},
+	StateDiffError:                                  {-4004, "State Diff Error"},
+	StateSnapshotError:                              {-4005, "State Snapshot Error"},
 }
 
 type BlockChainError struct {
@@ -0,0 +1,33 @@
+package pdex
+
+import (
+	"errors"
+
+	"github.com/levietcuong2602/incognito-chain/metadata"
+	metadataPdexv3 "github.com/levietcuong2602/incognito-chain/metadata/pdexv3"
+)
+
+// SimulateTrade returns the instructions the beacon would produce for the
+// trade request tx if it were the only trade of the next block, the state is
+// left untouched
+func SimulateTrade(state State, tx metadata.Transaction) ([][]string, error) {
+	s, ok := state.(*stateV2)
+	if !ok || s == nil {
+		return nil, errors.New("trades are simulated with the pdex v3 state")
+	}
+	// the trade only changes the pool pairs of its path, the others are read
+	// for the fee conversion and are shared with the state
+	poolPairs := make(map[string]*PoolPairState, len(s.poolPairs))
+	for pairID, poolPair := range s.poolPairs {
+		poolPairs[pairID] = poolPair
+	}
+	if md, ok := tx.GetMetadata().(*metadataPdexv3.TradeRequest); ok {
+		for _, pairID := range md.TradePath {
+			if poolPair, ok := s.poolPairs[pairID]; ok {
+				poolPairs[pairID] = poolPair.Clone()
+			}
+		}
+	}
+	instructions, _, err := s.producer.trade([]metadata.Transaction{tx}, poolPairs, s.params)
+	return instructions, err
+}
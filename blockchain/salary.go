@@ -1071,10 +1071,16 @@ func (blockchain *BlockChain) buildMintDelegationRewardTransaction(
 	}
 	return mintDRewardTx, mintInfo.RewardAmount, nil
 }
+
+// BeaconSharePriceHeight returns the beacon height whose consensus state holds
+// the share prices of the beginning of epoch
+func BeaconSharePriceHeight(epoch uint64) uint64 {
+	return (epoch-1)*config.Param().EpochParam.NumberOfBlockInEpoch + 1
+}
+
 func (blockchain *BlockChain) GetBeaconSharePriceByEpoch(epoch uint64, uid string) (uint64, error) {
-	blockHeight := (epoch - 1) * config.Param().EpochParam.NumberOfBlockInEpoch
 	beaconConsensusStateRootHash, err := blockchain.GetBeaconRootsHashFromBlockHeight(
-		blockHeight + 1,
+		BeaconSharePriceHeight(epoch),
 	)
 	if err != nil {
 		return 0, err
@@ -17,6 +17,7 @@ import (
 	"github.com/levietcuong2602/incognito-chain/incognitokey"
 	"github.com/levietcuong2602/incognito-chain/instruction"
 	"github.com/levietcuong2602/incognito-chain/metadata"
+	"github.com/levietcuong2602/incognito-chain/multiview"
 	"github.com/levietcuong2602/incognito-chain/trie"
 	"github.com/levietcuong2602/incognito-chain/wallet"
 )
@@ -473,6 +474,8 @@ func TestBlockChain_addShardRewardRequestToBeacon(t *testing.T) {
 	txFee[common.PRVCoinID] = txFee1
 	acceptedBlockRewardInfo1 := instruction.NewAcceptBlockRewardV1WithValue(0, txFee, 2)
 	acceptedBlockRewardInfo1Inst, _ := acceptedBlockRewardInfo1.String()
+	beaconView := multiview.NewBeaconMultiView()
+	beaconView.AddView(&BeaconBestState{ActiveShards: 1})
 	type fields struct {
 	}
 	type args struct {
@@ -518,7 +521,9 @@ func TestBlockChain_addShardRewardRequestToBeacon(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			blockchain := &BlockChain{}
+			blockchain := &BlockChain{
+				BeaconChain: &BeaconChain{multiView: beaconView},
+			}
 			if err := blockchain.addShardRewardRequestToBeacon(tt.args.beaconBlock, sDB, &BeaconBestState{}); (err != nil) != tt.wantErr {
 				t.Errorf("addShardRewardRequestToBeacon() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -883,7 +888,7 @@ func Test_getCommitteeToPayRewardMultiset(t *testing.T) {
 }
 
 func Test_getYearOfBlockChain(t *testing.T) {
-	config.LoadParam()
+	config.AbortParam()
 	config.Param().BlockTimeParam = make(map[string]int64)
 	config.Param().BlockTimeParam = map[string]int64{
 		BLOCKTIME_DEFAULT: 40,
@@ -58,6 +58,7 @@ func NewShardChain(
 	cfg := config.Config()
 	ffPath := path.Join(cfg.DataDir, cfg.DatabaseDir, fmt.Sprintf("shard%v", shardID), "blockstorage")
 	bs := NewBlockStorage(blockchain.GetShardChainDatabase(byte(shardID)), ffPath, shardID, false)
+	bs.openAncientStore(fmt.Sprintf("shard%v", shardID))
 
 	chain := &ShardChain{
 		shardID:      shardID,
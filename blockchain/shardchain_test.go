@@ -17,7 +17,7 @@ import (
 func TestShardChain_GetSigningCommittees(t *testing.T) {
 	type fields struct {
 		shardID     int
-		multiView   *multiview.MultiView
+		multiView   multiview.MultiView
 		BlockGen    *BlockGenerator
 		Blockchain  *BlockChain
 		hashHistory *lru.Cache
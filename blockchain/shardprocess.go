@@ -928,6 +928,7 @@ func (shardBestState *ShardBestState) initShardBestState(
 	if err != nil {
 		return err
 	}
+	shardBestState.attachStateSnapshots(blockchain)
 	shardBestState.ConsensusStateDBRootHash = common.EmptyRoot
 	shardBestState.SlashStateDBRootHash = common.EmptyRoot
 	shardBestState.RewardStateDBRootHash = common.EmptyRoot
@@ -1142,6 +1143,7 @@ func (blockchain *BlockChain) processStoreShardBlock(
 		Logger.log.Debug("Transaction in block with hash", blockHash, "and index", index)
 	}
 	if blockchain.UsingNewPool() {
+		blockchain.ShardChain[shardID].TxPool.GetFeeEstimator().RegisterBlock(shardBlock)
 		if len(listTxHashes) > 0 {
 			if blockchain.ShardChain[shardID].TxPool.IsRunning() {
 				blockchain.ShardChain[shardID].TxPool.RemoveTxs(listTxHashes)
@@ -0,0 +1,114 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+)
+
+// State database kinds of a block, a beacon block has no transaction state
+const (
+	ConsensusStateDB   = "consensus"
+	TransactionStateDB = "transaction"
+	FeatureStateDB     = "feature"
+	RewardStateDB      = "reward"
+	SlashStateDB       = "slash"
+)
+
+// GetStateRootByBlockHash returns the root of the kind state database after the
+// block of hash of chain cid, read from the chain database db
+func GetStateRootByBlockHash(db incdb.Database, cid int, hash common.Hash, kind string) (common.Hash, error) {
+	if cid == common.BeaconChainID {
+		roots, err := GetBeaconRootsHashByBlockHash(db, hash)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		switch kind {
+		case ConsensusStateDB:
+			return roots.ConsensusStateDBRootHash, nil
+		case FeatureStateDB:
+			return roots.FeatureStateDBRootHash, nil
+		case RewardStateDB:
+			return roots.RewardStateDBRootHash, nil
+		case SlashStateDB:
+			return roots.SlashStateDBRootHash, nil
+		}
+		return common.Hash{}, fmt.Errorf("beacon chain has no %v state database", kind)
+	}
+	roots, err := GetShardRootsHashByBlockHash(db, byte(cid), hash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	switch kind {
+	case ConsensusStateDB:
+		return roots.ConsensusStateDBRootHash, nil
+	case TransactionStateDB:
+		return roots.TransactionStateDBRootHash, nil
+	case FeatureStateDB:
+		return roots.FeatureStateDBRootHash, nil
+	case RewardStateDB:
+		return roots.RewardStateDBRootHash, nil
+	case SlashStateDB:
+		return roots.SlashStateDBRootHash, nil
+	}
+	return common.Hash{}, fmt.Errorf("shard chain has no %v state database", kind)
+}
+
+// DiffStateByBlockHash returns the changes of the kind state database from
+// the block fromHash to the block toHash of chain cid, see
+// statedb.DiffStateRoots
+func DiffStateByBlockHash(db incdb.Database, cid int, fromHash, toHash common.Hash, kind string, limit int) (*statedb.StateDiff, error) {
+	fromRoot, err := GetStateRootByBlockHash(db, cid, fromHash, kind)
+	if err != nil {
+		return nil, NewBlockChainError(StateDiffError, fmt.Errorf("state root of block %v: %v", fromHash.String(), err))
+	}
+	toRoot, err := GetStateRootByBlockHash(db, cid, toHash, kind)
+	if err != nil {
+		return nil, NewBlockChainError(StateDiffError, fmt.Errorf("state root of block %v: %v", toHash.String(), err))
+	}
+	diff, err := statedb.DiffStateRoots(statedb.NewDatabaseAccessWarper(db), fromRoot, toRoot, limit)
+	if err != nil {
+		return nil, NewBlockChainError(StateDiffError, err)
+	}
+	return diff, nil
+}
+
+// BlockStateDiff is the state diff of a block, see GetStateDiffByBlockHash
+type BlockStateDiff struct {
+	ChainID       int
+	BlockHash     common.Hash
+	FromBlockHash common.Hash
+	DBKind        string
+	*statedb.StateDiff
+}
+
+// GetStateDiffByBlockHash returns the changes of the kind state database made
+// by the block of hash, beacon or shard. With fromHash the state is compared
+// to the one after that block of the same chain instead of the previous block.
+func (blockchain *BlockChain) GetStateDiffByBlockHash(hash common.Hash, fromHash *common.Hash, kind string, limit int) (*BlockStateDiff, error) {
+	res := &BlockStateDiff{ChainID: common.BeaconChainID, BlockHash: hash, DBKind: kind}
+	var db incdb.Database
+	if blk, _, err := blockchain.BeaconChain.BlockStorage.GetBlock(hash); err == nil {
+		db = blockchain.GetBeaconChainDatabase()
+		res.FromBlockHash = blk.GetPrevHash()
+	} else {
+		shardBlock, _, err := blockchain.GetShardBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		res.ChainID = int(shardBlock.Header.ShardID)
+		db = blockchain.GetShardChainDatabase(shardBlock.Header.ShardID)
+		res.FromBlockHash = shardBlock.Header.PreviousBlockHash
+	}
+	if fromHash != nil {
+		res.FromBlockHash = *fromHash
+	}
+	diff, err := DiffStateByBlockHash(db, res.ChainID, res.FromBlockHash, hash, kind, limit)
+	if err != nil {
+		return nil, err
+	}
+	res.StateDiff = diff
+	return res, nil
+}
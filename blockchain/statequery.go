@@ -0,0 +1,146 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain/types"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+)
+
+// StateQuery selects the block whose state a query reads: the block of
+// BlockHash, else the block of Height on the best chain, else the best view
+type StateQuery struct {
+	Height    uint64       `json:"Height,omitempty"`
+	BlockHash *common.Hash `json:"BlockHash,omitempty"`
+}
+
+// IsBest returns true when the query reads the best view
+func (query StateQuery) IsBest() bool {
+	return query.Height == 0 && query.BlockHash == nil
+}
+
+// StateAt is a state database of a chain after a block, see GetStateDBAt
+type StateAt struct {
+	ChainID      int
+	Height       uint64
+	BlockHash    common.Hash
+	BeaconHeight uint64 //beacon height confirmed by the block, Height for beacon
+	Timestamp    int64
+	StateDB      *statedb.StateDB
+}
+
+// GetStateDBAt returns the kind state database of chain cid (common.BeaconChainID
+// for beacon) after the block selected by query. A historical state is opened
+// from the roots stored with the block, a StatePrunedError is returned when
+// the state pruner dropped it.
+func (blockchain *BlockChain) GetStateDBAt(cid int, kind string, query StateQuery) (*StateAt, error) {
+	if cid != common.BeaconChainID && (cid < 0 || cid >= blockchain.GetActiveShardNumber()) {
+		return nil, fmt.Errorf("chain %v is invalid", cid)
+	}
+	if query.IsBest() {
+		return blockchain.getBestStateDB(cid, kind)
+	}
+
+	var db incdb.Database
+	var blk types.BlockInterface
+	var err error
+	if cid == common.BeaconChainID {
+		db = blockchain.GetBeaconChainDatabase()
+		hash := query.BlockHash
+		if hash == nil {
+			hash, err = blockchain.GetBeaconBlockHashByHeight(blockchain.BeaconChain.GetFinalView(), blockchain.BeaconChain.GetBestView(), query.Height)
+			if err != nil {
+				return nil, err
+			}
+		}
+		blk, _, err = blockchain.BeaconChain.BlockStorage.GetBlock(*hash)
+	} else {
+		shardID := byte(cid)
+		db = blockchain.GetShardChainDatabase(shardID)
+		hash := query.BlockHash
+		if hash == nil {
+			hash, err = blockchain.GetShardBlockHashByHeight(blockchain.ShardChain[shardID].GetFinalView(), blockchain.ShardChain[shardID].GetBestView(), query.Height)
+			if err != nil {
+				return nil, err
+			}
+		}
+		blk, _, err = blockchain.ShardChain[shardID].BlockStorage.GetBlock(*hash)
+	}
+	if err != nil || blk == nil {
+		return nil, fmt.Errorf("block of chain %v not found, height %v, hash %v", cid, query.Height, query.BlockHash)
+	}
+	if query.BlockHash != nil && query.Height != 0 && query.Height != blk.GetHeight() {
+		return nil, fmt.Errorf("block %v is at height %v, not %v", query.BlockHash.String(), blk.GetHeight(), query.Height)
+	}
+
+	if err := blockchain.CheckStateAvailable(cid, blk.GetHeight()); err != nil {
+		return nil, err
+	}
+	root, err := GetStateRootByBlockHash(db, cid, *blk.Hash(), kind)
+	if err != nil {
+		return nil, err
+	}
+	stateDB, err := statedb.NewWithPrefixTrie(root, statedb.NewDatabaseAccessWarper(db))
+	if err != nil {
+		return nil, NewBlockChainError(StatePrunedError, fmt.Errorf("state of chain %v at height %v is not in the database, %v", cid, blk.GetHeight(), err))
+	}
+	return &StateAt{
+		ChainID:      cid,
+		Height:       blk.GetHeight(),
+		BlockHash:    *blk.Hash(),
+		BeaconHeight: blk.GetBeaconHeight(),
+		Timestamp:    blk.GetProduceTime(),
+		StateDB:      stateDB,
+	}, nil
+}
+
+func (blockchain *BlockChain) getBestStateDB(cid int, kind string) (*StateAt, error) {
+	if cid == common.BeaconChainID {
+		beaconBestState := blockchain.GetBeaconBestState()
+		res := &StateAt{
+			ChainID:      cid,
+			Height:       beaconBestState.BeaconHeight,
+			BlockHash:    beaconBestState.BestBlockHash,
+			BeaconHeight: beaconBestState.BeaconHeight,
+			Timestamp:    beaconBestState.BestBlock.Header.Timestamp,
+		}
+		switch kind {
+		case ConsensusStateDB:
+			res.StateDB = beaconBestState.GetBeaconConsensusStateDB()
+		case FeatureStateDB:
+			res.StateDB = beaconBestState.GetBeaconFeatureStateDB()
+		case RewardStateDB:
+			res.StateDB = beaconBestState.GetBeaconRewardStateDB()
+		case SlashStateDB:
+			res.StateDB = beaconBestState.GetBeaconSlashStateDB()
+		default:
+			return nil, fmt.Errorf("beacon chain has no %v state database", kind)
+		}
+		return res, nil
+	}
+	shardBestState := blockchain.GetBestStateShard(byte(cid))
+	res := &StateAt{
+		ChainID:      cid,
+		Height:       shardBestState.ShardHeight,
+		BlockHash:    shardBestState.BestBlockHash,
+		BeaconHeight: shardBestState.BeaconHeight,
+		Timestamp:    shardBestState.BestBlock.Header.Timestamp,
+	}
+	switch kind {
+	case ConsensusStateDB:
+		res.StateDB = shardBestState.GetCopiedConsensusStateDB()
+	case TransactionStateDB:
+		res.StateDB = shardBestState.GetCopiedTransactionStateDB()
+	case FeatureStateDB:
+		res.StateDB = shardBestState.GetCopiedFeatureStateDB()
+	case RewardStateDB:
+		res.StateDB = shardBestState.GetShardRewardStateDB()
+	case SlashStateDB:
+		res.StateDB = shardBestState.slashStateDB.Copy()
+	default:
+		return nil, fmt.Errorf("shard chain has no %v state database", kind)
+	}
+	return res, nil
+}
@@ -0,0 +1,125 @@
+package blockchain
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+)
+
+// stateSnapshots holds the snapshot trees of the state databases of the
+// chains, indexed by chain ID and kind. They are only used when the
+// state_snapshot config is set.
+type stateSnapshots struct {
+	lock  sync.Mutex
+	trees map[int]map[string]*statedb.SnapshotTree
+}
+
+func (blockchain *BlockChain) getStateSnapshotTree(cid int, kind string) (*statedb.SnapshotTree, error) {
+	snapshots := &blockchain.stateSnapshots
+	snapshots.lock.Lock()
+	defer snapshots.lock.Unlock()
+	if snapshots.trees == nil {
+		snapshots.trees = make(map[int]map[string]*statedb.SnapshotTree)
+	}
+	if snapshots.trees[cid] == nil {
+		snapshots.trees[cid] = make(map[string]*statedb.SnapshotTree)
+	}
+	if tree, ok := snapshots.trees[cid][kind]; ok {
+		return tree, nil
+	}
+	db, ok := blockchain.config.DataBase[cid]
+	if !ok {
+		return nil, fmt.Errorf("chain %v has no database", cid)
+	}
+	tree, err := statedb.NewSnapshotTree(db, kind, statedb.DefaultSnapshotDiffLayers)
+	if err != nil {
+		return nil, err
+	}
+	snapshots.trees[cid][kind] = tree
+	return tree, nil
+}
+
+// attachStateSnapshot makes the kind state database of chain cid read the
+// snapshot of its root. The snapshot of a chain synced without it is built by
+// the rebuildstatesnapshot command, reads use the trie until then.
+func (blockchain *BlockChain) attachStateSnapshot(cid int, kind string, stateDB *statedb.StateDB) {
+	if !config.Config().StateSnapshot || stateDB == nil {
+		return
+	}
+	tree, err := blockchain.getStateSnapshotTree(cid, kind)
+	if err != nil {
+		Logger.log.Errorf("Load %v state snapshot of chain %v: %v", kind, cid, err)
+		return
+	}
+	if err := stateDB.AttachSnapshot(tree); err != nil {
+		Logger.log.Warnf("No %v state snapshot for chain %v, reads use the trie: %v", kind, cid, err)
+	}
+}
+
+func (beaconBestState *BeaconBestState) attachStateSnapshots(bc *BlockChain) {
+	bc.attachStateSnapshot(common.BeaconChainID, ConsensusStateDB, beaconBestState.consensusStateDB)
+	bc.attachStateSnapshot(common.BeaconChainID, FeatureStateDB, beaconBestState.featureStateDB)
+	bc.attachStateSnapshot(common.BeaconChainID, RewardStateDB, beaconBestState.rewardStateDB)
+	bc.attachStateSnapshot(common.BeaconChainID, SlashStateDB, beaconBestState.slashStateDB)
+}
+
+func (shardBestState *ShardBestState) attachStateSnapshots(bc *BlockChain) {
+	cid := int(shardBestState.ShardID)
+	bc.attachStateSnapshot(cid, ConsensusStateDB, shardBestState.consensusStateDB)
+	bc.attachStateSnapshot(cid, TransactionStateDB, shardBestState.transactionStateDB)
+	bc.attachStateSnapshot(cid, FeatureStateDB, shardBestState.featureStateDB)
+	bc.attachStateSnapshot(cid, RewardStateDB, shardBestState.rewardStateDB)
+	bc.attachStateSnapshot(cid, SlashStateDB, shardBestState.slashStateDB)
+}
+
+// RebuildStateSnapshot writes the snapshot of the kind state database of
+// chain cid after the block hash from scratch, read from the chain database
+// db. It returns the number of state objects of the snapshot.
+func RebuildStateSnapshot(db incdb.Database, cid int, hash common.Hash, kind string) (int, error) {
+	root, err := GetStateRootByBlockHash(db, cid, hash, kind)
+	if err != nil {
+		return 0, NewBlockChainError(StateSnapshotError, fmt.Errorf("state root of block %v: %v", hash.String(), err))
+	}
+	tree, err := statedb.NewSnapshotTree(db, kind, statedb.DefaultSnapshotDiffLayers)
+	if err != nil {
+		return 0, NewBlockChainError(StateSnapshotError, err)
+	}
+	count, err := tree.Rebuild(root, statedb.NewDatabaseAccessWarper(db))
+	if err != nil {
+		return count, NewBlockChainError(StateSnapshotError, err)
+	}
+	return count, nil
+}
+
+// CheckStateSnapshot compares the snapshot of the kind state database of
+// chain cid with its trie after the block hash, at the root of the disk layer
+// of the snapshot when hash is nil. limit bounds the number of listed
+// mismatches, 0 for no limit.
+func CheckStateSnapshot(db incdb.Database, cid int, hash *common.Hash, kind string, limit int) (*statedb.SnapshotCheck, error) {
+	tree, err := statedb.NewSnapshotTree(db, kind, statedb.DefaultSnapshotDiffLayers)
+	if err != nil {
+		return nil, NewBlockChainError(StateSnapshotError, err)
+	}
+	root, ok := tree.DiskRoot()
+	if !ok {
+		return nil, NewBlockChainError(StateSnapshotError, fmt.Errorf("chain %v has no %v state snapshot", cid, kind))
+	}
+	warper := statedb.NewDatabaseAccessWarper(db)
+	if hash != nil {
+		if root, err = GetStateRootByBlockHash(db, cid, *hash, kind); err != nil {
+			return nil, NewBlockChainError(StateSnapshotError, fmt.Errorf("state root of block %v: %v", hash.String(), err))
+		}
+		if _, err := tree.Recover(root, warper); err != nil {
+			return nil, NewBlockChainError(StateSnapshotError, err)
+		}
+	}
+	check, err := tree.Verify(root, warper, limit)
+	if err != nil {
+		return nil, NewBlockChainError(StateSnapshotError, err)
+	}
+	return check, nil
+}
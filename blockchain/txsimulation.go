@@ -0,0 +1,136 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain/pdex"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/metadata"
+	metadataCommon "github.com/levietcuong2602/incognito-chain/metadata/common"
+	"github.com/pkg/errors"
+)
+
+// TxSimulationCheck is the result of one of the checks a tx goes through
+// before entering the pool, Code is the code of a metadata error
+type TxSimulationCheck struct {
+	Name   string
+	Passed bool
+	Code   int    `json:",omitempty"`
+	Error  string `json:",omitempty"`
+}
+
+// TxSimulation is the result of a dry run of a tx against the best views of
+// its shard and of the beacon. Instructions are the beacon instructions the tx
+// is expected to produce when its metadata has a predictable outcome.
+type TxSimulation struct {
+	TxID         string
+	ShardID      byte
+	ShardHeight  uint64
+	BeaconHeight uint64
+	Valid        bool
+	Checks       []TxSimulationCheck
+	Instructions [][]string `json:",omitempty"`
+}
+
+func (res *TxSimulation) check(name string, ok bool, err error) bool {
+	c := TxSimulationCheck{Name: name, Passed: ok && err == nil}
+	if err != nil {
+		c.Error = err.Error()
+		if mdErr, isMdErr := errors.Cause(err).(*metadataCommon.MetadataTxError); isMdErr {
+			c.Code = mdErr.Code
+		}
+	} else if !ok {
+		c.Error = "check failed"
+	}
+	res.Checks = append(res.Checks, c)
+	res.Valid = res.Valid && c.Passed
+	return c.Passed
+}
+
+// runCheck runs f as the check name, a panic of a malformed tx fails the check
+func (res *TxSimulation) runCheck(name string, f func() (bool, error)) (passed bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			passed = res.check(name, false, fmt.Errorf("panic: %v", r))
+		}
+	}()
+	ok, err := f()
+	return res.check(name, ok, err)
+}
+
+// SimulateTransaction runs the checks of the pool on tx against the best view
+// of its shard without adding it to the pool: the sanity and the correctness
+// of the tx, its fee, its validity with the chain state, metadata included,
+// and its double spends with the chain and with the pool. Every check is run
+// once the data of the tx is loaded so that all the failing ones are listed.
+func (blockchain *BlockChain) SimulateTransaction(tx metadata.Transaction) (*TxSimulation, error) {
+	shardID := common.GetShardIDFromLastByte(tx.GetSenderAddrLastByte())
+	if int(shardID) >= len(blockchain.ShardChain) {
+		return nil, fmt.Errorf("shard ID %v of tx %v is invalid", shardID, tx.Hash().String())
+	}
+	sChain := blockchain.ShardChain[shardID]
+	sView := sChain.GetBestState()
+	bcView, err := blockchain.GetBeaconViewStateDataFromBlockHash(sView.BestBeaconHash, true, true, false)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get beacon view %v: %v", sView.BestBeaconHash.String(), err)
+	}
+	verifier, ok := sChain.TxsVerifier.(*TxsVerifier)
+	if !ok {
+		return nil, fmt.Errorf("no tx verifier for shard %v", shardID)
+	}
+	res := &TxSimulation{
+		TxID:         tx.Hash().String(),
+		ShardID:      shardID,
+		ShardHeight:  sView.ShardHeight,
+		BeaconHeight: bcView.BeaconHeight,
+		Valid:        true,
+		Checks:       []TxSimulationCheck{},
+	}
+
+	tx.SetValidationEnv(UpdateTxEnvWithSView(sView, tx))
+	txDB := sView.GetCopiedTransactionStateDB()
+	if !res.runCheck("load-data", func() (bool, error) { return true, tx.LoadData(txDB.Copy()) }) {
+		return res, nil
+	}
+	res.runCheck("sanity", tx.ValidateSanityDataByItSelf)
+	res.runCheck("correctness", func() (bool, error) { return tx.ValidateTxCorrectness(txDB.Copy()) })
+	res.runCheck("fee", func() (bool, error) {
+		if verifier.checkFees(bcView.GetHeight(), tx, bcView.GetBeaconFeatureStateDB(), shardID) {
+			return true, nil
+		}
+		return false, errors.Errorf("Transaction fee %v PRV %v Token is invalid", tx.GetTxFee(), tx.GetTxFeeToken())
+	})
+	res.runCheck("sanity-with-blockchain", func() (bool, error) {
+		return tx.ValidateSanityDataWithBlockchain(blockchain, sView, bcView, sView.BeaconHeight)
+	})
+	if meta := tx.GetMetadata(); meta != nil {
+		res.runCheck("metadata", func() (bool, error) {
+			return meta.ValidateTxWithBlockChain(tx, blockchain, sView, bcView, shardID, txDB.Copy())
+		})
+	}
+	res.runCheck("double-spend-blockchain", func() (bool, error) {
+		tokenID := tx.GetValidationEnv().TokenID()
+		err := tx.ValidateDoubleSpendWithBlockchain(shardID, txDB.Copy(), &tokenID)
+		return err == nil, err
+	})
+	if blockchain.UsingNewPool() && sChain.TxPool != nil && sChain.TxPool.IsRunning() {
+		res.runCheck("double-spend-pool", func() (bool, error) {
+			isDoubleSpend, canReplace, oldTx, _ := sChain.TxPool.CheckDoubleSpendWithCurMem(tx)
+			if isDoubleSpend && !canReplace {
+				return false, errors.Errorf("Tx %v is double spend with tx %v in mempool", tx.Hash().String(), oldTx)
+			}
+			return true, nil
+		})
+	}
+
+	if res.Valid && tx.GetMetadataType() == metadataCommon.Pdexv3TradeRequestMeta {
+		res.runCheck("expected-instructions", func() (bool, error) {
+			instructions, err := pdex.SimulateTrade(bcView.PdeState(pdex.AmplifierVersion), tx)
+			res.Instructions = instructions
+			return err == nil, err
+		})
+		// the tx stays valid when its outcome can not be predicted
+		res.Valid = true
+	}
+	return res, nil
+}
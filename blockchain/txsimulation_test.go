@@ -0,0 +1,178 @@
+package blockchain
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/levietcuong2602/incognito-chain/blockchain/types"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	_ "github.com/levietcuong2602/incognito-chain/incdb/lvdb"
+	"github.com/levietcuong2602/incognito-chain/metadata"
+	metadataCommon "github.com/levietcuong2602/incognito-chain/metadata/common"
+	"github.com/levietcuong2602/incognito-chain/multiview"
+	"github.com/levietcuong2602/incognito-chain/transaction"
+	"github.com/levietcuong2602/incognito-chain/transaction/tx_generic"
+	"github.com/stretchr/testify/assert"
+)
+
+// simulationTestTx is a PRV tx whose checks fail when their error is set
+type simulationTestTx struct {
+	*transaction.TxVersion1
+	loadErr        error
+	sanityErr      error
+	doubleSpendErr error
+	panicMsg       string
+}
+
+func (tx *simulationTestTx) LoadData(*statedb.StateDB) error { return tx.loadErr }
+
+func (tx *simulationTestTx) ValidateSanityDataByItSelf() (bool, error) {
+	return tx.sanityErr == nil, tx.sanityErr
+}
+
+func (tx *simulationTestTx) ValidateTxCorrectness(*statedb.StateDB) (bool, error) { return true, nil }
+
+func (tx *simulationTestTx) ValidateSanityDataWithBlockchain(
+	metadata.ChainRetriever,
+	metadata.ShardViewRetriever,
+	metadata.BeaconViewRetriever,
+	uint64,
+) (bool, error) {
+	if tx.panicMsg != "" {
+		panic(tx.panicMsg)
+	}
+	return true, nil
+}
+
+func (tx *simulationTestTx) ValidateDoubleSpendWithBlockchain(byte, *statedb.StateDB, *common.Hash) error {
+	return tx.doubleSpendErr
+}
+
+// simulationFeeEstimator requires minFeePerTx PRV per tx
+type simulationFeeEstimator struct {
+	minFeePerTx uint64
+}
+
+func (f simulationFeeEstimator) RegisterBlock(*types.ShardBlock) error { return nil }
+func (f simulationFeeEstimator) EstimateFee(uint64, *common.Hash) (uint64, error) {
+	return 0, nil
+}
+func (f simulationFeeEstimator) GetLimitFeeForNativeToken() uint64 { return 1 }
+func (f simulationFeeEstimator) GetMinFeePerTx() uint64            { return f.minFeePerTx }
+func (f simulationFeeEstimator) GetSpecifiedFeeTx() uint64         { return 0 }
+func (f simulationFeeEstimator) GetSpecifiedFeePerKBType2() uint64 { return 0 }
+func (f simulationFeeEstimator) GetSpecifiedFeePerTxType2() uint64 { return 0 }
+
+// newSimulationTestChain returns a chain of one shard at height 5 confirming
+// the beacon height 3
+func newSimulationTestChain(t *testing.T, db incdb.Database, minFeePerTx uint64) *BlockChain {
+	stateDB, err := statedb.NewWithPrefixTrie(common.EmptyRoot, statedb.NewDatabaseAccessWarper(db))
+	assert.Nil(t, err)
+	beaconHash := common.HashH([]byte("beacon"))
+	bcView := NewBeaconBestState()
+	bcView.BeaconHeight = 3
+	bcView.featureStateDB = stateDB
+	shardBlock := types.NewShardBlock()
+	shardBlock.Header.Height = 5
+	sView := NewShardBestState()
+	sView.BestBlock = shardBlock
+	sView.ShardHeight = 5
+	sView.BeaconHeight = 3
+	sView.BestBeaconHash = beaconHash
+	sView.transactionStateDB = stateDB
+	multiView := multiview.NewShardMultiView()
+	_, err = multiView.AddView(sView)
+	assert.Nil(t, err)
+
+	bc := &BlockChain{}
+	bc.beaconViewCache, _ = lru.New(10)
+	bc.beaconViewCache.Add(beaconHash, bcView)
+	bc.ShardChain = []*ShardChain{{
+		multiView:   multiView,
+		TxsVerifier: &TxsVerifier{feeEstimator: simulationFeeEstimator{minFeePerTx: minFeePerTx}},
+	}}
+	return bc
+}
+
+func TestBlockChain_SimulateTransaction(t *testing.T) {
+	Logger.Init(common.NewBackend(nil).Logger("test", true))
+	config.AbortParam()
+	dir, err := ioutil.TempDir("", "txsimulation")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	db, err := incdb.Open("leveldb", path.Join(dir, "shard"))
+	assert.Nil(t, err)
+	defer db.Close()
+
+	sanityErr := metadataCommon.NewMetadataTxError(metadataCommon.IssuingRequestValidateSanityDataError, errors.New("no amount"))
+	tests := []struct {
+		name        string
+		tx          *simulationTestTx
+		minFeePerTx uint64
+		valid       bool
+		checks      []TxSimulationCheck
+	}{
+		{
+			name:  "valid",
+			tx:    &simulationTestTx{},
+			valid: true,
+			checks: []TxSimulationCheck{
+				{Name: "load-data", Passed: true},
+				{Name: "sanity", Passed: true},
+				{Name: "correctness", Passed: true},
+				{Name: "fee", Passed: true},
+				{Name: "sanity-with-blockchain", Passed: true},
+				{Name: "double-spend-blockchain", Passed: true},
+			},
+		},
+		{
+			name: "data not loaded",
+			tx:   &simulationTestTx{loadErr: errors.New("no commitment"), sanityErr: sanityErr},
+			checks: []TxSimulationCheck{
+				{Name: "load-data", Error: "no commitment"},
+			},
+		},
+		{
+			name: "every failing check listed",
+			tx: &simulationTestTx{
+				sanityErr:      sanityErr,
+				doubleSpendErr: errors.New("serial number exists"),
+				panicMsg:       "malformed",
+			},
+			minFeePerTx: 100,
+			checks: []TxSimulationCheck{
+				{Name: "load-data", Passed: true},
+				{Name: "sanity", Code: sanityErr.Code, Error: sanityErr.Error()},
+				{Name: "correctness", Passed: true},
+				{Name: "fee", Error: "Transaction fee 10 PRV 0 Token is invalid"},
+				{Name: "sanity-with-blockchain", Error: "panic: malformed"},
+				{Name: "double-spend-blockchain", Error: "serial number exists"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bc := newSimulationTestChain(t, db, tt.minFeePerTx)
+			tx := &transaction.TxVersion1{}
+			tx.Version = 1
+			tx.Type = common.TxNormalType
+			tx.Fee = 10
+			tx.SetValidationEnv(tx_generic.DefaultValEnv())
+			tt.tx.TxVersion1 = tx
+			res, err := bc.SimulateTransaction(tt.tx)
+			assert.Nil(t, err)
+			assert.Equal(t, tx.Hash().String(), res.TxID)
+			assert.Equal(t, uint64(5), res.ShardHeight)
+			assert.Equal(t, uint64(3), res.BeaconHeight)
+			assert.Equal(t, tt.valid, res.Valid)
+			assert.Equal(t, tt.checks, res.Checks)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"log"
 	"os"
@@ -8,61 +10,218 @@ import (
 	"path/filepath"
 	"strconv"
 	"syscall"
+	"time"
 
+	"github.com/levietcuong2602/incognito-chain/blockchain/bridgeagg"
+	"github.com/levietcuong2602/incognito-chain/blockchain/committeestate"
+	"github.com/levietcuong2602/incognito-chain/blockchain/pdex"
 	"github.com/levietcuong2602/incognito-chain/blockchain/types"
+	"github.com/levietcuong2602/incognito-chain/config"
 	consensus "github.com/levietcuong2602/incognito-chain/consensus_v2"
 	"github.com/levietcuong2602/incognito-chain/dataaccessobject"
-	"github.com/levietcuong2602/incognito-chain/peerv2"
+	"github.com/levietcuong2602/incognito-chain/instruction"
+	"github.com/levietcuong2602/incognito-chain/mempool"
+	"github.com/levietcuong2602/incognito-chain/metadata"
+	"github.com/levietcuong2602/incognito-chain/portal"
+	zkp "github.com/levietcuong2602/incognito-chain/privacy/privacy_v1/zeroknowledge"
+	"github.com/levietcuong2602/incognito-chain/syncker/finishsync"
+	"github.com/levietcuong2602/incognito-chain/transaction"
 	"github.com/levietcuong2602/incognito-chain/trie"
+	"github.com/levietcuong2602/incognito-chain/txpool"
+	"github.com/levietcuong2602/incognito-chain/wallet"
+	"github.com/levietcuong2602/incognito-chain/wire"
+	libp2p "github.com/libp2p/go-libp2p-peer"
 
 	"github.com/levietcuong2602/incognito-chain/blockchain"
 	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/incdb"
 	_ "github.com/levietcuong2602/incognito-chain/incdb/lvdb"
-	"github.com/levietcuong2602/incognito-chain/mempool"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
+	"github.com/levietcuong2602/incognito-chain/privacy"
 	"github.com/levietcuong2602/incognito-chain/pubsub"
 )
 
-func makeBlockChain(databaseDir string, testNet bool) (*blockchain.BlockChain, error) {
-	blockchain.Logger.Init(common.NewBackend(nil).Logger("ChainCMD", true))
-	blockchain.BLogger.Init(common.NewBackend(nil).Logger("ChainCMD", true))
-	mempool.Logger.Init(common.NewBackend(nil).Logger("ChainCMD", true))
-	dataaccessobject.Logger.Init(common.NewBackend(nil).Logger("ChainCMD", true))
-	trie.Logger.Init(common.NewBackend(nil).Logger("ChainCMD", true))
-	db, err := incdb.Open("leveldb", filepath.Join(databaseDir))
+// loadNetworkConfig loads the config of the network, read from the NETWORK
+// and NETWORK_VERSION environment variables as the node does. --testnet
+// selects testnet-1 when NETWORK is not set.
+func loadNetworkConfig(testNet bool) {
+	if testNet && os.Getenv(config.NetworkKey) == "" {
+		os.Setenv(config.NetworkKey, config.TestNetNetwork)
+	}
+	config.LoadConfig()
+}
+
+// loadNetworkParam loads the config and the param of the network, see
+// loadNetworkConfig
+func loadNetworkParam(testNet bool) error {
+	loadNetworkConfig(testNet)
+	config.LoadParam()
+	portal.SetupParam()
+	if err := wallet.InitPublicKeyBurningAddressByte(); err != nil {
+		return err
+	}
+	zkp.InitCheckpoint(config.Param().BCHeightBreakPointNewZKP)
+	blockchain.CreateGenesisBlocks()
+	return nil
+}
+
+func initChainLoggers() {
+	backend := common.NewBackend(nil)
+	blockchain.Logger.Init(backend.Logger("ChainCMD", true))
+	blockchain.BLogger.Init(backend.Logger("ChainCMD", true))
+	incdb.Logger.Init(backend.Logger("ChainCMD", true))
+	dataaccessobject.Logger.Init(backend.Logger("ChainCMD", true))
+	trie.Logger.Init(backend.Logger("ChainCMD", true))
+	txpool.Logger.Init(backend.Logger("ChainCMD", true))
+	transaction.Logger.Init(backend.Logger("ChainCMD", true))
+	metadata.Logger.Init(backend.Logger("ChainCMD", true))
+	instruction.Logger.Init(backend.Logger("ChainCMD", true))
+	committeestate.Logger.Init(backend.Logger("ChainCMD", true))
+	pdex.Logger.Init(backend.Logger("ChainCMD", true))
+	bridgeagg.Logger.Init(backend.Logger("ChainCMD", true))
+	finishsync.Logger.Init(backend.Logger("ChainCMD", true))
+	consensus.Logger.Init(backend.Logger("ChainCMD", true))
+	portal.Logger.Init(backend.Logger("ChainCMD", true))
+	privacy.LoggerV1.Init(backend.Logger("ChainCMD", true))
+	privacy.LoggerV2.Init(backend.Logger("ChainCMD", true))
+}
+
+// makeBlockChain loads the chains of chainDataDir (the <data_dir>/<database_dir>
+// folder of a node) as the node does, without connecting to the network. The
+// network param must be loaded, see loadNetworkParam.
+func makeBlockChain(chainDataDir string) (*blockchain.BlockChain, error) {
+	initChainLoggers()
+	cfg := config.Config()
+	// the block storage of every chain is under <data_dir>/<database_dir>
+	cfg.DataDir, cfg.DatabaseDir = chainDataDir, ""
+	driver := pebbledb.DetectDriver(filepath.Join(chainDataDir, common.BeaconChainDatabaseDirectory), cfg.DatabaseDriver)
+	db, err := incdb.OpenMultipleDB(driver, chainDataDir)
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Open leveldb at %+v successfully", filepath.Join(databaseDir))
-	bc := blockchain.NewBlockChain(&blockchain.Config{}, false)
-	var bcParams *blockchain.Params
-	if testNet {
-		bcParams = &blockchain.ChainTestParam
-	} else {
-		bcParams = &blockchain.ChainMainParam
-	}
+	log.Printf("Open %v database at %+v successfully", driver, chainDataDir)
 	pb := pubsub.NewPubSubManager()
-	txPool := &mempool.TxPool{}
-	txPool.Init(&mempool.Config{
-		PubSubManager: pb,
-		DataBase:      db,
-		BlockChain:    bc,
-		ChainParams:   bcParams,
-	})
+	go pb.Start()
+	poolManager, err := txpool.NewPoolManager(common.MaxShardNumber, pb, time.Duration(cfg.TxPoolTTL)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	bc := blockchain.NewBlockChain(&blockchain.Config{}, false)
+	syncker := &archiveSyncker{bc: bc}
 	err = bc.Init(&blockchain.Config{
-		ChainParams:     bcParams,
 		DataBase:        db,
 		PubSubManager:   pb,
-		TxPool:          txPool,
+		Syncker:         syncker,
+		Server:          offlineServer{},
+		FeeEstimator:    make(map[byte]blockchain.FeeEstimator),
 		ConsensusEngine: &consensus.Engine{},
-		Highway:         &peerv2.ConnManager{},
+		PoolManager:     poolManager,
 	})
 	if err != nil {
 		return nil, err
 	}
+	// the inserted shard blocks are removed from the tx pool and their txs
+	// are validated with the temp pool, as the server does
+	txPool := &mempool.TxPool{}
+	txPool.Init(&mempool.Config{
+		BlockChain:    bc,
+		DataBase:      db,
+		FeeEstimator:  make(map[byte]*mempool.FeeEstimator),
+		TxLifeTime:    cfg.TxPoolTTL,
+		MaxTx:         cfg.TxPoolMaxTx,
+		PubSubManager: pb,
+	})
+	bc.AddTxPool(txPool)
+	tempTxPool := &mempool.TxPool{}
+	tempTxPool.Init(&mempool.Config{
+		BlockChain:    bc,
+		DataBase:      db,
+		FeeEstimator:  make(map[byte]*mempool.FeeEstimator),
+		MaxTx:         cfg.TxPoolMaxTx,
+		PubSubManager: pb,
+	})
+	go tempTxPool.Start(make(chan struct{}))
+	bc.AddTempTxPool(tempTxPool)
 	return bc, nil
 }
 
+// offlineServer is the server of a chain loaded without network, the new
+// views and blocks are not sent to any peer
+type offlineServer struct{}
+
+func (offlineServer) PushBlockToAll(types.BlockInterface, string, bool) error { return nil }
+func (offlineServer) PushMessageToBeacon(wire.Message, map[libp2p.ID]bool) error {
+	return nil
+}
+func (offlineServer) RequestMissingViewViaStream(string, [][]byte, int, string) error {
+	return nil
+}
+func (offlineServer) InsertNewShardView(*blockchain.ShardBestState)   {}
+func (offlineServer) InsertNewBeaconView(*blockchain.BeaconBestState) {}
+
+// crossShardWaitTimeout is how long a shard block waits for a cross shard
+// block while its source shard import makes no progress
+const crossShardWaitTimeout = 30 * time.Second
+
+// archiveSyncker gives the shard blocks inserted from an archive the cross
+// shard blocks they need, made from the blocks of the source shards imported
+// in bc. The shards are imported at the same time, a cross shard block not
+// imported yet is waited for while its source shard import goes on.
+type archiveSyncker struct {
+	bc *blockchain.BlockChain
+}
+
+func (s *archiveSyncker) GetCrossShardBlocksForShardProducer(*blockchain.ShardBestState, map[byte][]uint64) map[byte][]interface{} {
+	return nil
+}
+
+func (s *archiveSyncker) GetCrossShardBlocksForShardValidator(view *blockchain.ShardBestState, list map[byte][]uint64) (map[byte][]interface{}, error) {
+	res := map[byte][]interface{}{}
+	for fromShard, heights := range list {
+		for _, height := range heights {
+			blk, err := s.waitShardBlock(fromShard, height)
+			if err != nil {
+				return nil, err
+			}
+			crossShardBlock, err := types.CreateCrossShardBlock(blk, view.ShardID)
+			if err != nil {
+				return nil, err
+			}
+			res[fromShard] = append(res[fromShard], crossShardBlock)
+		}
+	}
+	return res, nil
+}
+
+func (s *archiveSyncker) waitShardBlock(sid byte, height uint64) (*types.ShardBlock, error) {
+	if int(sid) >= len(s.bc.ShardChain) {
+		return nil, fmt.Errorf("shard %v is not an active shard", sid)
+	}
+	chain := s.bc.ShardChain[sid]
+	lastHeight, lastProgress := chain.GetBestViewHeight(), time.Now()
+	for chain.GetBestViewHeight() < height {
+		if h := chain.GetBestViewHeight(); h != lastHeight {
+			lastHeight, lastProgress = h, time.Now()
+		}
+		if time.Since(lastProgress) > crossShardWaitTimeout {
+			return nil, fmt.Errorf("cross shard block of shard %v at height %v is not imported", sid, height)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	blocks, err := s.bc.GetShardBlockByHeight(height, sid)
+	if err != nil {
+		return nil, err
+	}
+	for _, blk := range blocks {
+		return blk, nil
+	}
+	return nil, fmt.Errorf("shard %v block at height %v not found", sid, height)
+}
+
+func (s *archiveSyncker) SyncMissingBeaconBlock(context.Context, string, common.Hash)      {}
+func (s *archiveSyncker) SyncMissingShardBlock(context.Context, string, byte, common.Hash) {}
+func (s *archiveSyncker) ReceiveBlock(interface{}, string, string)                         {}
+
 //default chainDataDir is data/testnet/block
 func backupShardChain(bc *blockchain.BlockChain, shardID byte, outDatadir string, fileName string) error {
 	if fileName == "" {
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/flatfile"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
+)
+
+type flatFileCheckResult struct {
+	Dir      string
+	Healthy  bool
+	Segments []flatfile.SegmentReport
+	Repair   *flatfile.RepairReport `json:",omitempty"`
+	Dropped  []string               `json:",omitempty"` //block hashes removed from the index by the repair
+}
+
+// checkFlatFiles audits every block flat file directory (beacon/blockstorage,
+// shardX/blockstorage) under chainDataDir. With repair, a torn tail of the last
+// segment is cut and the index of the dropped blocks is removed, the repaired
+// flat file is opened with the compression of the node.
+func checkFlatFiles(chainDataDir string, repair bool, compression flatfile.Compression) ([]flatFileCheckResult, error) {
+	dirs := []string{}
+	err := filepath.Walk(chainDataDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == "blockstorage" {
+			dirs = append(dirs, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := []flatFileCheckResult{}
+	for _, dir := range dirs {
+		log.Printf("Check flatfile %v", dir)
+		segments, err := flatfile.ScanDir(dir, blockchain.BlockFlatFileSize, true)
+		if err != nil {
+			return results, err
+		}
+		result := flatFileCheckResult{Dir: dir, Healthy: true, Segments: segments}
+		for _, segment := range segments {
+			if !segment.Healthy() {
+				result.Healthy = false
+			}
+		}
+		if !result.Healthy && repair {
+			if err := repairFlatFile(&result, compression); err != nil {
+				return results, err
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func repairFlatFile(result *flatFileCheckResult, compression flatfile.Compression) error {
+	ff, err := flatfile.NewFlatFileWithCompression(result.Dir, blockchain.BlockFlatFileSize, compression)
+	if err != nil {
+		return err
+	}
+	report, err := ff.RepairTail()
+	ff.Close()
+	if err != nil || report == nil {
+		return err
+	}
+	result.Repair = report
+
+	kvPath := filepath.Join(result.Dir, "blockKV")
+	db, err := incdb.Open(pebbledb.DetectDriver(kvPath, incdb.DefaultDriver), kvPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	removed, err := rawdbv2.DeleteFlatFileIndexFrom(db, report.FirstDroppedIndex)
+	if err != nil {
+		return err
+	}
+	for _, hash := range removed {
+		result.Dropped = append(result.Dropped, hash.String())
+	}
+	return nil
+}
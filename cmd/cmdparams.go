@@ -6,8 +6,8 @@ import (
 	"path/filepath"
 
 	"github.com/0xsirrush/color"
-	"github.com/levietcuong2602/incognito-chain/blockchain"
 	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
 	"github.com/jessevdk/go-flags"
 )
 
@@ -35,10 +35,24 @@ type params struct {
 	// shardIDs:
 	// "all": process all shards
 	// 1,2,3,4: shard 1, shard 2, shard 3, shard 4
-	ShardIDs     string `long:"shardids" description:"Process one or many Shard Chain with ShardID"`
-	ChainDataDir string `long:"chaindatadir" description:"Directory of Stored Blockchain Database"`
-	OutDataDir   string `long:"outdatadir" description:"Directory of Export Blockchain Data"`
-	FileName     string `long:"filename" description:"Filename of Backup Blockchin Data"`
+	ShardIDs      string `long:"shardids" description:"Process one or many Shard Chain with ShardID"`
+	ChainDataDir  string `long:"chaindatadir" description:"Directory of Stored Blockchain Database"`
+	OutDataDir    string `long:"outdatadir" description:"Directory of Export Blockchain Data"`
+	AncientDir    string `long:"ancientdir" description:"Directory of the ancient block store (ancient_dir of the node), default to <chain dir>/ancient"`
+	FileName      string `long:"filename" description:"Filename of Backup Blockchin Data"`
+	Repair        bool   `long:"repair" description:"Repair the issues found by check commands"`
+	FromHeight    uint64 `long:"fromheight" description:"First block height to export or import"`
+	ToHeight      uint64 `long:"toheight" description:"Last block height to export or import, 0 for the last one"`
+	DryRun        bool   `long:"dryrun" description:"Report the changes without writing them"`
+	BlockHash     string `long:"blockhash" description:"Hash of the block to inspect"`
+	FromBlockHash string `long:"fromblockhash" description:"Hash of the block to compare with, the previous block by default"`
+	DBKind        string `long:"dbkind" description:"State database kind: consensus, transaction, feature, reward or slash"`
+	Root          string `long:"root" description:"State root to inspect, instead of the root after --blockhash"`
+	ObjectType    string `long:"objecttype" description:"State object type name, e.g. serial-number, committee, token"`
+	Prefix        string `long:"prefix" description:"Key prefix to filter on, in hex"`
+	Limit         int    `long:"limit" description:"Maximum number of results, 0 for no limit"`
+	Key           string `long:"key" description:"Raw database or state object key to decode, in hex"`
+	Value         string `long:"value" description:"Raw value to decode, in hex, read from the database by default"`
 	// wallet
 	WalletName        string `long:"wallet" description:"Wallet Database Name file, default is 'wallet'"`
 	WalletPassphrase  string `long:"walletpassphrase" description:"Wallet passphrase"`
@@ -76,9 +90,9 @@ func loadParams() (*params, error) {
 	}
 	cfg.DataDir = common.CleanAndExpandPath(cfg.DataDir, defaultHomeDir)
 	if cfg.TestNet {
-		cfg.DataDir = filepath.Join(cfg.DataDir, blockchain.ChainTestParam.Name)
+		cfg.DataDir = filepath.Join(cfg.DataDir, config.NewDefaultParam(config.TestNetNetwork+"-"+config.TestNetVersion1).Name)
 	} else {
-		cfg.DataDir = filepath.Join(cfg.DataDir, blockchain.ChainMainParam.Name)
+		cfg.DataDir = filepath.Join(cfg.DataDir, config.NewDefaultParam(config.MainnetNetwork).Name)
 	}
 
 	return &cfg, nil
@@ -1,13 +1,25 @@
 package main
 
 const (
-	createWalletCmd        = "createwallet"
-	listWalletAccountCmd   = "listaccounts"
-	getWalletAccountCmd    = "getaccount"
-	createWalletAccountCmd = "createaccount"
-	getPrivacyTokenID      = "getprivacytokenid"
-	backupChain            = "backupchain"
-	restoreChain           = "restorechain"
+	createWalletCmd         = "createwallet"
+	listWalletAccountCmd    = "listaccounts"
+	getWalletAccountCmd     = "getaccount"
+	createWalletAccountCmd  = "createaccount"
+	getPrivacyTokenID       = "getprivacytokenid"
+	backupChain             = "backupchain"
+	restoreChain            = "restorechain"
+	migrateDB               = "migratedb"
+	checkFlatFile           = "checkflatfile"
+	verifyBackupCmd         = "verifybackup"
+	dbSpaceCmd              = "dbspace"
+	exportChainCmd          = "exportchain"
+	importChainCmd          = "importchain"
+	migrateSchemaCmd        = "migrateschema"
+	stateDiffCmd            = "statediff"
+	rebuildStateSnapshotCmd = "rebuildstatesnapshot"
+	checkStateSnapshotCmd   = "checkstatesnapshot"
+	listStateObjectsCmd     = "liststateobjects"
+	decodeKeyCmd            = "decodekey"
 )
 
 var CmdList = []string{
@@ -18,4 +30,16 @@ var CmdList = []string{
 	getPrivacyTokenID,
 	backupChain,
 	restoreChain,
+	migrateDB,
+	checkFlatFile,
+	verifyBackupCmd,
+	dbSpaceCmd,
+	exportChainCmd,
+	importChainCmd,
+	migrateSchemaCmd,
+	stateDiffCmd,
+	rebuildStateSnapshotCmd,
+	checkStateSnapshotCmd,
+	listStateObjectsCmd,
+	decodeKeyCmd,
 }
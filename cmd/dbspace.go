@@ -0,0 +1,98 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
+)
+
+type familySpace struct {
+	Family     string
+	Keys       uint64
+	KeyBytes   uint64
+	ValueBytes uint64
+}
+
+type databaseSpace struct {
+	Dir        string
+	Driver     string
+	Keys       uint64
+	TotalBytes uint64
+	Families   []familySpace //largest first
+}
+
+// reportDatabaseSpace walks every leveldb or pebble database found under
+// chainDataDir (beacon, shardX, blockstorage/blockKV ...) and sums the size of
+// its keys and values per key family. The databases are opened read only, a
+// copy of a production chain directory can be walked.
+func reportDatabaseSpace(chainDataDir string) ([]databaseSpace, error) {
+	dbDirs := []string{}
+	err := filepath.Walk(chainDataDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && (pebbledb.IsLevelDBDir(path) || pebbledb.IsPebbleDir(path)) {
+			dbDirs = append(dbDirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := []databaseSpace{}
+	for _, dir := range dbDirs {
+		log.Printf("Walk database %v", dir)
+		result, err := walkDatabaseSpace(dir)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+func walkDatabaseSpace(dir string) (*databaseSpace, error) {
+	driver := pebbledb.DetectDriver(dir, incdb.DefaultDriver)
+	db, err := incdb.OpenReadOnly(driver, dir)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	families := map[string]*familySpace{}
+	result := &databaseSpace{Dir: dir, Driver: driver}
+	it := db.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		name := rawdbv2.KeyFamily(it.Key())
+		f, ok := families[name]
+		if !ok {
+			f = &familySpace{Family: name}
+			families[name] = f
+		}
+		f.Keys++
+		f.KeyBytes += uint64(len(it.Key()))
+		f.ValueBytes += uint64(len(it.Value()))
+		result.Keys++
+		result.TotalBytes += uint64(len(it.Key()) + len(it.Value()))
+		if result.Keys%1000000 == 0 {
+			log.Printf("... %v keys walked", result.Keys)
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	for _, f := range families {
+		result.Families = append(result.Families, *f)
+	}
+	sort.Slice(result.Families, func(i, j int) bool {
+		return result.Families[i].KeyBytes+result.Families[i].ValueBytes > result.Families[j].KeyBytes+result.Families[j].ValueBytes
+	})
+	return result, nil
+}
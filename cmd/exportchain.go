@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/levietcuong2602/incognito-chain/common"
+)
+
+// archiveChainIDs returns the chains to export: -1 for beacon, then the shards
+// of shardIDs ("all" selects every shard folder found in chainDataDir)
+func archiveChainIDs(chainDataDir string, beacon bool, shardIDs string) ([]int, error) {
+	ids := []int{}
+	if beacon {
+		ids = append(ids, common.BeaconChainID)
+	}
+	if shardIDs == "" {
+		return ids, nil
+	}
+	if shardIDs == "all" {
+		files, err := ioutil.ReadDir(chainDataDir)
+		if err != nil {
+			return nil, err
+		}
+		shards := []int{}
+		for _, f := range files {
+			if !f.IsDir() || !strings.HasPrefix(f.Name(), common.ShardChainDatabaseDirectory) {
+				continue
+			}
+			if sid, err := strconv.Atoi(strings.TrimPrefix(f.Name(), common.ShardChainDatabaseDirectory)); err == nil {
+				shards = append(shards, sid)
+			}
+		}
+		sort.Ints(shards)
+		return append(ids, shards...), nil
+	}
+	for _, value := range strings.Split(shardIDs, ",") {
+		sid, err := strconv.Atoi(value)
+		if err != nil || sid < 0 || sid >= common.MaxShardNumber {
+			return nil, fmt.Errorf("invalid shard id %v", value)
+		}
+		ids = append(ids, sid)
+	}
+	return ids, nil
+}
+
+func archiveChainDir(cid int) string {
+	if cid == common.BeaconChainID {
+		return common.BeaconChainDatabaseDirectory
+	}
+	return common.ShardChainDatabaseDirectory + strconv.Itoa(cid)
+}
+
+// archiveAncientDir returns the ancient store folder of a chain, ancientDir is
+// the ancient_dir of the node, empty for the default <chain dir>/ancient
+func archiveAncientDir(chainDataDir, ancientDir, chainDir string) string {
+	if ancientDir != "" {
+		return filepath.Join(ancientDir, chainDir)
+	}
+	return filepath.Join(chainDataDir, chainDir, "ancient")
+}
+
+// exportChains writes the finalized blocks of heights [from, to] of every
+// selected chain of chainDataDir to <outDir>/archive-<chain>.jsonl.gz. The
+// block storage and the ancient store are read directly and are not modified,
+// the node does not need to be loaded.
+func exportChains(chainDataDir, ancientDir, outDir string, chainIDs []int, from, to uint64) error {
+	if outDir == "" {
+		outDir = "./"
+	}
+	if err := os.MkdirAll(outDir, 0700); err != nil {
+		return err
+	}
+	for _, cid := range chainIDs {
+		chainDir := archiveChainDir(cid)
+		ffPath := filepath.Join(chainDataDir, chainDir, "blockstorage")
+		if _, err := os.Stat(ffPath); err != nil {
+			return err
+		}
+		blockStorage, err := blockchain.OpenBlockStorageReadOnly(ffPath, archiveAncientDir(chainDataDir, ancientDir, chainDir), cid)
+		if err != nil {
+			return fmt.Errorf("open %v: %v", chainDir, err)
+		}
+		file := filepath.Join(outDir, "archive-"+chainDir+".jsonl.gz")
+		count, err := exportChain(blockStorage, file, cid, from, to)
+		blockStorage.Close()
+		if err != nil {
+			return fmt.Errorf("export %v: %v", chainDir, err)
+		}
+		log.Printf("Export %v blocks of %v to %v", count, chainDir, file)
+	}
+	return nil
+}
+
+func exportChain(blockStorage *blockchain.BlockStorage, file string, cid int, from, to uint64) (uint64, error) {
+	fh, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer fh.Close()
+	aw, err := blockchain.NewArchiveWriter(fh, cid, from, to, true)
+	if err != nil {
+		return 0, err
+	}
+	count, err := blockStorage.ExportArchive(aw, from, to)
+	if err != nil {
+		return count, err
+	}
+	return count, aw.Close()
+}
+
+// importChains replays the archives of fileNames (beacon archives first)
+// through the block insert path of bc
+func importChains(bc *blockchain.BlockChain, fileNames []string, from, to uint64) error {
+	// Watch for Ctrl-C while the import is running, the import stops at the next block
+	interrupt := make(chan os.Signal, 1)
+	stop := make(chan struct{})
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	go func() {
+		<-interrupt
+		log.Println("Interrupted during import, stopping at next block")
+		close(stop)
+	}()
+
+	readers := []*blockchain.ArchiveReader{}
+	for _, fileName := range fileNames {
+		fh, err := os.Open(fileName)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		ar, err := blockchain.NewArchiveReader(fh)
+		if err != nil {
+			return fmt.Errorf("%v: %v", fileName, err)
+		}
+		readers = append(readers, ar)
+	}
+	// the shards read the beacon state, the beacon archives go first, then
+	// the shards are imported at the same time as they need the cross shard
+	// blocks of each other
+	shardReaders := []*blockchain.ArchiveReader{}
+	for _, ar := range readers {
+		if ar.Header.ChainID != common.BeaconChainID {
+			shardReaders = append(shardReaders, ar)
+			continue
+		}
+		if err := importArchive(bc, ar, from, to, stop); err != nil {
+			return err
+		}
+	}
+	select {
+	case <-stop:
+		return nil
+	default:
+	}
+	errs := make(chan error, len(shardReaders))
+	for _, ar := range shardReaders {
+		go func(ar *blockchain.ArchiveReader) {
+			errs <- importArchive(bc, ar, from, to, stop)
+		}(ar)
+	}
+	var err error
+	for range shardReaders {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func importArchive(bc *blockchain.BlockChain, ar *blockchain.ArchiveReader, from, to uint64, stop <-chan struct{}) error {
+	count, err := bc.ImportArchive(ar, from, to, stop)
+	if err != nil {
+		return fmt.Errorf("import %v: %v", archiveChainDir(ar.Header.ChainID), err)
+	}
+	log.Printf("Import %v blocks of %v", count, archiveChainDir(ar.Header.ChainID))
+	return nil
+}
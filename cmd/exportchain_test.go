@@ -0,0 +1,54 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
+	devframework "github.com/levietcuong2602/incognito-chain/testsuite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportChains(t *testing.T) {
+	dir, err := ioutil.TempDir("", "importchain")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	os.Setenv(config.ConfigDirKey, "../config")
+
+	//produce some blocks on a local simulated node
+	srcDir := filepath.Join(dir, "src")
+	node := devframework.NewStandaloneSimulation("import", devframework.Config{Network: devframework.ID_LOCAL, DataDir: srcDir})
+	config.Config().DataDir, config.Config().DatabaseDir = srcDir, ""
+	node.Init()
+	for i := 0; i < 5; i++ {
+		node.GenerateBlock().NextRound()
+	}
+	src := node.GetBlockchain()
+	assert.True(t, src.BeaconChain.GetFinalViewHeight() > 1)
+
+	//export the finalized blocks of every chain
+	files := []string{}
+	blockStorages := map[int]*blockchain.BlockStorage{common.BeaconChainID: src.BeaconChain.BlockStorage}
+	for sid, chain := range src.ShardChain {
+		blockStorages[sid] = chain.BlockStorage
+	}
+	for cid, blockStorage := range blockStorages {
+		file := filepath.Join(dir, "archive-"+archiveChainDir(cid)+".jsonl.gz")
+		_, err := exportChain(blockStorage, file, cid, 0, 0)
+		assert.Nil(t, err)
+		files = append(files, file)
+	}
+
+	//import them in a new node
+	bc, err := makeBlockChain(filepath.Join(dir, "dst"))
+	assert.Nil(t, err)
+	assert.Nil(t, importChains(bc, files, 0, 0))
+	assert.Equal(t, src.BeaconChain.GetFinalView().GetHash().String(), bc.BeaconChain.GetBestView().GetHash().String())
+	for sid, chain := range src.ShardChain {
+		assert.Equal(t, chain.GetFinalView().GetHash().String(), bc.ShardChain[sid].GetBestView().GetHash().String())
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
+	"github.com/levietcuong2602/incognito-chain/trie"
+)
+
+// DecodedDBKey is the result of decodekey, the decoded key and value as a
+// database key and, when it is one, as a state object
+type DecodedDBKey struct {
+	Key         string
+	DBKey       *rawdbv2.DecodedKey       `json:",omitempty"`
+	Value       interface{}               `json:",omitempty"`
+	StateObject *statedb.StateObjectEntry `json:",omitempty"`
+}
+
+// openChainDBReadOnly opens the database of chain cid of chainDataDir without
+// writing to it, a copy of a production chain directory can be inspected
+func openChainDBReadOnly(chainDataDir string, cid int) (incdb.Database, error) {
+	blockchain.Logger.Init(common.NewBackend(nil).Logger("ChainCMD", true))
+	trie.Logger.Init(common.NewBackend(nil).Logger("ChainCMD", true))
+	dbPath := filepath.Join(chainDataDir, archiveChainDir(cid))
+	return incdb.OpenReadOnly(pebbledb.DetectDriver(dbPath, incdb.DefaultDriver), dbPath)
+}
+
+// inspectStateRoot returns the root of the kind state database given as is or
+// after the block hash
+func inspectStateRoot(db incdb.Database, cid int, root, hash, kind string) (common.Hash, error) {
+	if root != "" {
+		r, err := common.Hash{}.NewHashFromStr(root)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		return *r, nil
+	}
+	if hash == "" || kind == "" {
+		return common.Hash{}, errors.New("a state root or a block hash with a state database kind is needed")
+	}
+	blockHash, err := common.Hash{}.NewHashFromStr(hash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return blockchain.GetStateRootByBlockHash(db, cid, *blockHash, kind)
+}
+
+// objectTypeOfName returns the object type of name, UnknownObjectType to find
+// it from the keys when name is empty
+func objectTypeOfName(name string) (int, error) {
+	if name == "" {
+		return statedb.UnknownObjectType, nil
+	}
+	objectType, ok := statedb.ObjectTypeByName(name)
+	if !ok {
+		return 0, errors.New("unknown object type " + name)
+	}
+	return objectType, nil
+}
+
+// listStateObjects lists the state objects of the trie at root, or after the
+// block hash for the state database kind, of chain cid. See
+// statedb.ListStateObjects for the filters.
+func listStateObjects(chainDataDir string, cid int, root, hash, kind, objectName, prefix string, limit int) (*statedb.StateObjectList, error) {
+	prefixBytes, err := hex.DecodeString(prefix)
+	if err != nil {
+		return nil, err
+	}
+	db, err := openChainDBReadOnly(chainDataDir, cid)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	stateRoot, err := inspectStateRoot(db, cid, root, hash, kind)
+	if err != nil {
+		return nil, err
+	}
+	return statedb.ListStateObjects(statedb.NewDatabaseAccessWarper(db), stateRoot, objectName, prefixBytes, limit)
+}
+
+// decodeDBKey decodes the raw key and value in hex. Without value, it is read
+// from the database of chain cid of chainDataDir, from its state trie when a
+// root or a block hash is given and from the raw database otherwise. A key
+// of 32 bytes is also decoded as a state object key, as is the key of a state
+// snapshot entry.
+func decodeDBKey(chainDataDir string, cid int, root, hash, kind, objectName, key, value string) (*DecodedDBKey, error) {
+	keyBytes, err := hex.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+	valueBytes, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	objectType, err := objectTypeOfName(objectName)
+	if err != nil {
+		return nil, err
+	}
+	res := &DecodedDBKey{Key: key, DBKey: rawdbv2.DecodeKey(keyBytes)}
+	objectKey := []byte{}
+	if len(keyBytes) == common.HashSize {
+		objectKey = keyBytes
+	} else if res.DBKey.Family == "st-snap" && len(keyBytes) > common.HashSize {
+		objectKey = keyBytes[len(keyBytes)-common.HashSize:]
+	}
+
+	if len(valueBytes) == 0 && chainDataDir != "" {
+		db, err := openChainDBReadOnly(chainDataDir, cid)
+		if err != nil {
+			return nil, err
+		}
+		defer db.Close()
+		if root != "" || hash != "" {
+			if len(keyBytes) != common.HashSize {
+				return nil, errors.New("a state object key has 32 bytes")
+			}
+			stateRoot, err := inspectStateRoot(db, cid, root, hash, kind)
+			if err != nil {
+				return nil, err
+			}
+			res.DBKey = nil
+			res.StateObject, err = statedb.GetStateObject(statedb.NewDatabaseAccessWarper(db), stateRoot, common.BytesToHash(keyBytes), objectType)
+			return res, err
+		}
+		if valueBytes, err = db.Get(keyBytes); err != nil {
+			return nil, err
+		}
+	}
+	res.Value = rawdbv2.DecodeValue(valueBytes)
+	if len(objectKey) != 0 && len(valueBytes) != 0 {
+		entry := statedb.DecodeStateObject(common.BytesToHash(objectKey), valueBytes, objectType)
+		if entry.ObjectType != statedb.UnknownObjectType {
+			res.StateObject = &entry
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
+)
+
+const migrateBatchSize = 4 * 1024 * 1024
+
+// migrateChainDir copies a leveldb chain directory (data/<network>/block) into
+// outDir. Every leveldb database found under srcDir (beacon, shardX, blockKV ...)
+// is rewritten with the pebble driver, the other files (flat file segments,
+// backup info) are copied as is. Each database is verified after being copied.
+func migrateChainDir(srcDir, outDir string) error {
+	if srcDir == "" || outDir == "" {
+		return fmt.Errorf("both chaindatadir and outdatadir are required")
+	}
+	srcDir = filepath.Clean(srcDir)
+	outDir = filepath.Clean(outDir)
+	if srcDir == outDir {
+		return fmt.Errorf("outdatadir must be different from chaindatadir")
+	}
+
+	// collect the databases first, everything else is copied as is
+	dbDirs := []string{}
+	files := []string{}
+	isDBDir := map[string]bool{}
+	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if pebbledb.IsLevelDBDir(path) {
+				dbDirs = append(dbDirs, path)
+				isDBDir[path] = true
+			}
+			return nil
+		}
+		if !isDBDir[filepath.Dir(path)] {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range dbDirs {
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(outDir, rel)
+		log.Printf("Migrate database %v -> %v", path, dst)
+		n, err := migrateDatabase(path, dst)
+		if err != nil {
+			return fmt.Errorf("migrate %v: %v", path, err)
+		}
+		if err := verifyMigratedDatabase(path, dst, n); err != nil {
+			return fmt.Errorf("verify %v: %v", path, err)
+		}
+		log.Printf("Database %v migrated and verified, %v keys", rel, n)
+	}
+	for _, path := range files {
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		if err := copyFile(path, filepath.Join(outDir, rel)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateDatabase streams all key/value pairs of the leveldb database at src
+// into a new pebble database at dst and returns the number of copied keys.
+func migrateDatabase(src, dst string) (uint64, error) {
+	srcDB, err := incdb.OpenReadOnly("leveldb", src)
+	if err != nil {
+		return 0, err
+	}
+	defer srcDB.Close()
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return 0, err
+	}
+	dstDB, err := incdb.Open(pebbledb.DriverName, dst)
+	if err != nil {
+		return 0, err
+	}
+	defer dstDB.Close()
+
+	it := srcDB.NewIterator()
+	defer it.Release()
+	batch := dstDB.NewBatch()
+	count := uint64(0)
+	for it.Next() {
+		if err := batch.Put(common.CopyBytes(it.Key()), common.CopyBytes(it.Value())); err != nil {
+			return count, err
+		}
+		count++
+		if batch.ValueSize() > migrateBatchSize {
+			if err := batch.Write(); err != nil {
+				return count, err
+			}
+			batch.Reset()
+		}
+		if count%1000000 == 0 {
+			log.Printf("... %v keys copied", count)
+		}
+	}
+	if err := it.Error(); err != nil {
+		return count, err
+	}
+	if err := batch.Write(); err != nil {
+		return count, err
+	}
+	return count, dstDB.Compact(nil, nil)
+}
+
+// verifyMigratedDatabase walks both databases side by side and checks that they
+// hold exactly the same key/value pairs.
+func verifyMigratedDatabase(src, dst string, expected uint64) error {
+	srcDB, err := incdb.OpenReadOnly("leveldb", src)
+	if err != nil {
+		return err
+	}
+	defer srcDB.Close()
+	dstDB, err := incdb.Open(pebbledb.DriverName, dst)
+	if err != nil {
+		return err
+	}
+	defer dstDB.Close()
+
+	srcIt, dstIt := srcDB.NewIterator(), dstDB.NewIterator()
+	defer srcIt.Release()
+	defer dstIt.Release()
+	count := uint64(0)
+	for {
+		srcOk, dstOk := srcIt.Next(), dstIt.Next()
+		if srcOk != dstOk {
+			return fmt.Errorf("key count mismatch after %v keys", count)
+		}
+		if !srcOk {
+			break
+		}
+		if !bytes.Equal(srcIt.Key(), dstIt.Key()) {
+			return fmt.Errorf("key mismatch at %v: %x != %x", count, srcIt.Key(), dstIt.Key())
+		}
+		if !bytes.Equal(srcIt.Value(), dstIt.Value()) {
+			return fmt.Errorf("value mismatch for key %x", srcIt.Key())
+		}
+		count++
+	}
+	if err := srcIt.Error(); err != nil {
+		return err
+	}
+	if err := dstIt.Error(); err != nil {
+		return err
+	}
+	if count != expected {
+		return fmt.Errorf("expect %v keys, found %v", expected, count)
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/migration"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
+)
+
+// migrateSchema runs the pending schema migration steps on the beacon and
+// shard databases of chainDataDir, the node must be stopped. With dryRun the
+// steps walk the data without writing anything.
+func migrateSchema(chainDataDir string, dryRun bool) ([]*migration.Report, error) {
+	dataaccessobject.Logger.Init(common.NewBackend(nil).Logger("ChainCMD", true))
+	dbs := map[int]incdb.Database{}
+	defer func() {
+		for _, db := range dbs {
+			db.Close()
+		}
+	}()
+	for cid := common.BeaconChainID; cid < common.MaxShardNumber; cid++ {
+		dbPath := filepath.Join(chainDataDir, archiveChainDir(cid))
+		if _, err := os.Stat(dbPath); err != nil {
+			continue
+		}
+		driver := pebbledb.DetectDriver(dbPath, incdb.DefaultDriver)
+		var db incdb.Database
+		var err error
+		if dryRun {
+			db, err = incdb.OpenReadOnly(driver, dbPath)
+		} else {
+			db, err = incdb.Open(driver, dbPath)
+		}
+		if err != nil {
+			return nil, err
+		}
+		dbs[cid] = db
+	}
+
+	lastLog := time.Now()
+	progress := func(p migration.Progress) {
+		if p.Done || time.Since(lastLog) > 5*time.Second {
+			log.Printf("Chain %v step %v (%v): %v keys visited, %v writes, done %v", p.ChainID, p.Version, p.Description, p.Keys, p.Writes, p.Done)
+			lastLog = time.Now()
+		}
+	}
+	log.Printf("Latest schema version %v, dry run %v", migration.LatestVersion(), dryRun)
+	return migration.MigrateAll(dbs, migration.Options{DryRun: dryRun, Progress: progress})
+}
@@ -2,13 +2,16 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/levietcuong2602/incognito-chain/privacy"
 	"log"
+	"os"
 	"strconv"
 	"strings"
 
-	"github.com/levietcuong2602/incognito-chain/blockchain"
 	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/flatfile"
 )
 
 func parseToJsonString(data interface{}) ([]byte, error) {
@@ -130,7 +133,11 @@ func processCmd() {
 				log.Println("No Expected Params")
 				return
 			}
-			bc, err := makeBlockChain(cfg.ChainDataDir, cfg.TestNet)
+			if err := loadNetworkParam(cfg.TestNet); err != nil {
+				log.Println("Error load network param ", err)
+				return
+			}
+			bc, err := makeBlockChain(cfg.ChainDataDir)
 			if err != nil {
 				log.Println("Error create blockchain variable ", err)
 				return
@@ -145,13 +152,7 @@ func processCmd() {
 			if cfg.ShardIDs != "" {
 				// all shard
 				if cfg.ShardIDs == "all" {
-					var numberOfShards int
-					if cfg.TestNet {
-						numberOfShards = blockchain.ChainTestParam.ActiveShards
-					} else {
-						numberOfShards = blockchain.ChainMainParam.ActiveShards
-					}
-					for i := 0; i < numberOfShards; i++ {
+					for i := 0; i < config.Param().ActiveShards; i++ {
 						shardIDs = append(shardIDs, byte(i))
 					}
 				} else {
@@ -193,7 +194,11 @@ func processCmd() {
 				log.Println("No Backup File to Process")
 				return
 			}
-			bc, err := makeBlockChain(cfg.ChainDataDir, cfg.TestNet)
+			if err := loadNetworkParam(cfg.TestNet); err != nil {
+				log.Println("Error load network param ", err)
+				return
+			}
+			bc, err := makeBlockChain(cfg.ChainDataDir)
 			if err != nil {
 				log.Println("Error create blockchain variable ", err)
 				return
@@ -213,5 +218,246 @@ func processCmd() {
 				}
 			}
 		}
+	case migrateDB:
+		{
+			if cfg.ChainDataDir == "" || cfg.OutDataDir == "" {
+				log.Println("No Expected Params")
+				return
+			}
+			if err := migrateChainDir(cfg.ChainDataDir, cfg.OutDataDir); err != nil {
+				log.Printf("Migrate database failed, err %+v", err)
+				return
+			}
+			log.Printf("Migrate database to %v successfully", cfg.OutDataDir)
+		}
+	case checkFlatFile:
+		{
+			if cfg.ChainDataDir == "" {
+				log.Println("No Expected Params")
+				return
+			}
+			compression := flatfile.CompressionNone
+			if cfg.Repair {
+				loadNetworkConfig(cfg.TestNet)
+				var err error
+				compression, err = flatfile.ParseCompression(config.Config().FlatFileCompression)
+				if err != nil {
+					log.Println(err)
+					return
+				}
+			}
+			results, err := checkFlatFiles(cfg.ChainDataDir, cfg.Repair, compression)
+			if err != nil {
+				log.Printf("Check flatfile failed, err %+v", err)
+			}
+			result, err := parseToJsonString(results)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			log.Println(string(result))
+		}
+	case verifyBackupCmd:
+		{
+			if cfg.ChainDataDir == "" {
+				log.Println("No Expected Params")
+				return
+			}
+			report, err := verifyBackup(cfg.ChainDataDir, cfg.OutDataDir)
+			if err != nil {
+				log.Printf("Verify backup failed, err %+v", err)
+				os.Exit(1)
+			}
+			result, err := parseToJsonString(report)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			fmt.Println(string(result))
+			if !report.Passed {
+				os.Exit(1)
+			}
+		}
+	case exportChainCmd:
+		{
+			if cfg.ChainDataDir == "" || (cfg.Beacon == false && cfg.ShardIDs == "") {
+				log.Println("No Expected Params")
+				return
+			}
+			chainIDs, err := archiveChainIDs(cfg.ChainDataDir, cfg.Beacon, cfg.ShardIDs)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			if err := exportChains(cfg.ChainDataDir, cfg.AncientDir, cfg.OutDataDir, chainIDs, cfg.FromHeight, cfg.ToHeight); err != nil {
+				log.Printf("Export chain failed, err %+v", err)
+				os.Exit(1)
+			}
+		}
+	case importChainCmd:
+		{
+			if cfg.ChainDataDir == "" || cfg.FileName == "" {
+				log.Println("No Expected Params")
+				return
+			}
+			if err := loadNetworkParam(cfg.TestNet); err != nil {
+				log.Println("Error load network param ", err)
+				return
+			}
+			bc, err := makeBlockChain(cfg.ChainDataDir)
+			if err != nil {
+				log.Println("Error create blockchain variable ", err)
+				return
+			}
+			if err := importChains(bc, strings.Split(cfg.FileName, ","), cfg.FromHeight, cfg.ToHeight); err != nil {
+				log.Printf("Import chain failed, err %+v", err)
+				os.Exit(1)
+			}
+		}
+	case migrateSchemaCmd:
+		{
+			if cfg.ChainDataDir == "" {
+				log.Println("No Expected Params")
+				return
+			}
+			reports, err := migrateSchema(cfg.ChainDataDir, cfg.DryRun)
+			result, jsonErr := parseToJsonString(reports)
+			if jsonErr == nil {
+				fmt.Println(string(result))
+			}
+			if err != nil {
+				log.Printf("Migrate schema failed, err %+v", err)
+				os.Exit(1)
+			}
+		}
+	case dbSpaceCmd:
+		{
+			if cfg.ChainDataDir == "" {
+				log.Println("No Expected Params")
+				return
+			}
+			results, err := reportDatabaseSpace(cfg.ChainDataDir)
+			if err != nil {
+				log.Printf("Report database space failed, err %+v", err)
+			}
+			result, err := parseToJsonString(results)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			fmt.Println(string(result))
+		}
+	case stateDiffCmd:
+		{
+			if cfg.ChainDataDir == "" || cfg.BlockHash == "" || cfg.DBKind == "" {
+				log.Println("No Expected Params")
+				return
+			}
+			cid := int(cfg.ShardID)
+			if cfg.Beacon {
+				cid = common.BeaconChainID
+			}
+			diff, err := diffState(cfg.ChainDataDir, cid, cfg.BlockHash, cfg.FromBlockHash, cfg.DBKind)
+			if err != nil {
+				log.Printf("State diff failed, err %+v", err)
+				os.Exit(1)
+			}
+			result, err := parseToJsonString(diff)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			fmt.Println(string(result))
+		}
+	case rebuildStateSnapshotCmd:
+		{
+			if cfg.ChainDataDir == "" || cfg.BlockHash == "" || cfg.DBKind == "" {
+				log.Println("No Expected Params")
+				return
+			}
+			cid := int(cfg.ShardID)
+			if cfg.Beacon {
+				cid = common.BeaconChainID
+			}
+			res, err := rebuildStateSnapshot(cfg.ChainDataDir, cid, cfg.BlockHash, cfg.DBKind)
+			if err != nil {
+				log.Printf("Rebuild state snapshot failed, err %+v", err)
+				os.Exit(1)
+			}
+			result, err := parseToJsonString(res)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			fmt.Println(string(result))
+		}
+	case checkStateSnapshotCmd:
+		{
+			if cfg.ChainDataDir == "" || cfg.DBKind == "" {
+				log.Println("No Expected Params")
+				return
+			}
+			cid := int(cfg.ShardID)
+			if cfg.Beacon {
+				cid = common.BeaconChainID
+			}
+			res, err := checkStateSnapshot(cfg.ChainDataDir, cid, cfg.BlockHash, cfg.DBKind, cfg.Repair)
+			if res != nil {
+				if result, jsonErr := parseToJsonString(res); jsonErr == nil {
+					fmt.Println(string(result))
+				}
+			}
+			if err != nil {
+				log.Printf("Check state snapshot failed, err %+v", err)
+				os.Exit(1)
+			}
+			if len(res.Check.Mismatches) != 0 && !res.Rebuilt {
+				os.Exit(1)
+			}
+		}
+	case listStateObjectsCmd:
+		{
+			if cfg.ChainDataDir == "" || (cfg.Root == "" && (cfg.BlockHash == "" || cfg.DBKind == "")) {
+				log.Println("No Expected Params")
+				return
+			}
+			cid := int(cfg.ShardID)
+			if cfg.Beacon {
+				cid = common.BeaconChainID
+			}
+			res, err := listStateObjects(cfg.ChainDataDir, cid, cfg.Root, cfg.BlockHash, cfg.DBKind, cfg.ObjectType, cfg.Prefix, cfg.Limit)
+			if err != nil {
+				log.Printf("List state objects failed, err %+v", err)
+				os.Exit(1)
+			}
+			result, err := parseToJsonString(res)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			fmt.Println(string(result))
+		}
+	case decodeKeyCmd:
+		{
+			if cfg.Key == "" {
+				log.Println("No Expected Params")
+				return
+			}
+			cid := int(cfg.ShardID)
+			if cfg.Beacon {
+				cid = common.BeaconChainID
+			}
+			res, err := decodeDBKey(cfg.ChainDataDir, cid, cfg.Root, cfg.BlockHash, cfg.DBKind, cfg.ObjectType, cfg.Key, cfg.Value)
+			if err != nil {
+				log.Printf("Decode key failed, err %+v", err)
+				os.Exit(1)
+			}
+			result, err := parseToJsonString(res)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			fmt.Println(string(result))
+		}
 	}
 }
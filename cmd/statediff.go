@@ -0,0 +1,47 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
+	"github.com/levietcuong2602/incognito-chain/trie"
+)
+
+// diffState compares the kind state database after the block hash of chain
+// cid with the one after the block fromHash, its previous block when fromHash
+// is empty. The chain database of chainDataDir is read directly, the node
+// must be stopped.
+func diffState(chainDataDir string, cid int, hash, fromHash, kind string) (*statedb.StateDiff, error) {
+	blockchain.Logger.Init(common.NewBackend(nil).Logger("ChainCMD", true))
+	trie.Logger.Init(common.NewBackend(nil).Logger("ChainCMD", true))
+	toHash, err := common.Hash{}.NewHashFromStr(hash)
+	if err != nil {
+		return nil, err
+	}
+	dbPath := filepath.Join(chainDataDir, archiveChainDir(cid))
+	db, err := incdb.Open(pebbledb.DetectDriver(dbPath, incdb.DefaultDriver), dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var from *common.Hash
+	if fromHash != "" {
+		if from, err = (common.Hash{}).NewHashFromStr(fromHash); err != nil {
+			return nil, err
+		}
+	} else {
+		blockStorage := blockchain.NewBlockStorage(db, filepath.Join(dbPath, "blockstorage"), cid, true)
+		blk, _, err := blockStorage.GetBlock(*toHash)
+		if err != nil {
+			return nil, err
+		}
+		prevHash := blk.GetPrevHash()
+		from = &prevHash
+	}
+	return blockchain.DiffStateByBlockHash(db, cid, *from, *toHash, kind, 0)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
+	"github.com/levietcuong2602/incognito-chain/trie"
+)
+
+// maxSnapshotMismatches bounds the mismatches listed by checkstatesnapshot
+const maxSnapshotMismatches = 100
+
+// StateSnapshotResult is the result of rebuildstatesnapshot and
+// checkstatesnapshot for one state database
+type StateSnapshotResult struct {
+	ChainID int
+	DBKind  string
+	Objects int
+	Check   *statedb.SnapshotCheck `json:",omitempty"`
+	Rebuilt bool                   `json:",omitempty"`
+}
+
+func openStateSnapshotDB(chainDataDir string, cid int) (incdb.Database, error) {
+	blockchain.Logger.Init(common.NewBackend(nil).Logger("ChainCMD", true))
+	trie.Logger.Init(common.NewBackend(nil).Logger("ChainCMD", true))
+	dbPath := filepath.Join(chainDataDir, archiveChainDir(cid))
+	return incdb.Open(pebbledb.DetectDriver(dbPath, incdb.DefaultDriver), dbPath)
+}
+
+// rebuildStateSnapshot writes the snapshot of the kind state database of
+// chain cid after the block hash from scratch. The node must be stopped, it
+// keeps the snapshot in step with the following blocks once state_snapshot is
+// set.
+func rebuildStateSnapshot(chainDataDir string, cid int, hash, kind string) (*StateSnapshotResult, error) {
+	blockHash, err := common.Hash{}.NewHashFromStr(hash)
+	if err != nil {
+		return nil, err
+	}
+	db, err := openStateSnapshotDB(chainDataDir, cid)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	count, err := blockchain.RebuildStateSnapshot(db, cid, *blockHash, kind)
+	if err != nil {
+		return nil, err
+	}
+	return &StateSnapshotResult{ChainID: cid, DBKind: kind, Objects: count, Rebuilt: true}, nil
+}
+
+// checkStateSnapshot compares the snapshot of the kind state database of
+// chain cid with its trie, after the block hash or at the root of the
+// snapshot on disk when hash is empty. With repair a snapshot which does not
+// match is rebuilt at the checked root.
+func checkStateSnapshot(chainDataDir string, cid int, hash, kind string, repair bool) (*StateSnapshotResult, error) {
+	var blockHash *common.Hash
+	if hash != "" {
+		h, err := common.Hash{}.NewHashFromStr(hash)
+		if err != nil {
+			return nil, err
+		}
+		blockHash = h
+	}
+	db, err := openStateSnapshotDB(chainDataDir, cid)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	check, err := blockchain.CheckStateSnapshot(db, cid, blockHash, kind, maxSnapshotMismatches)
+	if err != nil {
+		return nil, err
+	}
+	result := &StateSnapshotResult{ChainID: cid, DBKind: kind, Objects: check.Objects, Check: check}
+	if !repair || len(check.Mismatches) == 0 {
+		return result, nil
+	}
+	tree, err := statedb.NewSnapshotTree(db, kind, statedb.DefaultSnapshotDiffLayers)
+	if err != nil {
+		return result, err
+	}
+	if _, err := tree.Rebuild(check.Root, statedb.NewDatabaseAccessWarper(db)); err != nil {
+		return result, err
+	}
+	result.Rebuilt = true
+	return result, nil
+}
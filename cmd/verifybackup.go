@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
+)
+
+type backupCheck struct {
+	Chain  string //beacon, shard0, ...
+	Name   string //statedb name or "blocks"
+	Root   string `json:",omitempty"`
+	Nodes  uint64 `json:",omitempty"` //trie nodes and leaves walked
+	From   uint64 `json:",omitempty"` //lowest block height reached
+	To     uint64 `json:",omitempty"`
+	Passed bool
+	Error  string `json:",omitempty"`
+}
+
+type backupVerifyReport struct {
+	Checkpoint string
+	Chain      []string
+	Passed     bool
+	Checks     []backupCheck
+}
+
+// verifyBackup checks that a checkpoint folder written by the BackupManager is
+// complete: every node of the beacon and shard state tries of the final views
+// is present, and the blocks of the flat files hash-chain back from the
+// checkpoint block to the first backed up height. An incremental checkpoint is
+// first restored with its whole chain into restoreDir (a temp dir if empty).
+func verifyBackup(checkpointDir, restoreDir string) (*backupVerifyReport, error) {
+	checkpointDir = filepath.Clean(checkpointDir)
+	manifest, err := loadBackupManifest(checkpointDir)
+	if err != nil {
+		return nil, err
+	}
+	report := &backupVerifyReport{Checkpoint: manifest.CheckpointName, Chain: manifest.Chain, Passed: true}
+
+	dir := checkpointDir
+	minBeaconHeight := manifest.MinBeaconHeight
+	if len(manifest.Chain) > 1 {
+		if restoreDir == "" {
+			if restoreDir, err = ioutil.TempDir("", "verifybackup"); err != nil {
+				return nil, err
+			}
+			defer os.RemoveAll(restoreDir)
+		}
+		base, err := restoreBackupChain(filepath.Dir(checkpointDir), manifest, restoreDir)
+		if err != nil {
+			return nil, fmt.Errorf("restore backup chain: %v", err)
+		}
+		dir = restoreDir
+		minBeaconHeight = base.MinBeaconHeight
+	}
+
+	beaconDir := filepath.Join(dir, "beacon")
+	report.add(verifyStateDBs(beaconDir, "beacon", map[string]common.Hash{
+		"consensus": manifest.BeaconRoot.ConsensusStateDBRootHash,
+		"feature":   manifest.BeaconRoot.FeatureStateDBRootHash,
+		"reward":    manifest.BeaconRoot.RewardStateDBRootHash,
+		"slash":     manifest.BeaconRoot.SlashStateDBRootHash,
+	})...)
+	report.add(verifyBlockChain(beaconDir, "beacon", -1, manifest.BeaconBlockHash, manifest.BeaconHeight, minBeaconHeight))
+
+	sids := []int{}
+	for sid := range manifest.ShardHeight {
+		sids = append(sids, sid)
+	}
+	sort.Ints(sids)
+	for _, sid := range sids {
+		name := fmt.Sprintf("shard%v", sid)
+		root := manifest.ShardRoot[sid]
+		report.add(verifyStateDBs(filepath.Join(dir, name), name, map[string]common.Hash{
+			"consensus":   root.ConsensusStateDBRootHash,
+			"transaction": root.TransactionStateDBRootHash,
+			"feature":     root.FeatureStateDBRootHash,
+			"reward":      root.RewardStateDBRootHash,
+			"slash":       root.SlashStateDBRootHash,
+		})...)
+		report.add(verifyBlockChain(filepath.Join(dir, name), name, sid, manifest.ShardBlockHash[sid], manifest.ShardHeight[sid], 1))
+	}
+	return report, nil
+}
+
+func (r *backupVerifyReport) add(checks ...backupCheck) {
+	for _, check := range checks {
+		if !check.Passed {
+			r.Passed = false
+		}
+		r.Checks = append(r.Checks, check)
+	}
+}
+
+// loadBackupManifest reads the manifest of the checkpoint folder. Folders
+// written before manifests existed are described by the backupinfo file of the
+// database dir when they are the last backup.
+func loadBackupManifest(checkpointDir string) (*blockchain.BackupManifest, error) {
+	manifest, err := blockchain.ReadBackupManifest(checkpointDir)
+	if err == nil || !os.IsNotExist(err) {
+		return manifest, err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(filepath.Dir(checkpointDir), "backupinfo"))
+	if err != nil {
+		return nil, fmt.Errorf("no manifest in %v", checkpointDir)
+	}
+	info := &blockchain.BackupProcessInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, err
+	}
+	if info.CheckpointName != filepath.Base(checkpointDir) || info.BeaconView == nil {
+		return nil, fmt.Errorf("no manifest in %v", checkpointDir)
+	}
+	return blockchain.NewBackupManifest(info), nil
+}
+
+// restoreBackupChain copies the full backup of the chain into restoreDir and
+// applies every incremental backup on top of it, it returns the manifest of
+// the full backup
+func restoreBackupChain(backupDir string, manifest *blockchain.BackupManifest, restoreDir string) (*blockchain.BackupManifest, error) {
+	baseDir := filepath.Join(backupDir, manifest.Chain[0])
+	base, err := loadBackupManifest(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := copyDir(baseDir, restoreDir); err != nil {
+		return nil, err
+	}
+	for _, checkpoint := range manifest.Chain[1:] {
+		deltaDir := filepath.Join(backupDir, checkpoint)
+		if err := blockchain.ApplyBackupDelta(filepath.Join(restoreDir, "beacon"), filepath.Join(deltaDir, "beacon"), -1); err != nil {
+			return nil, fmt.Errorf("apply %v beacon: %v", checkpoint, err)
+		}
+		for sid := range manifest.ShardHeight {
+			name := fmt.Sprintf("shard%v", sid)
+			if err := blockchain.ApplyBackupDelta(filepath.Join(restoreDir, name), filepath.Join(deltaDir, name), sid); err != nil {
+				return nil, fmt.Errorf("apply %v %v: %v", checkpoint, name, err)
+			}
+		}
+	}
+	return base, nil
+}
+
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		return copyFile(path, filepath.Join(dst, rel))
+	})
+}
+
+func openBackupDB(dbPath string) (incdb.Database, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, err
+	}
+	return incdb.OpenReadOnly(pebbledb.DetectDriver(dbPath, incdb.DefaultDriver), dbPath)
+}
+
+// verifyStateDBs walks every node of the given state tries stored in the
+// database of chainDir, a missing node stops the walk with an error
+func verifyStateDBs(chainDir, chain string, roots map[string]common.Hash) []backupCheck {
+	names := []string{}
+	for name := range roots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checks := []backupCheck{}
+	db, err := openBackupDB(chainDir)
+	if err != nil {
+		for _, name := range names {
+			checks = append(checks, backupCheck{Chain: chain, Name: name, Root: roots[name].String(), Error: err.Error()})
+		}
+		return checks
+	}
+	defer db.Close()
+
+	for _, name := range names {
+		check := backupCheck{Chain: chain, Name: name, Root: roots[name].String()}
+		nodes, err := walkStateTrie(db, roots[name])
+		check.Nodes = nodes
+		if err != nil {
+			check.Error = err.Error()
+		} else {
+			check.Passed = true
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+func walkStateTrie(db incdb.Database, root common.Hash) (uint64, error) {
+	if root.IsEqual(&common.Hash{}) {
+		return 0, nil
+	}
+	stateDB, err := statedb.NewWithPrefixTrie(root, statedb.NewDatabaseAccessWarper(db))
+	if err != nil {
+		return 0, err
+	}
+	it := stateDB.GetIterator()
+	nodes := uint64(0)
+	for it.Next(false, true, true) {
+		nodes++
+	}
+	return nodes, it.Err
+}
+
+// verifyBlockChain follows the previous block hashes from the checkpoint block
+// down to the lowest height and checks every block against the finalized index.
+// Blocks are read through the block storage, from the flat file or from the
+// ancient store of the chain folder when there is one.
+func verifyBlockChain(chainDir, chain string, cid int, blockHash common.Hash, height, lowest uint64) backupCheck {
+	check := backupCheck{Chain: chain, Name: "blocks", Root: blockHash.String(), From: height, To: height}
+	fail := func(format string, a ...interface{}) backupCheck {
+		check.Error = fmt.Sprintf(format, a...)
+		return check
+	}
+
+	blockStorage, err := blockchain.OpenBlockStorageReadOnly(filepath.Join(chainDir, "blockstorage"), filepath.Join(chainDir, "ancient"), cid)
+	if err != nil {
+		return fail("%v", err)
+	}
+	defer blockStorage.Close()
+
+	hash := blockHash
+	for h := height; h >= lowest && h > 0; h-- {
+		blk, _, err := blockStorage.GetBlock(hash)
+		if err != nil {
+			return fail("block %v at height %v: %v", hash.String(), h, err)
+		}
+		if !blk.Hash().IsEqual(&hash) || blk.GetHeight() != h {
+			return fail("block at height %v is %v at height %v", h, blk.Hash().String(), blk.GetHeight())
+		}
+		var finalized *common.Hash
+		if cid == -1 {
+			finalized, err = blockStorage.GetFinalizedBeaconBlock(h)
+		} else {
+			finalized, err = blockStorage.GetFinalizedShardBlockHashByIndex(h)
+		}
+		if err != nil || !finalized.IsEqual(&hash) {
+			return fail("finalized index at height %v does not match block %v", h, hash.String())
+		}
+		check.From = h
+		hash = blk.GetPrevHash()
+	}
+	check.Passed = true
+	return check
+}
@@ -29,12 +29,18 @@ func AbortConfig() {
 
 type config struct {
 	//Basic config
-	DataDir     string `mapstructure:"data_dir" short:"d" long:"datadir" description:"Directory to store data"`
-	DatabaseDir string `mapstructure:"database_dir" long:"datapre" description:"Database dir"`
-	MempoolDir  string `mapstructure:"mempool_dir" short:"m" long:"mempooldir" description:"Mempool Directory"`
-	LogDir      string `mapstructure:"log_dir" short:"l" long:"logdir" description:"Directory to log output."`
-	LogLevel    string `mapstructure:"log_level" long:"loglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
-	LogFileName string `mapstructure:"log_file_name" long:"logfilename" description:"log file name"`
+	DataDir             string `mapstructure:"data_dir" short:"d" long:"datadir" description:"Directory to store data"`
+	DatabaseDir         string `mapstructure:"database_dir" long:"datapre" description:"Database dir"`
+	DatabaseDriver      string `mapstructure:"database_driver" long:"dbdriver" description:"Database driver of chain data {leveldb, pebble}"`
+	FlatFileCompression string `mapstructure:"flatfile_compression" long:"ffcompression" description:"Compression of new block flat file segments {none, snappy, zstd}"`
+	AncientBlockDepth   uint64 `mapstructure:"ancient_block_depth" long:"ancientblockdepth" description:"Move finalized blocks deeper than this number of blocks to the ancient store, 0 to disable"`
+	AncientDir          string `mapstructure:"ancient_dir" long:"ancientdir" description:"Directory of the ancient block store, default to <chain dir>/ancient"`
+	DatabaseMetrics     bool   `mapstructure:"database_metrics" long:"dbmetrics" description:"Record count, size and latency of the database operations per key family"`
+	StateSnapshot       bool   `mapstructure:"state_snapshot" long:"statesnapshot" description:"Read the state objects from a flat snapshot kept in step with the state tries, see the rebuildstatesnapshot command"`
+	MempoolDir          string `mapstructure:"mempool_dir" short:"m" long:"mempooldir" description:"Mempool Directory"`
+	LogDir              string `mapstructure:"log_dir" short:"l" long:"logdir" description:"Directory to log output."`
+	LogLevel            string `mapstructure:"log_level" long:"loglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
+	LogFileName         string `mapstructure:"log_file_name" long:"logfilename" description:"log file name"`
 
 	//Peer Config
 	AddPeers             []string `mapstructure:"add_peers" short:"a" long:"addpeer" description:"Add a peer to connect with at startup"`
@@ -92,6 +98,11 @@ type config struct {
 	//Txpool config
 	TxPoolTTL                 uint   `mapstructure:"tx_pool_ttl" long:"txpoolttl" description:"Set Time To Live (TTL) Value for transaction that enter pool"`
 	TxPoolMaxTx               uint64 `mapstructure:"tx_pool_max_tx" long:"txpoolmaxtx" description:"Set Maximum number of transaction in pool"`
+	TxPoolMaxSize             uint64 `mapstructure:"tx_pool_max_size" long:"txpoolmaxsize" description:"Set Maximum total size in KB of the transactions in the pool of a shard"`
+	TxPoolTypeQuota           string `mapstructure:"tx_pool_type_quota" long:"txpooltypequota" description:"Share in percent of the pool of a shard each metadata type may take, as type:percent separated by commas, * for the types not listed"`
+	TxPoolPersist             bool   `mapstructure:"tx_pool_persist" long:"txpoolpersist" description:"Store the transactions of the tx pool on disk and validate them again on restart"`
+	TxPoolPersistMaxTx        uint64 `mapstructure:"tx_pool_persist_max_tx" long:"txpoolpersistmaxtx" description:"Maximum number of transactions stored on disk per shard"`
+	TxPoolPersistMaxSize      uint64 `mapstructure:"tx_pool_persist_max_size" long:"txpoolpersistmaxsize" description:"Maximum total size in KB of the transactions stored on disk per shard"`
 	LimitFee                  uint64 `mapstructure:"limit_fee" long:"limitfee" description:"Limited fee for tx(per Kb data), default is 0.001 PRV"`
 	MinFeePerTx               uint64 `mapstructure:"min_fee_per_tx" long:"minfeepertx" description:"Required minimum fee per tx, default is 0.1 PRV"`
 	SpecifiedFeePerTx         uint64 `mapstructure:"specified_fee_per_tx" long:"specifiedfeepertx" description:"Specified fee for some txs, default is 10 PRV"`
@@ -125,9 +136,12 @@ type config struct {
 	OfflinePrune         bool   `mapstructure:"offline_prune" long:"offlineprune" description:"offline pruning flag"`
 	StateBloomSize       uint64 `mapstructure:"state_bloom_size" long:"statebloomsize" description:"state pruning bloom size"`
 	EnableAutoPrune      bool   `mapstructure:"enable_auto_prune" long:"enableautoprune" description:"enable auto prune"`
+	EnableBeaconPrune    bool   `mapstructure:"enable_beacon_prune" long:"enablebeaconprune" description:"also prune the beacon state on auto and offline prune"`
 	NumBlockTriggerPrune uint64 `mapstructure:"num_block_trigger_prune" long:"numblocktriggerprune" description:"number block trigger prune"`
+	StateRetentionEpochs uint64 `mapstructure:"state_retention_epochs" long:"stateretentionepochs" description:"keep the state of the last N epochs when pruning, 0 to keep only the views"`
 	//backup and bootstrap
 	BackupInterval int64 `mapstructure:"backup_interval" long:"backupinterval" description:"Backup Interval"`
+	BackupMaxDelta int64 `mapstructure:"backup_max_delta" long:"backupmaxdelta" description:"Max number of incremental backups on top of a full backup, 0 to always take full backups"`
 }
 
 // normalizeAddresses returns a new slice with all the passed peer addresses
@@ -223,6 +237,10 @@ func (c *config) verify() {
 	c.LogDir = filepath.Join(c.DataDir, c.LogDir)
 	c.LogFileName = filepath.Join(c.LogDir, c.LogFileName)
 
+	if c.DatabaseDriver == utils.EmptyString {
+		c.DatabaseDriver = DefaultDatabaseDriver
+	}
+
 	// --addPeer and --connect do not mix.
 	if len(c.AddPeers) > 0 && len(c.ConnectPeers) > 0 {
 		str := "%s: the --addpeer and --connect options can not be mixed"
@@ -25,6 +25,7 @@ const (
 const (
 	DefaultDataDirname                 = "data"
 	DefaultDatabaseDirname             = "block"
+	DefaultDatabaseDriver              = "leveldb"
 	DefaultMempoolDirname              = "mempool"
 	DefaultLogLevel                    = "info"
 	DefaultLogDirname                  = "logs"
@@ -65,6 +66,13 @@ const (
 	DefaultUnifiedTokenFileType = "json"
 )
 
+// tx pool persistence
+const (
+	DefaultTxPoolDirname        = "txpool"
+	DefaultTxPoolPersistMaxTx   = uint64(20000)
+	DefaultTxPoolPersistMaxSize = uint64(200 * 1024) // in KB, 200 MB
+)
+
 const (
 	LocalNetwork          = "local"
 	LocalDCSNetwork       = "local-dcs"
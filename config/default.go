@@ -65,6 +65,7 @@ var MainnetParam = &param{
 		BlockProducingV3Height:    1846560,
 		Lemma2Height:              1816555,
 		ByzantineDetectorHeight:   1e9,
+		EquivocationSlashHeight:   1e9,
 		Timeslot:                  40,
 		EpochBreakPointSwapNewKey: []uint64{1917},
 	},
@@ -152,6 +153,7 @@ var Testnet1Param = &param{
 		BlockProducingV3Height:    1e9,
 		Lemma2Height:              2868685,
 		ByzantineDetectorHeight:   1e9,
+		EquivocationSlashHeight:   1e9,
 		Timeslot:                  10,
 		EpochBreakPointSwapNewKey: []uint64{1280},
 	},
@@ -234,6 +236,7 @@ var Tesnet2Param = &param{
 		BlockProducingV3Height:    1e9,
 		Lemma2Height:              1e9,
 		ByzantineDetectorHeight:   1e9,
+		EquivocationSlashHeight:   1e9,
 		Timeslot:                  10,
 		EpochBreakPointSwapNewKey: []uint64{1280},
 	},
@@ -316,6 +319,7 @@ var LocalParam = &param{
 		BlockProducingV3Height:    1e9,
 		Lemma2Height:              1e9,
 		ByzantineDetectorHeight:   1e9,
+		EquivocationSlashHeight:   1e9,
 		Timeslot:                  10,
 		EpochBreakPointSwapNewKey: []uint64{1280},
 	},
@@ -397,6 +401,7 @@ var LocalDCSParam = &param{
 		Lemma2Height:              50,
 		BlockProducingV3Height:    1e9,
 		ByzantineDetectorHeight:   1e9,
+		EquivocationSlashHeight:   1e9,
 		Timeslot:                  10,
 		EpochBreakPointSwapNewKey: []uint64{1280},
 	},
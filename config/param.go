@@ -153,6 +153,7 @@ type consensusParam struct {
 	NotUseBurnedCoins         uint64   `mapstructure:"force_not_use_burned_coins"`
 	Lemma2Height              uint64   `mapstructure:"lemma2_height"`
 	ByzantineDetectorHeight   uint64   `mapstructure:"byzantine_detector_height"`
+	EquivocationSlashHeight   uint64   `mapstructure:"equivocation_slash_height"`
 	BlockProducingV3Height    uint64   `mapstructure:"block_producing_v3_height"`
 	Timeslot                  uint64   `mapstructure:"timeslot"`
 	EpochBreakPointSwapNewKey []uint64 `mapstructure:"epoch_break_point_swap_new_key"`
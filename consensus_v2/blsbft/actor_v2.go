@@ -1379,10 +1379,19 @@ func (a *actorV2) handleNewProposeMsg(
 func (a *actorV2) handleVoteMsg(voteMsg BFTVote) error {
 
 	if a.chainID != common.BeaconChainID {
-		if err := ByzantineDetectorObject.Validate(
+		signingCommittee := []string{}
+		if proposeBlockInfo, ok := a.GetReceiveBlockByHash(voteMsg.BlockHash); ok {
+			signingCommittee, _ = incognitokey.CommitteeKeyListToString(proposeBlockInfo.SigningCommittees)
+		}
+		evidence, err := ByzantineDetectorObject.Validate(
 			a.chain.GetBestViewHeight(),
 			&voteMsg,
-		); err != nil {
+			signingCommittee,
+		)
+		if evidence != nil {
+			go a.sendEquivocationEvidence(evidence)
+		}
+		if err != nil {
 			a.logger.Errorf("Found byzantine validator %+v, err %+v", voteMsg.Validator, err)
 			return err
 		}
@@ -1636,6 +1645,30 @@ func (a *actorV2) makeBFTVoteMsg(vote *BFTVote, chainKey string, ts int64, heigh
 	return msg, nil
 }
 
+// sendEquivocationEvidence gossips the evidence of a validator voting for two
+// blocks in one timeslot to the beacon committee, which slashes the validator
+func (a *actorV2) sendEquivocationEvidence(evidence *consensustypes.EquivocationEvidence) {
+	offender, err := evidence.Verify()
+	if err != nil {
+		a.logger.Error("Invalid equivocation evidence", err)
+		return
+	}
+	data, err := consensustypes.EncodeEquivocationEvidence(*evidence)
+	if err != nil {
+		a.logger.Error(err)
+		return
+	}
+	beaconChain, ok := a.committeeChain.(common.ChainInterface)
+	if !ok {
+		a.logger.Error("Cannot send equivocation evidence, beacon chain not found")
+		return
+	}
+	a.logger.Infof("Send equivocation evidence of validator %+v", offender)
+	if err := a.node.PushMessageToChain(wire.NewMessageEquivocation(data), beaconChain); err != nil {
+		a.logger.Error(err)
+	}
+}
+
 func (a *actorV2) makeBFTRequestBlk(request BFTRequestBlock, peerID string, chainKey string) (wire.Message, error) {
 	requestCtnBytes, err := json.Marshal(request)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/consensus_v2/consensustypes"
 	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdb_consensus"
 	"github.com/levietcuong2602/incognito-chain/incdb"
 	"github.com/levietcuong2602/incognito-chain/incognitokey"
@@ -39,6 +40,7 @@ type ByzantineDetector struct {
 	fixedNodes                   map[string]bool                                // fixed nodes
 	blackList                    map[string]*rawdb_consensus.BlackListValidator // validator => reason for blacklist
 	voteInTimeSlot               map[string]map[int64]*BFTVote                  // validator => timeslot => vote
+	voteCommittee                map[string]map[int64][]string                  // validator => timeslot => signing committee of the vote
 	validRecentVote              map[string]*BFTVote
 	smallestBlockProduceTimeSlot map[string]map[uint64]*BFTVote // validator => height => timeslot
 	logger                       common.Logger
@@ -66,6 +68,7 @@ func NewByzantineDetector(logger common.Logger) *ByzantineDetector {
 		logger:                       logger,
 		blackList:                    blackListValidators,
 		voteInTimeSlot:               make(map[string]map[int64]*BFTVote),
+		voteCommittee:                make(map[string]map[int64][]string),
 		smallestBlockProduceTimeSlot: make(map[string]map[uint64]*BFTVote),
 		validRecentVote:              make(map[string]*BFTVote),
 		mu:                           new(sync.RWMutex),
@@ -105,11 +108,16 @@ func (b ByzantineDetector) GetByzantineDetectorInfo() map[string]interface{} {
 	return m
 }
 
-func (b *ByzantineDetector) Validate(bestViewHeight uint64, vote *BFTVote) error {
+// Validate checks the vote against the recent votes of its validator. When the
+// validator already voted for another block in the same timeslot and the
+// signing committees of both votes are known, it also returns the evidence of
+// the equivocation. signingCommittee is the committee the BLS signature of the
+// vote was made for, empty when the propose block is not received yet.
+func (b *ByzantineDetector) Validate(bestViewHeight uint64, vote *BFTVote, signingCommittee []string) (*consensustypes.EquivocationEvidence, error) {
 
 	if vote.isEmptyDataForByzantineDetector() {
 		b.logger.Debug("Empty data to validate Byzantine vote")
-		return nil
+		return nil, nil
 	}
 
 	b.mu.Lock()
@@ -125,10 +133,12 @@ func (b *ByzantineDetector) Validate(bestViewHeight uint64, vote *BFTVote) error
 
 	if config.Param().ConsensusParam.ByzantineDetectorHeight < bestViewHeight {
 		if err := b.checkBlackListValidator(vote); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
+	evidence := b.equivocationEvidence(vote, signingCommittee)
+
 	for _, handler := range handlers {
 		err = handler(vote)
 		if err != nil {
@@ -138,12 +148,15 @@ func (b *ByzantineDetector) Validate(bestViewHeight uint64, vote *BFTVote) error
 	}
 
 	b.addNewVote(rawdb_consensus.GetConsensusDatabase(), vote, err)
+	if err == nil && len(signingCommittee) != 0 {
+		b.addVoteCommittee(vote, signingCommittee)
+	}
 
 	if config.Param().ConsensusParam.ByzantineDetectorHeight < bestViewHeight {
-		return err
+		return evidence, err
 	}
 
-	return nil
+	return nil, nil
 }
 
 func (b *ByzantineDetector) UpdateState(finalHeight uint64, finalTimeSlot int64) {
@@ -159,6 +172,14 @@ func (b *ByzantineDetector) UpdateState(finalHeight uint64, finalTimeSlot int64)
 		}
 	}
 
+	for _, voteCommittee := range b.voteCommittee {
+		for timeSlot, _ := range voteCommittee {
+			if timeSlot+defaultTimeSlotTTL < finalTimeSlot {
+				delete(voteCommittee, timeSlot)
+			}
+		}
+	}
+
 	for _, smallestTimeSlot := range b.smallestBlockProduceTimeSlot {
 		for height, _ := range smallestTimeSlot {
 			if height+defaultHeightTTL < finalHeight {
@@ -251,6 +272,33 @@ func (b ByzantineDetector) voteMoreThanOneTimesInATimeSlot(newVote *BFTVote) err
 	return nil
 }
 
+// equivocationEvidence returns the evidence of newVote being for another block
+// than the vote of its validator in the same timeslot
+func (b ByzantineDetector) equivocationEvidence(newVote *BFTVote, signingCommittee []string) *consensustypes.EquivocationEvidence {
+
+	if len(signingCommittee) == 0 || newVote.BlockHeight < config.Param().ConsensusParam.ByzantineDetectorHeight {
+		return nil
+	}
+
+	vote, ok := b.voteInTimeSlot[newVote.Validator][newVote.ProposeTimeSlot]
+	if !ok {
+		return nil
+	}
+
+	if vote.BlockHash == newVote.BlockHash ||
+		vote.ChainID != newVote.ChainID ||
+		!vote.CommitteeFromBlock.IsEqual(&newVote.CommitteeFromBlock) {
+		return nil
+	}
+
+	committee := b.voteCommittee[newVote.Validator][newVote.ProposeTimeSlot]
+	if len(committee) == 0 {
+		return nil
+	}
+
+	return consensustypes.NewEquivocationEvidence(vote.signedVote(committee), newVote.signedVote(signingCommittee))
+}
+
 func (b ByzantineDetector) voteForHigherTimeSlotSameHeight(newVote *BFTVote) error {
 
 	smallestTimeSlotBlock, ok := b.smallestBlockProduceTimeSlot[newVote.Validator]
@@ -344,3 +392,14 @@ func (b *ByzantineDetector) addNewVote(database incdb.Database, newVote *BFTVote
 
 	b.validRecentVote[newVote.Validator] = newVote
 }
+
+func (b *ByzantineDetector) addVoteCommittee(newVote *BFTVote, signingCommittee []string) {
+
+	if b.voteCommittee == nil {
+		b.voteCommittee = make(map[string]map[int64][]string)
+	}
+	if _, ok := b.voteCommittee[newVote.Validator]; !ok {
+		b.voteCommittee[newVote.Validator] = make(map[int64][]string)
+	}
+	b.voteCommittee[newVote.Validator][newVote.ProposeTimeSlot] = signingCommittee
+}
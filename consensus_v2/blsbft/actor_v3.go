@@ -50,10 +50,15 @@ type actorV3 struct {
 	blockVersion int
 
 	currentBestViewHeight uint64
+
+	// now is the clock of the actor and syncPush pushes the messages without
+	// a goroutine, both are replaced by the consensus simulation
+	now      func() time.Time
+	syncPush bool
 }
 
 func NewActorV3() *actorV3 {
-	return &actorV3{}
+	return &actorV3{now: time.Now}
 }
 
 func NewActorV3WithValue(
@@ -450,12 +455,26 @@ func (a *actorV3) sendBFTProposeMsg(
 ) error {
 
 	msg, _ := a.makeBFTProposeMsg(bftPropose, a.chainKey, a.currentTimeSlot)
-	go a.ProcessBFTMsg(msg.(*wire.MessageBFT))
-	go a.node.PushMessageToChain(msg, a.chain)
+	if a.syncPush {
+		if err := a.processProposeMsg(*bftPropose); err != nil {
+			a.logger.Error(err)
+		}
+	} else {
+		go a.ProcessBFTMsg(msg.(*wire.MessageBFT))
+	}
+	a.pushMessage(msg)
 
 	return nil
 }
 
+func (a *actorV3) pushMessage(msg wire.Message) {
+	if a.syncPush {
+		a.node.PushMessageToChain(msg, a.chain)
+		return
+	}
+	go a.node.PushMessageToChain(msg, a.chain)
+}
+
 func (a *actorV3) preValidateVote(blockHash []byte, vote *BFTVote, candidate []byte) error {
 	data := []byte{}
 	data = append(data, blockHash...)
@@ -558,7 +577,7 @@ func (a *actorV3) handleCleanMem() {
 
 	for h, proposeBlk := range a.receiveBlockByHash {
 		if proposeBlk.block == nil ||
-			(time.Now().Sub(proposeBlk.ReceiveTime) > time.Minute && len(proposeBlk.PreVotes) <= 2*len(proposeBlk.SigningCommittees)/3) ||
+			(a.now().Sub(proposeBlk.ReceiveTime) > time.Minute && len(proposeBlk.PreVotes) <= 2*len(proposeBlk.SigningCommittees)/3) ||
 			proposeBlk.block.GetHeight() < a.chain.GetFinalViewHeight()-2 {
 			if err := a.CleanReceiveBlockByHash(h); err != nil {
 				a.logger.Errorf("clean receive block by hash error %+v", err)
@@ -590,7 +609,7 @@ func (a *actorV3) makeBFTProposeMsg(
 	msg.(*wire.MessageBFT).Content = proposeCtnBytes
 	msg.(*wire.MessageBFT).Type = MSG_PROPOSE
 	msg.(*wire.MessageBFT).TimeSlot = ts
-	msg.(*wire.MessageBFT).Timestamp = time.Now().UnixNano() / int64(time.Millisecond)
+	msg.(*wire.MessageBFT).Timestamp = a.now().UnixNano() / int64(time.Millisecond)
 	msg.(*wire.MessageBFT).PeerID = proposeCtn.PeerID
 	return msg, nil
 }
@@ -605,7 +624,7 @@ func (a *actorV3) makeBFTVoteMsg(vote *BFTVote, chainKey string, ts int64, heigh
 	msg.(*wire.MessageBFT).Content = voteCtnBytes
 	msg.(*wire.MessageBFT).Type = MSG_VOTE
 	msg.(*wire.MessageBFT).TimeSlot = ts
-	msg.(*wire.MessageBFT).Timestamp = time.Now().UnixNano() / int64(time.Millisecond)
+	msg.(*wire.MessageBFT).Timestamp = a.now().UnixNano() / int64(time.Millisecond)
 	return msg, nil
 }
 
@@ -646,34 +665,13 @@ func (a *actorV3) run() error {
 				close(a.destroyCh)
 				return
 			case proposeMsg := <-a.proposeMessageCh:
-				if ActorRuleBuilderContext.HandleProposeRule != HANDLE_PROPOSE_MESSAGE_NORMAL {
-					continue
-				}
-				err := a.handleProposeMsg(proposeMsg)
-				if err != nil {
+				if err := a.processProposeMsg(proposeMsg); err != nil {
 					a.logger.Error(err)
-					continue
 				}
 
 			case voteMsg := <-a.voteMessageCh:
-				if ActorRuleBuilderContext.HandleVoteRule != HANDLE_VOTE_MESSAGE_COLLECT {
-					continue
-				}
-				switch voteMsg.Phase {
-				case "prevote":
-					err := a.handlePreVoteMsg(voteMsg)
-					if err != nil {
-						a.logger.Error(err)
-						continue
-					}
-				case "vote":
-					err := a.handleVoteMsg(voteMsg)
-					if err != nil {
-						a.logger.Error(err)
-						continue
-					}
-				default:
-					a.logger.Error("Cannot find vote type!")
+				if err := a.processVoteMsg(voteMsg); err != nil {
+					a.logger.Error(err)
 				}
 
 			case <-cleanMemTicker:
@@ -681,78 +679,103 @@ func (a *actorV3) run() error {
 				continue
 
 			case <-ticker:
-				if !a.chain.IsReady() {
-					continue
-				}
-				bestView := a.chain.GetBestView()
-				a.currentTime = time.Now().Unix()
-				currentTimeSlot := bestView.CalculateTimeSlot(a.currentTime)
+				a.handleTick()
+			}
+		}
+	}()
+	return nil
+}
 
-				newTimeSlot := false
-				if a.currentTimeSlot != currentTimeSlot {
-					newTimeSlot = true
-				}
+func (a *actorV3) processProposeMsg(proposeMsg BFTPropose) error {
+	if ActorRuleBuilderContext.HandleProposeRule != HANDLE_PROPOSE_MESSAGE_NORMAL {
+		return nil
+	}
+	return a.handleProposeMsg(proposeMsg)
+}
 
-				a.currentTimeSlot = currentTimeSlot
+func (a *actorV3) processVoteMsg(voteMsg BFTVote) error {
+	if ActorRuleBuilderContext.HandleVoteRule != HANDLE_VOTE_MESSAGE_COLLECT {
+		return nil
+	}
+	switch voteMsg.Phase {
+	case "prevote":
+		return a.handlePreVoteMsg(voteMsg)
+	case "vote":
+		return a.handleVoteMsg(voteMsg)
+	default:
+		return errors.New("Cannot find vote type!")
+	}
+}
 
-				a.currentBestViewHeight = bestView.GetHeight()
+// handleTick proposes, validates, votes and commits for the current timeslot
+func (a *actorV3) handleTick() {
+	if !a.chain.IsReady() {
+		return
+	}
+	bestView := a.chain.GetBestView()
+	a.currentTime = a.now().Unix()
+	currentTimeSlot := bestView.CalculateTimeSlot(a.currentTime)
 
-				//set round for monitor
-				round := a.currentTimeSlot - bestView.CalculateTimeSlot(bestView.GetBlock().GetProposeTime())
-				monitor.SetGlobalParam("RoundKey", fmt.Sprintf("%d_%d", bestView.GetHeight(), round))
+	newTimeSlot := false
+	if a.currentTimeSlot != currentTimeSlot {
+		newTimeSlot = true
+	}
 
-				if newTimeSlot {
-					a.logger.Info("")
-					a.logger.Info("======================================================")
-					if ActorRuleBuilderContext.CreateRule == CREATE_RULE_NORMAL {
-						err := a.maybeProposeBlock()
-						if err != nil {
-							a.logger.Error(err)
-						}
+	a.currentTimeSlot = currentTimeSlot
 
-					}
-				}
+	a.currentBestViewHeight = bestView.GetHeight()
 
-				//validatingreceived data: propose, prevote, vote
-				for _, proposeInfo := range a.receiveBlockByHash {
-					if ActorRuleBuilderContext.ValidatorRule == VALIDATOR_NO_VALIDATE {
-						break
-					}
-
-					//get propose info at current timeslot
-					if proposeInfo.block != nil && bestView.CalculateTimeSlot(proposeInfo.block.GetProposeTime()) == a.currentTimeSlot {
-						//validate the propose block
-						err := a.validateBlock(proposeInfo)
-						if err != nil {
-							a.logger.Errorf("%v", err)
-						}
-						//validate pre vote this current propose block
-						a.validatePreVote(proposeInfo)
-
-						//validate vote this current propose block
-						a.validateVote(proposeInfo)
-					}
-				}
+	//set round for monitor
+	round := a.currentTimeSlot - bestView.CalculateTimeSlot(bestView.GetBlock().GetProposeTime())
+	monitor.SetGlobalParam("RoundKey", fmt.Sprintf("%d_%d", bestView.GetHeight(), round))
 
-				if ActorRuleBuilderContext.PreVoteRule == VOTE_RULE_VOTE {
-					//prevote for this timeslot
-					a.maybePreVoteMsg()
-				}
+	if newTimeSlot {
+		a.logger.Info("")
+		a.logger.Info("======================================================")
+		if ActorRuleBuilderContext.CreateRule == CREATE_RULE_NORMAL {
+			err := a.maybeProposeBlock()
+			if err != nil {
+				a.logger.Error(err)
+			}
 
-				if ActorRuleBuilderContext.VoteRule == VOTE_RULE_VOTE {
-					//vote for this timeslot
-					a.maybeVoteMsg()
-				}
+		}
+	}
 
-				if ActorRuleBuilderContext.InsertRule == INSERT_AND_BROADCAST {
-					//commit for this timeslot
-					a.maybeCommit()
-				}
+	//validatingreceived data: propose, prevote, vote
+	for _, proposeInfo := range a.receiveBlockByHash {
+		if ActorRuleBuilderContext.ValidatorRule == VALIDATOR_NO_VALIDATE {
+			break
+		}
 
+		//get propose info at current timeslot
+		if proposeInfo.block != nil && bestView.CalculateTimeSlot(proposeInfo.block.GetProposeTime()) == a.currentTimeSlot {
+			//validate the propose block
+			err := a.validateBlock(proposeInfo)
+			if err != nil {
+				a.logger.Errorf("%v", err)
 			}
+			//validate pre vote this current propose block
+			a.validatePreVote(proposeInfo)
+
+			//validate vote this current propose block
+			a.validateVote(proposeInfo)
 		}
-	}()
-	return nil
+	}
+
+	if ActorRuleBuilderContext.PreVoteRule == VOTE_RULE_VOTE {
+		//prevote for this timeslot
+		a.maybePreVoteMsg()
+	}
+
+	if ActorRuleBuilderContext.VoteRule == VOTE_RULE_VOTE {
+		//vote for this timeslot
+		a.maybeVoteMsg()
+	}
+
+	if ActorRuleBuilderContext.InsertRule == INSERT_AND_BROADCAST {
+		//commit for this timeslot
+		a.maybeCommit()
+	}
 }
 
 // get lock block hash, which is blockhash that we had send vote message
@@ -781,7 +804,7 @@ func (a *actorV3) validateBlock(proposeBlockInfo *ProposeBlockInfo) error {
 	}
 
 	//interval 1s
-	if time.Since(proposeBlockInfo.LastValidateTime).Seconds() < 1 {
+	if a.now().Sub(proposeBlockInfo.LastValidateTime).Seconds() < 1 {
 		return nil
 	}
 
@@ -798,7 +821,7 @@ func (a *actorV3) validateBlock(proposeBlockInfo *ProposeBlockInfo) error {
 		}
 	}
 
-	proposeBlockInfo.LastValidateTime = time.Now()
+	proposeBlockInfo.LastValidateTime = a.now()
 	err := a.chain.ValidatePreSignBlock(proposeBlockInfo.block, proposeBlockInfo.SigningCommittees, proposeBlockInfo.Committees)
 	if err != nil {
 		a.logger.Error(err)
@@ -109,7 +109,7 @@ func (a *actorV3) createAndSendPreVote(
 
 	a.logger.Info(a.chainKey, "sending pre vote...", block.FullHashString())
 
-	go a.node.PushMessageToChain(msg, a.chain)
+	a.pushMessage(msg)
 
 	return nil
 }
@@ -264,7 +264,7 @@ func (a *actorV3) handleProposeMsg(proposeMsg BFTPropose) error {
 
 	proposeBlockInfo := &ProposeBlockInfo{
 		block:                   block,
-		ReceiveTime:             time.Now(),
+		ReceiveTime:             a.now(),
 		Votes:                   make(map[string]*BFTVote),
 		PreVotes:                make(map[string]*BFTVote),
 		Committees:              incognitokey.DeepCopy(committees),
@@ -0,0 +1,67 @@
+package blsbft
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/wire"
+)
+
+// NewSimulatedActorV3 returns a started actorV3 without its loop, for the
+// consensus simulation. The simulation delivers the BFT messages with
+// HandleBFTMsg, runs the timer with Tick on the clock now, and receives the
+// messages of the actor synchronously from node.
+func NewSimulatedActorV3(
+	chain Chain,
+	committeeChain CommitteeChainHandler,
+	chainKey string, blockVersion, chainID int,
+	node NodeInterface, logger common.Logger,
+	now func() time.Time,
+) *actorV3 {
+	a := newActorV3WithValue(
+		chain,
+		committeeChain,
+		chainKey,
+		blockVersion,
+		chainID,
+		node,
+		logger,
+	)
+	a.now = now
+	a.syncPush = true
+	a.isStarted = true
+	return a
+}
+
+// HandleBFTMsg processes msgBFT in the calling goroutine
+func (a *actorV3) HandleBFTMsg(msgBFT *wire.MessageBFT) error {
+	switch msgBFT.Type {
+	case MSG_PROPOSE:
+		var msgPropose BFTPropose
+		if err := json.Unmarshal(msgBFT.Content, &msgPropose); err != nil {
+			return err
+		}
+		msgPropose.PeerID = msgBFT.PeerID
+		return a.processProposeMsg(msgPropose)
+	case MSG_VOTE:
+		var msgVote BFTVote
+		if err := json.Unmarshal(msgBFT.Content, &msgVote); err != nil {
+			return err
+		}
+		return a.processVoteMsg(msgVote)
+	default:
+		return fmt.Errorf("Unknown BFT message type %+v", msgBFT.Type)
+	}
+}
+
+// Tick runs the timer of the actor loop once
+func (a *actorV3) Tick() {
+	a.handleTick()
+}
+
+// CleanMem runs the memory clean of the actor loop once
+func (a *actorV3) CleanMem() {
+	a.handleCleanMem()
+}
@@ -44,6 +44,10 @@ func (a *actorV3) shouldVote(proposeBlockInfo *ProposeBlockInfo) bool {
 	return false
 }
 
+// handleVoteMsg collects the votes of the beacon committee. As actorV2 does
+// for the beacon chain, it does not run the byzantine detector on them: an
+// equivocation instruction only force unstakes shard stakers, so a beacon
+// validator voting for two blocks of one timeslot is not reported
 func (a *actorV3) handleVoteMsg(voteMsg BFTVote) error {
 	voteMsg.IsValid = 0
 	if proposeBlockInfo, ok := a.GetReceiveBlockByHash(voteMsg.BlockHash); ok { //if received block is already initiated
@@ -87,7 +91,7 @@ func (a *actorV3) createAndSendVote(
 
 	a.logger.Info(a.chainKey, "sending vote...", block.FullHashString())
 
-	go a.node.PushMessageToChain(msg, a.chain)
+	a.pushMessage(msg)
 
 	return nil
 }
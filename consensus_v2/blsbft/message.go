@@ -7,6 +7,7 @@ import (
 	portalprocessv4 "github.com/levietcuong2602/incognito-chain/portal/portalv4/portalprocess"
 
 	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/consensus_v2/consensustypes"
 	signatureschemes2 "github.com/levietcuong2602/incognito-chain/consensus_v2/signatureschemes"
 )
 
@@ -66,6 +67,26 @@ func (s *BFTVote) isEmptyDataForByzantineDetector() bool {
 	return false
 }
 
+// signedVote returns the fields of the vote signed by its confirmation
+func (s *BFTVote) signedVote(signingCommittee []string) consensustypes.SignedVote {
+	return consensustypes.SignedVote{
+		Phase:              s.Phase,
+		Hash:               s.Hash,
+		BlockHash:          s.BlockHash,
+		PrevBlockHash:      s.PrevBlockHash,
+		BlockHeight:        s.BlockHeight,
+		ProduceTimeSlot:    s.ProduceTimeSlot,
+		ProposeTimeSlot:    s.ProposeTimeSlot,
+		Validator:          s.Validator,
+		CommitteeFromBlock: s.CommitteeFromBlock,
+		ChainID:            s.ChainID,
+		BLS:                s.BLS,
+		BRI:                s.BRI,
+		Confirmation:       s.Confirmation,
+		SigningCommittee:   signingCommittee,
+	}
+}
+
 func (s *BFTVote) signVote(key *signatureschemes2.MiningKey) error {
 
 	data := []byte{}
@@ -75,22 +96,7 @@ func (s *BFTVote) signVote(key *signatureschemes2.MiningKey) error {
 		data = append(data, s.BLS...)
 		data = append(data, s.BRI...)
 	} else {
-		if s.Phase != "" {
-			data = append(data, []byte(s.Phase)...)
-		}
-		if s.Hash != "" {
-			data = append(data, []byte(s.Hash)...)
-		}
-		data = append(data, s.BlockHash...)
-		data = append(data, s.BLS...)
-		data = append(data, s.BRI...)
-		data = append(data, common.Uint64ToBytes(s.BlockHeight)...)
-		data = append(data, common.Int64ToBytes(s.ProduceTimeSlot)...)
-		data = append(data, common.Int64ToBytes(s.ProposeTimeSlot)...)
-		data = append(data, []byte(s.Validator)...)
-		data = append(data, []byte(s.PrevBlockHash)...)
-		data = append(data, s.CommitteeFromBlock[:]...)
-		data = append(data, common.Int64ToBytes(int64(s.ChainID))...)
+		data = s.signedVote(nil).ConfirmationData()
 	}
 
 	data = common.HashB(data)
@@ -108,22 +114,7 @@ func (s *BFTVote) validateVoteOwner(ownerPk []byte) error {
 		data = append(data, s.BLS...)
 		data = append(data, s.BRI...)
 	} else {
-		if s.Phase != "" {
-			data = append(data, []byte(s.Phase)...)
-		}
-		if s.Hash != "" {
-			data = append(data, []byte(s.Hash)...)
-		}
-		data = append(data, s.BlockHash...)
-		data = append(data, s.BLS...)
-		data = append(data, s.BRI...)
-		data = append(data, common.Uint64ToBytes(s.BlockHeight)...)
-		data = append(data, common.Int64ToBytes(s.ProduceTimeSlot)...)
-		data = append(data, common.Int64ToBytes(s.ProposeTimeSlot)...)
-		data = append(data, []byte(s.Validator)...)
-		data = append(data, []byte(s.PrevBlockHash)...)
-		data = append(data, s.CommitteeFromBlock[:]...)
-		data = append(data, common.Int64ToBytes(int64(s.ChainID))...)
+		data = s.signedVote(nil).ConfirmationData()
 	}
 
 	dataHash := common.HashH(data)
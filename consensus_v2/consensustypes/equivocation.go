@@ -0,0 +1,169 @@
+package consensustypes
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/consensus_v2/signatureschemes/blsmultisig"
+	"github.com/levietcuong2602/incognito-chain/consensus_v2/signatureschemes/bridgesig"
+	"github.com/levietcuong2602/incognito-chain/incognitokey"
+)
+
+// SignedVote is the part of a BFT vote covered by the signatures of the
+// validator and the committee its BLS signature was made for
+type SignedVote struct {
+	Phase              string
+	Hash               string
+	BlockHash          string //propose block hash, signed by BLS
+	PrevBlockHash      string
+	BlockHeight        uint64
+	ProduceTimeSlot    int64
+	ProposeTimeSlot    int64
+	Validator          string //bls key base58
+	CommitteeFromBlock common.Hash
+	ChainID            int
+	BLS                []byte
+	BRI                []byte
+	Confirmation       []byte
+	SigningCommittee   []string //committee public keys base58
+}
+
+// EquivocationEvidence proves that a validator voted for two different
+// blocks proposed in the same timeslot
+type EquivocationEvidence struct {
+	Votes [2]SignedVote
+}
+
+// ConfirmationData returns the data signed by the confirmation of the vote
+func (v SignedVote) ConfirmationData() []byte {
+	data := []byte{}
+	if v.Phase != "" {
+		data = append(data, []byte(v.Phase)...)
+	}
+	if v.Hash != "" {
+		data = append(data, []byte(v.Hash)...)
+	}
+	data = append(data, v.BlockHash...)
+	data = append(data, v.BLS...)
+	data = append(data, v.BRI...)
+	data = append(data, common.Uint64ToBytes(v.BlockHeight)...)
+	data = append(data, common.Int64ToBytes(v.ProduceTimeSlot)...)
+	data = append(data, common.Int64ToBytes(v.ProposeTimeSlot)...)
+	data = append(data, []byte(v.Validator)...)
+	data = append(data, []byte(v.PrevBlockHash)...)
+	data = append(data, v.CommitteeFromBlock[:]...)
+	data = append(data, common.Int64ToBytes(int64(v.ChainID))...)
+	return data
+}
+
+// verify checks the BLS signature of the vote on the propose block hash and
+// the confirmation of the vote fields, both made by the validator
+func (v SignedVote) verify() (*incognitokey.CommitteePublicKey, error) {
+	if v.BlockHeight < config.Param().ConsensusParam.ByzantineDetectorHeight {
+		return nil, fmt.Errorf("vote at height %v does not sign its timeslot", v.BlockHeight)
+	}
+	committee, err := incognitokey.CommitteeBase58KeyListToStruct(v.SigningCommittee)
+	if err != nil {
+		return nil, err
+	}
+	idx := -1
+	blsKeys := []blsmultisig.PublicKey{}
+	for i, k := range committee {
+		if k.GetMiningKeyBase58(common.BlsConsensus) == v.Validator {
+			idx = i
+		}
+		blsKeys = append(blsKeys, k.MiningPubKey[common.BlsConsensus])
+	}
+	if idx == -1 {
+		return nil, fmt.Errorf("validator %v is not in the signing committee", v.Validator)
+	}
+	signer := &committee[idx]
+	blockHash, err := common.Hash{}.NewHashFromStr(v.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := blsmultisig.Verify(v.BLS, blockHash.GetBytes(), []int{idx}, blsKeys)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("invalid BLS signature")
+	}
+	dataHash := common.HashH(v.ConfirmationData())
+	ok, err = bridgesig.Verify(signer.MiningPubKey[common.BridgeConsensus], dataHash.GetBytes(), v.Confirmation)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("invalid vote confirmation")
+	}
+	return signer, nil
+}
+
+// NewEquivocationEvidence orders the two votes by block hash so that the
+// evidence is the same whichever vote was received first
+func NewEquivocationEvidence(vote1, vote2 SignedVote) *EquivocationEvidence {
+	if vote2.BlockHash < vote1.BlockHash {
+		vote1, vote2 = vote2, vote1
+	}
+	return &EquivocationEvidence{Votes: [2]SignedVote{vote1, vote2}}
+}
+
+// ChainID returns the chain the conflicting votes were cast on
+func (e EquivocationEvidence) ChainID() int {
+	return e.Votes[0].ChainID
+}
+
+// Verify checks that the votes conflict and are both signed by the same
+// validator, and returns its committee public key base58
+func (e EquivocationEvidence) Verify() (string, error) {
+	v1, v2 := e.Votes[0], e.Votes[1]
+	if v1.Validator != v2.Validator {
+		return "", fmt.Errorf("votes are from validators %v and %v", v1.Validator, v2.Validator)
+	}
+	if v1.ChainID != v2.ChainID || v1.CommitteeFromBlock != v2.CommitteeFromBlock {
+		return "", errors.New("votes are not for the same chain and committee")
+	}
+	if v1.ProposeTimeSlot != v2.ProposeTimeSlot {
+		return "", fmt.Errorf("votes are for timeslots %v and %v", v1.ProposeTimeSlot, v2.ProposeTimeSlot)
+	}
+	if v1.BlockHash == v2.BlockHash {
+		return "", errors.New("votes are for the same block")
+	}
+	signer1, err := v1.verify()
+	if err != nil {
+		return "", err
+	}
+	signer2, err := v2.verify()
+	if err != nil {
+		return "", err
+	}
+	if !signer1.IsEqual(*signer2) {
+		return "", errors.New("votes are signed by different committee public keys")
+	}
+	return signer1.ToBase58()
+}
+
+func DecodeEquivocationEvidence(data string) (*EquivocationEvidence, error) {
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+	var evidence EquivocationEvidence
+	if err := json.Unmarshal(b, &evidence); err != nil {
+		return nil, err
+	}
+	return &evidence, nil
+}
+
+func EncodeEquivocationEvidence(evidence EquivocationEvidence) (string, error) {
+	b, err := json.Marshal(evidence)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,158 @@
+package consensustypes
+
+import (
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/consensus_v2/signatureschemes/blsmultisig"
+	"github.com/levietcuong2602/incognito-chain/consensus_v2/signatureschemes/bridgesig"
+	"github.com/levietcuong2602/incognito-chain/incognitokey"
+	"github.com/stretchr/testify/assert"
+)
+
+type testValidator struct {
+	blsKey []byte
+	briKey []byte
+	pubKey incognitokey.CommitteePublicKey
+}
+
+func newTestValidator(t *testing.T, seed string) testValidator {
+	blsKey, _ := blsmultisig.KeyGen([]byte(seed))
+	briKey, _ := bridgesig.KeyGen([]byte(seed))
+	pubKey, err := incognitokey.NewCommitteeKeyFromSeed([]byte(seed), []byte(seed))
+	assert.Nil(t, err)
+	return testValidator{
+		blsKey: blsmultisig.SKBytes(blsKey),
+		briKey: bridgesig.SKBytes(&briKey),
+		pubKey: pubKey,
+	}
+}
+
+// newTestVote returns the vote of committee[idx] for block at timeslot signed
+// with the keys of signer
+func newTestVote(t *testing.T, committee []testValidator, idx int, signer testValidator, block string, timeSlot int64) SignedVote {
+	blsKeys := []blsmultisig.PublicKey{}
+	signingCommittee := []string{}
+	for _, v := range committee {
+		blsKeys = append(blsKeys, v.pubKey.MiningPubKey[common.BlsConsensus])
+		k, err := v.pubKey.ToBase58()
+		assert.Nil(t, err)
+		signingCommittee = append(signingCommittee, k)
+	}
+	blockHash := common.HashH([]byte(block))
+	vote := SignedVote{
+		Phase:            "vote",
+		BlockHash:        blockHash.String(),
+		PrevBlockHash:    common.HashH([]byte("prev")).String(),
+		BlockHeight:      10,
+		ProduceTimeSlot:  timeSlot,
+		ProposeTimeSlot:  timeSlot,
+		Validator:        committee[idx].pubKey.GetMiningKeyBase58(common.BlsConsensus),
+		ChainID:          1,
+		SigningCommittee: signingCommittee,
+	}
+	var err error
+	vote.BLS, err = blsmultisig.Sign(blockHash.GetBytes(), signer.blsKey, idx, blsKeys)
+	assert.Nil(t, err)
+	vote.Confirmation, err = bridgesig.Sign(signer.briKey, common.HashB(vote.ConfirmationData()))
+	assert.Nil(t, err)
+	return vote
+}
+
+func TestEquivocationEvidence_Verify(t *testing.T) {
+	config.AbortParam()
+	config.Param().ConsensusParam.ByzantineDetectorHeight = 10
+	committee := []testValidator{
+		newTestValidator(t, "validator 0"),
+		newTestValidator(t, "validator 1"),
+		newTestValidator(t, "validator 2"),
+	}
+	offender, err := committee[1].pubKey.ToBase58()
+	assert.Nil(t, err)
+	forged := committee[1]
+	forged.briKey = committee[2].briKey
+	tests := []struct {
+		name    string
+		vote1   SignedVote
+		vote2   SignedVote
+		edit    func(*SignedVote)
+		wantErr string
+	}{
+		{
+			name:  "two blocks in one timeslot",
+			vote1: newTestVote(t, committee, 1, committee[1], "block a", 100),
+			vote2: newTestVote(t, committee, 1, committee[1], "block b", 100),
+		},
+		{
+			name:    "same block",
+			vote1:   newTestVote(t, committee, 1, committee[1], "block a", 100),
+			vote2:   newTestVote(t, committee, 1, committee[1], "block a", 100),
+			wantErr: "votes are for the same block",
+		},
+		{
+			name:    "two timeslots",
+			vote1:   newTestVote(t, committee, 1, committee[1], "block a", 100),
+			vote2:   newTestVote(t, committee, 1, committee[1], "block b", 101),
+			wantErr: "votes are for timeslots 100 and 101",
+		},
+		{
+			name:  "two validators",
+			vote1: newTestVote(t, committee, 1, committee[1], "block a", 100),
+			vote2: newTestVote(t, committee, 2, committee[2], "block b", 100),
+			wantErr: "votes are from validators " + committee[1].pubKey.GetMiningKeyBase58(common.BlsConsensus) +
+				" and " + committee[2].pubKey.GetMiningKeyBase58(common.BlsConsensus),
+		},
+		{
+			name:    "confirmation signed by another bridge key",
+			vote1:   newTestVote(t, committee, 1, committee[1], "block a", 100),
+			vote2:   newTestVote(t, committee, 1, forged, "block b", 100),
+			wantErr: "invalid vote confirmation",
+		},
+		{
+			name:    "BLS signature of another block",
+			vote1:   newTestVote(t, committee, 1, committee[1], "block a", 100),
+			vote2:   newTestVote(t, committee, 1, committee[1], "block b", 100),
+			edit:    func(v *SignedVote) { v.BLS = newTestVote(t, committee, 1, committee[1], "block c", 100).BLS },
+			wantErr: "invalid BLS signature",
+		},
+		{
+			name:    "validator not in the signing committee",
+			vote1:   newTestVote(t, committee, 1, committee[1], "block a", 100),
+			vote2:   newTestVote(t, committee, 1, committee[1], "block b", 100),
+			edit:    func(v *SignedVote) { v.SigningCommittee = v.SigningCommittee[:1] },
+			wantErr: "validator " + committee[1].pubKey.GetMiningKeyBase58(common.BlsConsensus) + " is not in the signing committee",
+		},
+		{
+			name:    "vote before the detector height",
+			vote1:   newTestVote(t, committee, 1, committee[1], "block a", 100),
+			vote2:   newTestVote(t, committee, 1, committee[1], "block b", 100),
+			edit:    func(v *SignedVote) { v.BlockHeight = 9 },
+			wantErr: "vote at height 9 does not sign its timeslot",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.edit != nil {
+				tt.edit(&tt.vote1)
+				tt.edit(&tt.vote2)
+			}
+			evidence := NewEquivocationEvidence(tt.vote1, tt.vote2)
+			got, err := evidence.Verify()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, offender, got)
+
+			//the evidence is the same whichever vote comes first
+			assert.Equal(t, evidence, NewEquivocationEvidence(tt.vote2, tt.vote1))
+			data, err := EncodeEquivocationEvidence(*evidence)
+			assert.Nil(t, err)
+			decoded, err := DecodeEquivocationEvidence(data)
+			assert.Nil(t, err)
+			assert.Equal(t, evidence, decoded)
+		})
+	}
+}
@@ -1,86 +1,352 @@
-package main
+package simulation
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/levietcuong2602/incognito-chain/blockchain/types"
 	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/consensus_v2/consensustypes"
+	"github.com/levietcuong2602/incognito-chain/consensus_v2/signatureschemes/blsmultisig"
+	"github.com/levietcuong2602/incognito-chain/incdb"
 	"github.com/levietcuong2602/incognito-chain/incognitokey"
 	"github.com/levietcuong2602/incognito-chain/multiview"
+	"github.com/levietcuong2602/incognito-chain/portal/portalv4"
 )
 
+// Chain is the beacon chain of a simulated node, its blocks carry no
+// instruction and are valid if proposed by the proposer of their timeslot.
+// Inserted blocks must be signed by more than 2/3 of the committee.
 type Chain struct {
-	multiview *multiview.MultiView
-	chainID   int
-	chainName string
+	node             *Node
+	multiView        *multiview.BeaconMultiView
+	blocks           map[common.Hash]*types.BeaconBlock
+	committee        []incognitokey.CommitteePublicKey
+	timeSlotDuration int64
 }
 
-func NewChain(chainID int, chainName string, committee []incognitokey.CommitteePublicKey) *Chain {
-	c := new(Chain)
-	c.chainID = chainID
-	c.chainName = chainName
-	c.multiview = multiview.NewMultiView()
-	state := &State{
-		NewBlock(1, 1, "Genesis", common.Hash{}),
-		committee,
+func NewChain(node *Node, genesis *types.BeaconBlock, committee []incognitokey.CommitteePublicKey, timeSlotDuration int64) *Chain {
+	c := &Chain{
+		node:             node,
+		multiView:        multiview.NewBeaconMultiView(),
+		blocks:           make(map[common.Hash]*types.BeaconBlock),
+		committee:        committee,
+		timeSlotDuration: timeSlotDuration,
 	}
-	c.multiview.AddView(state)
+	c.blocks[*genesis.Hash()] = genesis
+	c.multiView.AddView(c.newView(genesis))
 	return c
 }
 
+func (c *Chain) newView(block *types.BeaconBlock) *View {
+	return &View{
+		block:            block,
+		committee:        c.committee,
+		timeSlotDuration: c.timeSlotDuration,
+	}
+}
+
+func (c *Chain) BestViewCommitteeFromBlock() common.Hash {
+	return common.Hash{}
+}
+
+func (c *Chain) GetMultiView() multiview.MultiView {
+	return c.multiView
+}
+
 func (c *Chain) GetFinalView() multiview.View {
-	return c.multiview.GetFinalView()
+	return c.multiView.GetFinalView()
 }
 
 func (c *Chain) GetBestView() multiview.View {
-	return c.multiview.GetBestView()
+	return c.multiView.GetBestView()
+}
+
+func (c *Chain) GetEpoch() uint64 {
+	return 1
 }
 
 func (c *Chain) GetChainName() string {
-	return c.chainName
+	return common.BeaconChainKey
+}
+
+func (c *Chain) GetConsensusType() string {
+	return common.BlsConsensus
+}
+
+func (c *Chain) GetBlockConsensusData() map[int]types.BlockConsensusData {
+	return map[int]types.BlockConsensusData{}
+}
+
+func (c *Chain) GetLastBlockTimeStamp() int64 {
+	return c.GetBestView().GetBlock().GetProduceTime()
+}
+
+func (c *Chain) GetMinBlkInterval() time.Duration {
+	return time.Duration(c.timeSlotDuration) * time.Second
+}
+
+func (c *Chain) GetMaxBlkCreateTime() time.Duration {
+	return time.Duration(c.timeSlotDuration) * time.Second / 2
+}
+
+func (c *Chain) IsReady() bool {
+	return true
+}
+
+func (c *Chain) SetReady(bool) {
+}
+
+func (c *Chain) GetActiveShardNumber() int {
+	return 0
+}
+
+func (c *Chain) CurrentHeight() uint64 {
+	return c.GetBestView().GetHeight()
 }
 
-func (s *Chain) IsReady() bool {
+func (c *Chain) GetCommitteeSize() int {
+	return len(c.committee)
+}
+
+func (c *Chain) IsBeaconChain() bool {
 	return true
 }
 
-func (s *Chain) UnmarshalBlock(blockString []byte) (common.BlockInterface, error) {
-	blk := &blockchain.ShardBlock{}
-	json.Unmarshal(blockString, blk)
-	return blk, nil
+func (c *Chain) GetCommittee() []incognitokey.CommitteePublicKey {
+	return c.committee
+}
+
+func (c *Chain) GetPendingCommittee() []incognitokey.CommitteePublicKey {
+	return []incognitokey.CommitteePublicKey{}
+}
+
+func (c *Chain) GetPubKeyCommitteeIndex(pubKey string) int {
+	for i, k := range c.committee {
+		if k.GetMiningKeyBase58(common.BlsConsensus) == pubKey {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *Chain) GetLastProposerIndex() int {
+	return c.GetPubKeyCommitteeIndex(c.GetBestView().GetBlock().GetProposer())
+}
+
+func (c *Chain) UnmarshalBlock(blockString []byte) (types.BlockInterface, error) {
+	block := types.NewBeaconBlock()
+	if err := json.Unmarshal(blockString, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (c *Chain) CreateNewBlock(
+	version int,
+	proposer string,
+	round int,
+	startTime int64,
+	committees []incognitokey.CommitteePublicKey,
+	hash common.Hash,
+) (types.BlockInterface, error) {
+	bestView := c.GetBestView()
+	block := types.NewBeaconBlock()
+	block.Header = types.BeaconHeader{
+		Version:           version,
+		Height:            bestView.GetHeight() + 1,
+		Epoch:             1,
+		Round:             round,
+		Timestamp:         startTime,
+		PreviousBlockHash: *bestView.GetHash(),
+		ConsensusType:     common.BlsConsensus,
+		Producer:          proposer,
+		ProducerPubKeyStr: proposer,
+		Proposer:          proposer,
+		ProposeTime:       startTime,
+	}
+	return block, nil
+}
+
+func (c *Chain) CreateNewBlockFromOldBlock(oldBlock types.BlockInterface, proposer string, startTime int64, isValidRePropose bool) (types.BlockInterface, error) {
+	block := *oldBlock.(*types.BeaconBlock)
+	block.Header.Proposer = proposer
+	block.Header.ProposeTime = startTime
+	return &block, nil
+}
+
+// InsertBlock adds a block committed by the node and announces it to the
+// other nodes, as their syncker would get it
+func (c *Chain) InsertBlock(block types.BlockInterface, shouldValidate bool) error {
+	inserted, err := c.insertBlock(block.(*types.BeaconBlock))
+	if err != nil || !inserted {
+		return err
+	}
+	return c.node.PushBlockToAll(block, "", true)
+}
+
+// insertBlock adds block to the chain if it is signed by more than 2/3 of the
+// committee, a known block is ignored
+func (c *Chain) insertBlock(block *types.BeaconBlock) (bool, error) {
+	if c.hasBlock(*block.Hash()) {
+		return false, nil
+	}
+	if !c.hasBlock(block.GetPrevHash()) {
+		return false, fmt.Errorf("previous block %v of block %v not found", block.GetPrevHash().String(), block.GetHeight())
+	}
+	if err := c.validateCommitteeSig(block); err != nil {
+		return false, err
+	}
+	c.blocks[*block.Hash()] = block
+	if _, err := c.multiView.AddView(c.newView(block)); err != nil {
+		return false, err
+	}
+	c.node.updateFinality()
+	return true, nil
 }
 
-func (c *Chain) CreateNewBlock(version int, proposer string, round int, startTime int64) (common.BlockInterface, error) {
-	newBlock := NewBlock(c.GetBestView().GetHeight()+1, time.Now().Unix(), proposer, *c.GetBestView().GetHash())
-	return newBlock, nil
+func (c *Chain) validateCommitteeSig(block *types.BeaconBlock) error {
+	valData, err := consensustypes.DecodeValidationData(block.GetValidationField())
+	if err != nil {
+		return err
+	}
+	if len(valData.ValidatiorsIdx) <= 2*len(c.committee)/3 {
+		return fmt.Errorf("block %v is signed by %v validators of %v", block.GetHeight(), len(valData.ValidatiorsIdx), len(c.committee))
+	}
+	committeeBLSKeys := []blsmultisig.PublicKey{}
+	for _, k := range c.committee {
+		committeeBLSKeys = append(committeeBLSKeys, k.MiningPubKey[common.BlsConsensus])
+	}
+	ok, err := blsmultisig.Verify(valData.AggSig, block.ProposeHash().GetBytes(), valData.ValidatiorsIdx, committeeBLSKeys)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("invalid committee signature of block %v", block.GetHeight())
+	}
+	return nil
 }
 
-func (c *Chain) CreateNewBlockFromOldBlock(oldBlock common.BlockInterface, proposer string, startTime int64) (common.BlockInterface, error) {
-	//TODO: must using the old block data, and timestamp
-	oldBlock.(*blockchain.ShardBlock).Header.Proposer = proposer
-	oldBlock.(*blockchain.ShardBlock).Header.ProposeTime = time.Now().Unix()
-	// newBlock := NewBlock(c.GetBestView().GetHeight()+1, oldBlock.GetProduceTime(), proposer, *c.GetBestView().GetHash())
-	return oldBlock, nil
+func (c *Chain) InsertAndBroadcastBlock(block types.BlockInterface) error {
+	return c.InsertBlock(block, true)
 }
 
-func (s *Chain) InsertAndBroadcastBlock(block common.BlockInterface) error {
-	state := &State{
-		block,
-		s.multiview.GetBestView().GetCommittee(),
+func (c *Chain) InsertWithPrevValidationData(block types.BlockInterface, previousValidationData string) error {
+	return c.InsertBlock(block, true)
+}
+
+func (c *Chain) InsertAndBroadcastBlockWithPrevValidationData(block types.BlockInterface, previousValidationData string) error {
+	return c.InsertBlock(block, true)
+}
+
+func (c *Chain) ValidatePreSignBlock(block types.BlockInterface, signingCommittees, committees []incognitokey.CommitteePublicKey) error {
+	previousView := c.GetViewByHash(block.GetPrevHash())
+	if previousView == nil {
+		return errors.New("previous view not found")
+	}
+	if block.GetHeight() != previousView.GetHeight()+1 {
+		return fmt.Errorf("block height %v after view height %v", block.GetHeight(), previousView.GetHeight())
+	}
+	proposer, _ := previousView.GetProposerByTimeSlot(previousView.CalculateTimeSlot(block.GetProposeTime()), block.GetVersion())
+	proposerBase58, _ := proposer.ToBase58()
+	if block.GetProposer() != proposerBase58 {
+		return fmt.Errorf("block %v is not proposed by the proposer of its timeslot", block.GetHeight())
 	}
-	s.multiview.AddView(state)
 	return nil
 }
 
-func (s *Chain) ValidatePreSignBlock(block common.BlockInterface) error {
+func (c *Chain) GetShardID() int {
+	return common.BeaconChainID
+}
+
+func (c *Chain) GetChainDatabase() incdb.Database {
+	return nil
+}
+
+func (c *Chain) GetBestViewHeight() uint64 {
+	return c.GetBestView().GetHeight()
+}
+
+func (c *Chain) GetFinalViewHeight() uint64 {
+	return c.GetFinalView().GetHeight()
+}
+
+func (c *Chain) GetBestViewHash() string {
+	return c.GetBestView().GetHash().String()
+}
+
+func (c *Chain) GetFinalViewHash() string {
+	return c.GetFinalView().GetHash().String()
+}
+
+func (c *Chain) GetViewByHash(hash common.Hash) multiview.View {
+	return c.multiView.GetViewByHash(hash)
+}
+
+func (c *Chain) CommitteeEngineVersion() int {
+	return 0
+}
+
+func (c *Chain) GetProposerByTimeSlotFromCommitteeList(ts int64, committees []incognitokey.CommitteePublicKey) (incognitokey.CommitteePublicKey, int) {
+	id := blockchain.GetProposerByTimeSlot(ts, len(committees))
+	return committees[id], id
+}
+
+func (c *Chain) ReplacePreviousValidationData(previousBlockHash common.Hash, previousProposeHash common.Hash, previousCommittee []incognitokey.CommitteePublicKey, newValidationData string) error {
+	block, ok := c.blocks[previousBlockHash]
+	if !ok || !block.ProposeHash().IsEqual(&previousProposeHash) || newValidationData == "" {
+		return nil
+	}
+	block.SetValidationField(newValidationData)
 	return nil
 }
 
-func (s *Chain) GetShardID() int {
-	return s.chainID
+func (c *Chain) GetSigningCommittees(proposerIndex int, committees []incognitokey.CommitteePublicKey, blockVersion int) []incognitokey.CommitteePublicKey {
+	return append([]incognitokey.CommitteePublicKey{}, committees...)
+}
+
+func (c *Chain) GetPortalParamsV4(beaconHeight uint64) portalv4.PortalParams {
+	return portalv4.PortalParams{}
+}
+
+func (c *Chain) GetBlockByHash(hash common.Hash) (types.BlockInterface, error) {
+	block, ok := c.blocks[hash]
+	if !ok {
+		return nil, fmt.Errorf("block %v not found", hash.String())
+	}
+	return block, nil
+}
+
+func (c *Chain) CollectTxs(view multiview.View) {
+}
+
+func (c *Chain) CommitteesFromViewHashForShard(committeeHash common.Hash, shardID byte) ([]incognitokey.CommitteePublicKey, error) {
+	return c.committee, nil
+}
+
+func (c *Chain) FinalView() multiview.View {
+	return c.GetFinalView()
+}
+
+// getBlocksFrom returns the blocks from the first block after a block known
+// by hasBlock to the block hash
+func (c *Chain) getBlocksFrom(hash common.Hash, hasBlock func(common.Hash) bool) []*types.BeaconBlock {
+	res := []*types.BeaconBlock{}
+	for !hasBlock(hash) {
+		block, ok := c.blocks[hash]
+		if !ok {
+			return nil
+		}
+		res = append([]*types.BeaconBlock{block}, res...)
+		hash = block.GetPrevHash()
+	}
+	return res
 }
 
-func (c Chain) GetViewByHash(hash common.Hash) multiview.View {
-	return c.multiview.GetViewByHash(hash)
+func (c *Chain) hasBlock(hash common.Hash) bool {
+	_, ok := c.blocks[hash]
+	return ok
 }
@@ -0,0 +1,132 @@
+package simulation
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/consensus_v2/blsbft"
+	"github.com/levietcuong2602/incognito-chain/wire"
+)
+
+var msgOrder = map[string]int{MsgPropose: 0, MsgPreVote: 1, MsgVote: 2, MsgBlock: 3}
+
+// packet is a message on its way from a node to another
+type packet struct {
+	from, to  int
+	deliverAt int64 //ms
+	kind      string
+	bftMsg    *wire.MessageBFT
+	block     []byte
+	hash      common.Hash
+}
+
+// network delivers the messages of the nodes with the faults of the scenario.
+// The messages are delivered at the first tick after their delivery time, in
+// an order that does not depend on the order they were sent in.
+type network struct {
+	sim     *Simulation
+	packets []*packet
+}
+
+func (net *network) sendBFTMsg(from int, msg *wire.MessageBFT) {
+	kind := msg.Type
+	if kind == blsbft.MSG_VOTE {
+		vote := struct{ Phase string }{}
+		if err := json.Unmarshal(msg.Content, &vote); err == nil && vote.Phase == MsgPreVote {
+			kind = MsgPreVote
+		}
+	}
+	net.send(&packet{from: from, kind: kind, bftMsg: msg, hash: common.HashH(msg.Content)})
+}
+
+func (net *network) sendBlock(from int, data []byte) {
+	net.send(&packet{from: from, kind: MsgBlock, block: data, hash: common.HashH(data)})
+}
+
+// send broadcasts p to all nodes, a node always receives its own messages
+func (net *network) send(p *packet) {
+	for to := range net.sim.nodes {
+		delay := int64(0)
+		if to != p.from {
+			var ok bool
+			if delay, ok = net.linkDelay(p.from, to, p.kind); !ok {
+				continue
+			}
+		}
+		q := *p
+		q.to = to
+		q.deliverAt = net.sim.now + delay
+		net.packets = append(net.packets, &q)
+	}
+}
+
+// linkDelay returns the delay of a message of kind from a node to another
+// at the current time, false if it is lost
+func (net *network) linkDelay(from, to int, kind string) (int64, bool) {
+	if !net.sim.isReachable(from, to) {
+		return 0, false
+	}
+	delay := net.sim.scenario.LatencyMs
+	for _, f := range net.sim.scenario.Faults {
+		if !f.isActive(net.sim.timeSlot) || !f.matches(from, to, kind) {
+			continue
+		}
+		switch f.Type {
+		case FaultDrop:
+			return 0, false
+		case FaultDelay:
+			delay += f.DelayMs
+		}
+	}
+	return delay, true
+}
+
+// popDue removes and returns the packets to deliver at the current time
+func (net *network) popDue() []*packet {
+	due := []*packet{}
+	remain := []*packet{}
+	for _, p := range net.packets {
+		if p.deliverAt <= net.sim.now {
+			due = append(due, p)
+		} else {
+			remain = append(remain, p)
+		}
+	}
+	net.packets = remain
+	sort.Slice(due, func(i, j int) bool {
+		a, b := due[i], due[j]
+		if a.deliverAt != b.deliverAt {
+			return a.deliverAt < b.deliverAt
+		}
+		if a.to != b.to {
+			return a.to < b.to
+		}
+		if a.from != b.from {
+			return a.from < b.from
+		}
+		if msgOrder[a.kind] != msgOrder[b.kind] {
+			return msgOrder[a.kind] < msgOrder[b.kind]
+		}
+		return a.hash.String() < b.hash.String()
+	})
+	return due
+}
+
+// deliver hands the due packets to the running nodes, the packets of a
+// crashed node are lost
+func (net *network) deliver() {
+	for due := net.popDue(); len(due) > 0; due = net.popDue() {
+		for _, p := range due {
+			if net.sim.isCrashed(p.to) {
+				continue
+			}
+			node := net.sim.nodes[p.to]
+			if p.kind == MsgBlock {
+				node.receiveBlock(p.from, p.block)
+			} else {
+				node.receiveBFTMsg(p.bftMsg)
+			}
+		}
+	}
+}
@@ -1,182 +1,195 @@
-package main
+package simulation
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
-	"strings"
-	"sync"
-	"time"
-
-	"github.com/levietcuong2602/incognito-chain/consensus_v2/signatureschemes"
 
+	"github.com/levietcuong2602/incognito-chain/blockchain/types"
 	"github.com/levietcuong2602/incognito-chain/common"
-	"github.com/levietcuong2602/incognito-chain/consensus_v2/blsbftv2"
-	"github.com/levietcuong2602/incognito-chain/incognitokey"
+	"github.com/levietcuong2602/incognito-chain/consensus_v2/blsbft"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdb_consensus"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	_ "github.com/levietcuong2602/incognito-chain/incdb/lvdb"
 	"github.com/levietcuong2602/incognito-chain/wire"
-	libp2p "github.com/libp2p/go-libp2p-peer"
+	peer "github.com/libp2p/go-libp2p-peer"
 )
 
-var mapNodeID = make(map[string]string)
-var mapLock = sync.Mutex{}
+// actor is the part of the BFT actor driven by the simulation
+type actor interface {
+	HandleBFTMsg(msg *wire.MessageBFT) error
+	Tick()
+	CleanMem()
+}
 
+// Node is a committee member of the simulation, it implements the node
+// interface of the BFT actor over the simulated network
 type Node struct {
-	id              string
-	consensusEngine *blsbftv2.BLSBFT_V2
-	chain           *Chain
-	nodeList        []*Node
+	index  int
+	peerID peer.ID
+	sim    *Simulation
+	logger common.Logger
+	chain  *Chain
+	actor  actor
+	db     incdb.Database
+	dbPath string
+
+	finalHeight   uint64
+	finalHashes   map[uint64]common.Hash
+	finalizeSlots []int64 //timeslots the final height increased in
 }
 
-type logWriter struct {
-	NodeID string
-	fd     *os.File
+func newNode(sim *Simulation, index int) (*Node, error) {
+	dbPath, err := ioutil.TempDir(os.TempDir(), "consensus-simulation")
+	if err != nil {
+		return nil, err
+	}
+	db, err := incdb.Open("leveldb", dbPath)
+	if err != nil {
+		os.RemoveAll(dbPath)
+		return nil, err
+	}
+	name := fmt.Sprintf("node%d", index)
+	n := &Node{
+		index:       index,
+		peerID:      peer.ID(name),
+		sim:         sim,
+		logger:      common.NewBackend(sim.logWriter).Logger(fmt.Sprintf("%v %v", sim.scenario.Name, name), false),
+		db:          db,
+		dbPath:      dbPath,
+		finalHashes: make(map[uint64]common.Hash),
+	}
+	n.chain = NewChain(n, sim.newGenesis(), sim.committee, sim.scenario.TimeSlotDuration)
+	n.finalHashes[n.chain.GetFinalViewHeight()] = *n.chain.GetFinalView().GetHash()
+	n.finalHeight = n.chain.GetFinalViewHeight()
+	n.activate()
+	a := blsbft.NewSimulatedActorV3(n.chain, n.chain, common.BeaconChainKey, blockVersion, common.BeaconChainID, n, n.logger, sim.clock)
+	a.LoadUserKeys(sim.miningKeys[index : index+1])
+	n.actor = a
+	return n, nil
 }
 
-func (s logWriter) Write(p []byte) (n int, err error) {
-	s.fd.Write(p)
-	return len(p), nil
+// activate sets the consensus database of the node, the actors of the nodes
+// run one at a time
+func (n *Node) activate() {
+	rawdb_consensus.SetConsensusDatabase(n.db)
 }
 
-func NewNode(committeePkStruct []incognitokey.CommitteePublicKey, miningKey *signatureschemes.MiningKey, index int, testname string) *Node {
-	name := fmt.Sprintf("%s_log%d", testname, index)
-	fd, err := os.OpenFile(fmt.Sprintf("%s.log", name), os.O_CREATE|os.O_WRONLY, 0666)
-	if err != nil {
-		panic(err)
+func (n *Node) close() {
+	n.db.Close()
+	os.RemoveAll(n.dbPath)
+}
+
+func (n *Node) tick() {
+	n.activate()
+	n.actor.Tick()
+}
+
+func (n *Node) cleanMem() {
+	n.activate()
+	n.actor.CleanMem()
+}
+
+func (n *Node) receiveBFTMsg(msg *wire.MessageBFT) {
+	n.activate()
+	if err := n.actor.HandleBFTMsg(msg); err != nil {
+		n.logger.Error(err)
+	}
+}
+
+// receiveBlock inserts a block announced by from, syncing the missing
+// previous blocks from it
+func (n *Node) receiveBlock(from int, data []byte) {
+	block := types.NewBeaconBlock()
+	if err := json.Unmarshal(data, block); err != nil {
+		n.logger.Error(err)
+		return
 	}
-	fd.Truncate(0)
-	backendLog := common.NewBackend(logWriter{
-		NodeID: name,
-		fd:     fd,
-	})
-	consensusLogger := backendLog.Logger("Consensus", false)
-	consensusLogger.SetLevel(1)
-	chainViewLogger := backendLog.Logger("ShardView", false)
-	chainViewLogger.SetLevel(1)
-
-	node := Node{id: fmt.Sprintf("%d", index)}
-	node.chain = NewChain(0, "shard0", committeePkStruct)
-
-	node.consensusEngine = &blsbftv2.BLSBFT_V2{
-		Chain:    node.chain,
-		Node:     &node,
-		ChainKey: "shard",
-		PeerID:   name,
-		Logger:   consensusLogger,
+	if n.chain.hasBlock(*block.Hash()) {
+		return
 	}
-	node.consensusEngine.LoadUserKeys([]signatureschemes.MiningKey{*miningKey})
-	return &node
-}
-
-func (s *Node) PushMessageToChain(msg wire.Message, chain common.ChainInterface) error {
-	time.AfterFunc(time.Millisecond*100, func() {
-		if msg.(*wire.MessageBFT).Type == "propose" {
-			timeSlot := uint64(msg.(*wire.MessageBFT).TimeSlot)
-			if timeSlot > GetSimulation().maxTimeSlot {
-				os.Exit(0)
-			}
-			pComm := GetSimulation().scenario.proposeComm
-			if senderComm, ok := pComm[timeSlot]; ok {
-				for i, c := range s.nodeList {
-					if s.id == c.id {
-						continue
-					}
-					if senderComm[i] == 0 {
-						fmt.Printf("Don'trrrrrr send propose from %v to %v at timeslot %v\n", s.id, c.id, timeSlot-GetSimulation().startTimeSlot+1)
-						// c.consensusEngine.ProcessBFTMsg(msg.(*wire.MessageBFT))
-					} else {
-						fmt.Printf("Send propose from %v to %v at timeslot %v\n", s.id, c.id, timeSlot-GetSimulation().startTimeSlot+1)
-						c.consensusEngine.ProcessBFTMsg(msg.(*wire.MessageBFT))
-					}
-				}
-			} else {
-				for _, c := range s.nodeList {
-					if s.id == c.id {
-						continue
-					}
-					fmt.Printf("Send* propose from %v to %v at timeslot %v\n", s.id, c.id, timeSlot-GetSimulation().startTimeSlot+1)
-					c.consensusEngine.ProcessBFTMsg(msg.(*wire.MessageBFT))
-				}
-			}
-			return
-		}
+	if !n.chain.hasBlock(block.GetPrevHash()) {
+		n.sim.syncBlocks(n, from, block.GetPrevHash())
+	}
+	if _, err := n.chain.insertBlock(block); err != nil {
+		n.logger.Error(err)
+	}
+}
 
-		if msg.(*wire.MessageBFT).Type == "vote" {
-			vComm := GetSimulation().scenario.voteComm
-			timeSlot := uint64(msg.(*wire.MessageBFT).TimeSlot)
-			if timeSlot > GetSimulation().maxTimeSlot {
-				os.Exit(0)
-			}
-			if comm, ok := vComm[timeSlot]; ok {
-				for i, c := range s.nodeList {
-					if s.id == c.id {
-						continue
-					}
-					if senderComm, ok := comm[s.id]; ok {
-						if senderComm[i] == 0 {
-							fmt.Printf("Don't send vote from %v to %v at timeslot %v\n", s.id, c.id, timeSlot-GetSimulation().startTimeSlot+1)
-							// c.consensusEngine.ProcessBFTMsg(msg.(*wire.MessageBFT))
-						} else {
-							fmt.Printf("Send vote from %v to %v at timeslot %v\n", s.id, c.id, timeSlot-GetSimulation().startTimeSlot+1)
-							c.consensusEngine.ProcessBFTMsg(msg.(*wire.MessageBFT))
-						}
-					} else {
-						fmt.Printf("Send vote from %v to %v at timeslot %v\n", s.id, c.id, timeSlot-GetSimulation().startTimeSlot+1)
-						c.consensusEngine.ProcessBFTMsg(msg.(*wire.MessageBFT))
-					}
-
-				}
-			} else {
-				for _, c := range s.nodeList {
-					if s.id == c.id {
-						continue
-					}
-					fmt.Printf("Send* vote from %v to %v at timeslot %v\n", s.id, c.id, timeSlot-GetSimulation().startTimeSlot+1)
-					c.consensusEngine.ProcessBFTMsg(msg.(*wire.MessageBFT))
-				}
-			}
-			return
+// updateFinality records the blocks finalized since the last call and checks
+// that the node never reverts a final block
+func (n *Node) updateFinality() {
+	finalView := n.chain.GetFinalView()
+	height := finalView.GetHeight()
+	if height <= n.finalHeight {
+		if height == n.finalHeight && *finalView.GetHash() != n.finalHashes[height] {
+			n.sim.violate("safety: node %v replaced its final block %v at height %v by %v", n.index, n.finalHashes[height].String(), height, finalView.GetHash().String())
 		}
-	})
+		return
+	}
+	hash := *finalView.GetHash()
+	for h := height; h > n.finalHeight; h-- {
+		n.finalHashes[h] = hash
+		n.sim.checkFinalBlock(n, h, hash)
+		hash = n.chain.blocks[hash].GetPrevHash()
+	}
+	if hash != n.finalHashes[n.finalHeight] {
+		n.sim.violate("safety: node %v finalized height %v on a branch without its final block %v at height %v", n.index, height, n.finalHashes[n.finalHeight].String(), n.finalHeight)
+	}
+	n.finalHeight = height
+	n.finalizeSlots = append(n.finalizeSlots, n.sim.timeSlot)
+}
 
+func (n *Node) PushMessageToChain(msg wire.Message, chain common.ChainInterface) error {
+	bftMsg, ok := msg.(*wire.MessageBFT)
+	if !ok {
+		return fmt.Errorf("message %v is not simulated", msg.MessageType())
+	}
+	n.sim.network.sendBFTMsg(n.index, bftMsg)
 	return nil
 }
 
-func (s *Node) RequestMissingViewViaStream(peerID string, hashes [][]byte, fromCID int, chainName string) (err error) {
-	str := []string{}
-	for _, h := range hashes {
-		pH, _ := common.Hash{}.NewHash(h)
-		str = append(str, pH.String())
-	}
-	for _, c := range s.nodeList {
-		if s.id == c.id {
-			continue
-		}
-		if c.id == mapNodeID[peerID] {
-			for _, h := range hashes {
-				pH, _ := common.Hash{}.NewHash(h)
-				v := c.chain.multiview.GetViewByHash(*pH)
-				s.chain.multiview.AddView(v)
-			}
-		}
+func (n *Node) PushBlockToAll(block types.BlockInterface, previousValidationData string, isBeacon bool) error {
+	data, err := json.Marshal(block)
+	if err != nil {
+		return err
 	}
-	fmt.Printf("Node %v RequestMissingViewViaStream from %v hash %v \n", s.id, mapNodeID[peerID], strings.Join(str, ","))
+	n.sim.network.sendBlock(n.index, data)
 	return nil
 }
 
-func (s *Node) GetSelfPeerID() libp2p.ID {
-	return libp2p.ID(s.id)
+func (n *Node) IsEnableMining() bool {
+	return true
 }
 
-func (s *Node) Start() {
-	fmt.Printf("Node %s log is %s, peerID: %v \n", s.id, fmt.Sprintf("log%s.log", s.id), libp2p.ID(s.id).String())
-	mapLock.Lock()
-	defer mapLock.Unlock()
-	mapNodeID[libp2p.ID(s.id).String()] = s.id
-	s.consensusEngine.Start()
+func (n *Node) GetMiningKeys() string {
+	return ""
+}
+
+func (n *Node) GetPrivateKey() string {
+	return ""
+}
+
+func (n *Node) GetUserMiningState() (role string, chainID int) {
+	return common.CommitteeRole, common.BeaconChainID
+}
+
+func (n *Node) RequestMissingViewViaStream(peerID string, hashes [][]byte, fromCID int, chainName string) error {
+	from, ok := n.sim.nodeByPeerID[peerID]
+	if !ok {
+		return fmt.Errorf("unknown peer %v", peerID)
+	}
+	for _, h := range hashes {
+		hash, err := common.Hash{}.NewHash(h)
+		if err != nil {
+			return err
+		}
+		n.sim.syncBlocks(n, from, *hash)
+	}
+	return nil
 }
 
-func (s *Node) Stop() {
-	mapLock.Lock()
-	defer mapLock.Unlock()
-	delete(mapNodeID, libp2p.ID(s.id).String())
-	s.consensusEngine.Stop()
+func (n *Node) GetSelfPeerID() peer.ID {
+	return n.peerID
 }
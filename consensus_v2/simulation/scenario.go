@@ -0,0 +1,224 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	FaultPartition = "partition"
+	FaultDelay     = "delay"
+	FaultDrop      = "drop"
+	FaultCrash     = "crash"
+
+	MsgPropose = "propose"
+	MsgPreVote = "prevote"
+	MsgVote    = "vote"
+	MsgBlock   = "block"
+
+	defaultTimeSlotDuration = 10
+	defaultTickMs           = 1000
+)
+
+// Scenario describes a simulation run: the committee, the number of
+// timeslots, the faults of the network and the expected behaviour of the
+// consensus. Nodes are the indexes of the committee, timeslots start from 1.
+type Scenario struct {
+	Name             string      `json:"name"`
+	CommitteeSize    int         `json:"committee_size"`
+	TimeSlots        int64       `json:"timeslots"`
+	TimeSlotDuration int64       `json:"timeslot_duration"` //seconds
+	TickMs           int64       `json:"tick_ms"`
+	LatencyMs        int64       `json:"latency_ms"`
+	Faults           []Fault     `json:"faults"`
+	Expect           Expectation `json:"expect"`
+}
+
+// Fault is active from timeslot From to timeslot To included
+//   - partition: nodes of different Groups do not communicate, a node of no
+//     group is isolated
+//   - delay: the Messages from Nodes to Receivers arrive DelayMs later
+//   - drop: the Messages from Nodes to Receivers are lost
+//   - crash: Nodes, or the proposer of each timeslot if Proposer, neither
+//     tick nor receive messages
+//
+// Empty Nodes, Receivers or Messages match all of them.
+type Fault struct {
+	Type      string   `json:"type"`
+	From      int64    `json:"from"`
+	To        int64    `json:"to"`
+	Groups    [][]int  `json:"groups,omitempty"`
+	Nodes     []int    `json:"nodes,omitempty"`
+	Receivers []int    `json:"receivers,omitempty"`
+	Messages  []string `json:"messages,omitempty"`
+	DelayMs   int64    `json:"delay_ms,omitempty"`
+	Proposer  bool     `json:"proposer,omitempty"`
+}
+
+// Expectation is checked at the end of the run, safety is always checked
+//   - FinalizeWithin: after the last fault, every running node finalizes a
+//     block in every FinalizeWithin timeslots, 0 skips the check
+//   - MinFinalHeight: every running node reaches this final height
+type Expectation struct {
+	FinalizeWithin int64  `json:"finalize_within"`
+	MinFinalHeight uint64 `json:"min_final_height"`
+}
+
+// LoadScenario reads a scenario from a json file, its name defaults to the
+// file name
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	scenario := &Scenario{}
+	if err := json.Unmarshal(data, scenario); err != nil {
+		return nil, fmt.Errorf("scenario %v: %v", path, err)
+	}
+	if scenario.Name == "" {
+		scenario.Name = filepath.Base(path)
+	}
+	return scenario, nil
+}
+
+// LoadScenarios reads the json scenarios of dir sorted by file name
+func LoadScenarios(dir string) ([]*Scenario, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	scenarios := []*Scenario{}
+	for _, path := range paths {
+		scenario, err := LoadScenario(path)
+		if err != nil {
+			return nil, err
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	return scenarios, nil
+}
+
+func (s *Scenario) setDefault() {
+	if s.TimeSlotDuration == 0 {
+		s.TimeSlotDuration = defaultTimeSlotDuration
+	}
+	if s.TickMs == 0 {
+		s.TickMs = defaultTickMs
+	}
+}
+
+func (s *Scenario) validate() error {
+	if s.CommitteeSize < 1 {
+		return fmt.Errorf("scenario %v: committee size %v", s.Name, s.CommitteeSize)
+	}
+	if s.TimeSlots < 1 {
+		return fmt.Errorf("scenario %v: %v timeslots", s.Name, s.TimeSlots)
+	}
+	if s.TimeSlotDuration < 1 || s.TickMs < 1 || s.TickMs > s.TimeSlotDuration*1000 || s.LatencyMs < 0 {
+		return fmt.Errorf("scenario %v: invalid timing", s.Name)
+	}
+	checkNodes := func(nodes []int) error {
+		for _, n := range nodes {
+			if n < 0 || n >= s.CommitteeSize {
+				return fmt.Errorf("scenario %v: unknown node %v", s.Name, n)
+			}
+		}
+		return nil
+	}
+	for i, f := range s.Faults {
+		if f.From < 1 || f.To < f.From {
+			return fmt.Errorf("scenario %v: fault %v from %v to %v", s.Name, i, f.From, f.To)
+		}
+		switch f.Type {
+		case FaultPartition:
+			for _, g := range f.Groups {
+				if err := checkNodes(g); err != nil {
+					return err
+				}
+			}
+		case FaultDelay:
+			if f.DelayMs <= 0 {
+				return fmt.Errorf("scenario %v: fault %v delay %v", s.Name, i, f.DelayMs)
+			}
+		case FaultCrash:
+			if len(f.Nodes) == 0 && !f.Proposer {
+				return fmt.Errorf("scenario %v: fault %v crashes no node", s.Name, i)
+			}
+		case FaultDrop:
+		default:
+			return fmt.Errorf("scenario %v: fault %v type %v", s.Name, i, f.Type)
+		}
+		if err := checkNodes(f.Nodes); err != nil {
+			return err
+		}
+		if err := checkNodes(f.Receivers); err != nil {
+			return err
+		}
+		for _, m := range f.Messages {
+			switch m {
+			case MsgPropose, MsgPreVote, MsgVote, MsgBlock:
+			default:
+				return fmt.Errorf("scenario %v: fault %v message %v", s.Name, i, m)
+			}
+		}
+	}
+	return nil
+}
+
+// lastFaultTimeSlot returns the last timeslot with an active fault, 0 if none
+func (s *Scenario) lastFaultTimeSlot() int64 {
+	res := int64(0)
+	for _, f := range s.Faults {
+		if f.To > res {
+			res = f.To
+		}
+	}
+	return res
+}
+
+func (f *Fault) isActive(timeSlot int64) bool {
+	return timeSlot >= f.From && timeSlot <= f.To
+}
+
+func (f *Fault) matches(from, to int, kind string) bool {
+	return containsOrEmpty(f.Nodes, from) && containsOrEmpty(f.Receivers, to) && containsStrOrEmpty(f.Messages, kind)
+}
+
+// isPartitioned returns whether from and to are in different groups
+func (f *Fault) isPartitioned(from, to int) bool {
+	for _, g := range f.Groups {
+		if contains(g, from) {
+			return !contains(g, to)
+		}
+	}
+	return true
+}
+
+func contains(l []int, v int) bool {
+	for _, x := range l {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsOrEmpty(l []int, v int) bool {
+	return len(l) == 0 || contains(l, v)
+}
+
+func containsStrOrEmpty(l []string, v string) bool {
+	if len(l) == 0 {
+		return true
+	}
+	for _, x := range l {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -1,56 +1,277 @@
-package main
+package simulation
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/levietcuong2602/incognito-chain/blockchain/types"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/common/base58"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/consensus_v2"
+	"github.com/levietcuong2602/incognito-chain/consensus_v2/signatureschemes"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdb_consensus"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/incognitokey"
+)
+
+const (
+	blockVersion = types.INSTANT_FINALITY_VERSION_V2
+	// genesisTime is the time of the genesis block, timeslot 1 of the
+	// scenario starts one timeslot later
+	genesisTime = 1600000000
 )
 
+// Simulation runs the BFT actors of a committee over a simulated network on a
+// deterministic clock: at each tick the due messages are delivered, then the
+// running nodes tick in their committee order.
 type Simulation struct {
-	nodeList      []*Node
-	fd            os.File
-	maxTimeSlot   uint64
-	startTimeSlot uint64
-	scenario      Scenario
-	expected      Expected
+	scenario   *Scenario
+	logWriter  io.Writer
+	miningKeys []signatureschemes.MiningKey
+	committee  []incognitokey.CommitteePublicKey
+
+	nodes        []*Node
+	nodeByPeerID map[string]int
+	network      *network
+	previousDB   incdb.Database
+
+	startTimeSlot int64 //absolute timeslot of the genesis block
+	timeSlot      int64 //current timeslot of the scenario
+	now           int64 //ms
+
+	finalBlocks map[uint64]finalBlock
+	violations  []string
+}
+
+type finalBlock struct {
+	hash common.Hash
+	node int
+}
+
+// Result is the state of the nodes at the end of a run and the violated
+// properties, if any
+type Result struct {
+	Scenario     string
+	FinalHeights []uint64
+	FinalHashes  []string
+	Violations   []string
+}
+
+func (r *Result) Err() error {
+	if len(r.Violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("scenario %v:\n%v", r.Scenario, strings.Join(r.Violations, "\n"))
+}
+
+// InitSimulation creates the nodes of scenario, the logs of the actors are
+// written to logWriter if not nil
+func InitSimulation(scenario *Scenario, logWriter io.Writer) (*Simulation, error) {
+	scenario.setDefault()
+	if err := scenario.validate(); err != nil {
+		return nil, err
+	}
+	if config.Param() == nil {
+		config.AbortParam()
+	}
+	if logWriter == nil {
+		logWriter = ioutil.Discard
+	}
+	s := &Simulation{
+		scenario:      scenario,
+		logWriter:     logWriter,
+		nodeByPeerID:  make(map[string]int),
+		previousDB:    rawdb_consensus.GetConsensusDatabase(),
+		startTimeSlot: genesisTime / scenario.TimeSlotDuration,
+		finalBlocks:   make(map[uint64]finalBlock),
+	}
+	s.network = &network{sim: s}
+	s.setTime(0, 0)
+	for i := 0; i < scenario.CommitteeSize; i++ {
+		seed := common.HashB([]byte(fmt.Sprintf("consensus simulation %d", i)))
+		miningKey, err := consensus_v2.GetMiningKeyFromPrivateSeed(base58.Base58Check{}.Encode(seed, common.Base58Version))
+		if err != nil {
+			return nil, err
+		}
+		s.miningKeys = append(s.miningKeys, *miningKey)
+		s.committee = append(s.committee, *miningKey.GetPublicKey())
+	}
+	for i := 0; i < scenario.CommitteeSize; i++ {
+		node, err := newNode(s, i)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.nodes = append(s.nodes, node)
+		s.nodeByPeerID[node.peerID.String()] = i
+	}
+	return s, nil
+}
+
+// RunScenario runs scenario in a new simulation
+func RunScenario(scenario *Scenario, logWriter io.Writer) (*Result, error) {
+	s, err := InitSimulation(scenario, logWriter)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	return s.Run(), nil
+}
+
+// Close releases the databases of the nodes
+func (s *Simulation) Close() {
+	for _, node := range s.nodes {
+		node.close()
+	}
+	s.nodes = nil
+	rawdb_consensus.SetConsensusDatabase(s.previousDB)
+}
+
+func (s *Simulation) newGenesis() *types.BeaconBlock {
+	genesis := types.NewBeaconBlock()
+	genesisTime := s.startTimeSlot * s.scenario.TimeSlotDuration
+	genesis.Header = types.BeaconHeader{
+		Version:        blockVersion,
+		Height:         1,
+		Epoch:          1,
+		Timestamp:      genesisTime,
+		ConsensusType:  common.BlsConsensus,
+		ProposeTime:    genesisTime,
+		FinalityHeight: 1,
+	}
+	return genesis
 }
 
-type Scenario struct {
-	proposeComm map[uint64][]int // timeSlot -> sender -> receiver
-	voteComm    map[uint64]map[string][]int
-	sync        map[string][]string
+func (s *Simulation) setTime(timeSlot int64, tick int64) {
+	s.timeSlot = timeSlot
+	s.now = (s.startTimeSlot+timeSlot)*s.scenario.TimeSlotDuration*1000 + tick*s.scenario.TickMs
 }
 
-type Expected struct {
+func (s *Simulation) clock() time.Time {
+	return time.Unix(0, s.now*int64(time.Millisecond))
+}
+
+// Run runs the timeslots of the scenario and checks its expectation
+func (s *Simulation) Run() *Result {
+	ticks := s.scenario.TimeSlotDuration * 1000 / s.scenario.TickMs
+	for ts := int64(1); ts <= s.scenario.TimeSlots; ts++ {
+		for tick := int64(0); tick < ticks; tick++ {
+			s.setTime(ts, tick)
+			s.network.deliver()
+			for i, node := range s.nodes {
+				if s.isCrashed(i) {
+					continue
+				}
+				if tick == 0 {
+					node.cleanMem()
+				}
+				node.tick()
+			}
+		}
+	}
+	s.checkExpectation()
+
+	result := &Result{
+		Scenario:   s.scenario.Name,
+		Violations: s.violations,
+	}
+	for _, node := range s.nodes {
+		result.FinalHeights = append(result.FinalHeights, node.finalHeight)
+		result.FinalHashes = append(result.FinalHashes, node.finalHashes[node.finalHeight].String())
+	}
+	return result
 }
 
-var simulation *Simulation
+func (s *Simulation) violate(format string, a ...interface{}) {
+	s.violations = append(s.violations, fmt.Sprintf("timeslot %v: ", s.timeSlot)+fmt.Sprintf(format, a...))
+}
 
-func InitSimulation(scenario []map[string]Scenario, expected []map[string]Expected) *Simulation {
-	return simulation
+// checkFinalBlock checks that no other node finalized another block at height
+func (s *Simulation) checkFinalBlock(node *Node, height uint64, hash common.Hash) {
+	final, ok := s.finalBlocks[height]
+	if !ok {
+		s.finalBlocks[height] = finalBlock{hash: hash, node: node.index}
+		return
+	}
+	if final.hash != hash {
+		s.violate("safety: node %v finalized block %v at height %v, node %v finalized block %v", node.index, hash.String(), height, final.node, final.hash.String())
+	}
 }
 
-func GetSimulation() *Simulation {
-	if simulation == nil {
-		simulation = new(Simulation)
-		simulation.scenario = Scenario{
-			proposeComm: make(map[uint64][]int),
-			voteComm:    make(map[uint64]map[string][]int),
-			sync:        make(map[string][]string),
+// checkExpectation checks the liveness of the nodes running at the end
+func (s *Simulation) checkExpectation() {
+	expect := s.scenario.Expect
+	healed := s.scenario.lastFaultTimeSlot() + 1
+	for i, node := range s.nodes {
+		if s.isCrashed(i) {
+			continue
+		}
+		if node.finalHeight < expect.MinFinalHeight {
+			s.violate("liveness: node %v final height %v, expected at least %v", i, node.finalHeight, expect.MinFinalHeight)
+		}
+		if expect.FinalizeWithin == 0 || healed > s.scenario.TimeSlots {
+			continue
+		}
+		last := healed - 1
+		for _, ts := range append(node.finalizeSlots, s.scenario.TimeSlots+1) {
+			if ts < healed {
+				continue
+			}
+			if ts-last > expect.FinalizeWithin {
+				s.violate("liveness: node %v finalized no block from timeslot %v to %v", i, last+1, ts-1)
+				break
+			}
+			last = ts
 		}
 	}
-	return simulation
 }
 
-func (s *Simulation) setMaxTimeSlot(max uint64) {
-	fmt.Println("Set Max Time Slot", max)
-	s.maxTimeSlot = max
+func (s *Simulation) proposer(timeSlot int64) int {
+	return blockchain.GetProposerByTimeSlot(s.startTimeSlot+timeSlot, len(s.committee))
 }
-func (s *Simulation) setStartTimeSlot(start uint64) {
-	fmt.Println("Set Start Time Slot", start)
-	s.startTimeSlot = start
+
+func (s *Simulation) isCrashed(node int) bool {
+	for _, f := range s.scenario.Faults {
+		if f.Type != FaultCrash || !f.isActive(s.timeSlot) {
+			continue
+		}
+		if contains(f.Nodes, node) || (f.Proposer && s.proposer(s.timeSlot) == node) {
+			return true
+		}
+	}
+	return false
 }
 
-func (s *Simulation) setLogfile(fd os.File) {
-	s.fd = fd
+// isReachable returns whether from can communicate with to at the current time
+func (s *Simulation) isReachable(from, to int) bool {
+	if s.isCrashed(from) || s.isCrashed(to) {
+		return false
+	}
+	for _, f := range s.scenario.Faults {
+		if f.Type == FaultPartition && f.isActive(s.timeSlot) && f.isPartitioned(from, to) {
+			return false
+		}
+	}
+	return true
+}
 
+// syncBlocks inserts into node the blocks of from up to hash, as the syncker
+// would stream them
+func (s *Simulation) syncBlocks(node *Node, from int, hash common.Hash) {
+	if !s.isReachable(from, node.index) {
+		return
+	}
+	blocks := s.nodes[from].chain.getBlocksFrom(hash, node.chain.hasBlock)
+	for _, b := range blocks {
+		block := *b
+		if _, err := node.chain.insertBlock(&block); err != nil {
+			node.logger.Error(err)
+			return
+		}
+	}
 }
@@ -0,0 +1,66 @@
+package simulation
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestScenarios(t *testing.T) {
+	scenarios, err := LoadScenarios("scenarios")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scenarios) == 0 {
+		t.Fatal("no scenario found")
+	}
+	for _, scenario := range scenarios {
+		scenario := scenario
+		t.Run(scenario.Name, func(t *testing.T) {
+			var logWriter io.Writer
+			if testing.Verbose() {
+				logWriter = os.Stdout
+			}
+			result, err := RunScenario(scenario, logWriter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Logf("final heights %v", result.FinalHeights)
+			if err := result.Err(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestDeterministicRun(t *testing.T) {
+	run := func() *Result {
+		scenario, err := LoadScenario("scenarios/minority_partition.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := RunScenario(scenario, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	first, second := run(), run()
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("runs differ: %+v, %+v", first, second)
+	}
+}
+
+func TestSafetyViolation(t *testing.T) {
+	s := &Simulation{finalBlocks: make(map[uint64]finalBlock)}
+	s.checkFinalBlock(&Node{index: 0}, 2, [32]byte{1})
+	s.checkFinalBlock(&Node{index: 1}, 2, [32]byte{1})
+	if len(s.violations) != 0 {
+		t.Fatalf("unexpected violations %v", s.violations)
+	}
+	s.checkFinalBlock(&Node{index: 2}, 2, [32]byte{2})
+	if len(s.violations) != 1 {
+		t.Fatalf("expected a safety violation, got %v", s.violations)
+	}
+}
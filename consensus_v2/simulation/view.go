@@ -1,38 +1,79 @@
-package main
+package simulation
 
 import (
 	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/levietcuong2602/incognito-chain/blockchain/types"
 	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/incdb"
 	"github.com/levietcuong2602/incognito-chain/incognitokey"
+	"github.com/levietcuong2602/incognito-chain/multiview"
 )
 
-type State struct {
-	block     common.BlockInterface
-	committee []incognitokey.CommitteePublicKey
+// View is the beacon view of a simulated chain, the committee never changes
+type View struct {
+	block            *types.BeaconBlock
+	committee        []incognitokey.CommitteePublicKey
+	timeSlotDuration int64
 }
 
-func (s *State) GetHash() *common.Hash {
-	return s.block.Hash()
+func (v *View) CalculateTimeSlot(t int64) int64 {
+	return t / v.timeSlotDuration
 }
 
-func (s *State) GetPreviousHash() *common.Hash {
-	hash := s.block.GetPrevHash()
+func (v *View) GetCurrentTimeSlot() int64 {
+	return v.timeSlotDuration
+}
+
+func (v *View) GetHash() *common.Hash {
+	return v.block.Hash()
+}
+
+func (v *View) GetPreviousHash() *common.Hash {
+	hash := v.block.GetPrevHash()
 	return &hash
 }
 
-func (s *State) GetHeight() uint64 {
-	return s.block.GetHeight()
+func (v *View) GetHeight() uint64 {
+	return v.block.GetHeight()
+}
+
+func (v *View) GetCommittee() []incognitokey.CommitteePublicKey {
+	return v.committee
+}
+
+func (v *View) GetPreviousBlockCommittee(db incdb.Database) ([]incognitokey.CommitteePublicKey, error) {
+	return v.committee, nil
+}
+
+func (v *View) CommitteeStateVersion() int {
+	return 0
+}
+
+func (v *View) GetBlock() types.BlockInterface {
+	return v.block
+}
+
+func (v *View) ReplaceBlock(blk types.BlockInterface) {
+	v.block = blk.(*types.BeaconBlock)
+}
+
+func (v *View) GetBeaconHeight() uint64 {
+	return v.block.GetHeight()
+}
+
+func (v *View) GetProposerByTimeSlot(ts int64, version int) (incognitokey.CommitteePublicKey, int) {
+	id := blockchain.GetProposerByTimeSlot(ts, len(v.committee))
+	return v.committee[id], id
 }
 
-func (s *State) GetCommittee() []incognitokey.CommitteePublicKey {
-	return s.committee
+func (v *View) GetProposerLength() int {
+	return len(v.committee)
 }
 
-func (s *State) GetProposerByTimeSlot(ts int64, version int) (incognitokey.CommitteePublicKey, int) {
-	id := blockchain.GetProposerByTimeSlot(ts, len(s.committee))
-	return s.committee[id], id
+func (v *View) CompareCommitteeFromBlock(multiview.View) int {
+	return 0
 }
 
-func (s *State) GetBlock() common.BlockInterface {
-	return s.block
+func (v *View) PastHalfTimeslot(int64) bool {
+	return false
 }
@@ -0,0 +1,227 @@
+package flatfile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+)
+
+// Ancient store layout
+//
+//	segments/  flat file of the frozen items, large zstd segments
+//	index      height-indexed table, the 32 bytes hash of every frozen item
+//	meta       8 bytes height of the first frozen item
+//
+// Items are frozen in height order without gap, the flat file index of the
+// item at height h is h - first. The table entry is written before the item,
+// so on open the table is cut to the number of items in the flat file.
+const (
+	AncientSegmentSize = 50000
+
+	ancientSegmentDir = "segments"
+	ancientTableFile  = "index"
+	ancientMetaFile   = "meta"
+	ancientHashLen    = common.HashSize
+)
+
+// AncientStore is an append only store of finalized items (blocks) indexed by
+// height. Items are never modified once frozen.
+type AncientStore struct {
+	dir   string
+	ff    *FlatFileManager
+	table *os.File
+	first uint64 //height of the first item, 0 when empty
+	count uint64 //number of items
+	lock  sync.RWMutex
+}
+
+// OpenAncientStore opens or creates the ancient store at dir
+func OpenAncientStore(dir string) (*AncientStore, error) {
+	a := &AncientStore{dir: dir}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// OpenAncientStoreReadOnly opens the existing ancient store at dir for the
+// tools, nothing is written and a torn tail is ignored instead of repaired
+func OpenAncientStoreReadOnly(dir string) (*AncientStore, error) {
+	ff, err := OpenFlatFileReadOnly(path.Join(dir, ancientSegmentDir), AncientSegmentSize)
+	if err != nil {
+		return nil, err
+	}
+	table, err := os.Open(path.Join(dir, ancientTableFile))
+	if err != nil {
+		return nil, err
+	}
+	stat, err := table.Stat()
+	if err != nil {
+		table.Close()
+		return nil, err
+	}
+	count := uint64(stat.Size()) / ancientHashLen
+	if ff.Size() < count {
+		count = ff.Size()
+	}
+	first := uint64(0)
+	if b, err := ioutil.ReadFile(path.Join(dir, ancientMetaFile)); err == nil && len(b) == 8 {
+		first = binary.BigEndian.Uint64(b)
+	} else if count > 0 {
+		table.Close()
+		return nil, fmt.Errorf("ancient store %v: missing meta file", dir)
+	}
+	return &AncientStore{dir: dir, ff: ff, table: table, first: first, count: count}, nil
+}
+
+func (a *AncientStore) open() error {
+	ff, err := NewFlatFileWithCompression(path.Join(a.dir, ancientSegmentDir), AncientSegmentSize, CompressionZstd)
+	if err != nil {
+		return err
+	}
+	if _, err := ff.RepairTail(); err != nil {
+		return err
+	}
+	table, err := os.OpenFile(path.Join(a.dir, ancientTableFile), os.O_CREATE|os.O_RDWR, 0666)
+	if err != nil {
+		return err
+	}
+	stat, err := table.Stat()
+	if err != nil {
+		table.Close()
+		return err
+	}
+	count := uint64(stat.Size()) / ancientHashLen
+	if ff.Size() > count {
+		table.Close()
+		return fmt.Errorf("ancient store %v: %v items but %v table entries", a.dir, ff.Size(), count)
+	}
+	if count > ff.Size() || uint64(stat.Size())%ancientHashLen != 0 {
+		count = ff.Size()
+		if err := table.Truncate(int64(count * ancientHashLen)); err != nil {
+			table.Close()
+			return err
+		}
+	}
+
+	first := uint64(0)
+	if b, err := ioutil.ReadFile(path.Join(a.dir, ancientMetaFile)); err == nil && len(b) == 8 {
+		first = binary.BigEndian.Uint64(b)
+	} else if count > 0 {
+		table.Close()
+		return fmt.Errorf("ancient store %v: missing meta file", a.dir)
+	}
+
+	a.ff = ff
+	a.table = table
+	a.first = first
+	a.count = count
+	return nil
+}
+
+// Range returns the frozen heights [first, next), next is the height of the
+// next item to freeze (0 when the store is empty)
+func (a *AncientStore) Range() (uint64, uint64) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	if a.count == 0 {
+		return 0, 0
+	}
+	return a.first, a.first + a.count
+}
+
+// Has reports whether the item at height is frozen
+func (a *AncientStore) Has(height uint64) bool {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.has(height)
+}
+
+func (a *AncientStore) has(height uint64) bool {
+	return a.count > 0 && height >= a.first && height < a.first+a.count
+}
+
+// HashByHeight returns the hash of the item at height
+func (a *AncientStore) HashByHeight(height uint64) (*common.Hash, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	if !a.has(height) {
+		return nil, fmt.Errorf("height %v is not in ancient store", height)
+	}
+	b := make([]byte, ancientHashLen)
+	if _, err := a.table.ReadAt(b, int64((height-a.first)*ancientHashLen)); err != nil {
+		return nil, err
+	}
+	return common.Hash{}.NewHash(b)
+}
+
+// ReadByHeight returns the item at height
+func (a *AncientStore) ReadByHeight(height uint64) ([]byte, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	if !a.has(height) {
+		return nil, fmt.Errorf("height %v is not in ancient store", height)
+	}
+	return a.ff.Read(height - a.first)
+}
+
+// Append freezes the item of height, which must follow the last frozen item
+func (a *AncientStore) Append(height uint64, hash common.Hash, data []byte) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.count == 0 {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, height)
+		if err := ioutil.WriteFile(path.Join(a.dir, ancientMetaFile), b, 0666); err != nil {
+			return err
+		}
+		a.first = height
+	} else if height != a.first+a.count {
+		return fmt.Errorf("cannot freeze height %v, next ancient height is %v", height, a.first+a.count)
+	}
+
+	if _, err := a.table.WriteAt(hash.Bytes(), int64(a.count*ancientHashLen)); err != nil {
+		return err
+	}
+	if err := a.table.Sync(); err != nil {
+		return err
+	}
+	index, err := a.ff.Append(data)
+	if err != nil {
+		return err
+	}
+	if index != a.count {
+		return fmt.Errorf("ancient store %v: item of height %v written at index %v", a.dir, height, index)
+	}
+	a.count++
+	return nil
+}
+
+// Reset removes every frozen item
+func (a *AncientStore) Reset() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.close()
+	if err := os.RemoveAll(a.dir); err != nil {
+		return err
+	}
+	return a.open()
+}
+
+func (a *AncientStore) Close() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.close()
+}
+
+func (a *AncientStore) close() error {
+	if a.ff.currentFD != nil {
+		a.ff.currentFD.Close()
+	}
+	return a.table.Close()
+}
@@ -0,0 +1,85 @@
+package flatfile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+)
+
+func TestAncientStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ancient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	a, err := OpenAncientStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first, next := a.Range(); first != 0 || next != 0 {
+		t.Fatalf("expect empty store, got [%v, %v)", first, next)
+	}
+	for h := uint64(10); h < 30; h++ {
+		if err := a.Append(h, common.HashH([]byte(fmt.Sprint(h))), []byte(fmt.Sprint("block", h))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.Append(31, common.Hash{}, nil); err == nil {
+		t.Fatal("expect error when freezing with a gap")
+	}
+	a.Close()
+
+	//torn table entry, written before its item
+	a, err = OpenAncientStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.table.WriteAt(make([]byte, ancientHashLen+5), int64(20*ancientHashLen))
+	a.Close()
+
+	a, err = OpenAncientStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	if first, next := a.Range(); first != 10 || next != 30 {
+		t.Fatalf("expect [10, 30), got [%v, %v)", first, next)
+	}
+	for h := uint64(10); h < 30; h++ {
+		hash, err := a.HashByHeight(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expect := common.HashH([]byte(fmt.Sprint(h))); !hash.IsEqual(&expect) {
+			t.Fatalf("height %v: wrong hash %v", h, hash.String())
+		}
+		data, err := a.ReadByHeight(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != fmt.Sprint("block", h) {
+			t.Fatalf("height %v: wrong data %v", h, string(data))
+		}
+	}
+	if a.Has(9) || a.Has(30) {
+		t.Fatal("expect heights out of range not to be frozen")
+	}
+	if err := a.Append(30, common.Hash{}, []byte("block30")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path.Join(dir, ancientMetaFile)); !os.IsNotExist(err) {
+		t.Fatal("expect meta file removed")
+	}
+	if first, next := a.Range(); first != 0 || next != 0 {
+		t.Fatalf("expect empty store after reset, got [%v, %v)", first, next)
+	}
+}
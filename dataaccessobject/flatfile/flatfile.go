@@ -1,8 +1,6 @@
 package flatfile
 
 import (
-	"bytes"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -48,6 +46,9 @@ type FlatFileManager struct {
 	currentFD       *os.File
 	currentFile     uint64
 	currentFileSize uint64
+	currentHeader   segmentHeader
+	compression     Compression //codec of newly created segments
+	readOnly        bool
 
 	parseCache *lru.Cache
 	itemCache  *lru.Cache
@@ -58,6 +59,40 @@ type ReadInfo struct {
 	fd     *os.File
 	offset int64
 	size   int64
+	crc    uint32
+	header segmentHeader
+}
+
+// read returns the decoded item, checksum is verified for versioned segments
+func (r ReadInfo) read() ([]byte, error) {
+	b := make([]byte, r.size)
+	if _, err := r.fd.ReadAt(b, r.offset); err != nil {
+		return nil, err
+	}
+	return r.header.decodeRecord(r, b)
+}
+
+// Close closes the segment files opened by the manager, it is not usable after
+func (f *FlatFileManager) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, fileID := range f.parseCache.Keys() {
+		v, ok := f.parseCache.Peek(fileID)
+		if !ok {
+			continue
+		}
+		//the items of a segment share its fd
+		for _, readInfo := range v.(map[uint64]ReadInfo) {
+			readInfo.fd.Close()
+			break
+		}
+	}
+	f.parseCache.Purge()
+	f.itemCache.Purge()
+	if f.currentFD != nil {
+		return f.currentFD.Close()
+	}
+	return nil
 }
 
 func (ff *FlatFileManager) FileSize() uint64 {
@@ -73,6 +108,9 @@ func (ff *FlatFileManager) Size() uint64 {
 }
 
 func (ff *FlatFileManager) Truncate(lastIndex uint64) error {
+	if ff.readOnly {
+		return errors.New("flat file is opened read only")
+	}
 	lastFile := lastIndex / ff.fileSizeLimit
 	files, err := ioutil.ReadDir(ff.dataDir)
 	if err != nil {
@@ -108,31 +146,34 @@ func (f *FlatFileManager) PasreFile(fileID uint64) (map[uint64]ReadInfo, error)
 	if err != nil {
 		return nil, err
 	}
+	header, err := readSegmentHeader(fd)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := fd.Stat()
+	if err != nil {
+		return nil, err
+	}
 	readInfos := make(map[uint64]ReadInfo)
-	offset := int64(0)
-	size := 0
+	offset := header.dataOffset()
 	for {
-		b := make([]byte, 8)
-		n, err := fd.ReadAt(b, offset)
-		if err != nil && err.Error() != "EOF" {
-			return nil, err
-		}
-		var result int64
-		err = binary.Read(bytes.NewBuffer(b), binary.LittleEndian, &result)
-		if err != nil || n == 0 {
+		result, crc, err := header.readRecordHeader(fd, offset)
+		//stop at a torn record, see RepairTail
+		if err != nil || offset+header.recordOverhead()+result > stat.Size() {
 			break
 		}
 
 		readInf := ReadInfo{
 			fd,
-			offset + 8,
+			offset + header.recordOverhead(),
 			result,
+			crc,
+			header,
 		}
 		id := len(readInfos)
 		readInfos[uint64(id)] = readInf
-		offset += 8
+		offset += header.recordOverhead()
 		offset += result
-		size++
 	}
 
 	f.parseCache.Add(fileID, readInfos)
@@ -173,18 +214,19 @@ func (f FlatFileManager) Read(index uint64) ([]byte, error) {
 		return nil, errors.New(fmt.Sprintf("Cannot read item at index %v", index))
 	}
 
-	b := make([]byte, readInfo[itemFileIndex].size)
-	readInfo[itemFileIndex].fd.ReadAt(b, int64(readInfo[itemFileIndex].offset))
-	rawB, err := ioutil.ReadAll(bytes.NewBuffer(b))
+	rawB, err := readInfo[itemFileIndex].read()
 	if err != nil {
-		return nil, err
+		return nil, errors.New(fmt.Sprintf("Cannot read item at index %v: %v", index, err))
 	}
 	return rawB, nil
 }
 
+// ReadFromIndex streams the items from index on. On a read error the index of
+// the failing item is sent on the error channel, which holds it until it is
+// read, and the data channel is closed.
 func (f FlatFileManager) ReadFromIndex(index uint64) (chan []byte, chan uint64, func()) {
 	c := make(chan []byte)
-	e := make(chan uint64)
+	e := make(chan uint64, 1)
 	closed := false
 
 	var cancel = func() {
@@ -214,16 +256,21 @@ func (f FlatFileManager) ReadFromIndex(index uint64) (chan []byte, chan uint64,
 			}
 
 			if err != nil {
-				e <- 1
+				e <- uint64(i) * f.fileSizeLimit
 				cancel()
+				return
 			}
 			startIndex := uint64(0)
 			if i == int64(fromFile) {
 				startIndex = offset
 			}
 			for j := int(startIndex); j < len(readInfo); j++ {
-				rawB := make([]byte, readInfo[uint64(j)].size)
-				readInfo[uint64(j)].fd.ReadAt(rawB, readInfo[uint64(j)].offset)
+				rawB, err := readInfo[uint64(j)].read()
+				if err != nil {
+					e <- uint64(i)*f.fileSizeLimit + uint64(j)
+					cancel()
+					return
+				}
 
 			LOOP:
 				if !closed {
@@ -258,8 +305,13 @@ func (f *FlatFileManager) newNextFile() (*os.File, error) {
 	if err != nil {
 		return nil, err
 	}
+	header := segmentHeader{version: SegmentVersion, compression: f.compression}
+	if _, err := fd.Write(header.bytes()); err != nil {
+		return nil, err
+	}
 
 	f.currentFD = fd
+	f.currentHeader = header
 	f.currentFile = uint64(i)
 	f.currentFileSize = 0
 	f.folderMap[uint64(i)] = true
@@ -287,18 +339,20 @@ func (f *FlatFileManager) checkFileSize() (uint64, error) {
 	if f.currentFD == nil {
 		return 0, errors.New("Not yet open file!")
 	}
-	offset := uint64(0)
+	stat, err := f.currentFD.Stat()
+	if err != nil {
+		return 0, err
+	}
+	header := f.currentHeader
+	offset := header.dataOffset()
 	size := uint64(0)
 	for {
-		b := make([]byte, 8)
-		n, _ := f.currentFD.ReadAt(b, int64(offset))
-		var result uint64
-		err := binary.Read(bytes.NewBuffer(b), binary.LittleEndian, &result)
-		if err != nil || n == 0 {
+		result, _, err := header.readRecordHeader(f.currentFD, offset)
+		if err != nil || offset+header.recordOverhead()+result > stat.Size() {
 			break
 		}
 
-		offset += 8
+		offset += header.recordOverhead()
 		offset += result
 
 		size++
@@ -308,17 +362,18 @@ func (f *FlatFileManager) checkFileSize() (uint64, error) {
 
 func (f *FlatFileManager) Append(data []byte) (uint64, error) {
 	//fmt.Println("debug write", len(data))
+	if f.readOnly {
+		return 0, errors.New("flat file is opened read only")
+	}
 	f.lock.Lock()
 	defer f.lock.Unlock()
 
 	//append size-bytes uint64o current FD, if max -> create new file, update currentFD
-	var result = make([]byte, 8)
-	binary.LittleEndian.PutUint64(result, uint64(len(data)))
-	_, err := f.currentFD.Write(result)
+	record, err := f.currentHeader.encodeRecord(data)
 	if err != nil {
 		return 0, err
 	}
-	_, err = f.currentFD.Write(data)
+	_, err = f.currentFD.Write(record)
 	if err != nil {
 		return 0, err
 	}
@@ -336,12 +391,57 @@ func (f *FlatFileManager) Append(data []byte) (uint64, error) {
 	return addedItemIndex, err
 }
 
+// OpenFlatFileReadOnly opens the existing flat file at dir for the tools, no
+// segment is created or written and a torn tail is left as is
+func OpenFlatFileReadOnly(dir string, fileBound uint64) (*FlatFileManager, error) {
+	cache, _ := lru.New(4)
+	itemCache, _ := lru.New(50)
+	ff := &FlatFileManager{
+		dataDir:       dir,
+		fileSizeLimit: fileBound,
+		folderMap:     make(map[uint64]bool),
+		lock:          new(sync.RWMutex),
+		parseCache:    cache,
+		itemCache:     itemCache,
+		readOnly:      true,
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	currentFile := -1
+	for _, f := range files {
+		if i, err := strconv.Atoi(filepath.Base(f.Name())); err == nil {
+			ff.folderMap[uint64(i)] = true
+			if currentFile < i {
+				currentFile = i
+			}
+		}
+	}
+	if currentFile > -1 {
+		readInfos, err := ff.PasreFile(uint64(currentFile))
+		if err != nil {
+			return nil, err
+		}
+		ff.currentFile = uint64(currentFile)
+		ff.currentFileSize = uint64(len(readInfos))
+	}
+	return ff, nil
+}
+
 func NewFlatFile(dir string, fileBound uint64) (*FlatFileManager, error) {
+	return NewFlatFileWithCompression(dir, fileBound, CompressionNone)
+}
+
+// NewFlatFileWithCompression opens the flat file at dir, new segments are
+// written with the given compression. Existing segments keep their own format.
+func NewFlatFileWithCompression(dir string, fileBound uint64, compression Compression) (*FlatFileManager, error) {
 	cache, _ := lru.New(4)
 	itemCache, _ := lru.New(50)
 	ff := &FlatFileManager{
 		dataDir:       dir,
 		fileSizeLimit: fileBound,
+		compression:   compression,
 		folderMap:     make(map[uint64]bool),
 		lock:          new(sync.RWMutex),
 		parseCache:    cache,
@@ -379,6 +479,18 @@ func NewFlatFile(dir string, fileBound uint64) (*FlatFileManager, error) {
 		}
 		ff.currentFD = fd
 		ff.currentFile = uint64(currentFile)
+		if stat, err := fd.Stat(); err == nil && stat.Size() == 0 {
+			//segment created but header not written yet
+			ff.currentHeader = segmentHeader{version: SegmentVersion, compression: compression}
+			if _, err := fd.Write(ff.currentHeader.bytes()); err != nil {
+				return nil, err
+			}
+		} else {
+			ff.currentHeader, err = readSegmentHeader(fd)
+			if err != nil {
+				return nil, err
+			}
+		}
 		//read file size, and create new file in need
 		err = ff.update()
 		if err != nil {
@@ -2,6 +2,10 @@ package flatfile
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -29,3 +33,241 @@ func TestFlatFileManager_ReadFromIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestFlatFileManager_Compression(t *testing.T) {
+	for _, compression := range []Compression{CompressionNone, CompressionSnappy, CompressionZstd} {
+		dir, err := ioutil.TempDir("", "ff")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		ff, err := NewFlatFileWithCompression(dir, 10, compression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 25; i++ {
+			if _, err := ff.Append([]byte(strings.Repeat(fmt.Sprint(i), 100))); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		//reopen and read without item cache
+		ff, err = NewFlatFileWithCompression(dir, 10, compression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ff.Size() != 25 {
+			t.Fatalf("%v: expect size 25, got %v", compression, ff.Size())
+		}
+		for i := 0; i < 25; i++ {
+			data, err := ff.Read(uint64(i))
+			if err != nil {
+				t.Fatal(compression, err)
+			}
+			if string(data) != strings.Repeat(fmt.Sprint(i), 100) {
+				t.Fatalf("%v: wrong data at %v", compression, i)
+			}
+		}
+	}
+}
+
+func TestFlatFileManager_LegacySegment(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	//write a full legacy segment and a partial one
+	legacy := segmentHeader{version: SegmentVersionLegacy}
+	for fileID, count := range []int{10, 3} {
+		buf := []byte{}
+		for i := 0; i < count; i++ {
+			record, _ := legacy.encodeRecord([]byte(fmt.Sprint(fileID*10 + i)))
+			buf = append(buf, record...)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprint(fileID)), buf, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ff, err := NewFlatFileWithCompression(dir, 10, CompressionZstd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ff.Size() != 13 {
+		t.Fatalf("expect size 13, got %v", ff.Size())
+	}
+	//keep appending in legacy format until the segment is full
+	for i := 13; i < 22; i++ {
+		if _, err := ff.Append([]byte(fmt.Sprint(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ff, err = NewFlatFileWithCompression(dir, 10, CompressionZstd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 22; i++ {
+		data, err := ff.Read(uint64(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != fmt.Sprint(i) {
+			t.Fatalf("wrong data at %v: %v", i, string(data))
+		}
+	}
+	h, err := readSegmentHeader(ff.currentFD)
+	if err != nil || h.version != SegmentVersion || h.compression != CompressionZstd {
+		t.Fatalf("new segment should use the versioned format, got %+v %v", h, err)
+	}
+}
+
+func TestFlatFileManager_Checksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ff, err := NewFlatFile(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		ff.Append([]byte("data" + fmt.Sprint(i)))
+	}
+
+	//flip the last byte of the last record
+	p := filepath.Join(dir, "0")
+	b, _ := ioutil.ReadFile(p)
+	b[len(b)-1] ^= 0xff
+	ioutil.WriteFile(p, b, 0666)
+
+	ff, err = NewFlatFile(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ff.Read(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ff.Read(2); err == nil {
+		t.Fatal("expect checksum error")
+	}
+
+	//the stream stops at the corrupted record and reports its index
+	c, e, _ := ff.ReadFromIndex(0)
+	for i := 0; i < 2; i++ {
+		if data := <-c; string(data) != "data"+fmt.Sprint(i) {
+			t.Fatalf("wrong data at %v: %s", i, data)
+		}
+	}
+	if _, ok := <-c; ok {
+		t.Fatal("expect data channel closed")
+	}
+	if index := <-e; index != 2 {
+		t.Fatalf("expect error at index 2, got %v", index)
+	}
+}
+
+func TestFlatFileManager_RepairTail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ff, err := NewFlatFile(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 15; i++ {
+		ff.Append([]byte(fmt.Sprint(i)))
+	}
+
+	//simulate a crash in the middle of an append
+	record, _ := ff.currentHeader.encodeRecord([]byte("half written"))
+	fd, _ := os.OpenFile(filepath.Join(dir, "1"), os.O_APPEND|os.O_WRONLY, 0666)
+	fd.Write(record[:len(record)-3])
+	fd.Close()
+
+	reports, err := ScanDir(dir, 10, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 || !reports[0].Healthy() || reports[1].Healthy() || reports[1].Records != 5 {
+		t.Fatalf("unexpected scan result %+v", reports)
+	}
+
+	ff, err = NewFlatFile(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repair, err := ff.RepairTail()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repair == nil || repair.FirstDroppedIndex != 15 || repair.DroppedBytes != int64(len(record)-3) {
+		t.Fatalf("unexpected repair result %+v", repair)
+	}
+	if repair, err := ff.RepairTail(); repair != nil || err != nil {
+		t.Fatalf("second repair should be a no-op, got %+v %v", repair, err)
+	}
+
+	index, err := ff.Append([]byte("15"))
+	if err != nil || index != 15 {
+		t.Fatalf("expect index 15, got %v %v", index, err)
+	}
+	ff, _ = NewFlatFile(dir, 10)
+	for i := 0; i < 16; i++ {
+		data, err := ff.Read(uint64(i))
+		if err != nil || string(data) != fmt.Sprint(i) {
+			t.Fatalf("wrong data at %v: %v %v", i, string(data), err)
+		}
+	}
+}
+
+func TestFlatFileManager_RepairTailKeepsCorruptedRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ff, err := NewFlatFile(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		ff.Append([]byte("data" + fmt.Sprint(i)))
+	}
+
+	//flip a payload byte of the second record, the records after it are good
+	header := ff.currentHeader
+	record, _ := header.encodeRecord([]byte("data0"))
+	p := filepath.Join(dir, "0")
+	b, _ := ioutil.ReadFile(p)
+	b[int(header.dataOffset())+2*len(record)-1] ^= 0xff
+	ioutil.WriteFile(p, b, 0666)
+
+	ff, err = NewFlatFile(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repair, err := ff.RepairTail(); repair != nil || err != nil {
+		t.Fatalf("a checksum failure should not be cut, got %+v %v", repair, err)
+	}
+	verify, err := ff.VerifyTail()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verify.Healthy() || verify.Records != 1 {
+		t.Fatalf("expect the corrupted record to be reported, got %+v", verify)
+	}
+	if _, err := ff.Read(1); err == nil {
+		t.Fatal("expect checksum error")
+	}
+	for _, i := range []uint64{0, 2, 3, 4} {
+		data, err := ff.Read(i)
+		if err != nil || string(data) != "data"+fmt.Sprint(i) {
+			t.Fatalf("wrong data at %v: %v %v", i, string(data), err)
+		}
+	}
+}
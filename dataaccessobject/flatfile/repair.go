@@ -0,0 +1,183 @@
+package flatfile
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+)
+
+// SegmentReport is the result of scanning one segment file
+type SegmentReport struct {
+	FileID      uint64
+	Version     byte
+	Compression string
+	Records     uint64 //number of good records
+	ValidSize   int64  //offset right after the last good record
+	FileSize    int64
+	Error       string `json:",omitempty"` //why the scan stopped before FileSize
+}
+
+// Healthy reports whether every byte of the segment belongs to a good record
+func (r SegmentReport) Healthy() bool {
+	return r.Error == "" && r.ValidSize == r.FileSize
+}
+
+// RepairReport describes what RepairTail dropped from the last segment
+type RepairReport struct {
+	FileID            uint64
+	FirstDroppedIndex uint64 //item index of the first dropped record, new Size() of the flat file
+	DroppedBytes      int64
+	Reason            string
+}
+
+// scanSegment walks every record of a segment and stops at the first torn or
+// corrupted one. A record is torn when its header or payload runs past the end
+// of the file or when its size is zero (zero filled tail after power loss, items
+// are never empty). With verify, the checksum of every payload is checked too.
+func scanSegment(fd *os.File, fileID uint64, verify bool) (SegmentReport, error) {
+	stat, err := fd.Stat()
+	if err != nil {
+		return SegmentReport{}, err
+	}
+	report := SegmentReport{FileID: fileID, FileSize: stat.Size()}
+	header, err := readSegmentHeader(fd)
+	if err != nil {
+		return report, err
+	}
+	report.Version = header.version
+	report.Compression = header.compression.String()
+
+	offset := header.dataOffset()
+	report.ValidSize = offset
+	for offset < stat.Size() {
+		size, crc, err := header.readRecordHeader(fd, offset)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			report.Error = fmt.Sprintf("torn record header at offset %v", offset)
+			return report, nil
+		}
+		if size == 0 {
+			report.Error = fmt.Sprintf("empty record at offset %v", offset)
+			return report, nil
+		}
+		end := offset + header.recordOverhead() + size
+		if size < 0 || end > stat.Size() {
+			report.Error = fmt.Sprintf("torn record at offset %v, size %v exceeds file size", offset, size)
+			return report, nil
+		}
+		if verify {
+			info := ReadInfo{fd, offset + header.recordOverhead(), size, crc, header}
+			if _, err := info.read(); err != nil {
+				report.Error = fmt.Sprintf("bad record at offset %v: %v", offset, err)
+				return report, nil
+			}
+		}
+		offset = end
+		report.Records++
+		report.ValidSize = offset
+	}
+	return report, nil
+}
+
+// segmentIDs returns the sorted ids of the segment files in dir
+func segmentIDs(dir string) ([]uint64, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	ids := []uint64{}
+	for _, f := range files {
+		i, err := strconv.Atoi(f.Name())
+		if err == nil && !f.IsDir() {
+			ids = append(ids, uint64(i))
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// ScanDir audits all segments of a flat file directory without modifying it.
+// Every segment but the last one must be healthy and hold fileSizeLimit records.
+func ScanDir(dir string, fileSizeLimit uint64, verify bool) ([]SegmentReport, error) {
+	ids, err := segmentIDs(dir)
+	if err != nil {
+		return nil, err
+	}
+	reports := []SegmentReport{}
+	for i, id := range ids {
+		fd, err := os.Open(path.Join(dir, strconv.Itoa(int(id))))
+		if err != nil {
+			return reports, err
+		}
+		report, err := scanSegment(fd, id, verify)
+		fd.Close()
+		if err != nil {
+			return reports, err
+		}
+		if i != len(ids)-1 && report.Error == "" && report.Records != fileSizeLimit {
+			report.Error = fmt.Sprintf("segment has %v records, expect %v", report.Records, fileSizeLimit)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// RepairTail scans the last segment and cuts it right after the last complete
+// record, so a half written item left by a crash does not shadow new appends.
+// Only structural tears are cut (torn header, size past the end of the file,
+// zero size), checksums are not verified: a complete record that fails its
+// checksum is reported by VerifyTail and left in place.
+// It returns nil when nothing was dropped.
+func (f *FlatFileManager) RepairTail() (*RepairReport, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	report, err := scanSegment(f.currentFD, f.currentFile, false)
+	if err != nil {
+		return nil, err
+	}
+	if report.Healthy() {
+		return nil, nil
+	}
+	if err := f.truncateSegment(report.ValidSize); err != nil {
+		return nil, err
+	}
+	f.currentFileSize = report.Records
+	f.parseCache.Remove(f.currentFile)
+	f.itemCache.Purge()
+
+	repair := &RepairReport{
+		FileID:            f.currentFile,
+		FirstDroppedIndex: f.currentFile*f.fileSizeLimit + report.Records,
+		DroppedBytes:      report.FileSize - report.ValidSize,
+		Reason:            report.Error,
+	}
+	if f.currentFileSize >= f.fileSizeLimit {
+		if _, err := f.newNextFile(); err != nil {
+			return repair, err
+		}
+	}
+	return repair, nil
+}
+
+// VerifyTail checks the checksum of every record of the last segment without
+// modifying it. The report is not healthy when a record is corrupted.
+func (f *FlatFileManager) VerifyTail() (SegmentReport, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return scanSegment(f.currentFD, f.currentFile, true)
+}
+
+// truncateSegment cuts the current segment at size
+func (f *FlatFileManager) truncateSegment(size int64) error {
+	if err := f.currentFD.Truncate(size); err != nil {
+		return err
+	}
+	return f.currentFD.Sync()
+}
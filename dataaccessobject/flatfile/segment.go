@@ -0,0 +1,195 @@
+package flatfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Segment layout
+//
+// version 1 (legacy, no header): [8 bytes size][data] ...
+// version 2: [8 bytes header][8 bytes size][4 bytes crc][payload] ...
+//
+// The version 2 header is the magic "INFF", the format version, the compression
+// codec of every payload in the segment and 2 reserved bytes. The crc is the
+// CRC32 (Castagnoli) of the stored (compressed) payload.
+const (
+	SegmentVersionLegacy = byte(1)
+	SegmentVersion       = byte(2)
+
+	segmentHeaderSize = 8
+	recordSizeLen     = 8
+	recordCRCLen      = 4
+)
+
+var segmentMagic = []byte("INFF")
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+var ErrChecksumMismatch = errors.New("flatfile record checksum mismatch")
+
+// Compression is the codec used for the records of a segment
+type Compression byte
+
+const (
+	CompressionNone Compression = iota
+	CompressionSnappy
+	CompressionZstd
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionSnappy:
+		return "snappy"
+	case CompressionZstd:
+		return "zstd"
+	}
+	return fmt.Sprintf("unknown(%v)", byte(c))
+}
+
+// ParseCompression returns the codec with the given name, empty means no compression
+func ParseCompression(name string) (Compression, error) {
+	switch name {
+	case "", "none":
+		return CompressionNone, nil
+	case "snappy":
+		return CompressionSnappy, nil
+	case "zstd":
+		return CompressionZstd, nil
+	}
+	return CompressionNone, fmt.Errorf("unknown flatfile compression %v", name)
+}
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+func (c Compression) compress(data []byte) ([]byte, error) {
+	switch c {
+	case CompressionNone:
+		return data, nil
+	case CompressionSnappy:
+		return snappy.Encode(nil, data), nil
+	case CompressionZstd:
+		return zstdEncoder.EncodeAll(data, nil), nil
+	}
+	return nil, fmt.Errorf("unknown flatfile compression %v", byte(c))
+}
+
+func (c Compression) decompress(data []byte) ([]byte, error) {
+	switch c {
+	case CompressionNone:
+		return data, nil
+	case CompressionSnappy:
+		return snappy.Decode(nil, data)
+	case CompressionZstd:
+		return zstdDecoder.DecodeAll(data, nil)
+	}
+	return nil, fmt.Errorf("unknown flatfile compression %v", byte(c))
+}
+
+type segmentHeader struct {
+	version     byte
+	compression Compression
+}
+
+func (h segmentHeader) bytes() []byte {
+	b := make([]byte, segmentHeaderSize)
+	copy(b, segmentMagic)
+	b[4] = h.version
+	b[5] = byte(h.compression)
+	return b
+}
+
+// dataOffset is the offset of the first record in the segment
+func (h segmentHeader) dataOffset() int64 {
+	if h.version == SegmentVersionLegacy {
+		return 0
+	}
+	return segmentHeaderSize
+}
+
+// recordOverhead is the number of bytes stored in front of each record payload
+func (h segmentHeader) recordOverhead() int64 {
+	if h.version == SegmentVersionLegacy {
+		return recordSizeLen
+	}
+	return recordSizeLen + recordCRCLen
+}
+
+// encodeRecord returns the bytes to append to the segment for data
+func (h segmentHeader) encodeRecord(data []byte) ([]byte, error) {
+	if h.version == SegmentVersionLegacy {
+		buf := make([]byte, recordSizeLen+len(data))
+		binary.LittleEndian.PutUint64(buf, uint64(len(data)))
+		copy(buf[recordSizeLen:], data)
+		return buf, nil
+	}
+	payload, err := h.compression.compress(data)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, recordSizeLen+recordCRCLen+len(payload))
+	binary.LittleEndian.PutUint64(buf, uint64(len(payload)))
+	binary.LittleEndian.PutUint32(buf[recordSizeLen:], crc32.Checksum(payload, crcTable))
+	copy(buf[recordSizeLen+recordCRCLen:], payload)
+	return buf, nil
+}
+
+// decodeRecord verifies the checksum of a stored payload and decompresses it
+func (h segmentHeader) decodeRecord(info ReadInfo, payload []byte) ([]byte, error) {
+	if h.version == SegmentVersionLegacy {
+		return payload, nil
+	}
+	if crc32.Checksum(payload, crcTable) != info.crc {
+		return nil, ErrChecksumMismatch
+	}
+	return h.compression.decompress(payload)
+}
+
+// readSegmentHeader detects the format of a segment. Segments written before
+// the header was introduced are reported as SegmentVersionLegacy.
+func readSegmentHeader(fd *os.File) (segmentHeader, error) {
+	b := make([]byte, segmentHeaderSize)
+	n, err := fd.ReadAt(b, 0)
+	if err != nil && err != io.EOF {
+		return segmentHeader{}, err
+	}
+	if n < segmentHeaderSize || !bytes.Equal(b[:len(segmentMagic)], segmentMagic) {
+		return segmentHeader{version: SegmentVersionLegacy}, nil
+	}
+	h := segmentHeader{version: b[4], compression: Compression(b[5])}
+	if h.version != SegmentVersion {
+		return h, fmt.Errorf("unsupported flatfile segment version %v in %v", h.version, fd.Name())
+	}
+	return h, nil
+}
+
+// readRecordHeader reads the record header at offset, it returns the payload
+// size and checksum (zero for legacy segments)
+func (h segmentHeader) readRecordHeader(fd *os.File, offset int64) (size int64, crc uint32, err error) {
+	b := make([]byte, h.recordOverhead())
+	n, err := fd.ReadAt(b, offset)
+	if n == 0 {
+		return 0, 0, io.EOF
+	}
+	if n < len(b) {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	size = int64(binary.LittleEndian.Uint64(b))
+	if h.version != SegmentVersionLegacy {
+		crc = binary.LittleEndian.Uint32(b[recordSizeLen:])
+	}
+	return size, crc, nil
+}
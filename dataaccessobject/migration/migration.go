@@ -0,0 +1,369 @@
+// Package migration upgrades the key layout of the chain databases. Every
+// chain database stores its schema version; the registered steps above it are
+// run in version order when the node starts or with the migrateschema command.
+//
+// A step must be idempotent: it may be run again from its last checkpoint, or
+// from scratch, after a crash. A step walking many keys saves its progress
+// with ForEachKey, which stores a cursor in the same batch as the rewritten
+// keys, so an interrupted step resumes where it stopped. The schema version
+// is only bumped once the step returns without error.
+package migration
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/pkg/errors"
+)
+
+// Scope selects the chain databases a step applies to
+type Scope int
+
+const (
+	ScopeAll Scope = iota
+	ScopeBeacon
+	ScopeShard
+)
+
+func (s Scope) match(cid int) bool {
+	switch s {
+	case ScopeBeacon:
+		return cid == common.BeaconChainID
+	case ScopeShard:
+		return cid != common.BeaconChainID
+	}
+	return true
+}
+
+// Step upgrades a chain database to Version from Version-1
+type Step struct {
+	Version     uint64
+	Description string
+	Scope       Scope
+	Run         func(ctx *Context) error
+}
+
+var registry = struct {
+	steps []Step
+	lock  sync.Mutex
+}{}
+
+// Register adds a migration step, versions start at 1 and have no gap. It is
+// meant to be called from init functions.
+func Register(step Step) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	for _, s := range registry.steps {
+		if s.Version == step.Version {
+			panic(fmt.Sprintf("schema migration version %v is registered twice", step.Version))
+		}
+	}
+	if step.Version == 0 || step.Run == nil {
+		panic(fmt.Sprintf("schema migration version %v is invalid", step.Version))
+	}
+	registry.steps = append(registry.steps, step)
+	sort.Slice(registry.steps, func(i, j int) bool { return registry.steps[i].Version < registry.steps[j].Version })
+}
+
+// Steps returns the registered steps in version order
+func Steps() []Step {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	return append([]Step{}, registry.steps...)
+}
+
+// LatestVersion returns the schema version written by the current code
+func LatestVersion() uint64 {
+	steps := Steps()
+	if len(steps) == 0 {
+		return 0
+	}
+	return steps[len(steps)-1].Version
+}
+
+// Options of a migration run
+type Options struct {
+	DryRun   bool           //run the steps without writing, the schema version is not changed
+	Progress func(Progress) //called at every checkpoint and at the end of every step, may be nil
+}
+
+// Progress of a running step
+type Progress struct {
+	ChainID     int
+	Version     uint64
+	Description string
+	Keys        uint64 //keys visited by ForEachKey
+	Writes      uint64 //keys put or deleted
+	Done        bool
+}
+
+// StepReport is the result of one step on one chain database
+type StepReport struct {
+	ChainID     int
+	Version     uint64
+	Description string
+	Keys        uint64
+	Writes      uint64
+	Resumed     bool `json:",omitempty"` //the step continued from a saved cursor
+	Skipped     bool `json:",omitempty"` //the step does not apply to this chain
+}
+
+// Report is the result of the migration of one chain database
+type Report struct {
+	ChainID     int
+	FromVersion uint64
+	ToVersion   uint64
+	DryRun      bool
+	Steps       []StepReport
+}
+
+// Context is given to a running step
+type Context struct {
+	DB      incdb.Database
+	ChainID int
+	DryRun  bool
+
+	step     Step
+	cursor   []byte
+	report   *StepReport
+	progress func(Progress)
+}
+
+// Cursor returns the progress saved by the step before it was interrupted,
+// nil when it starts from scratch
+func (ctx *Context) Cursor() []byte {
+	return ctx.cursor
+}
+
+// NewBatch returns a batch of the database, which drops its writes in dry run
+func (ctx *Context) NewBatch() incdb.Batch {
+	if ctx.DryRun {
+		return &dryRunBatch{report: ctx.report}
+	}
+	return &countingBatch{Batch: ctx.DB.NewBatch(), report: ctx.report}
+}
+
+// Checkpoint writes the batch together with the cursor of the step, a resumed
+// step gets the cursor back from Cursor
+func (ctx *Context) Checkpoint(batch incdb.Batch, cursor []byte) error {
+	if !ctx.DryRun {
+		if err := rawdbv2.StoreSchemaMigrationCursor(batch, ctx.step.Version, cursor); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	batch.Reset()
+	ctx.cursor = common.CopyBytes(cursor)
+	ctx.notify(false)
+	return nil
+}
+
+func (ctx *Context) notify(done bool) {
+	if ctx.progress == nil {
+		return
+	}
+	ctx.progress(Progress{
+		ChainID:     ctx.ChainID,
+		Version:     ctx.step.Version,
+		Description: ctx.step.Description,
+		Keys:        ctx.report.Keys,
+		Writes:      ctx.report.Writes,
+		Done:        done,
+	})
+}
+
+// ForEachKey calls fn for every key with prefix, in key order, starting after
+// the saved cursor. fn writes its changes in batch, the batch is written with
+// the key as cursor every incdb.IdealBatchSize bytes. fn must not change the
+// keys after the current key within the prefix, they are still to be visited.
+// A step walks a single prefix with ForEachKey, the cursor is a key of it.
+func (ctx *Context) ForEachKey(prefix []byte, fn func(batch incdb.Batch, key, value []byte) error) error {
+	var it incdb.Iterator
+	if ctx.cursor != nil && !bytes.HasPrefix(ctx.cursor, prefix) {
+		return fmt.Errorf("saved cursor %x is not a key of prefix %x", ctx.cursor, prefix)
+	}
+	if ctx.cursor != nil {
+		//the cursor is the last key done, start right after it
+		it = ctx.DB.NewIteratorWithPrefixStart(prefix, append(common.CopyBytes(ctx.cursor[len(prefix):]), 0))
+	} else {
+		it = ctx.DB.NewIteratorWithPrefix(prefix)
+	}
+	defer it.Release()
+
+	batch := ctx.NewBatch()
+	var last []byte
+	for it.Next() {
+		key := common.CopyBytes(it.Key())
+		if err := fn(batch, key, common.CopyBytes(it.Value())); err != nil {
+			return errors.Wrapf(err, "migrate key %x", key)
+		}
+		ctx.report.Keys++
+		last = key
+		if batch.ValueSize() >= incdb.IdealBatchSize {
+			if err := ctx.Checkpoint(batch, last); err != nil {
+				return err
+			}
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if last != nil {
+		return ctx.Checkpoint(batch, last)
+	}
+	return nil
+}
+
+// Migrate runs the steps above the schema version of the database of chain
+// cid. A database without version and without data is new, it is set to the
+// latest version directly. A database with a version above the latest one was
+// written by a newer node and is refused.
+func Migrate(db incdb.Database, cid int, opts Options) (*Report, error) {
+	latest := LatestVersion()
+	version, found, err := rawdbv2.GetSchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{ChainID: cid, FromVersion: version, ToVersion: version, DryRun: opts.DryRun}
+	if version > latest {
+		return report, fmt.Errorf("chain %v database schema version %v is newer than the supported version %v", cid, version, latest)
+	}
+	if !found && isEmpty(db) {
+		report.FromVersion, report.ToVersion = latest, latest
+		if opts.DryRun {
+			return report, nil
+		}
+		return report, rawdbv2.StoreSchemaVersion(db, latest)
+	}
+
+	for _, step := range Steps() {
+		if step.Version <= version {
+			continue
+		}
+		stepReport := StepReport{ChainID: cid, Version: step.Version, Description: step.Description}
+		if step.Scope.match(cid) {
+			if err := runStep(db, cid, step, opts, &stepReport); err != nil {
+				report.Steps = append(report.Steps, stepReport)
+				return report, errors.Wrapf(err, "chain %v schema migration to version %v", cid, step.Version)
+			}
+		} else {
+			stepReport.Skipped = true
+		}
+		if !opts.DryRun {
+			if err := rawdbv2.StoreSchemaVersion(db, step.Version); err != nil {
+				return report, err
+			}
+		}
+		report.ToVersion = step.Version
+		report.Steps = append(report.Steps, stepReport)
+	}
+	if !found && !opts.DryRun && len(report.Steps) == 0 {
+		return report, rawdbv2.StoreSchemaVersion(db, latest)
+	}
+	return report, nil
+}
+
+func runStep(db incdb.Database, cid int, step Step, opts Options, report *StepReport) error {
+	cursor, err := rawdbv2.GetSchemaMigrationCursor(db, step.Version)
+	if err != nil {
+		return err
+	}
+	report.Resumed = cursor != nil
+	dataaccessobject.Logger.Log.Infof("[schema-migration] chain %v: start step %v (%v), resumed %v", cid, step.Version, step.Description, report.Resumed)
+	ctx := &Context{
+		DB:       db,
+		ChainID:  cid,
+		DryRun:   opts.DryRun,
+		step:     step,
+		cursor:   cursor,
+		report:   report,
+		progress: opts.Progress,
+	}
+	if err := step.Run(ctx); err != nil {
+		return err
+	}
+	if !opts.DryRun {
+		if err := rawdbv2.DeleteSchemaMigrationCursor(db, step.Version); err != nil {
+			return err
+		}
+	}
+	ctx.notify(true)
+	dataaccessobject.Logger.Log.Infof("[schema-migration] chain %v: finish step %v, %v keys visited, %v writes", cid, step.Version, report.Keys, report.Writes)
+	return nil
+}
+
+// MigrateAll migrates every chain database, see Migrate
+func MigrateAll(dbs map[int]incdb.Database, opts Options) ([]*Report, error) {
+	cids := []int{}
+	for cid := range dbs {
+		cids = append(cids, cid)
+	}
+	sort.Ints(cids)
+	reports := []*Report{}
+	for _, cid := range cids {
+		report, err := Migrate(dbs[cid], cid, opts)
+		if report != nil {
+			reports = append(reports, report)
+		}
+		if err != nil {
+			return reports, err
+		}
+	}
+	return reports, nil
+}
+
+func isEmpty(db incdb.Database) bool {
+	it := db.NewIterator()
+	defer it.Release()
+	return !it.Next()
+}
+
+// countingBatch counts the writes of a step
+type countingBatch struct {
+	incdb.Batch
+	report *StepReport
+}
+
+func (b *countingBatch) Put(key []byte, value []byte) error {
+	b.report.Writes++
+	return b.Batch.Put(key, value)
+}
+
+func (b *countingBatch) Delete(key []byte) error {
+	b.report.Writes++
+	return b.Batch.Delete(key)
+}
+
+// dryRunBatch counts the writes of a step and drops them
+type dryRunBatch struct {
+	report *StepReport
+	size   int
+}
+
+func (b *dryRunBatch) Put(key []byte, value []byte) error {
+	b.report.Writes++
+	b.size += len(key) + len(value)
+	return nil
+}
+
+func (b *dryRunBatch) Delete(key []byte) error {
+	b.report.Writes++
+	b.size += len(key)
+	return nil
+}
+
+func (b *dryRunBatch) ValueSize() int { return b.size }
+
+func (b *dryRunBatch) Write() error { return nil }
+
+func (b *dryRunBatch) Reset() { b.size = 0 }
+
+func (b *dryRunBatch) Replay(w incdb.KeyValueWriter) error { return nil }
@@ -0,0 +1,141 @@
+package migration
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	dataaccessobject.Logger.Init(common.NewBackend(nil).Logger("test", true))
+}
+
+func openTestDB(t *testing.T) (incdb.Database, func()) {
+	dir, err := ioutil.TempDir("", "migration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := incdb.Open(pebbledb.DriverName, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// withSteps replaces the registered steps during a test
+func withSteps(t *testing.T, steps ...Step) {
+	saved := registry.steps
+	registry.steps = nil
+	for _, step := range steps {
+		Register(step)
+	}
+	t.Cleanup(func() { registry.steps = saved })
+}
+
+var failAfter = -1
+
+// renameStep moves the keys of prefix "old-" to "new-"
+func renameStep(version uint64) Step {
+	return Step{
+		Version:     version,
+		Description: "rename old- keys",
+		Run: func(ctx *Context) error {
+			done := 0
+			return ctx.ForEachKey([]byte("old-"), func(batch incdb.Batch, key, value []byte) error {
+				if done == failAfter {
+					return errors.New("interrupted")
+				}
+				done++
+				if err := batch.Put(append([]byte("new-"), key[4:]...), value); err != nil {
+					return err
+				}
+				return batch.Delete(key)
+			})
+		},
+	}
+}
+
+func TestMigrateNewDatabase(t *testing.T) {
+	withSteps(t, renameStep(1))
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	report, err := Migrate(db, 0, Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(report.Steps))
+	version, found, err := rawdbv2.GetSchemaVersion(db)
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(1), version)
+}
+
+func TestMigrateResumeAndScope(t *testing.T) {
+	beaconOnly := Step{Version: 2, Description: "beacon only", Scope: ScopeBeacon, Run: func(ctx *Context) error {
+		return ctx.DB.Put([]byte("beacon-step"), []byte{1})
+	}}
+	withSteps(t, renameStep(1), beaconOnly)
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	value := bytes.Repeat([]byte{1}, 1000)
+	for i := 0; i < 500; i++ {
+		assert.Nil(t, db.Put([]byte(fmt.Sprintf("old-%04d", i)), value))
+	}
+
+	//dry run changes nothing
+	report, err := Migrate(db, 0, Options{DryRun: true})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(500), report.Steps[0].Keys)
+	assert.Equal(t, uint64(1000), report.Steps[0].Writes)
+	_, found, _ := rawdbv2.GetSchemaVersion(db)
+	assert.False(t, found)
+
+	//interrupted after some checkpoints
+	failAfter = 300
+	_, err = Migrate(db, 0, Options{})
+	assert.NotNil(t, err)
+	failAfter = -1
+	version, _, _ := rawdbv2.GetSchemaVersion(db)
+	assert.Equal(t, uint64(0), version)
+	cursor, err := rawdbv2.GetSchemaMigrationCursor(db, 1)
+	assert.Nil(t, err)
+	assert.NotNil(t, cursor)
+
+	checkpoints := 0
+	report, err = Migrate(db, 0, Options{Progress: func(p Progress) { checkpoints++ }})
+	assert.Nil(t, err)
+	assert.True(t, report.Steps[0].Resumed)
+	assert.True(t, report.Steps[0].Keys < 500)
+	assert.True(t, report.Steps[1].Skipped)
+	assert.True(t, checkpoints > 1)
+	assert.Equal(t, uint64(2), report.ToVersion)
+
+	for i := 0; i < 500; i++ {
+		has, _ := db.Has([]byte(fmt.Sprintf("old-%04d", i)))
+		assert.False(t, has)
+		has, _ = db.Has([]byte(fmt.Sprintf("new-%04d", i)))
+		assert.True(t, has)
+	}
+	has, _ := db.Has([]byte("beacon-step"))
+	assert.False(t, has)
+	cursor, _ = rawdbv2.GetSchemaMigrationCursor(db, 1)
+	assert.Nil(t, cursor)
+
+	//a newer database is refused
+	assert.Nil(t, rawdbv2.StoreSchemaVersion(db, 3))
+	_, err = Migrate(db, 0, Options{})
+	assert.NotNil(t, err)
+}
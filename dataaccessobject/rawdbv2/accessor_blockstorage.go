@@ -1,6 +1,8 @@
 package rawdbv2
 
 import (
+	"bytes"
+
 	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/incdb"
 )
@@ -25,6 +27,98 @@ func GetFlatFileIndexByBlockHash(db incdb.KeyValueReader, hash common.Hash) (uin
 
 }
 
+// DeleteFlatFileIndexFrom removes every block hash => ff index entry pointing at
+// an index >= fromIndex, it returns the hashes of the removed blocks
+func DeleteFlatFileIndexFrom(db incdb.Database, fromIndex uint64) ([]common.Hash, error) {
+	it := db.NewIteratorWithPrefix(blockHashToFFIndexPrefix)
+	defer it.Release()
+	removed := []common.Hash{}
+	for it.Next() {
+		index, err := common.BytesToUint64(it.Value())
+		if err != nil || index < fromIndex {
+			continue
+		}
+		hash := common.Hash{}
+		if err := hash.SetBytes(it.Key()[len(blockHashToFFIndexPrefix):]); err != nil {
+			continue
+		}
+		removed = append(removed, hash)
+	}
+	if err := it.Error(); err != nil {
+		return nil, NewRawdbError(DeleteFFIndexError, err)
+	}
+	for _, hash := range removed {
+		if err := db.Delete(GetBlockHashToFFIndexKey(hash)); err != nil {
+			return nil, NewRawdbError(DeleteFFIndexError, err)
+		}
+	}
+	return removed, nil
+}
+
+// GetAllFlatFileIndex returns the ff index => block hash of every block in db
+func GetAllFlatFileIndex(db incdb.Database) (map[uint64]common.Hash, error) {
+	it := db.NewIteratorWithPrefix(blockHashToFFIndexPrefix)
+	defer it.Release()
+	res := map[uint64]common.Hash{}
+	for it.Next() {
+		index, err := common.BytesToUint64(it.Value())
+		if err != nil {
+			return nil, NewRawdbError(GetFFIndexError, err)
+		}
+		hash := common.Hash{}
+		if err := hash.SetBytes(it.Key()[len(blockHashToFFIndexPrefix):]); err != nil {
+			return nil, NewRawdbError(GetFFIndexError, err)
+		}
+		res[index] = hash
+	}
+	if err := it.Error(); err != nil {
+		return nil, NewRawdbError(GetFFIndexError, err)
+	}
+	return res, nil
+}
+
+// IsFlatFileIndexKey reports whether key is a block hash => ff index key
+func IsFlatFileIndexKey(key []byte) bool {
+	return bytes.HasPrefix(key, blockHashToFFIndexPrefix)
+}
+
+// DeleteFlatFileIndexByBlockHash removes the block hash => ff index entry
+func DeleteFlatFileIndexByBlockHash(db incdb.KeyValueWriter, hash common.Hash) error {
+	if err := db.Delete(GetBlockHashToFFIndexKey(hash)); err != nil {
+		return NewRawdbError(DeleteFFIndexError, err)
+	}
+	return nil
+}
+
+// store block hash => height of a block moved to the ancient store
+func StoreAncientHeightByBlockHash(db incdb.KeyValueWriter, hash common.Hash, height uint64) error {
+	if err := db.Put(GetBlockHashToAncientHeightKey(hash), common.Uint64ToBytes(height)); err != nil {
+		return NewRawdbError(StoreAncientHeightError, err)
+	}
+	return nil
+}
+
+func GetAncientHeightByBlockHash(db incdb.KeyValueReader, hash common.Hash) (uint64, error) {
+	data, err := db.Get(GetBlockHashToAncientHeightKey(hash))
+	if err != nil {
+		return 0, NewRawdbError(GetAncientHeightError, err)
+	}
+	return common.BytesToUint64(data)
+}
+
+// DeleteFinalizedBlockHashByIndex removes the height => finalized block hash
+// entry of the chain cid (common.BeaconChainID for beacon)
+func DeleteFinalizedBlockHashByIndex(db incdb.KeyValueWriter, cid int, index uint64) error {
+	key := GetBeaconIndexToBlockHashKey(index)
+	if cid != common.BeaconChainID {
+		key = GetShardIndexToBlockHashPrefix(byte(cid), index)
+	}
+	if err := db.Delete(key); err != nil {
+		return NewRawdbError(DeleteFinalizedBlockIndexError, err)
+	}
+	return nil
+}
+
 // store block hash => validation data
 func StoreValidationDataByBlockHash(db incdb.KeyValueWriter, hash common.Hash, val []byte) error {
 	keyHash := GetBlockHashToValidationDataKey(hash)
@@ -0,0 +1,70 @@
+package rawdbv2
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/levietcuong2602/incognito-chain/incdb"
+)
+
+func StoreSchemaVersion(db incdb.KeyValueWriter, version uint64) error {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, version)
+	if err := db.Put(GetSchemaVersionKey(), value); err != nil {
+		return NewRawdbError(StoreSchemaVersionError, err)
+	}
+	return nil
+}
+
+// GetSchemaVersion returns the schema version of the database, found is false
+// when no version was ever stored
+func GetSchemaVersion(db incdb.KeyValueReader) (version uint64, found bool, err error) {
+	key := GetSchemaVersionKey()
+	has, err := db.Has(key)
+	if err != nil {
+		return 0, false, NewRawdbError(GetSchemaVersionError, err)
+	}
+	if !has {
+		return 0, false, nil
+	}
+	value, err := db.Get(key)
+	if err != nil {
+		return 0, false, NewRawdbError(GetSchemaVersionError, err)
+	}
+	if len(value) != 8 {
+		return 0, false, NewRawdbError(GetSchemaVersionError, fmt.Errorf("invalid schema version %x", value))
+	}
+	return binary.BigEndian.Uint64(value), true, nil
+}
+
+func StoreSchemaMigrationCursor(db incdb.KeyValueWriter, version uint64, cursor []byte) error {
+	if err := db.Put(GetSchemaMigrationCursorKey(version), cursor); err != nil {
+		return NewRawdbError(StoreMigrationCursorError, err)
+	}
+	return nil
+}
+
+// GetSchemaMigrationCursor returns the progress saved by the migration step to
+// version, nil when the step has not started
+func GetSchemaMigrationCursor(db incdb.KeyValueReader, version uint64) ([]byte, error) {
+	key := GetSchemaMigrationCursorKey(version)
+	has, err := db.Has(key)
+	if err != nil {
+		return nil, NewRawdbError(GetMigrationCursorError, err)
+	}
+	if !has {
+		return nil, nil
+	}
+	cursor, err := db.Get(key)
+	if err != nil {
+		return nil, NewRawdbError(GetMigrationCursorError, err)
+	}
+	return cursor, nil
+}
+
+func DeleteSchemaMigrationCursor(db incdb.KeyValueWriter, version uint64) error {
+	if err := db.Delete(GetSchemaMigrationCursorKey(version)); err != nil {
+		return NewRawdbError(StoreMigrationCursorError, err)
+	}
+	return nil
+}
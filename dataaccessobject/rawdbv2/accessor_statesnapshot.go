@@ -0,0 +1,73 @@
+package rawdbv2
+
+import (
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+)
+
+// StoreStateSnapshotRoot records the state root the flat snapshot of
+// namespace is in step with
+func StoreStateSnapshotRoot(db incdb.KeyValueWriter, namespace []byte, root common.Hash) error {
+	if err := db.Put(GetStateSnapshotRootKey(namespace), root[:]); err != nil {
+		return NewRawdbError(StoreStateSnapshotError, err)
+	}
+	return nil
+}
+
+// GetStateSnapshotRoot returns the state root of the flat snapshot of
+// namespace, found is false when there is no complete snapshot
+func GetStateSnapshotRoot(db incdb.KeyValueReader, namespace []byte) (root common.Hash, found bool, err error) {
+	key := GetStateSnapshotRootKey(namespace)
+	has, err := db.Has(key)
+	if err != nil {
+		return common.Hash{}, false, NewRawdbError(GetStateSnapshotError, err)
+	}
+	if !has {
+		return common.Hash{}, false, nil
+	}
+	value, err := db.Get(key)
+	if err != nil {
+		return common.Hash{}, false, NewRawdbError(GetStateSnapshotError, err)
+	}
+	return common.BytesToHash(value), true, nil
+}
+
+func DeleteStateSnapshotRoot(db incdb.KeyValueWriter, namespace []byte) error {
+	if err := db.Delete(GetStateSnapshotRootKey(namespace)); err != nil {
+		return NewRawdbError(StoreStateSnapshotError, err)
+	}
+	return nil
+}
+
+func StoreStateSnapshotObject(db incdb.KeyValueWriter, namespace []byte, key common.Hash, value []byte) error {
+	if err := db.Put(GetStateSnapshotKey(namespace, key), value); err != nil {
+		return NewRawdbError(StoreStateSnapshotError, err)
+	}
+	return nil
+}
+
+func DeleteStateSnapshotObject(db incdb.KeyValueWriter, namespace []byte, key common.Hash) error {
+	if err := db.Delete(GetStateSnapshotKey(namespace, key)); err != nil {
+		return NewRawdbError(StoreStateSnapshotError, err)
+	}
+	return nil
+}
+
+// GetStateSnapshotObject returns the encoded state object of key in the flat
+// snapshot of namespace, nil when the snapshot has no such object. It is on
+// the read path of the state objects so a found object costs a single read.
+func GetStateSnapshotObject(db incdb.KeyValueReader, namespace []byte, key common.Hash) ([]byte, error) {
+	snapshotKey := GetStateSnapshotKey(namespace, key)
+	value, err := db.Get(snapshotKey)
+	if err == nil {
+		return value, nil
+	}
+	has, hasErr := db.Has(snapshotKey)
+	if hasErr != nil {
+		return nil, NewRawdbError(GetStateSnapshotError, hasErr)
+	}
+	if has {
+		return nil, NewRawdbError(GetStateSnapshotError, err)
+	}
+	return nil, nil
+}
@@ -0,0 +1,54 @@
+package rawdbv2
+
+import (
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+)
+
+func StoreTxPoolTx(db incdb.KeyValueWriter, shardID byte, txHash common.Hash, value []byte) error {
+	if err := db.Put(GetTxPoolTxKey(shardID, txHash), value); err != nil {
+		return NewRawdbError(StoreTxPoolTxError, err)
+	}
+	return nil
+}
+
+func DeleteTxPoolTx(db incdb.KeyValueWriter, shardID byte, txHash common.Hash) error {
+	if err := db.Delete(GetTxPoolTxKey(shardID, txHash)); err != nil {
+		return NewRawdbError(StoreTxPoolTxError, err)
+	}
+	return nil
+}
+
+// GetTxPoolTxs returns the stored transactions of the pool of shardID by hash
+func GetTxPoolTxs(db incdb.Database, shardID byte) (map[common.Hash][]byte, error) {
+	prefix := GetTxPoolTxPrefix(shardID)
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+	res := map[common.Hash][]byte{}
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+common.HashSize {
+			continue
+		}
+		res[common.BytesToHash(key[len(prefix):])] = common.CopyBytes(it.Value())
+	}
+	if err := it.Error(); err != nil {
+		return nil, NewRawdbError(GetTxPoolTxError, err)
+	}
+	return res, nil
+}
+
+func StoreTxPoolFeeEstimator(db incdb.KeyValueWriter, shardID byte, value []byte) error {
+	if err := db.Put(GetTxPoolFeeEstimatorKey(shardID), value); err != nil {
+		return NewRawdbError(StoreTxPoolFeeEstimatorError, err)
+	}
+	return nil
+}
+
+func GetTxPoolFeeEstimator(db incdb.KeyValueReader, shardID byte) ([]byte, error) {
+	res, err := db.Get(GetTxPoolFeeEstimatorKey(shardID))
+	if err != nil {
+		return nil, NewRawdbError(GetTxPoolFeeEstimatorError, err)
+	}
+	return res, nil
+}
@@ -115,6 +115,26 @@ const (
 	GetFFIndexError
 	StoreShardStakingTx
 	GetShardStakingTx
+	DeleteFFIndexError
+	StoreAncientHeightError
+	GetAncientHeightError
+	DeleteFinalizedBlockIndexError
+
+	// schema migration
+	StoreSchemaVersionError
+	GetSchemaVersionError
+	StoreMigrationCursorError
+	GetMigrationCursorError
+
+	// state snapshot
+	StoreStateSnapshotError
+	GetStateSnapshotError
+
+	// tx pool persistence
+	StoreTxPoolTxError
+	GetTxPoolTxError
+	StoreTxPoolFeeEstimatorError
+	GetTxPoolFeeEstimatorError
 )
 
 var ErrCodeMessage = map[int]struct {
@@ -216,6 +236,25 @@ var ErrCodeMessage = map[int]struct {
 
 	StoreShardStakingTx: {-7006, "Store shard stakign error"},
 	GetShardStakingTx:   {-7007, "Get shard staking error"},
+	DeleteFFIndexError:  {-7008, "Delete FF Index error"},
+
+	StoreAncientHeightError:        {-7009, "Store ancient block height error"},
+	GetAncientHeightError:          {-7010, "Get ancient block height error"},
+	DeleteFinalizedBlockIndexError: {-7011, "Delete finalized block index error"},
+
+	StoreSchemaVersionError:   {-7012, "Store schema version error"},
+	GetSchemaVersionError:     {-7013, "Get schema version error"},
+	StoreMigrationCursorError: {-7014, "Store schema migration cursor error"},
+	GetMigrationCursorError:   {-7015, "Get schema migration cursor error"},
+
+	StoreStateSnapshotError: {-7016, "Store state snapshot error"},
+	GetStateSnapshotError:   {-7017, "Get state snapshot error"},
+
+	StoreTxPoolTxError: {-7018, "Store tx pool transaction error"},
+	GetTxPoolTxError:   {-7019, "Get tx pool transaction error"},
+
+	StoreTxPoolFeeEstimatorError: {-7020, "Store tx pool fee estimator error"},
+	GetTxPoolFeeEstimatorError:   {-7021, "Get tx pool fee estimator error"},
 }
 
 type RawdbError struct {
@@ -0,0 +1,150 @@
+package rawdbv2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"unicode"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+)
+
+// keyFamilyNames describes the families of the keys of schema.go
+var keyFamilyNames = map[string]string{
+	TrieNodeFamily:          "state trie node by hash",
+	TriePreimageFamily:      "state trie key by hash",
+	"LastShardBlock":        "last shard block",
+	"LastBeaconBlock":       "last beacon block",
+	"BeaconViews":           "beacon views",
+	"ShardViews":            "shard views by shard",
+	"s-b-h":                 "shard block by hash",
+	"V":                     "view by hash and height",
+	"s-b-i":                 "shard block hash by shard and height",
+	"b-c-s":                 "shard block hash confirmed by beacon by shard and height",
+	"s-b-H":                 "shard block height by hash",
+	"b-b-h":                 "beacon block by hash",
+	"b-h-ff-i":              "flat file index by block hash",
+	"b-h-v-d":               "validation data by block hash",
+	"b-h-a-h":               "ancient store height by block hash",
+	"b-b-i":                 "beacon block hash by height",
+	"b-b-H":                 "beacon block height by hash",
+	"tx-h":                  "transaction index by hash",
+	"c-s-n-h":               "next cross shard height by shard pair and height",
+	"p-c-c-s":               "last beacon height confirming cross shard blocks",
+	"fee-est":               "fee estimator by shard",
+	"tx-pb":                 "transactions by public key",
+	"R-H-b-co":              "beacon consensus root hash by height",
+	"R-H-b-re":              "beacon reward root hash by height",
+	"R-H-b-fe":              "beacon feature root hash by height",
+	"R-H-b-sl":              "beacon slash root hash by height",
+	"R-H-s-cr":              "shard committee reward root hash by shard and height",
+	"R-H-s-co":              "shard consensus root hash by shard and height",
+	"R-H-s-tx":              "shard transaction root hash by shard and height",
+	"R-H-s-sl":              "shard slash root hash by shard and height",
+	"R-H-s-fe":              "shard feature root hash by shard and height",
+	"S-R-H":                 "shard state root hashes by shard and block hash",
+	"B-R-H-":                "beacon state root hashes by block hash",
+	"st-tx-":                "staking transaction by shard and hash",
+	"previous-best-state":   "previous best state by chain",
+	"reindexed-output-coin": "indexed output coins by token, shard and public key",
+	"reindexed-key":         "indexed OTA key",
+	"coinhash-key":          "cached coin hash",
+	"tx-index":              "transaction by coin index, token and shard",
+	"tx-sn":                 "transaction by serial number, token and shard",
+	"p-s":                   "prune status",
+	"c-c-f-b":               "committee from block by hash and chain",
+	"schema-version":        "schema version",
+	"schema-cursor":         "schema migration cursor by version",
+	"st-snap":               "state snapshot by namespace and key",
+	"st-snap-r":             "state snapshot root by namespace",
+	"txpool-tx":             "pending transaction of the tx pool by shard and hash",
+	"txpool-fee":            "fee history of the tx pool fee estimator by shard",
+}
+
+// DecodedKey is a database key split in its parts, see DecodeKey
+type DecodedKey struct {
+	Family string
+	Name   string `json:",omitempty"`
+	Fields []interface{}
+}
+
+// DecodeKey splits a raw key of a chain database in its family, the prefix of
+// KeyFamily, and the fields of the rest of the key. A field of 32 bytes is a
+// hash, of 8 bytes a little endian uint64 as written by common.Uint64ToBytes,
+// of 1 byte a shard ID, a printable field is a string and any other field is
+// in hex.
+func DecodeKey(key []byte) *DecodedKey {
+	res := &DecodedKey{Fields: []interface{}{}}
+	var rest []byte
+	switch {
+	case len(key) == common.HashSize:
+		res.Family = TrieNodeFamily
+		rest = key
+	case bytes.HasPrefix(key, triePreimagePrefix):
+		res.Family = TriePreimageFamily
+		rest = key[len(triePreimagePrefix):]
+	default:
+		res.Family = OtherFamily
+		rest = key
+		// the family of a key with splitter is its first part unless it holds
+		// a binary field, like the shard ID of the shard root hashes
+		if i := bytes.Index(key, splitter); i > 0 && isPrintable(key[:i]) {
+			res.Family = string(key[:i])
+			rest = key[i+len(splitter):]
+			break
+		}
+		for _, prefix := range unsplitPrefixes {
+			if bytes.HasPrefix(key, prefix) {
+				res.Family = string(bytes.TrimRight(prefix, "-"))
+				rest = key[len(prefix):]
+				break
+			}
+		}
+	}
+	res.Name = keyFamilyNames[res.Family]
+	for _, part := range bytes.Split(rest, splitter) {
+		if len(part) == 0 {
+			continue
+		}
+		res.Fields = append(res.Fields, decodeField(part))
+	}
+	return res
+}
+
+// DecodeValue returns the value of a rawdbv2 key in a printable form, as is
+// when it is JSON, as a hash or a uint64 when its length is the one of these
+// types and in hex otherwise
+func DecodeValue(value []byte) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+	if json.Valid(value) {
+		return json.RawMessage(value)
+	}
+	return decodeField(value)
+}
+
+func decodeField(part []byte) interface{} {
+	switch len(part) {
+	case common.HashSize:
+		return common.BytesToHash(part).String()
+	case common.Uint64Size:
+		n, _ := common.BytesToUint64(part)
+		return n
+	case 1:
+		return uint8(part[0])
+	}
+	if isPrintable(part) {
+		return string(part)
+	}
+	return hex.EncodeToString(part)
+}
+
+func isPrintable(b []byte) bool {
+	for _, r := range string(b) {
+		if r == unicode.ReplacementChar || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
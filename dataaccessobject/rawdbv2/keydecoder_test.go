@@ -0,0 +1,37 @@
+package rawdbv2_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeKey(t *testing.T) {
+	hash := common.HashH([]byte("block"))
+	tests := []struct {
+		key    []byte
+		family string
+		fields []interface{}
+	}{
+		{rawdbv2.GetShardIndexToBlockHashKey(2, 100, hash), "s-b-i", []interface{}{uint8(2), uint64(100), hash.String()}},
+		{rawdbv2.GetBeaconIndexToBlockHashKey(7), "b-b-i", []interface{}{uint64(7)}},
+		{rawdbv2.GetShardRootsHashKey(0, hash), "S-R-H", []interface{}{uint8(0), hash.String()}},
+		{rawdbv2.GetBeaconRootsHashKey(hash), "B-R-H-", []interface{}{hash.String()}},
+		{rawdbv2.GetBeaconConsensusRootHashKey(5), "R-H-b-co", []interface{}{uint64(5)}},
+		{rawdbv2.GetSchemaVersionKey(), "schema-version", []interface{}{}},
+		{hash[:], rawdbv2.TrieNodeFamily, []interface{}{hash.String()}},
+	}
+	for _, test := range tests {
+		decoded := rawdbv2.DecodeKey(test.key)
+		assert.Equal(t, test.family, decoded.Family, "%q", test.key)
+		assert.NotEmpty(t, decoded.Name, "%q", test.key)
+		assert.Equal(t, test.fields, decoded.Fields, "%q", test.key)
+	}
+
+	assert.Equal(t, uint64(42), rawdbv2.DecodeValue(common.Uint64ToBytes(42)))
+	assert.Equal(t, hash.String(), rawdbv2.DecodeValue(hash[:]))
+	assert.Equal(t, json.RawMessage(`{"a":1}`), rawdbv2.DecodeValue([]byte(`{"a":1}`)))
+}
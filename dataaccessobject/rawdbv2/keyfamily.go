@@ -0,0 +1,58 @@
+package rawdbv2
+
+import (
+	"bytes"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+)
+
+var triePreimagePrefix = []byte("secure-key-")
+
+const (
+	TrieNodeFamily     = "trie-node"
+	TriePreimageFamily = "trie-preimage"
+	OtherFamily        = "other"
+)
+
+// prefixes of the keys without splitter, a prefix comes before its own prefixes
+var unsplitPrefixes = [][]byte{
+	WaitingPDEContributionPrefix,
+	PDEFeeWithdrawalStatusPrefix,
+	PDEContributionStatusPrefix,
+	PDEWithdrawalStatusPrefix,
+	PDETradeStatusPrefix,
+	PDETradingFeePrefix,
+	PDETradeFeePrefix,
+	PDESharePrefix,
+	PDEPoolPrefix,
+	lastBeaconBlockKey,
+	beaconViewsPrefix,
+	shardRootHashPrefix,
+	beaconRootHashPrefix,
+	rootHashPrefix,
+	txByPublicKeyPrefix,
+	pruneStatusPrefix,
+	schemaVersionKey,
+}
+
+// KeyFamily returns the family of a database key: trie-node for the 32 bytes
+// keys of the state tries, the prefix before the splitter for rawdbv2 and
+// consensus keys ("s-b-h", "b-h-ff-i", "R-H-b-co"...), or the prefix of the
+// keys without splitter
+func KeyFamily(key []byte) string {
+	if len(key) == common.HashSize {
+		return TrieNodeFamily
+	}
+	if bytes.HasPrefix(key, triePreimagePrefix) {
+		return TriePreimageFamily
+	}
+	if i := bytes.Index(key, splitter); i > 0 {
+		return string(key[:i])
+	}
+	for _, prefix := range unsplitPrefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return string(bytes.TrimRight(prefix, "-"))
+		}
+	}
+	return OtherFamily
+}
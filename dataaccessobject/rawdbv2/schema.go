@@ -18,6 +18,7 @@ var (
 	beaconHashToBlockPrefix           = []byte("b-b-h" + string(splitter))
 	blockHashToFFIndexPrefix          = []byte("b-h-ff-i" + string(splitter))
 	blockHashToValidationDataPrefix   = []byte("b-h-v-d" + string(splitter))
+	blockHashToAncientHeightPrefix    = []byte("b-h-a-h" + string(splitter))
 	beaconIndexToBlockHashPrefix      = []byte("b-b-i" + string(splitter))
 	beaconBlockHashToIndexPrefix      = []byte("b-b-H" + string(splitter))
 	txHashPrefix                      = []byte("tx-h" + string(splitter))
@@ -52,6 +53,15 @@ var (
 	pruneStatusPrefix         = []byte("p-s")
 
 	cacheCommitteeFromBlockPrefix = []byte("c-c-f-b" + string(splitter))
+
+	schemaVersionKey            = []byte("schema-version")
+	schemaMigrationCursorPrefix = []byte("schema-cursor" + string(splitter))
+
+	stateSnapshotPrefix     = []byte("st-snap" + string(splitter))
+	stateSnapshotRootPrefix = []byte("st-snap-r" + string(splitter))
+
+	txPoolTxPrefix           = []byte("txpool-tx" + string(splitter))
+	txPoolFeeEstimatorPrefix = []byte("txpool-fee" + string(splitter))
 )
 
 func GetLastShardBlockKey(shardID byte) []byte {
@@ -136,6 +146,12 @@ func GetBlockHashToFFIndexKey(hash common.Hash) []byte {
 	return append(temp, hash[:]...)
 }
 
+func GetBlockHashToAncientHeightKey(hash common.Hash) []byte {
+	temp := make([]byte, 0, len(blockHashToAncientHeightPrefix))
+	temp = append(temp, blockHashToAncientHeightPrefix...)
+	return append(temp, hash[:]...)
+}
+
 func GetBlockHashToValidationDataKey(hash common.Hash) []byte {
 	temp := make([]byte, 0, len(blockHashToValidationDataPrefix))
 	temp = append(temp, blockHashToValidationDataPrefix...)
@@ -476,6 +492,13 @@ func GetPruneStatusKey() []byte {
 	return append(temp)
 }
 
+func GetBeaconRootsHashPrefix() []byte {
+	temp := make([]byte, 0, len(beaconRootHashPrefix))
+	temp = append(temp, beaconRootHashPrefix...)
+	key := append(temp, splitter...)
+	return key
+}
+
 func GetShardRootsHashPrefix(shardID byte) []byte {
 	temp := make([]byte, 0, len(shardRootHashPrefix))
 	temp = append(temp, shardRootHashPrefix...)
@@ -483,3 +506,54 @@ func GetShardRootsHashPrefix(shardID byte) []byte {
 	key = append(key, splitter...)
 	return key
 }
+
+// ============================= Schema migration =======================================
+
+func GetSchemaVersionKey() []byte {
+	temp := make([]byte, 0, len(schemaVersionKey))
+	return append(temp, schemaVersionKey...)
+}
+
+func GetSchemaMigrationCursorKey(version uint64) []byte {
+	temp := make([]byte, 0, len(schemaMigrationCursorPrefix))
+	temp = append(temp, schemaMigrationCursorPrefix...)
+	return append(temp, common.Uint64ToBytes(version)...)
+}
+
+// ============================= State snapshot =======================================
+
+func GetStateSnapshotPrefix(namespace []byte) []byte {
+	temp := make([]byte, 0, len(stateSnapshotPrefix)+len(namespace)+len(splitter))
+	temp = append(temp, stateSnapshotPrefix...)
+	temp = append(temp, namespace...)
+	return append(temp, splitter...)
+}
+
+func GetStateSnapshotKey(namespace []byte, key common.Hash) []byte {
+	return append(GetStateSnapshotPrefix(namespace), key[:]...)
+}
+
+func GetStateSnapshotRootKey(namespace []byte) []byte {
+	temp := make([]byte, 0, len(stateSnapshotRootPrefix)+len(namespace))
+	temp = append(temp, stateSnapshotRootPrefix...)
+	return append(temp, namespace...)
+}
+
+// ============================= Tx pool persistence =======================================
+
+func GetTxPoolTxPrefix(shardID byte) []byte {
+	temp := make([]byte, 0, len(txPoolTxPrefix)+1+len(splitter))
+	temp = append(temp, txPoolTxPrefix...)
+	temp = append(temp, shardID)
+	return append(temp, splitter...)
+}
+
+func GetTxPoolTxKey(shardID byte, txHash common.Hash) []byte {
+	return append(GetTxPoolTxPrefix(shardID), txHash[:]...)
+}
+
+func GetTxPoolFeeEstimatorKey(shardID byte) []byte {
+	temp := make([]byte, 0, len(txPoolFeeEstimatorPrefix)+1)
+	temp = append(temp, txPoolFeeEstimatorPrefix...)
+	return append(temp, shardID)
+}
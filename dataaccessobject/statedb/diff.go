@@ -0,0 +1,289 @@
+package statedb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/trie"
+)
+
+// UnknownObjectType is the object type of a key whose prefix is not one of
+// the fixed prefixes of schema.go, such as the prefixes hashed with an epoch
+// or a pool pair ID
+const UnknownObjectType = -1
+
+// StateDiffEntry is a state object which differs between two roots of a trie,
+// Old is unset for an added object and New for a removed one. The values are
+// the JSON of the decoded object, the raw value when it can not be decoded.
+type StateDiffEntry struct {
+	Key        common.Hash
+	ObjectType int
+	ObjectName string
+	Old        json.RawMessage `json:",omitempty"`
+	New        json.RawMessage `json:",omitempty"`
+}
+
+// StateDiff lists the state objects added, removed and modified from FromRoot
+// to ToRoot, in key order
+type StateDiff struct {
+	FromRoot     common.Hash
+	ToRoot       common.Hash
+	Added        []StateDiffEntry
+	Removed      []StateDiffEntry
+	Modified     []StateDiffEntry
+	NodesScanned int  //trie nodes loaded by the walk, shared subtrees are skipped
+	Truncated    bool `json:",omitempty"` //the limit of entries was reached
+}
+
+// Count returns the number of entries of the diff
+func (diff *StateDiff) Count() int {
+	return len(diff.Added) + len(diff.Removed) + len(diff.Modified)
+}
+
+// DiffStateRoots compares two roots of the same trie of db. Only the subtrees
+// whose hash differs are walked, so the cost follows the size of the change
+// and not the size of the state. limit bounds the number of returned entries,
+// 0 for no limit.
+func DiffStateRoots(db DatabaseAccessWarper, fromRoot, toRoot common.Hash, limit int) (*StateDiff, error) {
+	from, err := db.OpenPrefixTrie(fromRoot)
+	if err != nil {
+		return nil, err
+	}
+	to, err := db.OpenPrefixTrie(toRoot)
+	if err != nil {
+		return nil, err
+	}
+	diff := &StateDiff{
+		FromRoot: fromRoot,
+		ToRoot:   toRoot,
+		Added:    []StateDiffEntry{},
+		Removed:  []StateDiffEntry{},
+		Modified: []StateDiffEntry{},
+	}
+	if fromRoot == toRoot {
+		return diff, nil
+	}
+
+	// both walks return their leaves in key order, a key found by both of them
+	// is a modified object
+	newLeaves, newCount := newDiffLeafIterator(from, to)
+	oldLeaves, oldCount := newDiffLeafIterator(to, from)
+	defer func() { diff.NodesScanned = *newCount + *oldCount }()
+	newLeaves.next()
+	oldLeaves.next()
+	for newLeaves.ok || oldLeaves.ok {
+		if limit > 0 && diff.Count() >= limit {
+			diff.Truncated = true
+			break
+		}
+		cmp := 0
+		switch {
+		case !oldLeaves.ok:
+			cmp = 1
+		case !newLeaves.ok:
+			cmp = -1
+		default:
+			cmp = bytes.Compare(oldLeaves.key[:], newLeaves.key[:])
+		}
+		switch {
+		case cmp < 0:
+			diff.Removed = append(diff.Removed, newStateDiffEntry(oldLeaves.key, oldLeaves.value, nil))
+			oldLeaves.next()
+		case cmp > 0:
+			diff.Added = append(diff.Added, newStateDiffEntry(newLeaves.key, nil, newLeaves.value))
+			newLeaves.next()
+		default:
+			diff.Modified = append(diff.Modified, newStateDiffEntry(newLeaves.key, oldLeaves.value, newLeaves.value))
+			oldLeaves.next()
+			newLeaves.next()
+		}
+	}
+	if newLeaves.it.Err != nil {
+		return nil, newLeaves.it.Err
+	}
+	if oldLeaves.it.Err != nil {
+		return nil, oldLeaves.it.Err
+	}
+	return diff, nil
+}
+
+// diffLeafIterator walks the leaves of trie b which are not in trie a
+type diffLeafIterator struct {
+	it    *trie.Iterator
+	ok    bool
+	key   common.Hash
+	value []byte
+}
+
+func newDiffLeafIterator(a, b Trie) (*diffLeafIterator, *int) {
+	it, count := trie.NewDifferenceIterator(a.NodeIterator(nil), b.NodeIterator(nil))
+	return &diffLeafIterator{it: trie.NewIterator(it)}, count
+}
+
+func (l *diffLeafIterator) next() {
+	l.ok = l.it.Next(true, false, true)
+	if l.ok {
+		l.key = common.BytesToHash(l.it.Key)
+		l.value = common.CopyBytes(l.it.Value)
+	}
+}
+
+func newStateDiffEntry(key common.Hash, oldValue, newValue []byte) StateDiffEntry {
+	objectType, name := ObjectTypeOfKey(key)
+	return StateDiffEntry{
+		Key:        key,
+		ObjectType: objectType,
+		ObjectName: name,
+		Old:        decodeStateObjectValue(objectType, key, oldValue),
+		New:        decodeStateObjectValue(objectType, key, newValue),
+	}
+}
+
+func decodeStateObjectValue(objectType int, key common.Hash, value []byte) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	if objectType != UnknownObjectType {
+		if obj, err := newStateObjectWithValue(nil, objectType, key, value); err == nil {
+			if data, err := json.Marshal(obj.GetValue()); err == nil {
+				return data
+			}
+		}
+	}
+	if json.Valid(value) {
+		return value
+	}
+	data, _ := json.Marshal(hex.EncodeToString(value))
+	return data
+}
+
+type objectPrefix struct {
+	objectType int
+	name       string
+}
+
+var objectPrefixes = struct {
+	once   sync.Once
+	lookup map[string]objectPrefix
+}{}
+
+// ObjectTypeOfKey returns the object type and the name of the schema prefix
+// of a state object key, UnknownObjectType when the prefix is not known
+func ObjectTypeOfKey(key common.Hash) (int, string) {
+	objectPrefixes.once.Do(buildObjectPrefixes)
+	if p, ok := objectPrefixes.lookup[string(key[:prefixHashKeyLength])]; ok {
+		return p.objectType, p.name
+	}
+	return UnknownObjectType, "unknown"
+}
+
+// buildObjectPrefixes lists the prefixes of schema.go which do not depend on
+// unbounded values. Per token prefixes are listed for PRV and the
+// confidential asset on every shard.
+func buildObjectPrefixes() {
+	lookup := map[string]objectPrefix{}
+	add := func(objectType int, name string, prefixes ...[]byte) {
+		for _, prefix := range prefixes {
+			if len(prefix) >= prefixHashKeyLength {
+				lookup[string(prefix[:prefixHashKeyLength])] = objectPrefix{objectType: objectType, name: name}
+			}
+		}
+	}
+
+	for _, role := range []int{NextEpochShardCandidate, CurrentEpochShardCandidate, NextEpochBeaconCandidate, CurrentEpochBeaconCandidate} {
+		add(CommitteeObjectType, "committee", GetCommitteePrefixWithRole(role, CandidateChainID))
+	}
+	for _, role := range []int{SubstituteValidator, CurrentValidator, SyncingValidators, BeaconWaitingPool, BeaconLockingPool} {
+		for shardID := BeaconChainID; shardID < common.MaxShardNumber; shardID++ {
+			add(CommitteeObjectType, "committee", GetCommitteePrefixWithRole(role, shardID))
+		}
+	}
+	beaconStakerInfo := common.HashH(beaconStakerInfoPrefix)
+	beaconSharePrice := common.HashH(beaconSharePricePrefix)
+	committeeData := GetCommitteeDataKey()
+	add(ShardStakerObjectType, "staker-info", GetStakerInfoPrefix())
+	add(BeaconStakerObjectType, "beacon-staker-info", beaconStakerInfo[:])
+	add(BeaconSharePriceType, "beacon-share-price", beaconSharePrice[:])
+	add(CommitteeDataObjectType, "committee-data", committeeData[:])
+	add(CommitteeRewardObjectType, "committee-reward", GetCommitteeRewardPrefix())
+	add(DelegationRewardObjectType, "delegation-reward", GetDelegationRewardPrefix())
+	add(BlackListProducerObjectType, "black-list-producer", GetBlackListProducerPrefix())
+
+	for _, tokenID := range []common.Hash{common.PRVCoinID, common.ConfidentialAssetID} {
+		for shardID := 0; shardID < common.MaxShardNumber; shardID++ {
+			add(SerialNumberObjectType, "serial-number", GetSerialNumberPrefix(tokenID, byte(shardID)))
+			add(CommitmentObjectType, "commitment", GetCommitmentPrefix(tokenID, byte(shardID)))
+			add(CommitmentIndexObjectType, "commitment-index", GetCommitmentIndexPrefix(tokenID, byte(shardID)))
+			add(OTACoinIndexObjectType, "ota-coin-index", GetOTACoinIndexPrefix(tokenID, byte(shardID)))
+		}
+		add(SNDerivatorObjectType, "sn-derivator", GetSNDerivatorPrefix(tokenID))
+		add(OnetimeAddressObjectType, "onetime-address", GetOnetimeAddressPrefix(tokenID))
+		add(TokenTransactionObjectType, "token-transaction", GetTokenTransactionPrefix(tokenID))
+	}
+	add(CommitmentLengthObjectType, "commitment-length", GetCommitmentLengthPrefix())
+	add(OTACoinLengthObjectType, "ota-coin-length", GetOTACoinLengthPrefix())
+	add(TokenObjectType, "token", GetTokenPrefix())
+
+	add(WaitingPDEContributionObjectType, "pde-waiting-contribution", GetWaitingPDEContributionPrefix())
+	add(PDEPoolPairObjectType, "pde-pool-pair", GetPDEPoolPairPrefix())
+	add(PDEShareObjectType, "pde-share", GetPDESharePrefix())
+	add(PDETradingFeeObjectType, "pde-trading-fee", GetPDETradingFeePrefix())
+	add(PDEStatusObjectType, "pde-status", GetPDEStatusPrefix())
+
+	add(BridgeEthTxObjectType, "bridge-eth-tx", GetBridgeEthTxPrefix())
+	add(BridgeBSCTxObjectType, "bridge-bsc-tx", GetBridgeBSCTxPrefix())
+	add(BridgePRVEVMObjectType, "bridge-prv-evm-tx", GetBridgePRVEVMPrefix())
+	add(BridgePLGTxObjectType, "bridge-plg-tx", GetBridgePLGTxPrefix())
+	add(BridgeFTMTxObjectType, "bridge-ftm-tx", GetBridgeFTMTxPrefix())
+	add(BridgeAURORATxObjectType, "bridge-aurora-tx", GetBridgeAURORATxPrefix())
+	add(BridgeAVAXTxObjectType, "bridge-avax-tx", GetBridgeAVAXTxPrefix())
+	add(BridgeNEARTxObjectType, "bridge-near-tx", GetBridgeNEARTxPrefix())
+	add(BridgeTokenInfoObjectType, "bridge-token-info", GetBridgeTokenInfoPrefix(true), GetBridgeTokenInfoPrefix(false))
+	add(BridgeStatusObjectType, "bridge-status", GetBridgeStatusPrefix())
+	add(BurningConfirmObjectType, "burning-confirm", GetBurningConfirmPrefix())
+
+	add(PortalFinalExchangeRatesStateObjectType, "portal-final-exchange-rates", GetFinalExchangeRatesStatePrefix())
+	add(PortalUnlockOverRateCollaterals, "portal-unlock-over-rate-collaterals", GetPortalUnlockOverRateCollateralsPrefix())
+	add(PortalWaitingPortingRequestObjectType, "portal-waiting-porting-request", GetPortalWaitingPortingRequestPrefix())
+	add(PortalLiquidationPoolObjectType, "portal-liquidation-pool", GetPortalLiquidationPoolPrefix())
+	add(CustodianStateObjectType, "portal-custodian", GetPortalCustodianStatePrefix())
+	add(WaitingRedeemRequestObjectType, "portal-redeem-request", GetWaitingRedeemRequestPrefix(), GetMatchedRedeemRequestPrefix())
+	add(PortalStatusObjectType, "portal-status", GetPortalStatusPrefix())
+	add(LockedCollateralStateObjectType, "portal-locked-collateral", GetLockedCollateralStatePrefix())
+	add(PortalExternalTxObjectType, "portal-external-tx", GetPortalExternalTxPrefix())
+	add(PortalConfirmProofObjectType, "portal-confirm-proof", GetPortalConfirmProofPrefixV3(PortalWithdrawCollateralProofType()))
+	for _, statusType := range [][]byte{
+		PortalShieldingRequestStatusPrefix(), PortalUnshieldRequestStatusPrefix(), PortalBatchUnshieldRequestStatusPrefix(),
+		PortaConvertVaultRequestStatusPrefix(), PortalUnshielFeeReplacementBatchStatusPrefix(), PortalSubmitConfirmedTxStatusPrefix(),
+	} {
+		add(PortalV4StatusObjectType, "portalv4-status", GetPortalV4StatusPrefix(statusType))
+	}
+
+	for _, statusType := range [][]byte{
+		Pdexv3ParamsModifyingStatusPrefix(), Pdexv3TradeStatusPrefix(), Pdexv3WithdrawalLPFeeStatusPrefix(),
+		Pdexv3WithdrawalProtocolFeeStatusPrefix(), Pdexv3WithdrawalStakingRewardStatusPrefix(), Pdexv3AddOrderStatusPrefix(),
+		Pdexv3WithdrawOrderStatusPrefix(), Pdexv3WithdrawLiquidityStatusPrefix(), Pdexv3UserMintNftStatusPrefix(),
+		InscriptionStatusPrefix(), Pdexv3ContributionStatusPrefix(), Pdexv3StakingStatusPrefix(), Pdexv3UnstakingStatusPrefix(),
+	} {
+		add(Pdexv3StatusObjectType, "pdexv3-status", GetPdexv3StatusPrefix(statusType))
+	}
+	add(Pdexv3ParamsObjectType, "pdexv3-params", GetPdexv3ParamsPrefix())
+	add(Pdexv3ContributionObjectType, "pdexv3-contribution", GetPdexv3WaitingContributionsPrefix())
+	add(Pdexv3PoolPairObjectType, "pdexv3-pool-pair", GetPdexv3PoolPairsPrefix())
+	add(Pdexv3NftObjectType, "pdexv3-nft", GetPdexv3NftPrefix())
+	add(InscriptionTokenIDObjectType, "pdexv3-inscription", GetPdexv3InscriptionPrefix())
+	add(InscriptionNumberObjectType, "pdexv3-inscription-number", GetPdexv3InscriptionNumberPrefix())
+
+	for _, statusType := range [][]byte{
+		BridgeAggModifyParamStatusPrefix(), BridgeAggConvertStatusPrefix(), BridgeAggShieldStatusPrefix(), BridgeAggUnshieldStatusPrefix(),
+	} {
+		add(BridgeAggStatusObjectType, "bridgeagg-status", GetBridgeAggStatusPrefix(statusType))
+	}
+	add(BridgeAggUnifiedTokenObjectType, "bridgeagg-unified-token", GetBridgeAggUnifiedTokenPrefix())
+	add(BridgeAggParamObjectType, "bridgeagg-param", GetBridgeAggParamPrefix())
+
+	objectPrefixes.lookup = lookup
+}
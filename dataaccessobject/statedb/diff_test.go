@@ -0,0 +1,66 @@
+package statedb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffStateRoots(t *testing.T) {
+	sDB, err := NewWithPrefixTrie(emptyRoot, warperDBStatedbTest)
+	assert.Nil(t, err)
+	serialNumbers := testGenerateSerialNumberList(100)
+	assert.Nil(t, StoreSerialNumbers(sDB, common.PRVCoinID, serialNumbers, 0))
+	unknownKey := common.HashH([]byte("diff-unknown-object"))
+	assert.Nil(t, sDB.SetStateObject(TestObjectType, unknownKey, []byte{1, 2, 3}))
+	fromRoot, err := sDB.Commit(true)
+	assert.Nil(t, err)
+	assert.Nil(t, sDB.Database().TrieDB().Commit(fromRoot, false))
+
+	// remove one serial number, rewrite another and add a new one
+	removedKey := GenerateSerialNumberObjectKey(common.ConfidentialAssetID, 0, serialNumbers[0])
+	sDB.MarkDeleteStateObject(SerialNumberObjectType, removedKey)
+	modifiedKey := GenerateSerialNumberObjectKey(common.ConfidentialAssetID, 0, serialNumbers[1])
+	assert.Nil(t, sDB.SetStateObject(SerialNumberObjectType, modifiedKey, NewSerialNumberStateWithValue(common.ConfidentialAssetID, 1, serialNumbers[1])))
+	added := testGenerateSerialNumberList(1)
+	assert.Nil(t, StoreSerialNumbers(sDB, common.PRVCoinID, added, 0))
+	toRoot, err := sDB.Commit(true)
+	assert.Nil(t, err)
+	assert.Nil(t, sDB.Database().TrieDB().Commit(toRoot, false))
+
+	diff, err := DiffStateRoots(warperDBStatedbTest, fromRoot, toRoot, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(diff.Added))
+	assert.Equal(t, 1, len(diff.Removed))
+	assert.Equal(t, 1, len(diff.Modified))
+	assert.Equal(t, removedKey, diff.Removed[0].Key)
+	assert.Equal(t, SerialNumberObjectType, diff.Removed[0].ObjectType)
+	assert.Nil(t, diff.Removed[0].New)
+	assert.Equal(t, modifiedKey, diff.Modified[0].Key)
+	oldState, newState := NewSerialNumberState(), NewSerialNumberState()
+	assert.Nil(t, json.Unmarshal(diff.Modified[0].Old, oldState))
+	assert.Nil(t, json.Unmarshal(diff.Modified[0].New, newState))
+	assert.Equal(t, byte(0), oldState.ShardID())
+	assert.Equal(t, byte(1), newState.ShardID())
+	assert.Equal(t, "serial-number", diff.Added[0].ObjectName)
+
+	// the reverse diff swaps added and removed
+	reverse, err := DiffStateRoots(warperDBStatedbTest, toRoot, fromRoot, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, diff.Added[0].Key, reverse.Removed[0].Key)
+	assert.Equal(t, diff.Removed[0].Key, reverse.Added[0].Key)
+
+	truncated, err := DiffStateRoots(warperDBStatedbTest, fromRoot, toRoot, 2)
+	assert.Nil(t, err)
+	assert.True(t, truncated.Truncated)
+	assert.Equal(t, 2, truncated.Count())
+
+	same, err := DiffStateRoots(warperDBStatedbTest, toRoot, toRoot, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, same.Count())
+
+	objectType, _ := ObjectTypeOfKey(unknownKey)
+	assert.Equal(t, UnknownObjectType, objectType)
+}
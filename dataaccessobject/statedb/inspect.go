@@ -0,0 +1,158 @@
+package statedb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/trie"
+)
+
+// StateObjectEntry is a state object of a trie, Value is the JSON of the
+// decoded object, the raw value when it can not be decoded. RawKey is the key
+// in hex in its byte order, Key prints it reversed as every hash.
+type StateObjectEntry struct {
+	Key        common.Hash
+	RawKey     string
+	ObjectType int
+	ObjectName string
+	Value      json.RawMessage
+}
+
+// StateObjectList lists the state objects of Root matching a filter, in key
+// order
+type StateObjectList struct {
+	Root      common.Hash
+	Objects   []StateObjectEntry
+	Scanned   int  //leaves read, matching the filter or not
+	Truncated bool `json:",omitempty"` //the limit of objects was reached
+}
+
+// ObjectTypeNames returns the names of the object types ObjectTypeOfKey
+// recognizes, sorted
+func ObjectTypeNames() []string {
+	objectPrefixes.once.Do(buildObjectPrefixes)
+	seen := map[string]bool{}
+	names := []string{}
+	for _, p := range objectPrefixes.lookup {
+		if !seen[p.name] {
+			seen[p.name] = true
+			names = append(names, p.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ObjectTypeByName returns the object type of the name ObjectTypeOfKey gives
+// its keys
+func ObjectTypeByName(name string) (int, bool) {
+	objectPrefixes.once.Do(buildObjectPrefixes)
+	for _, p := range objectPrefixes.lookup {
+		if p.name == name {
+			return p.objectType, true
+		}
+	}
+	return UnknownObjectType, false
+}
+
+// objectTypePrefixes returns the known key prefixes of the object type name,
+// sorted
+func objectTypePrefixes(name string) [][]byte {
+	objectPrefixes.once.Do(buildObjectPrefixes)
+	prefixes := [][]byte{}
+	for prefix, p := range objectPrefixes.lookup {
+		if p.name == name {
+			prefixes = append(prefixes, []byte(prefix))
+		}
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return bytes.Compare(prefixes[i], prefixes[j]) < 0 })
+	return prefixes
+}
+
+// DecodeStateObject decodes the raw value of the state object key. The object
+// type is found from the prefix of the key when objectType is
+// UnknownObjectType, for the keys whose prefix depends on a value it must be
+// given.
+func DecodeStateObject(key common.Hash, value []byte, objectType int) StateObjectEntry {
+	name := "unknown"
+	if objectType == UnknownObjectType {
+		objectType, name = ObjectTypeOfKey(key)
+	} else {
+		objectPrefixes.once.Do(buildObjectPrefixes)
+		for _, p := range objectPrefixes.lookup {
+			if p.objectType == objectType {
+				name = p.name
+				break
+			}
+		}
+	}
+	return StateObjectEntry{
+		Key:        key,
+		RawKey:     hex.EncodeToString(key[:]),
+		ObjectType: objectType,
+		ObjectName: name,
+		Value:      decodeStateObjectValue(objectType, key, value),
+	}
+}
+
+// GetStateObject reads and decodes the state object key of the trie at root
+func GetStateObject(db DatabaseAccessWarper, root common.Hash, key common.Hash, objectType int) (*StateObjectEntry, error) {
+	tr, err := db.OpenPrefixTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	value, err := tr.TryGet(key[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(value) == 0 {
+		return nil, fmt.Errorf("no state object %v at root %v", key.String(), root.String())
+	}
+	entry := DecodeStateObject(key, value, objectType)
+	return &entry, nil
+}
+
+// ListStateObjects lists the state objects of the trie at root whose key
+// starts with prefix and whose type is objectName, an empty filter matches
+// every object. Only the subtrees of the prefixes of objectName are walked
+// when no prefix is given. limit bounds the number of objects, 0 for no limit.
+func ListStateObjects(db DatabaseAccessWarper, root common.Hash, objectName string, prefix []byte, limit int) (*StateObjectList, error) {
+	tr, err := db.OpenPrefixTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	prefixes := [][]byte{prefix}
+	if objectName != "" {
+		if _, ok := ObjectTypeByName(objectName); !ok {
+			return nil, fmt.Errorf("unknown object type %v, expect one of %v", objectName, ObjectTypeNames())
+		}
+		if len(prefix) == 0 {
+			prefixes = objectTypePrefixes(objectName)
+		}
+	}
+	res := &StateObjectList{Root: root, Objects: []StateObjectEntry{}}
+	for _, p := range prefixes {
+		it := trie.NewIterator(tr.NodeIterator(p))
+		for it.Next(true, false, true) {
+			res.Scanned++
+			key := common.BytesToHash(it.Key)
+			entry := DecodeStateObject(key, common.CopyBytes(it.Value), UnknownObjectType)
+			if objectName != "" && entry.ObjectName != objectName {
+				continue
+			}
+			if limit > 0 && len(res.Objects) >= limit {
+				res.Truncated = true
+				return res, nil
+			}
+			res.Objects = append(res.Objects, entry)
+		}
+		if it.Err != nil {
+			return res, it.Err
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,52 @@
+package statedb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListStateObjects(t *testing.T) {
+	_, warper := newSnapshotTestDB(t)
+	sDB, err := NewWithPrefixTrie(emptyRoot, warper)
+	assert.Nil(t, err)
+	serialNumbers := testGenerateSerialNumberList(20)
+	assert.Nil(t, StoreSerialNumbers(sDB, common.ConfidentialAssetID, serialNumbers, 0))
+	assert.Nil(t, StoreSerialNumbers(sDB, common.ConfidentialAssetID, testGenerateSerialNumberList(5), 1))
+	assert.Nil(t, StorePrivacyToken(sDB, common.HashH([]byte("token")), "token", "TK", 0, false, 100, nil, common.Hash{}))
+	root := commitSnapshotTest(t, sDB)
+
+	// by object type, every shard
+	list, err := ListStateObjects(warper, root, "serial-number", nil, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 25, len(list.Objects))
+	assert.False(t, list.Truncated)
+	for _, object := range list.Objects {
+		assert.Equal(t, SerialNumberObjectType, object.ObjectType)
+	}
+
+	// by prefix, with a limit
+	prefix := GetSerialNumberPrefix(common.ConfidentialAssetID, 0)
+	list, err = ListStateObjects(warper, root, "", prefix, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(list.Objects))
+	assert.True(t, list.Truncated)
+
+	// every object
+	list, err = ListStateObjects(warper, root, "", nil, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 26, len(list.Objects))
+
+	_, err = ListStateObjects(warper, root, "no-such-type", nil, 0)
+	assert.NotNil(t, err)
+
+	key := GenerateSerialNumberObjectKey(common.ConfidentialAssetID, 0, serialNumbers[0])
+	object, err := GetStateObject(warper, root, key, UnknownObjectType)
+	assert.Nil(t, err)
+	assert.Equal(t, "serial-number", object.ObjectName)
+	want, err := json.Marshal(NewSerialNumberStateWithValue(common.ConfidentialAssetID, 0, serialNumbers[0]))
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(want), string(object.Value))
+}
@@ -0,0 +1,33 @@
+package statedb
+
+import (
+	"fmt"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/stateproof"
+	"github.com/levietcuong2602/incognito-chain/trie"
+)
+
+// Prove returns the Merkle proof of the state object of key, or of its
+// absence, in the committed state of the database. Changes not committed yet
+// are not part of the proof.
+func (stateDB *StateDB) Prove(objectType int, key common.Hash) (*stateproof.StateProof, error) {
+	if knownType, name := ObjectTypeOfKey(key); knownType != UnknownObjectType && knownType != objectType {
+		return nil, fmt.Errorf("key %v is a key of %v (object type %v), not of object type %v", key.String(), name, knownType, objectType)
+	}
+	value, err := stateDB.trie.TryGet(key[:])
+	if err != nil {
+		return nil, err
+	}
+	proofSet := trie.ProofSet{}
+	if err := stateDB.trie.Prove(key[:], 0, proofSet); err != nil {
+		return nil, err
+	}
+	return &stateproof.StateProof{
+		Root:       stateDB.trie.Hash(),
+		ObjectType: objectType,
+		Key:        key,
+		Value:      common.CopyBytes(value),
+		Nodes:      proofSet.Nodes(),
+	}, nil
+}
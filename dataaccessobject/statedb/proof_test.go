@@ -0,0 +1,60 @@
+package statedb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/stateproof"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateDBProve(t *testing.T) {
+	sDB, err := NewWithPrefixTrie(emptyRoot, warperDBStatedbTest)
+	assert.Nil(t, err)
+	serialNumbers := testGenerateSerialNumberList(50)
+	assert.Nil(t, StoreSerialNumbers(sDB, common.PRVCoinID, serialNumbers, 0))
+	root, err := sDB.Commit(true)
+	assert.Nil(t, err)
+	assert.Nil(t, sDB.Database().TrieDB().Commit(root, false))
+
+	sDB, err = NewWithPrefixTrie(root, warperDBStatedbTest)
+	assert.Nil(t, err)
+	key := GenerateSerialNumberObjectKey(common.ConfidentialAssetID, 0, serialNumbers[3])
+	proof, err := sDB.Prove(SerialNumberObjectType, key)
+	assert.Nil(t, err)
+	assert.True(t, proof.Exists())
+	value, err := proof.VerifyAgainst(root)
+	assert.Nil(t, err)
+	state := NewSerialNumberState()
+	assert.Nil(t, json.Unmarshal(value, state))
+	assert.Equal(t, serialNumbers[3], state.SerialNumber())
+
+	// the proof survives its JSON form
+	data, err := json.Marshal(proof)
+	assert.Nil(t, err)
+	_, err = stateproof.VerifyJSON(data, root.String())
+	assert.Nil(t, err)
+	_, err = stateproof.VerifyJSON(data, "")
+	assert.NotNil(t, err)
+
+	// a forged value or a different root is refused
+	proof.Value = append(common.CopyBytes(proof.Value), 0)
+	_, err = proof.Verify()
+	assert.NotNil(t, err)
+	_, err = proof.VerifyAgainst(common.HashH([]byte("root")))
+	assert.NotNil(t, err)
+
+	// absence of an object
+	absent := GenerateSerialNumberObjectKey(common.ConfidentialAssetID, 0, []byte("absent"))
+	proof, err = sDB.Prove(SerialNumberObjectType, absent)
+	assert.Nil(t, err)
+	assert.False(t, proof.Exists())
+	value, err = proof.VerifyAgainst(root)
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+
+	// a key of another object type is refused
+	_, err = sDB.Prove(CommitmentObjectType, key)
+	assert.NotNil(t, err)
+}
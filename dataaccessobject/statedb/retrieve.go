@@ -14,6 +14,18 @@ func (stateDB *StateDB) GetIterator() *trie.Iterator {
 	return it
 }
 
+// GetDiffIterator returns an iterator over the nodes of this trie which are not
+// part of the trie at parentRoot, both tries are read from the same database.
+// Subtrees shared with the parent trie are skipped without being loaded.
+func (stateDB *StateDB) GetDiffIterator(parentRoot common.Hash) (*trie.Iterator, error) {
+	parent, err := stateDB.db.OpenPrefixTrie(parentRoot)
+	if err != nil {
+		return nil, err
+	}
+	diff, _ := trie.NewDifferenceIterator(parent.NodeIterator(nil), stateDB.trie.NodeIterator(nil))
+	return trie.NewIterator(diff), nil
+}
+
 func (stateDB *StateDB) Recheck() error {
 	fmt.Println("[prune] start recheck")
 	temp := stateDB.trie.NodeIterator(nil)
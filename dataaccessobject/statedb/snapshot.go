@@ -0,0 +1,464 @@
+package statedb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/trie"
+)
+
+// DefaultSnapshotDiffLayers is the number of diff layers kept in memory above
+// the disk layer, older layers are flattened into the disk layer
+const DefaultSnapshotDiffLayers = 128
+
+var (
+	// ErrSnapshotStale is returned by the reads of a layer which was flattened
+	// or dropped, the reader falls back to the trie
+	ErrSnapshotStale = errors.New("state snapshot layer is stale")
+	// ErrSnapshotNotFound is returned when there is no layer for a root
+	ErrSnapshotNotFound = errors.New("state snapshot layer not found")
+)
+
+// Snapshot is a flat key to value view of the state objects of one state
+// root, a read costs a map lookup per diff layer and a single database read
+// instead of a walk of the trie from its root
+type Snapshot interface {
+	// Root returns the state root the snapshot is in step with
+	Root() common.Hash
+
+	// Get returns the encoded state object of key, nil when the object does
+	// not exist. ErrSnapshotStale is returned when the layer can not be read
+	// anymore.
+	Get(key common.Hash) ([]byte, error)
+}
+
+type snapshotLayer interface {
+	Snapshot
+	get(key common.Hash) ([]byte, error)
+	isStale() bool
+}
+
+// diskLayer is the flat snapshot persisted in the database
+type diskLayer struct {
+	tree  *SnapshotTree
+	root  common.Hash
+	stale bool
+}
+
+func (dl *diskLayer) Root() common.Hash {
+	return dl.root
+}
+
+func (dl *diskLayer) Get(key common.Hash) ([]byte, error) {
+	dl.tree.lock.RLock()
+	defer dl.tree.lock.RUnlock()
+	return dl.get(key)
+}
+
+func (dl *diskLayer) get(key common.Hash) ([]byte, error) {
+	if dl.stale {
+		return nil, ErrSnapshotStale
+	}
+	return rawdbv2.GetStateSnapshotObject(dl.tree.db, dl.tree.namespace, key)
+}
+
+func (dl *diskLayer) isStale() bool {
+	return dl.stale
+}
+
+// diffLayer holds the objects changed by the commit of root on top of its
+// parent layer, a nil value for a deleted object
+type diffLayer struct {
+	tree    *SnapshotTree
+	root    common.Hash
+	parent  snapshotLayer
+	objects map[common.Hash][]byte
+	stale   bool
+}
+
+func (dl *diffLayer) Root() common.Hash {
+	return dl.root
+}
+
+func (dl *diffLayer) Get(key common.Hash) ([]byte, error) {
+	dl.tree.lock.RLock()
+	defer dl.tree.lock.RUnlock()
+	return dl.get(key)
+}
+
+func (dl *diffLayer) get(key common.Hash) ([]byte, error) {
+	if dl.stale {
+		return nil, ErrSnapshotStale
+	}
+	if value, ok := dl.objects[key]; ok {
+		return value, nil
+	}
+	return dl.parent.get(key)
+}
+
+func (dl *diffLayer) isStale() bool {
+	return dl.stale
+}
+
+// SnapshotTree keeps the flat snapshot of the state trie stored under
+// namespace in db: one disk layer persisted in db and the diff layers of the
+// recent commits of every view on top of it, indexed by state root. The disk
+// layer moves up when a chain of diff layers grows over maxDiffLayers, forks
+// which do not descend from the new disk layer are dropped.
+type SnapshotTree struct {
+	db            incdb.Database
+	namespace     []byte
+	maxDiffLayers int
+
+	lock   sync.RWMutex
+	disk   *diskLayer
+	layers map[common.Hash]snapshotLayer
+}
+
+// NewSnapshotTree loads the snapshot tree of namespace, it has no layer until
+// Rebuild is called when db has no complete snapshot
+func NewSnapshotTree(db incdb.Database, namespace string, maxDiffLayers int) (*SnapshotTree, error) {
+	if maxDiffLayers <= 0 {
+		maxDiffLayers = DefaultSnapshotDiffLayers
+	}
+	tree := &SnapshotTree{
+		db:            db,
+		namespace:     []byte(namespace),
+		maxDiffLayers: maxDiffLayers,
+		layers:        make(map[common.Hash]snapshotLayer),
+	}
+	root, found, err := rawdbv2.GetStateSnapshotRoot(db, tree.namespace)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		tree.setDiskLayer(root)
+	}
+	return tree, nil
+}
+
+// DiskRoot returns the state root of the disk layer, false when the tree has
+// no snapshot
+func (tree *SnapshotTree) DiskRoot() (common.Hash, bool) {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+	if tree.disk == nil {
+		return common.Hash{}, false
+	}
+	return tree.disk.root, true
+}
+
+// Snapshot returns the layer of root, nil when the tree has none
+func (tree *SnapshotTree) Snapshot(root common.Hash) Snapshot {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+	if layer, ok := tree.layers[root]; ok {
+		return layer
+	}
+	return nil
+}
+
+// Update adds the layer of root on top of the layer of parent with the
+// objects changed between them, then flattens the layers which are too deep
+func (tree *SnapshotTree) Update(root, parent common.Hash, objects map[common.Hash][]byte) error {
+	tree.lock.Lock()
+	defer tree.lock.Unlock()
+	if _, ok := tree.layers[root]; ok {
+		return nil
+	}
+	parentLayer, ok := tree.layers[parent]
+	if !ok {
+		return ErrSnapshotNotFound
+	}
+	layer := &diffLayer{
+		tree:    tree,
+		root:    root,
+		parent:  parentLayer,
+		objects: make(map[common.Hash][]byte, len(objects)),
+	}
+	for key, value := range objects {
+		if len(value) == 0 {
+			layer.objects[key] = nil
+		} else {
+			layer.objects[key] = common.CopyBytes(value)
+		}
+	}
+	tree.layers[root] = layer
+	return tree.capLayers(layer)
+}
+
+// Recover builds the layer of root from the disk layer when the diff layers
+// between them were lost, e.g. by a restart of the node. The cost follows the
+// size of the change from the disk layer, see DiffStateRoots.
+func (tree *SnapshotTree) Recover(root common.Hash, warper DatabaseAccessWarper) (Snapshot, error) {
+	if snap := tree.Snapshot(root); snap != nil {
+		return snap, nil
+	}
+	diskRoot, ok := tree.DiskRoot()
+	if !ok {
+		return nil, ErrSnapshotNotFound
+	}
+	diff, err := DiffStateRoots(warper, diskRoot, root, 0)
+	if err != nil {
+		return nil, err
+	}
+	tr, err := warper.OpenPrefixTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	objects := make(map[common.Hash][]byte, diff.Count())
+	for _, entries := range [][]StateDiffEntry{diff.Added, diff.Modified} {
+		for _, entry := range entries {
+			value, err := tr.TryGet(entry.Key[:])
+			if err != nil {
+				return nil, err
+			}
+			objects[entry.Key] = value
+		}
+	}
+	for _, entry := range diff.Removed {
+		objects[entry.Key] = nil
+	}
+	if err := tree.Update(root, diskRoot, objects); err != nil {
+		return nil, err
+	}
+	if snap := tree.Snapshot(root); snap != nil {
+		return snap, nil
+	}
+	return nil, ErrSnapshotNotFound
+}
+
+// Rebuild writes the flat snapshot of the trie at root to the database from
+// scratch and makes it the disk layer, every other layer is dropped. The
+// root marker is removed first so an interrupted rebuild leaves no snapshot.
+func (tree *SnapshotTree) Rebuild(root common.Hash, warper DatabaseAccessWarper) (int, error) {
+	tr, err := warper.OpenPrefixTrie(root)
+	if err != nil {
+		return 0, err
+	}
+	tree.lock.Lock()
+	defer tree.lock.Unlock()
+	tree.dropLayers()
+	if err := rawdbv2.DeleteStateSnapshotRoot(tree.db, tree.namespace); err != nil {
+		return 0, err
+	}
+	if err := tree.wipeObjects(); err != nil {
+		return 0, err
+	}
+
+	batch := tree.db.NewBatch()
+	count := 0
+	it := trie.NewIterator(tr.NodeIterator(nil))
+	for it.Next(true, false, true) {
+		if err := rawdbv2.StoreStateSnapshotObject(batch, tree.namespace, common.BytesToHash(it.Key), it.Value); err != nil {
+			return count, err
+		}
+		count++
+		if batch.ValueSize() >= incdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return count, err
+			}
+			batch.Reset()
+		}
+	}
+	if it.Err != nil {
+		return count, it.Err
+	}
+	if err := rawdbv2.StoreStateSnapshotRoot(batch, tree.namespace, root); err != nil {
+		return count, err
+	}
+	if err := batch.Write(); err != nil {
+		return count, err
+	}
+	tree.setDiskLayer(root)
+	return count, nil
+}
+
+// SnapshotMismatch is an object whose value in the snapshot differs from its
+// value in the trie, a nil value for an object missing on one side
+type SnapshotMismatch struct {
+	Key      common.Hash
+	Trie     []byte `json:",omitempty"`
+	Snapshot []byte `json:",omitempty"`
+}
+
+// SnapshotCheck is the result of the comparison of the layer of a root with
+// the trie, limit bounds the number of listed mismatches
+type SnapshotCheck struct {
+	Root       common.Hash
+	Objects    int //objects of the trie
+	Mismatches []SnapshotMismatch
+	Truncated  bool `json:",omitempty"`
+}
+
+// Verify compares the layer of root with the trie at root: every object of
+// the trie must be read from the snapshot with the same value and, when the
+// layer of root is the disk layer, the disk must hold no other object
+func (tree *SnapshotTree) Verify(root common.Hash, warper DatabaseAccessWarper, limit int) (*SnapshotCheck, error) {
+	snap := tree.Snapshot(root)
+	if snap == nil {
+		return nil, ErrSnapshotNotFound
+	}
+	tr, err := warper.OpenPrefixTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	check := &SnapshotCheck{Root: root, Mismatches: []SnapshotMismatch{}}
+	mismatch := func(m SnapshotMismatch) bool {
+		if limit > 0 && len(check.Mismatches) >= limit {
+			check.Truncated = true
+			return false
+		}
+		check.Mismatches = append(check.Mismatches, m)
+		return true
+	}
+
+	it := trie.NewIterator(tr.NodeIterator(nil))
+	for it.Next(true, false, true) {
+		check.Objects++
+		key := common.BytesToHash(it.Key)
+		value, err := snap.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(value, it.Value) {
+			if !mismatch(SnapshotMismatch{Key: key, Trie: common.CopyBytes(it.Value), Snapshot: value}) {
+				return check, nil
+			}
+		}
+	}
+	if it.Err != nil {
+		return nil, it.Err
+	}
+
+	if _, ok := snap.(*diskLayer); !ok {
+		return check, nil
+	}
+	prefix := rawdbv2.GetStateSnapshotPrefix(tree.namespace)
+	dbIt := tree.db.NewIteratorWithPrefix(prefix)
+	defer dbIt.Release()
+	for dbIt.Next() {
+		key := common.BytesToHash(dbIt.Key()[len(prefix):])
+		value, err := tr.TryGet(key[:])
+		if err != nil {
+			return nil, err
+		}
+		if len(value) == 0 {
+			if !mismatch(SnapshotMismatch{Key: key, Snapshot: common.CopyBytes(dbIt.Value())}) {
+				return check, nil
+			}
+		}
+	}
+	return check, dbIt.Error()
+}
+
+func (tree *SnapshotTree) setDiskLayer(root common.Hash) {
+	tree.disk = &diskLayer{tree: tree, root: root}
+	tree.layers[root] = tree.disk
+}
+
+// capLayers flattens the bottom diff layer of the chain of top into the disk
+// layer when the chain is deeper than maxDiffLayers
+func (tree *SnapshotTree) capLayers(top *diffLayer) error {
+	depth := 0
+	bottom := top
+	for {
+		depth++
+		parent, ok := bottom.parent.(*diffLayer)
+		if !ok {
+			break
+		}
+		bottom = parent
+	}
+	if depth <= tree.maxDiffLayers || bottom.parent != tree.disk {
+		return nil
+	}
+
+	batch := tree.db.NewBatch()
+	for key, value := range bottom.objects {
+		var err error
+		if value == nil {
+			err = rawdbv2.DeleteStateSnapshotObject(batch, tree.namespace, key)
+		} else {
+			err = rawdbv2.StoreStateSnapshotObject(batch, tree.namespace, key, value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := rawdbv2.StoreStateSnapshotRoot(batch, tree.namespace, bottom.root); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("flatten state snapshot layer %v: %v", bottom.root.String(), err)
+	}
+
+	oldDisk := tree.disk
+	oldDisk.stale = true
+	bottom.stale = true
+	delete(tree.layers, oldDisk.root)
+	tree.setDiskLayer(bottom.root)
+	for _, layer := range tree.layers {
+		if diff, ok := layer.(*diffLayer); ok && diff.parent == bottom {
+			diff.parent = tree.disk
+		}
+	}
+	// the forks of the flattened layer sit on the stale disk layer
+	for root, layer := range tree.layers {
+		if bottomOf(layer).isStale() {
+			layer.(*diffLayer).stale = true
+			delete(tree.layers, root)
+		}
+	}
+	return nil
+}
+
+func bottomOf(layer snapshotLayer) snapshotLayer {
+	for {
+		diff, ok := layer.(*diffLayer)
+		if !ok {
+			return layer
+		}
+		layer = diff.parent
+	}
+}
+
+func (tree *SnapshotTree) dropLayers() {
+	for _, layer := range tree.layers {
+		switch l := layer.(type) {
+		case *diskLayer:
+			l.stale = true
+		case *diffLayer:
+			l.stale = true
+		}
+	}
+	tree.disk = nil
+	tree.layers = make(map[common.Hash]snapshotLayer)
+}
+
+func (tree *SnapshotTree) wipeObjects() error {
+	prefix := rawdbv2.GetStateSnapshotPrefix(tree.namespace)
+	it := tree.db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+	batch := tree.db.NewBatch()
+	for it.Next() {
+		if err := batch.Delete(common.CopyBytes(it.Key())); err != nil {
+			return err
+		}
+		if batch.ValueSize() >= incdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
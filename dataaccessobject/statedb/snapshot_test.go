@@ -0,0 +1,139 @@
+package statedb
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSnapshotTestDB(t *testing.T) (incdb.Database, DatabaseAccessWarper) {
+	dbPath, err := ioutil.TempDir(os.TempDir(), "test_statedb_snapshot_")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dbPath) })
+	diskDB, err := incdb.Open("leveldb", dbPath)
+	assert.Nil(t, err)
+	t.Cleanup(func() { diskDB.Close() })
+	return diskDB, NewDatabaseAccessWarper(diskDB)
+}
+
+func commitSnapshotTest(t *testing.T, sDB *StateDB) common.Hash {
+	root, err := sDB.Commit(true)
+	assert.Nil(t, err)
+	assert.Nil(t, sDB.Database().TrieDB().Commit(root, false))
+	return root
+}
+
+func TestSnapshotTree(t *testing.T) {
+	diskDB, warper := newSnapshotTestDB(t)
+	tree, err := NewSnapshotTree(diskDB, "test", 2)
+	assert.Nil(t, err)
+
+	// a statedb at the empty root starts the snapshot
+	sDB, err := NewWithPrefixTrie(emptyRoot, warper)
+	assert.Nil(t, err)
+	assert.Nil(t, sDB.AttachSnapshot(tree))
+	assert.Equal(t, emptyRoot, sDB.Snapshot().Root())
+	serialNumbers := testGenerateSerialNumberList(20)
+	assert.Nil(t, StoreSerialNumbers(sDB, common.PRVCoinID, serialNumbers, 0))
+	root := commitSnapshotTest(t, sDB)
+	assert.Equal(t, root, sDB.Snapshot().Root())
+
+	// without a snapshot the statedb reads the trie
+	other, err := NewSnapshotTree(diskDB, "other", 2)
+	assert.Nil(t, err)
+	sDB, err = NewWithPrefixTrie(root, warper)
+	assert.Nil(t, err)
+	assert.Equal(t, ErrSnapshotNotFound, sDB.AttachSnapshot(other))
+	assert.Nil(t, sDB.Snapshot())
+	has, err := HasSerialNumber(sDB, common.ConfidentialAssetID, serialNumbers[1], 0)
+	assert.Nil(t, err)
+	assert.True(t, has)
+
+	count, err := tree.Rebuild(root, warper)
+	assert.Nil(t, err)
+	assert.Equal(t, 20, count)
+	check, err := tree.Verify(root, warper, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 20, check.Objects)
+	assert.Empty(t, check.Mismatches)
+
+	// commits on top of the disk layer add diff layers
+	sDB, err = NewWithPrefixTrie(root, warper)
+	assert.Nil(t, err)
+	assert.Nil(t, sDB.AttachSnapshot(tree))
+	removedKey := GenerateSerialNumberObjectKey(common.ConfidentialAssetID, 0, serialNumbers[0])
+	sDB.MarkDeleteStateObject(SerialNumberObjectType, removedKey)
+	added := testGenerateSerialNumberList(1)
+	assert.Nil(t, StoreSerialNumbers(sDB, common.PRVCoinID, added, 0))
+	root1 := commitSnapshotTest(t, sDB)
+	assert.Equal(t, root1, sDB.Snapshot().Root())
+
+	value, err := sDB.Snapshot().Get(removedKey)
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+	has, err = HasSerialNumber(sDB, common.ConfidentialAssetID, added[0], 0)
+	assert.Nil(t, err)
+	assert.True(t, has)
+	has, err = HasSerialNumber(sDB, common.ConfidentialAssetID, serialNumbers[0], 0)
+	assert.Nil(t, err)
+	assert.False(t, has)
+	check, err = tree.Verify(root1, warper, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, check.Mismatches)
+
+	// a fork of the disk layer
+	fork, err := NewWithPrefixTrie(root, warper)
+	assert.Nil(t, err)
+	assert.Nil(t, fork.AttachSnapshot(tree))
+	assert.Nil(t, StoreSerialNumbers(fork, common.PRVCoinID, testGenerateSerialNumberList(1), 0))
+	forkRoot := commitSnapshotTest(t, fork)
+	assert.NotNil(t, tree.Snapshot(forkRoot))
+
+	// the third layer flattens the first one into the disk layer and drops
+	// the fork
+	copied := sDB.Copy()
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, StoreSerialNumbers(sDB, common.PRVCoinID, testGenerateSerialNumberList(1), 0))
+		commitSnapshotTest(t, sDB)
+	}
+	diskRoot, ok := tree.DiskRoot()
+	assert.True(t, ok)
+	assert.Equal(t, root1, diskRoot)
+	assert.Nil(t, tree.Snapshot(forkRoot))
+	assert.Nil(t, tree.Snapshot(root))
+	check, err = tree.Verify(root1, warper, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, check.Mismatches)
+	check, err = tree.Verify(sDB.Snapshot().Root(), warper, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, check.Mismatches)
+	// the copy holds the flattened layer, which is stale, and reads the trie
+	has, err = HasSerialNumber(copied, common.ConfidentialAssetID, added[0], 0)
+	assert.Nil(t, err)
+	assert.True(t, has)
+
+	// a restart loses the diff layers, they are recovered from the disk layer
+	reloaded, err := NewSnapshotTree(diskDB, "test", 2)
+	assert.Nil(t, err)
+	sDB, err = NewWithPrefixTrie(sDB.Snapshot().Root(), warper)
+	assert.Nil(t, err)
+	assert.Nil(t, sDB.AttachSnapshot(reloaded))
+	assert.NotNil(t, sDB.Snapshot())
+	check, err = reloaded.Verify(sDB.Snapshot().Root(), warper, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, check.Mismatches)
+
+	// a corrupted disk layer is reported
+	addedKey := GenerateSerialNumberObjectKey(common.ConfidentialAssetID, 0, added[0])
+	assert.Nil(t, rawdbv2.DeleteStateSnapshotObject(diskDB, []byte("test"), addedKey))
+	check, err = reloaded.Verify(root1, warper, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(check.Mismatches))
+	assert.Equal(t, addedKey, check.Mismatches[0].Key)
+	assert.Nil(t, check.Mismatches[0].Snapshot)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/common/base58"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject"
 	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
 	"github.com/levietcuong2602/incognito-chain/privacy/key"
 	"github.com/levietcuong2602/incognito-chain/trie"
@@ -30,6 +31,13 @@ type StateDB struct {
 	stateObjectsPending map[common.Hash]struct{} // State objects finalized but not yet written to the trie
 	stateObjectsDirty   map[common.Hash]struct{} // State objects modified in the current execution
 
+	// Optional flat snapshot of the trie, see SnapshotTree. snapObjects holds
+	// the objects written to the trie since the root of snap, a nil value for
+	// a deleted one.
+	snaps       *SnapshotTree
+	snap        Snapshot
+	snapObjects map[common.Hash][]byte
+
 	// DB error.
 	// State objects are used by the consensus core which are
 	// unable to deal with database-level errors. Any error that occurs
@@ -83,9 +91,36 @@ func (stateDB *StateDB) Reset(root common.Hash) error {
 	stateDB.stateObjects = make(map[common.Hash]StateObject)
 	stateDB.stateObjectsPending = make(map[common.Hash]struct{})
 	stateDB.stateObjectsDirty = make(map[common.Hash]struct{})
+	if stateDB.snaps != nil {
+		stateDB.snap = stateDB.snaps.Snapshot(root)
+		stateDB.snapObjects = make(map[common.Hash][]byte)
+	}
 	return nil
 }
 
+// AttachSnapshot makes the reads of state objects use the layer of snaps at
+// the root of the statedb, which is recovered from the disk layer when it is
+// missing. A statedb at the empty root starts the snapshot of a tree which
+// has none. Without a layer the statedb keeps reading the trie.
+func (stateDB *StateDB) AttachSnapshot(snaps *SnapshotTree) error {
+	stateDB.snaps = snaps
+	stateDB.snapObjects = make(map[common.Hash][]byte)
+	root := stateDB.trie.Hash()
+	if _, ok := snaps.DiskRoot(); !ok && root == common.EmptyRoot {
+		if _, err := snaps.Rebuild(root, stateDB.db); err != nil {
+			return err
+		}
+	}
+	snap, err := snaps.Recover(root, stateDB.db)
+	stateDB.snap = snap
+	return err
+}
+
+// Snapshot returns the snapshot layer the statedb reads, nil when it has none
+func (stateDB *StateDB) Snapshot() Snapshot {
+	return stateDB.snap
+}
+
 func (stateDB *StateDB) ClearObjects() {
 	stateDB.stateObjects = make(map[common.Hash]StateObject)
 	stateDB.stateObjectsPending = make(map[common.Hash]struct{})
@@ -138,9 +173,29 @@ func (stateDB *StateDB) Commit(deleteEmptyObjects bool) (common.Hash, error) {
 		stateDB.stateObjectsDirty = make(map[common.Hash]struct{})
 	}
 	// Write the account trie changes, measuing the amount of wasted time
-	return stateDB.trie.Commit(func(leaf []byte, parent common.Hash) error {
+	root, err := stateDB.trie.Commit(func(leaf []byte, parent common.Hash) error {
 		return nil
 	})
+	if err != nil {
+		return root, err
+	}
+	stateDB.updateSnapshot(root)
+	return root, nil
+}
+
+// updateSnapshot adds the layer of the committed root on top of the layer the
+// statedb was reading. A missing parent layer only disables the snapshot.
+func (stateDB *StateDB) updateSnapshot(root common.Hash) {
+	if stateDB.snaps == nil {
+		return
+	}
+	if stateDB.snap != nil && stateDB.snap.Root() != root {
+		if err := stateDB.snaps.Update(root, stateDB.snap.Root(), stateDB.snapObjects); err != nil {
+			dataaccessobject.Logger.Log.Warnf("Update state snapshot to root %v: %v", root.String(), err)
+		}
+	}
+	stateDB.snap = stateDB.snaps.Snapshot(root)
+	stateDB.snapObjects = make(map[common.Hash][]byte)
 }
 
 // Database return current database access warper
@@ -150,13 +205,23 @@ func (stateDB *StateDB) Database() DatabaseAccessWarper {
 
 // Copy duplicate statedb and return new statedb instance
 func (stateDB *StateDB) Copy() *StateDB {
-	return &StateDB{
+	res := &StateDB{
 		db:                  stateDB.db,
 		trie:                stateDB.db.CopyTrie(stateDB.trie),
 		stateObjects:        make(map[common.Hash]StateObject),
 		stateObjectsPending: make(map[common.Hash]struct{}),
 		stateObjectsDirty:   make(map[common.Hash]struct{}),
+		snaps:               stateDB.snaps,
+		snap:                stateDB.snap,
 	}
+	if stateDB.snaps != nil {
+		// the copied trie holds the objects written since the snapshot root
+		res.snapObjects = make(map[common.Hash][]byte, len(stateDB.snapObjects))
+		for key, value := range stateDB.snapObjects {
+			res.snapObjects[key] = value
+		}
+	}
+	return res
 }
 
 // Exist check existence of a state object in statedb
@@ -189,7 +254,7 @@ func (stateDB *StateDB) getDeletedStateObject(objectType int, hash common.Hash)
 		defer func(start time.Time) { stateDB.StateObjectReads += time.Since(start) }(time.Now())
 	}
 	// Load the object from the database
-	enc, err := stateDB.trie.TryGet(hash[:])
+	enc, err := stateDB.readObject(hash)
 	if len(enc) == 0 {
 		stateDB.setError(err)
 		return nil, nil
@@ -205,6 +270,20 @@ func (stateDB *StateDB) getDeletedStateObject(objectType int, hash common.Hash)
 	return obj, nil
 }
 
+// readObject reads the encoded object of hash from the snapshot when the
+// statedb has one, from the trie otherwise or when the snapshot layer is stale
+func (stateDB *StateDB) readObject(hash common.Hash) ([]byte, error) {
+	if stateDB.snap != nil {
+		if enc, ok := stateDB.snapObjects[hash]; ok {
+			return enc, nil
+		}
+		if enc, err := stateDB.snap.Get(hash); err == nil {
+			return enc, nil
+		}
+	}
+	return stateDB.trie.TryGet(hash[:])
+}
+
 // updateStateObject writes the given object to the trie.
 func (stateDB *StateDB) updateStateObject(obj StateObject) {
 	// Track the amount of time wasted on updating the account from the trie
@@ -215,6 +294,9 @@ func (stateDB *StateDB) updateStateObject(obj StateObject) {
 	addr := obj.GetHash()
 	data := obj.GetValueBytes()
 	stateDB.setError(stateDB.trie.TryUpdate(addr[:], data))
+	if stateDB.snaps != nil {
+		stateDB.snapObjects[addr] = data
+	}
 }
 
 // deleteStateObject removes the given object from the state trie.
@@ -226,6 +308,9 @@ func (stateDB *StateDB) deleteStateObject(obj StateObject) {
 	// Delete the account from the trie
 	addr := obj.GetHash()
 	stateDB.setError(stateDB.trie.TryDelete(addr[:]))
+	if stateDB.snaps != nil {
+		stateDB.snapObjects[addr] = nil
+	}
 }
 
 // createStateObject creates a new state object. If there is an existing account with
@@ -0,0 +1,76 @@
+// Package stateproof holds the Merkle proof of a state object against the
+// state root of a block, and its verifier. It only depends on the trie so a
+// light client can check a state read without trusting the node serving it.
+//
+// The block headers do not commit to the state roots, so the root a proof is
+// verified against must come from a source the client already trusts: the
+// roots of the beacon or shard best state (getbeaconbeststate,
+// getshardbeststate) of a node it runs, or the same root returned for the
+// block by several independent nodes. The root in the proof is not trusted.
+package stateproof
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/trie"
+)
+
+// StateProof proves the value of the state object of Key, or its absence, in
+// the state database of root Root
+type StateProof struct {
+	Root       common.Hash
+	ObjectType int
+	Key        common.Hash
+	Value      []byte   `json:",omitempty"` //encoded object, empty when the object is absent
+	Nodes      [][]byte //encoded trie nodes on the path from the root to the object
+}
+
+// Exists returns true when the proof claims the object exists
+func (proof *StateProof) Exists() bool {
+	return len(proof.Value) != 0
+}
+
+// Verify checks the nodes of the proof against its root and returns the
+// encoded object, nil when the proof shows the object is absent. The root
+// itself must be trusted by the caller, see VerifyAgainst.
+func (proof *StateProof) Verify() ([]byte, error) {
+	value, _, err := trie.VerifyProof(proof.Root, proof.Key[:], trie.NewProofSet(proof.Nodes))
+	if err != nil {
+		return nil, fmt.Errorf("invalid proof of key %v: %v", proof.Key.String(), err)
+	}
+	if !bytes.Equal(value, proof.Value) {
+		return nil, fmt.Errorf("proof of key %v does not match its value", proof.Key.String())
+	}
+	return value, nil
+}
+
+// VerifyAgainst is Verify for a root the caller trusts, e.g. read from a block
+// header it checked
+func (proof *StateProof) VerifyAgainst(root common.Hash) ([]byte, error) {
+	if proof.Root != root {
+		return nil, fmt.Errorf("proof root %v is not the trusted root %v", proof.Root.String(), root.String())
+	}
+	return proof.Verify()
+}
+
+// VerifyJSON decodes a StateProof from its JSON form and verifies it against
+// the trusted root, hex encoded
+func VerifyJSON(data []byte, root string) (*StateProof, error) {
+	if root == "" {
+		return nil, errors.New("a trusted root is required")
+	}
+	trustedRoot, err := common.Hash{}.NewHashFromStr(root)
+	if err != nil {
+		return nil, err
+	}
+	proof := &StateProof{}
+	if err := json.Unmarshal(data, proof); err != nil {
+		return nil, err
+	}
+	_, err = proof.VerifyAgainst(*trustedRoot)
+	return proof, err
+}
@@ -15,6 +15,9 @@ type Driver struct {
 	Open   func(args ...interface{}) (Database, error)
 }
 
+// DefaultDriver is the driver used when no database type is configured.
+const DefaultDriver = "leveldb"
+
 var drivers = make(map[string]*Driver)
 
 // RegisterDriver registers the driver d.
@@ -28,16 +31,37 @@ func RegisterDriver(d Driver) error {
 
 // Open opens the db connection.
 func Open(typ string, args ...interface{}) (Database, error) {
+	if typ == "" {
+		typ = DefaultDriver
+	}
 	d, exists := drivers[typ]
 	if !exists {
 		return nil, errors.Wrapf(errors.New("Driver is not registered"), typ)
 	}
-	return d.Open(args...)
+	db, err := d.Open(args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 0 {
+		if dbPath, ok := args[0].(string); ok {
+			db = instrument(db, dbPath)
+		}
+	}
+	return db, nil
+}
+
+// OpenReadOnly opens the db at dbPath without writing to it, for the tools
+// inspecting the database of a stopped node
+func OpenReadOnly(typ string, dbPath string) (Database, error) {
+	return Open(typ, dbPath, true)
 }
 
 // Open opens the db connection.
 func OpenMultipleDB(typ string, dbPath string) (map[int]Database, error) {
 	m := make(map[int]Database)
+	if typ == "" {
+		typ = DefaultDriver
+	}
 	d, exists := drivers[typ]
 	if !exists {
 		return nil, errors.Wrapf(errors.New("Driver is not registered"), typ)
@@ -53,7 +77,7 @@ func OpenMultipleDB(typ string, dbPath string) (map[int]Database, error) {
 		if err != nil {
 			return nil, errors.WithStack(fmt.Errorf("Open database error %+v", err))
 		}
-		m[i] = db
+		m[i] = instrument(db, newPath)
 	}
 	return m, nil
 }
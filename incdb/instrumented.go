@@ -0,0 +1,303 @@
+package incdb
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/levietcuong2602/incognito-chain/metrics"
+)
+
+// KeyClassifier returns the key family (prefix) a database key belongs to
+type KeyClassifier func(key []byte) string
+
+// Operations recorded by the instrumented database
+const (
+	OpHas         = "has"
+	OpGet         = "get"
+	OpPut         = "put"
+	OpDelete      = "delete"
+	OpBatchPut    = "batchput"
+	OpBatchDelete = "batchdelete"
+	OpBatchWrite  = "batchwrite"
+	OpIterate     = "iterate"
+
+	// batchFamily groups the batch writes, which mix keys of several families
+	batchFamily = "batch"
+)
+
+var instrumentation = struct {
+	classify KeyClassifier
+	rootDir  string
+	stats    map[string]*dbStats //key is database name
+	lock     sync.Mutex
+}{stats: map[string]*dbStats{}}
+
+// EnableMetrics makes Open and OpenMultipleDB return instrumented databases.
+// A database is named after its path relative to rootDir.
+func EnableMetrics(rootDir string, classify KeyClassifier) {
+	instrumentation.lock.Lock()
+	defer instrumentation.lock.Unlock()
+	instrumentation.classify = classify
+	instrumentation.rootDir = rootDir
+}
+
+// instrument wraps db when metrics are enabled
+func instrument(db Database, dbPath string) Database {
+	instrumentation.lock.Lock()
+	classify, rootDir := instrumentation.classify, instrumentation.rootDir
+	instrumentation.lock.Unlock()
+	if classify == nil || db == nil {
+		return db
+	}
+	name := filepath.Base(dbPath)
+	if rel, err := filepath.Rel(rootDir, dbPath); err == nil && !strings.HasPrefix(rel, "..") {
+		name = filepath.ToSlash(rel)
+	}
+	return NewInstrumentedDatabase(name, db, classify)
+}
+
+// opStats holds the metrics of one operation on one key family
+type opStats struct {
+	count   metrics.Counter
+	bytes   metrics.Counter
+	latency metrics.Timer
+}
+
+// dbStats holds the metrics of a database, it outlives the database handle so
+// that a reopened database keeps counting
+type dbStats struct {
+	name string
+	ops  map[string]map[string]*opStats //key family -> operation -> metrics
+	lock sync.RWMutex
+}
+
+func getDBStats(name string) *dbStats {
+	instrumentation.lock.Lock()
+	defer instrumentation.lock.Unlock()
+	s, ok := instrumentation.stats[name]
+	if !ok {
+		s = &dbStats{name: name, ops: map[string]map[string]*opStats{}}
+		instrumentation.stats[name] = s
+	}
+	return s
+}
+
+func (s *dbStats) get(family, op string) *opStats {
+	s.lock.RLock()
+	o, ok := s.ops[family][op]
+	s.lock.RUnlock()
+	if ok {
+		return o
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if o, ok = s.ops[family][op]; ok {
+		return o
+	}
+	if s.ops[family] == nil {
+		s.ops[family] = map[string]*opStats{}
+	}
+	name := "db/" + s.name + "/" + family + "/" + op
+	o = &opStats{
+		count:   metrics.GetOrRegisterCounter(name+"/count", nil),
+		bytes:   metrics.GetOrRegisterCounter(name+"/bytes", nil),
+		latency: metrics.GetOrRegisterTimer(name+"/latency", nil),
+	}
+	s.ops[family][op] = o
+	return o
+}
+
+func (s *dbStats) record(family, op string, size int, start time.Time) {
+	o := s.get(family, op)
+	o.count.Inc(1)
+	o.bytes.Inc(int64(size))
+	if !start.IsZero() {
+		o.latency.UpdateSince(start)
+	}
+}
+
+// OpStats is a snapshot of the metrics of one operation on one key family.
+// Latencies are in microseconds.
+type OpStats struct {
+	Database string
+	Family   string
+	Op       string
+	Count    int64
+	Bytes    int64
+	MeanUs   float64
+	P50Us    float64
+	P99Us    float64
+	MaxUs    float64
+}
+
+// MetricsSnapshot returns the metrics of every instrumented database, sorted
+// by database, key family and operation
+func MetricsSnapshot() []OpStats {
+	instrumentation.lock.Lock()
+	all := make([]*dbStats, 0, len(instrumentation.stats))
+	for _, s := range instrumentation.stats {
+		all = append(all, s)
+	}
+	instrumentation.lock.Unlock()
+
+	res := []OpStats{}
+	for _, s := range all {
+		s.lock.RLock()
+		for family, ops := range s.ops {
+			for op, o := range ops {
+				latency := o.latency.Snapshot()
+				ps := latency.Percentiles([]float64{0.5, 0.99})
+				res = append(res, OpStats{
+					Database: s.name,
+					Family:   family,
+					Op:       op,
+					Count:    o.count.Count(),
+					Bytes:    o.bytes.Count(),
+					MeanUs:   latency.Mean() / 1e3,
+					P50Us:    ps[0] / 1e3,
+					P99Us:    ps[1] / 1e3,
+					MaxUs:    float64(latency.Max()) / 1e3,
+				})
+			}
+		}
+		s.lock.RUnlock()
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Database != res[j].Database {
+			return res[i].Database < res[j].Database
+		}
+		if res[i].Family != res[j].Family {
+			return res[i].Family < res[j].Family
+		}
+		return res[i].Op < res[j].Op
+	})
+	return res
+}
+
+// InstrumentedDatabase records count, size and latency of the operations on
+// the wrapped database, grouped by key family
+type InstrumentedDatabase struct {
+	Database
+	classify KeyClassifier
+	stats    *dbStats
+}
+
+// NewInstrumentedDatabase wraps db, its metrics are registered in the default
+// metrics registry as db/<name>/<family>/<op>/{count,bytes,latency}
+func NewInstrumentedDatabase(name string, db Database, classify KeyClassifier) *InstrumentedDatabase {
+	return &InstrumentedDatabase{
+		Database: db,
+		classify: classify,
+		stats:    getDBStats(name),
+	}
+}
+
+func (db *InstrumentedDatabase) Has(key []byte) (bool, error) {
+	start := time.Now()
+	ok, err := db.Database.Has(key)
+	db.stats.record(db.classify(key), OpHas, 0, start)
+	return ok, err
+}
+
+func (db *InstrumentedDatabase) Get(key []byte) ([]byte, error) {
+	start := time.Now()
+	value, err := db.Database.Get(key)
+	db.stats.record(db.classify(key), OpGet, len(value), start)
+	return value, err
+}
+
+func (db *InstrumentedDatabase) Put(key []byte, value []byte) error {
+	start := time.Now()
+	err := db.Database.Put(key, value)
+	db.stats.record(db.classify(key), OpPut, len(key)+len(value), start)
+	return err
+}
+
+func (db *InstrumentedDatabase) Delete(key []byte) error {
+	start := time.Now()
+	err := db.Database.Delete(key)
+	db.stats.record(db.classify(key), OpDelete, 0, start)
+	return err
+}
+
+func (db *InstrumentedDatabase) NewBatch() Batch {
+	return &instrumentedBatch{Batch: db.Database.NewBatch(), db: db}
+}
+
+func (db *InstrumentedDatabase) NewIterator() Iterator {
+	return &instrumentedIterator{Iterator: db.Database.NewIterator(), db: db}
+}
+
+func (db *InstrumentedDatabase) NewIteratorWithStart(start []byte) Iterator {
+	return &instrumentedIterator{Iterator: db.Database.NewIteratorWithStart(start), db: db}
+}
+
+func (db *InstrumentedDatabase) NewIteratorWithPrefix(prefix []byte) Iterator {
+	return &instrumentedIterator{Iterator: db.Database.NewIteratorWithPrefix(prefix), db: db}
+}
+
+func (db *InstrumentedDatabase) NewIteratorWithPrefixStart(prefix []byte, start []byte) Iterator {
+	return &instrumentedIterator{Iterator: db.Database.NewIteratorWithPrefixStart(prefix, start), db: db}
+}
+
+type batchEntry struct {
+	family string
+	op     string
+	size   int
+}
+
+// instrumentedBatch records its entries when written, the latency of the
+// write is recorded once for the whole batch
+type instrumentedBatch struct {
+	Batch
+	db      *InstrumentedDatabase
+	entries []batchEntry
+}
+
+func (b *instrumentedBatch) Put(key []byte, value []byte) error {
+	b.entries = append(b.entries, batchEntry{b.db.classify(key), OpBatchPut, len(key) + len(value)})
+	return b.Batch.Put(key, value)
+}
+
+func (b *instrumentedBatch) Delete(key []byte) error {
+	b.entries = append(b.entries, batchEntry{b.db.classify(key), OpBatchDelete, 0})
+	return b.Batch.Delete(key)
+}
+
+func (b *instrumentedBatch) Write() error {
+	start := time.Now()
+	err := b.Batch.Write()
+	if err != nil {
+		return err
+	}
+	b.db.stats.record(batchFamily, OpBatchWrite, b.Batch.ValueSize(), start)
+	for _, e := range b.entries {
+		b.db.stats.record(e.family, e.op, e.size, time.Time{})
+	}
+	return nil
+}
+
+func (b *instrumentedBatch) Reset() {
+	b.entries = b.entries[:0]
+	b.Batch.Reset()
+}
+
+// instrumentedIterator records every item read, with the latency of the Next
+// call that reached it
+type instrumentedIterator struct {
+	Iterator
+	db *InstrumentedDatabase
+}
+
+func (it *instrumentedIterator) Next() bool {
+	start := time.Now()
+	ok := it.Iterator.Next()
+	if ok {
+		key := it.Iterator.Key()
+		it.db.stats.record(it.db.classify(key), OpIterate, len(key)+len(it.Iterator.Value()), start)
+	}
+	return ok
+}
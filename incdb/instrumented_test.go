@@ -0,0 +1,79 @@
+package incdb_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
+	"github.com/stretchr/testify/assert"
+)
+
+func firstByteFamily(key []byte) string {
+	return string(key[:1])
+}
+
+func findStats(stats []incdb.OpStats, database, family, op string) *incdb.OpStats {
+	for i := range stats {
+		if stats[i].Database == database && stats[i].Family == family && stats[i].Op == op {
+			return &stats[i]
+		}
+	}
+	return nil
+}
+
+func TestInstrumentedDatabase(t *testing.T) {
+	rootDir, err := ioutil.TempDir(os.TempDir(), "test_instrumented_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+	incdb.EnableMetrics(rootDir, firstByteFamily)
+	defer incdb.EnableMetrics("", nil)
+
+	db, err := incdb.Open(pebbledb.DriverName, filepath.Join(rootDir, "block", "beacon"))
+	assert.Nil(t, err)
+	defer db.Close()
+	_, ok := db.(*incdb.InstrumentedDatabase)
+	assert.True(t, ok)
+
+	assert.Nil(t, db.Put([]byte("a1"), []byte("value")))
+	_, err = db.Get([]byte("a1"))
+	assert.Nil(t, err)
+	_, err = db.Has([]byte("b1"))
+	assert.Nil(t, err)
+	batch := db.NewBatch()
+	assert.Nil(t, batch.Put([]byte("b1"), []byte("v")))
+	assert.Nil(t, batch.Put([]byte("b2"), []byte("v")))
+	assert.Nil(t, batch.Delete([]byte("a1")))
+	assert.Nil(t, batch.Write())
+	it := db.NewIteratorWithPrefix([]byte("b"))
+	for it.Next() {
+	}
+	it.Release()
+
+	stats := incdb.MetricsSnapshot()
+	for _, c := range []struct {
+		family, op   string
+		count, bytes int64
+	}{
+		{"a", incdb.OpPut, 1, 7},
+		{"a", incdb.OpGet, 1, 5},
+		{"b", incdb.OpHas, 1, 0},
+		{"b", incdb.OpBatchPut, 2, 6},
+		{"a", incdb.OpBatchDelete, 1, 0},
+		{"batch", incdb.OpBatchWrite, 1, -1},
+		{"b", incdb.OpIterate, 2, 6},
+	} {
+		s := findStats(stats, "block/beacon", c.family, c.op)
+		if !assert.NotNil(t, s, "%v %v", c.family, c.op) {
+			continue
+		}
+		assert.Equal(t, c.count, s.Count, "%v %v", c.family, c.op)
+		if c.bytes >= 0 {
+			assert.Equal(t, c.bytes, s.Bytes, "%v %v", c.family, c.op)
+		}
+	}
+}
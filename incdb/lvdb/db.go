@@ -40,13 +40,22 @@ func init() {
 }
 
 func openDriver(args ...interface{}) (incdb.Database, error) {
-	if len(args) != 1 {
+	if len(args) != 1 && len(args) != 2 {
 		return nil, errors.New("invalid arguments")
 	}
 	dbPath, ok := args[0].(string)
 	if !ok {
 		return nil, errors.New("expected db path")
 	}
+	if len(args) == 2 {
+		readOnly, ok := args[1].(bool)
+		if !ok {
+			return nil, errors.New("expected read only flag")
+		}
+		if readOnly {
+			return openReadOnly(dbPath)
+		}
+	}
 	return open(dbPath)
 }
 
@@ -67,6 +76,22 @@ func open(dbPath string) (incdb.Database, error) {
 	}
 	return &db{fn: dbPath, lvdb: lvdb, dbPath: dbPath}, nil
 }
+
+// openReadOnly opens the database without recovering or compacting it
+func openReadOnly(dbPath string) (incdb.Database, error) {
+	lvdb, err := leveldb.OpenFile(dbPath, &opt.Options{
+		OpenFilesCacheCapacity: 256,
+		BlockCacheCapacity:     4 * opt.MiB,
+		Filter:                 filter.NewBloomFilter(10),
+		ErrorIfMissing:         true,
+		ReadOnly:               true,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "levelvdb.OpenFile %s", dbPath)
+	}
+	return &db{fn: dbPath, lvdb: lvdb, dbPath: dbPath}, nil
+}
+
 func (db *db) GetPath() string {
 	return db.fn
 }
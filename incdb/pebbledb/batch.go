@@ -0,0 +1,143 @@
+package pebbledb
+
+import (
+	"github.com/cockroachdb/pebble"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+)
+
+// batch is a write-only pebble batch that commits changes to its host database
+// when Write is called. A batch cannot be used concurrently.
+type batch struct {
+	b    *pebble.Batch
+	db   *db
+	size int
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *batch) Put(key, value []byte) error {
+	if err := b.b.Set(key, value, nil); err != nil {
+		return err
+	}
+	b.size += len(value)
+	return nil
+}
+
+// Delete inserts the a key removal into the batch for later committing.
+func (b *batch) Delete(key []byte) error {
+	if err := b.b.Delete(key, nil); err != nil {
+		return err
+	}
+	b.size++
+	return nil
+}
+
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int {
+	return b.size
+}
+
+// Write flushes any accumulated data to disk.
+func (b *batch) Write() error {
+	b.db.lock.RLock()
+	defer b.db.lock.RUnlock()
+	return b.b.Commit(pebble.NoSync)
+}
+
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.b.Reset()
+	b.size = 0
+}
+
+// Replay replays the batch contents.
+func (b *batch) Replay(w incdb.KeyValueWriter) error {
+	reader := b.b.Reader()
+	for {
+		kind, k, v, ok := reader.Next()
+		if !ok {
+			return nil
+		}
+		var err error
+		switch kind {
+		case pebble.InternalKeyKindSet:
+			err = w.Put(k, v)
+		case pebble.InternalKeyKindDelete:
+			err = w.Delete(k)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// iterator wraps a pebble iterator so that it follows the incdb.Iterator
+// semantics: the iterator starts before the first pair and the first call to
+// Next positions it on the first pair.
+type iterator struct {
+	it       *pebble.Iterator
+	start    []byte
+	started  bool
+	released bool
+}
+
+func newIterator(it *pebble.Iterator, start []byte) *iterator {
+	return &iterator{it: it, start: start}
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *iterator) Next() bool {
+	if it.released {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		if it.start != nil {
+			return it.it.SeekGE(it.start)
+		}
+		return it.it.First()
+	}
+	return it.it.Next()
+}
+
+// Last moves the iterator to the last key/value pair.
+func (it *iterator) Last() bool {
+	if it.released {
+		return false
+	}
+	it.started = true
+	return it.it.Last()
+}
+
+// Error returns any accumulated error.
+func (it *iterator) Error() error {
+	if it.released {
+		return nil
+	}
+	return it.it.Error()
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (it *iterator) Key() []byte {
+	if it.released || !it.started || !it.it.Valid() {
+		return nil
+	}
+	return it.it.Key()
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (it *iterator) Value() []byte {
+	if it.released || !it.started || !it.it.Valid() {
+		return nil
+	}
+	return it.it.Value()
+}
+
+// Release releases associated resources. It can be called multiple times.
+func (it *iterator) Release() {
+	if it.released {
+		return
+	}
+	it.released = true
+	it.it.Close()
+}
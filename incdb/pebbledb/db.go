@@ -0,0 +1,449 @@
+package pebbledb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/bloom"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/pkg/errors"
+)
+
+// DriverName is the name this driver is registered with in incdb.
+const DriverName = "pebble"
+
+type db struct {
+	fn     string // filename for reporting
+	dbPath string
+	pdb    *pebble.DB
+	lock   sync.RWMutex
+}
+
+func init() {
+	driver := incdb.Driver{
+		DbType: DriverName,
+		Open:   openDriver,
+	}
+	if err := incdb.RegisterDriver(driver); err != nil {
+		panic("failed to register db driver")
+	}
+}
+
+func openDriver(args ...interface{}) (incdb.Database, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, errors.New("invalid arguments")
+	}
+	dbPath, ok := args[0].(string)
+	if !ok {
+		return nil, errors.New("expected db path")
+	}
+	if len(args) == 2 {
+		readOnly, ok := args[1].(bool)
+		if !ok {
+			return nil, errors.New("expected read only flag")
+		}
+		if readOnly {
+			return openReadOnly(dbPath)
+		}
+	}
+	return open(dbPath)
+}
+
+func newOptions() *pebble.Options {
+	cache := 8
+	handles := 256
+	return &pebble.Options{
+		Cache:                       pebble.NewCache(int64(cache / 2 * 1024 * 1024)),
+		MaxOpenFiles:                handles,
+		MemTableSize:                cache / 4 * 1024 * 1024, // Two of these are used internally
+		MemTableStopWritesThreshold: 2,
+		Levels: []pebble.LevelOptions{
+			{FilterPolicy: bloom.FilterPolicy(10)},
+		},
+	}
+}
+
+func openPebble(dbPath string) (*pebble.DB, error) {
+	opts := newOptions()
+	defer opts.Cache.Unref()
+	pdb, err := pebble.Open(dbPath, opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "pebble.Open %s", dbPath)
+	}
+	return pdb, nil
+}
+
+func open(dbPath string) (incdb.Database, error) {
+	if IsLevelDBDir(dbPath) {
+		return nil, errors.Errorf("%s contains a leveldb database, migrate it with the migratedb command first", dbPath)
+	}
+	pdb, err := openPebble(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	return &db{fn: dbPath, pdb: pdb, dbPath: dbPath}, nil
+}
+
+// openReadOnly opens the database without flushing or compacting it
+func openReadOnly(dbPath string) (incdb.Database, error) {
+	if IsLevelDBDir(dbPath) {
+		return nil, errors.Errorf("%s contains a leveldb database", dbPath)
+	}
+	opts := newOptions()
+	defer opts.Cache.Unref()
+	opts.ReadOnly = true
+	opts.ErrorIfNotExists = true
+	pdb, err := pebble.Open(dbPath, opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "pebble.Open %s", dbPath)
+	}
+	return &db{fn: dbPath, pdb: pdb, dbPath: dbPath}, nil
+}
+
+func (db *db) GetPath() string {
+	return db.fn
+}
+
+func (db *db) Close() error {
+	return errors.Wrap(db.pdb.Close(), "db.pdb.Close")
+}
+
+func (db *db) ReOpen() error {
+	pdb, err := openPebble(db.dbPath)
+	if err != nil {
+		return err
+	}
+	db.pdb = pdb
+	return nil
+}
+
+func (db *db) Has(key []byte) (bool, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	_, closer, err := db.pdb.Get(key)
+	if err == pebble.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	closer.Close()
+	return true, nil
+}
+
+func (db *db) Get(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	value, closer, err := db.pdb.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	// the returned slice is only valid until closer is closed
+	ret := make([]byte, len(value))
+	copy(ret, value)
+	closer.Close()
+	return ret, nil
+}
+
+func (db *db) Put(key, value []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	return db.pdb.Set(key, value, pebble.NoSync)
+}
+
+func (db *db) Delete(key []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	return db.pdb.Delete(key, pebble.NoSync)
+}
+
+// NewBatch creates a write-only key-value store that buffers changes to its host
+// database until a final write is called.
+func (db *db) NewBatch() incdb.Batch {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	return &batch{
+		b:  db.pdb.NewBatch(),
+		db: db,
+	}
+}
+
+// NewIterator creates a binary-alphabetical iterator over the entire keyspace
+// contained within the pebble database.
+func (db *db) NewIterator() incdb.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	return newIterator(db.pdb.NewIter(nil), nil)
+}
+
+// NewIteratorWithStart creates a binary-alphabetical iterator over a subset of
+// database content starting at a particular initial key (or after, if it does
+// not exist).
+func (db *db) NewIteratorWithStart(start []byte) incdb.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	return newIterator(db.pdb.NewIter(&pebble.IterOptions{LowerBound: start}), start)
+}
+
+// NewIteratorWithPrefix creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix.
+func (db *db) NewIteratorWithPrefix(prefix []byte) incdb.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	return newIterator(db.pdb.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: upperBound(prefix),
+	}), prefix)
+}
+
+// NewIteratorWithPrefixStart creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (db *db) NewIteratorWithPrefixStart(prefix []byte, start []byte) incdb.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	lower := append(append([]byte{}, prefix...), start...)
+	return newIterator(db.pdb.NewIter(&pebble.IterOptions{
+		LowerBound: lower,
+		UpperBound: upperBound(prefix),
+	}), lower)
+}
+
+// Stat returns a particular internal stat of the database. Pebble does not
+// expose leveldb style properties, the whole metrics report is returned instead.
+func (db *db) Stat(property string) (string, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	return db.pdb.Metrics().String(), nil
+}
+
+// Compact flattens the underlying data store for the given key range. In essence,
+// deleted and overwritten versions are discarded, and the data is rearranged to
+// reduce the cost of operations needed to access them.
+//
+// A nil start is treated as a key before all keys in the data store; a nil limit
+// is treated as a key after all keys in the data store. If both is nil then it
+// will compact entire data store.
+func (db *db) Compact(start []byte, limit []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	if start == nil || limit == nil {
+		it := db.pdb.NewIter(nil)
+		if start == nil && it.First() {
+			start = append([]byte{}, it.Key()...)
+		}
+		if limit == nil && it.Last() {
+			limit = append(append([]byte{}, it.Key()...), 0)
+		}
+		if err := it.Close(); err != nil {
+			return err
+		}
+		// empty database, nothing to compact
+		if start == nil || limit == nil {
+			return nil
+		}
+	}
+	return db.pdb.Compact(start, limit)
+}
+
+// Path returns the path to the database directory.
+func (db *db) Path() string {
+	return db.fn
+}
+
+func (db *db) PreloadBackup(backupFile string) error {
+	err := uncompress(backupFile, db.dbPath+"_")
+	if err != nil {
+		return err
+	}
+
+	incdb.Logger.Log.Infof("remove %v", db.dbPath)
+	err = os.RemoveAll(db.dbPath)
+	if err != nil {
+		return err
+	}
+	incdb.Logger.Log.Infof("rename %v", db.dbPath)
+	err = os.Rename(db.dbPath+"_", db.dbPath)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *db) LatestBackup(path string) (int, string) {
+	backupFolder := filepath.Join(db.dbPath, path)
+	files, err := ioutil.ReadDir(backupFolder)
+	if err != nil {
+		return 0, ""
+	}
+	if len(files) == 0 {
+		return 0, ""
+	}
+	latestBackupEpoch := 0
+	//Get max epoch
+	for _, file := range files {
+		epoch, err := strconv.Atoi(file.Name())
+		if err != nil {
+			return 0, ""
+		}
+		if epoch > latestBackupEpoch {
+			latestBackupEpoch = epoch
+		}
+	}
+
+	return latestBackupEpoch, fmt.Sprintf("%v/%v", backupFolder, latestBackupEpoch)
+}
+
+func (db *db) RemoveBackup(backupFile string) {
+	backupFile = filepath.Join(db.dbPath, backupFile)
+	os.Remove(backupFile)
+}
+
+func (db *db) Backup(backupFile string) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	backupFile = filepath.Join(db.dbPath, backupFile)
+
+	if err := os.MkdirAll(filepath.Dir(backupFile), 0700); err != nil {
+		return err
+	}
+
+	if err := db.pdb.Flush(); err != nil {
+		return err
+	}
+	if err := db.Close(); err != nil {
+		return err
+	}
+
+	err := common.CompressDatabase(db.dbPath, backupFile)
+	if err != nil {
+		return err
+	}
+
+	if err := db.ReOpen(); err != nil {
+		panic(err)
+	}
+
+	if err := removeUnusedBackupDatabase(backupFile); err != nil {
+		panic(err)
+	}
+
+	return nil
+}
+
+func (db *db) Clear() error {
+	files, err := filepath.Glob(filepath.Join(db.dbPath, "*"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		err = os.RemoveAll(file)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeUnusedBackupDatabase ...
+// for remove unused databases in backup folder
+func removeUnusedBackupDatabase(filePath string) error {
+	latestEpoch, err := strconv.Atoi(filepath.Base(filePath))
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(filePath)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	//Get file name and compare with latest epoch
+	for _, file := range files {
+		epoch, err := strconv.Atoi(file.Name())
+		if err != nil {
+			return err
+		}
+		if epoch != latestEpoch && epoch != latestEpoch-1 {
+			err = os.Remove(filepath.Join(dir, file.Name()))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Uncompress file from zip file
+func uncompress(srcPath, desPath string) error {
+	incdb.Logger.Log.Infof("start decompress %v", srcPath)
+	if err := os.RemoveAll(desPath); err != nil {
+		return err
+	}
+	//Create new data
+	if err := os.MkdirAll(desPath, 0700); err != nil {
+		return err
+	}
+
+	err := common.DecompressDatabaseBackup(srcPath, desPath)
+	if err != nil {
+		return err
+	}
+
+	incdb.Logger.Log.Infof("done decompress %v", desPath)
+	return nil
+}
+
+// upperBound returns the smallest key that is greater than every key with the
+// given prefix, or nil if no such key exists (prefix is empty or all 0xff).
+func upperBound(prefix []byte) []byte {
+	limit := make([]byte, len(prefix))
+	copy(limit, prefix)
+	for i := len(limit) - 1; i >= 0; i-- {
+		limit[i]++
+		if limit[i] != 0 {
+			return limit[:i+1]
+		}
+	}
+	return nil
+}
+
+// IsLevelDBDir reports whether the directory holds a leveldb database: it has a
+// CURRENT file but no OPTIONS file.
+func IsLevelDBDir(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, "CURRENT")); err != nil {
+		return false
+	}
+	return !IsPebbleDir(dir)
+}
+
+// IsPebbleDir reports whether the directory looks like a pebble database, pebble
+// always writes an OPTIONS file next to its MANIFEST while leveldb does not.
+func IsPebbleDir(dir string) bool {
+	matches, err := filepath.Glob(filepath.Join(dir, "OPTIONS-*"))
+	if err != nil {
+		return false
+	}
+	return len(matches) > 0
+}
+
+// DetectDriver returns the driver of the database stored in dir, or def when
+// dir does not hold a database yet
+func DetectDriver(dir, def string) string {
+	if IsPebbleDir(dir) {
+		return DriverName
+	}
+	if IsLevelDBDir(dir) {
+		return "leveldb"
+	}
+	return def
+}
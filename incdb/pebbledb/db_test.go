@@ -0,0 +1,154 @@
+package pebbledb_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	_ "github.com/levietcuong2602/incognito-chain/incdb/lvdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestDB(t *testing.T) (incdb.Database, string) {
+	dbPath, err := ioutil.TempDir(os.TempDir(), "test_pebble_")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %+v", err)
+	}
+	db, err := incdb.Open(pebbledb.DriverName, dbPath)
+	if err != nil {
+		t.Fatalf("could not open db path: %s, %+v", dbPath, err)
+	}
+	return db, dbPath
+}
+
+func TestDb_Base(t *testing.T) {
+	db, dbPath := openTestDB(t)
+	defer os.RemoveAll(dbPath)
+	defer db.Close()
+
+	err := db.Put([]byte("a"), []byte{1})
+	assert.Equal(t, nil, err)
+	result, err := db.Get([]byte("a"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []byte{1}, result)
+	has, err := db.Has([]byte("a"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, has)
+
+	err = db.Delete([]byte("a"))
+	assert.Equal(t, nil, err)
+	has, err = db.Has([]byte("a"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, has)
+	_, err = db.Get([]byte("a"))
+	assert.NotEqual(t, nil, err)
+	assert.True(t, pebbledb.IsPebbleDir(dbPath))
+}
+
+func TestDb_BatchAndIterator(t *testing.T) {
+	db, dbPath := openTestDB(t)
+	defer os.RemoveAll(dbPath)
+	defer db.Close()
+
+	batch := db.NewBatch()
+	for _, k := range []string{"abc1", "abc2", "abd1", "b"} {
+		assert.Equal(t, nil, batch.Put([]byte(k), []byte(k)))
+	}
+	assert.Equal(t, 13, batch.ValueSize())
+	assert.Equal(t, nil, batch.Write())
+	batch.Reset()
+	assert.Equal(t, 0, batch.ValueSize())
+
+	collect := func(it incdb.Iterator) []string {
+		defer it.Release()
+		res := []string{}
+		for it.Next() {
+			res = append(res, string(it.Key()))
+			assert.Equal(t, string(it.Key()), string(it.Value()))
+		}
+		assert.Equal(t, nil, it.Error())
+		return res
+	}
+	assert.Equal(t, []string{"abc1", "abc2", "abd1", "b"}, collect(db.NewIterator()))
+	assert.Equal(t, []string{"abd1", "b"}, collect(db.NewIteratorWithStart([]byte("abd"))))
+	assert.Equal(t, []string{"abc1", "abc2"}, collect(db.NewIteratorWithPrefix([]byte("abc"))))
+	assert.Equal(t, []string{"abc2", "abd1"}, collect(db.NewIteratorWithPrefixStart([]byte("ab"), []byte("c2"))))
+
+	it := db.NewIterator()
+	assert.True(t, it.Last())
+	assert.Equal(t, "b", string(it.Key()))
+	it.Release()
+	it.Release()
+
+	assert.Equal(t, nil, db.Compact(nil, nil))
+}
+
+func TestDb_ReOpenAndClear(t *testing.T) {
+	db, dbPath := openTestDB(t)
+	defer os.RemoveAll(dbPath)
+
+	assert.Equal(t, nil, db.Put([]byte("k"), []byte("v")))
+	assert.Equal(t, nil, db.Close())
+	assert.Equal(t, nil, db.ReOpen())
+	v, err := db.Get([]byte("k"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "v", string(v))
+	assert.Equal(t, nil, db.Close())
+
+	assert.Equal(t, nil, db.Clear())
+	assert.Equal(t, nil, db.ReOpen())
+	has, err := db.Has([]byte("k"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, has)
+	db.Close()
+}
+
+func TestDb_RefuseLevelDBDir(t *testing.T) {
+	dbPath, err := ioutil.TempDir(os.TempDir(), "test_pebble_")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %+v", err)
+	}
+	defer os.RemoveAll(dbPath)
+	lvdb, err := incdb.Open("leveldb", dbPath)
+	assert.Equal(t, nil, err)
+	lvdb.Close()
+
+	assert.True(t, pebbledb.IsLevelDBDir(dbPath))
+	_, err = incdb.Open(pebbledb.DriverName, dbPath)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestDb_WriteDuringBackup(t *testing.T) {
+	db, dbPath := openTestDB(t)
+	defer os.RemoveAll(dbPath)
+	defer db.Close()
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			key := []byte{byte(i), byte(i >> 8)}
+			assert.Equal(t, nil, db.Put(key, key))
+			_, err := db.Has(key)
+			assert.Equal(t, nil, err)
+			batch := db.NewBatch()
+			assert.Equal(t, nil, batch.Delete(key))
+			assert.Equal(t, nil, batch.Write())
+		}
+	}()
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, nil, db.Backup(filepath.Join("backup", strconv.Itoa(i))))
+	}
+	close(stop)
+	<-done
+}
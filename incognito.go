@@ -11,7 +11,9 @@ import (
 	"runtime/debug"
 	"strconv"
 
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/migration"
 	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdb_consensus"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
 	"github.com/levietcuong2602/incognito-chain/metadata/evmcaller"
 	"github.com/levietcuong2602/incognito-chain/pruner"
 
@@ -27,6 +29,7 @@ import (
 	_ "github.com/levietcuong2602/incognito-chain/databasemp/lvdb"
 	"github.com/levietcuong2602/incognito-chain/incdb"
 	_ "github.com/levietcuong2602/incognito-chain/incdb/lvdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
 	"github.com/levietcuong2602/incognito-chain/limits"
 	btcrelaying "github.com/levietcuong2602/incognito-chain/relaying/btc"
 	"github.com/levietcuong2602/incognito-chain/wallet"
@@ -111,13 +114,21 @@ func mainMaster(serverChan chan<- *Server) error {
 	if interruptRequested(interrupt) {
 		return nil
 	}
-	db, err := incdb.OpenMultipleDB("leveldb", filepath.Join(cfg.DataDir, cfg.DatabaseDir))
+	if cfg.DatabaseMetrics {
+		incdb.EnableMetrics(cfg.DataDir, rawdbv2.KeyFamily)
+	}
+	db, err := incdb.OpenMultipleDB(cfg.DatabaseDriver, filepath.Join(cfg.DataDir, cfg.DatabaseDir))
 	// Create db and use it.
 	if err != nil {
 		Logger.log.Error("could not open connection to leveldb")
 		Logger.log.Error(err)
 		panic(err)
 	}
+	if _, err := migration.MigrateAll(db, migration.Options{}); err != nil {
+		Logger.log.Error("could not migrate database schema")
+		Logger.log.Error(err)
+		panic(err)
+	}
 
 	p := pruner.NewPrunerManager(db)
 	if p == nil { //cannot init pruner
@@ -130,7 +141,9 @@ func mainMaster(serverChan chan<- *Server) error {
 	}
 
 	// Create db for mempool and use it
-	consensusDB, err := incdb.Open("leveldb", filepath.Join(cfg.DataDir, "consensus"))
+	// an existing database keeps its driver, a new one uses the configured driver
+	consensusDBPath := filepath.Join(cfg.DataDir, "consensus")
+	consensusDB, err := incdb.Open(pebbledb.DetectDriver(consensusDBPath, cfg.DatabaseDriver), consensusDBPath)
 	if err != nil {
 		Logger.log.Error("could not open connection to leveldb")
 		Logger.log.Error(err)
@@ -200,7 +213,8 @@ func mainMaster(serverChan chan<- *Server) error {
 	useOutcoinDb := len(cfg.UseOutcoinDatabase) >= 1
 	var outcoinDb *incdb.Database = nil
 	if useOutcoinDb {
-		temp, err := incdb.Open("leveldb", filepath.Join(cfg.DataDir, cfg.OutcoinDatabaseDir))
+		outcoinDBPath := filepath.Join(cfg.DataDir, cfg.OutcoinDatabaseDir)
+		temp, err := incdb.Open(pebbledb.DetectDriver(outcoinDBPath, cfg.DatabaseDriver), outcoinDBPath)
 		if err != nil {
 			Logger.log.Error("could not open leveldb instance for coin storing")
 		}
@@ -13,6 +13,7 @@ const (
 	SET_ACTION                     = "set"
 	RETURN_ACTION                  = "return"
 	UNSTAKE_ACTION                 = "unstake"
+	EQUIVOCATION_ACTION            = "equivocation"
 	ADD_STAKING_ACTION             = "addstake"
 	RETURN_BEACON_ACTION           = "returnb"
 	SHARD_INST                     = "shard"
@@ -38,6 +38,7 @@ func IsConsensusInstruction(action string) bool {
 		action == SET_ACTION ||
 		action == SWAP_SHARD_ACTION ||
 		action == UNSTAKE_ACTION ||
+		action == EQUIVOCATION_ACTION ||
 		action == ACCEPT_BLOCK_REWARD_V3_ACTION ||
 		action == SHARD_RECEIVE_REWARD_V3_ACTION ||
 		action == FINISH_SYNC_ACTION ||
@@ -105,6 +106,8 @@ func ValidateAndImportInstructionFromString(inst []string) (
 		buildInstructionFromString = BuildFinishSyncInstructionFromString
 	case UNSTAKE_ACTION:
 		buildInstructionFromString = BuildUnstakeInstructionFromString
+	case EQUIVOCATION_ACTION:
+		buildInstructionFromString = BuildEquivocationInstructionFromString
 	case ADD_STAKING_ACTION:
 		buildInstructionFromString = BuildAddStakingInstructionFromString
 	case RETURN_ACTION:
@@ -183,6 +186,13 @@ func BuildUnstakeInstructionFromString(instruction []string) (Instruction, error
 	return ImportUnstakeInstructionFromString(instruction), nil
 }
 
+func BuildEquivocationInstructionFromString(instruction []string) (Instruction, error) {
+	if err := ValidateEquivocationInstructionSanity(instruction); err != nil {
+		return nil, err
+	}
+	return ImportEquivocationInstructionFromString(instruction), nil
+}
+
 func BuildAddStakingInstructionFromString(instruction []string) (Instruction, error) {
 	if err := ValidateAddStakingInstructionSanity(instruction); err != nil {
 		return nil, err
@@ -0,0 +1,82 @@
+package instruction
+
+import (
+	"fmt"
+
+	"github.com/levietcuong2602/incognito-chain/incognitokey"
+)
+
+// EquivocationInstruction : Hold the evidence of a validator voting for two blocks in one timeslot
+// format: "equivocation", "offenderKey", "evidence"
+type EquivocationInstruction struct {
+	CommitteePublicKey       string
+	CommitteePublicKeyStruct incognitokey.CommitteePublicKey
+	Evidence                 string
+}
+
+// NewEquivocationInstructionWithValue : Constructor with value
+func NewEquivocationInstructionWithValue(committeePublicKey, evidence string) *EquivocationInstruction {
+	equivocationInstruction := &EquivocationInstruction{Evidence: evidence}
+	equivocationInstruction.SetCommitteePublicKey(committeePublicKey)
+	return equivocationInstruction
+}
+
+// NewEquivocationInstruction : Default constructor
+func NewEquivocationInstruction() *EquivocationInstruction {
+	return &EquivocationInstruction{}
+}
+
+func (e *EquivocationInstruction) SetCommitteePublicKey(publicKey string) error {
+	e.CommitteePublicKey = publicKey
+	return e.CommitteePublicKeyStruct.FromBase58(publicKey)
+}
+
+// GetType : Get type of equivocation instruction
+func (e *EquivocationInstruction) GetType() string {
+	return EQUIVOCATION_ACTION
+}
+
+// ToString : Convert class to string
+func (e *EquivocationInstruction) ToString() []string {
+	return []string{EQUIVOCATION_ACTION, e.CommitteePublicKey, e.Evidence}
+}
+
+// ValidateAndImportEquivocationInstructionFromString : Validate and import equivocation instruction from string
+func ValidateAndImportEquivocationInstructionFromString(instruction []string) (*EquivocationInstruction, error) {
+	if err := ValidateEquivocationInstructionSanity(instruction); err != nil {
+		return nil, err
+	}
+	return ImportEquivocationInstructionFromString(instruction), nil
+}
+
+// ImportEquivocationInstructionFromString : Import equivocation instruction from string
+func ImportEquivocationInstructionFromString(instruction []string) *EquivocationInstruction {
+	equivocationInstruction := NewEquivocationInstruction()
+	if len(instruction) == 3 {
+		equivocationInstruction.SetCommitteePublicKey(instruction[1])
+		equivocationInstruction.Evidence = instruction[2]
+	}
+	return equivocationInstruction
+}
+
+// ValidateEquivocationInstructionSanity : Validate equivocation instruction data type
+// the evidence itself is verified against the signatures of the votes by the beacon chain
+func ValidateEquivocationInstructionSanity(instruction []string) error {
+	if instruction == nil {
+		return fmt.Errorf("Instruction is null")
+	}
+	if len(instruction) != 3 {
+		return fmt.Errorf("invalid length, %+v", instruction)
+	}
+	if instruction[0] != EQUIVOCATION_ACTION {
+		return fmt.Errorf("invalid equivocation action, %+v", instruction)
+	}
+	publicKey := incognitokey.CommitteePublicKey{}
+	if err := publicKey.FromBase58(instruction[1]); err != nil {
+		return err
+	}
+	if len(instruction[2]) == 0 {
+		return fmt.Errorf("empty equivocation evidence, %+v", instruction)
+	}
+	return nil
+}
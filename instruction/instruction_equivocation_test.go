@@ -0,0 +1,76 @@
+package instruction
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateAndImportEquivocationInstructionFromString(t *testing.T) {
+	type args struct {
+		instruction []string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *EquivocationInstruction
+		wantErr bool
+	}{
+		{
+			name: "Null List Instruction",
+			args: args{
+				instruction: nil,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid Length",
+			args: args{
+				instruction: []string{EQUIVOCATION_ACTION, key1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Action is not equivocation",
+			args: args{
+				instruction: []string{UNSTAKE_ACTION, key1, "evidence"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid Committee Public Key",
+			args: args{
+				instruction: []string{EQUIVOCATION_ACTION, "key1", "evidence"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Empty Evidence",
+			args: args{
+				instruction: []string{EQUIVOCATION_ACTION, key1, ""},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid Input",
+			args: args{
+				instruction: []string{EQUIVOCATION_ACTION, key1, "evidence"},
+			},
+			want: NewEquivocationInstructionWithValue(key1, "evidence"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateAndImportEquivocationInstructionFromString(tt.args.instruction)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAndImportEquivocationInstructionFromString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateAndImportEquivocationInstructionFromString() = %v, want %v", got, tt.want)
+			}
+			if got != nil && !reflect.DeepEqual(got.ToString(), tt.args.instruction) {
+				t.Errorf("EquivocationInstruction.ToString() = %v, want %v", got.ToString(), tt.args.instruction)
+			}
+		})
+	}
+}
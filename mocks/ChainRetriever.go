@@ -8,6 +8,12 @@ import (
 
 	common "github.com/levietcuong2602/incognito-chain/metadata/common"
 
+	incdb "github.com/levietcuong2602/incognito-chain/incdb"
+
+	statedb "github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+
+	key "github.com/levietcuong2602/incognito-chain/privacy/key"
+
 	incognito_chaincommon "github.com/levietcuong2602/incognito-chain/common"
 
 	mock "github.com/stretchr/testify/mock"
@@ -544,3 +550,56 @@ func (_m *ChainRetriever) ValidatePortalRemoteAddresses(remoteAddresses map[stri
 
 	return r0, r1
 }
+
+// GetBeaconChainDatabase provides a mock function with no fields
+func (_m *ChainRetriever) GetBeaconChainDatabase() incdb.Database {
+	ret := _m.Called()
+
+	var r0 incdb.Database
+	if rf, ok := ret.Get(0).(func() incdb.Database); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(incdb.Database)
+		}
+	}
+
+	return r0
+}
+
+// GetDelegationRewardAmount provides a mock function with given fields: stateDB, pk
+func (_m *ChainRetriever) GetDelegationRewardAmount(stateDB *statedb.StateDB, pk key.PublicKey) (uint64, error) {
+	ret := _m.Called(stateDB, pk)
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*statedb.StateDB, key.PublicKey) uint64); ok {
+		r0 = rf(stateDB, pk)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(*statedb.StateDB, key.PublicKey) error); ok {
+		r1 = rf(stateDB, pk)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPdexv3Cached provides a mock function with given fields: _a0
+func (_m *ChainRetriever) GetPdexv3Cached(_a0 incognito_chaincommon.Hash) interface{} {
+	ret := _m.Called(_a0)
+
+	var r0 interface{}
+	if rf, ok := ret.Get(0).(func(incognito_chaincommon.Hash) interface{}); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interface{})
+		}
+	}
+
+	return r0
+}
@@ -403,6 +403,7 @@ func getMessagesForLayer(layer, role string, shardID []byte) []string {
 				wire.CmdTx,
 				wire.CmdPrivacyCustomToken,
 				wire.CmdMsgFeatureStat,
+				wire.CmdMsgEquivocation,
 			}
 
 		case common.SyncingRole:
@@ -434,6 +435,7 @@ func getMessagesForLayer(layer, role string, shardID []byte) []string {
 			wire.CmdBlockShard,
 			wire.CmdMsgFinishSync,
 			wire.CmdMsgFeatureStat,
+			wire.CmdMsgEquivocation,
 		}
 	default:
 		containShard := false
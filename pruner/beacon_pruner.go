@@ -0,0 +1,435 @@
+package pruner
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/trie"
+	"github.com/pkg/errors"
+)
+
+// BeaconPruner removes the nodes of the beacon consensus, feature, reward and
+// slash tries which are not reachable from the beacon views kept in database.
+// The consensus tries of the beacon blocks used as committee from block by the
+// shard views are kept too, shards still read their committees from them. So is
+// the state read by consensus at older heights: every height from the lowest
+// beacon height of the shard views (staking txs, return staking) and the epoch
+// boundaries (share price and committee of every epoch, for the rewards).
+type BeaconPruner struct {
+	//state
+	blockStorage *blockchain.BlockStorage
+	db           incdb.Database
+	stateBloom   *trie.StateBloom
+	bloomSize    uint64
+	finalHeight  uint64
+	bestView     *blockchain.BeaconBestState
+	keepBlocks   func() []common.Hash
+	shardHeight  func() uint64 //lowest beacon height of the shard views, 0 if unknown
+
+	//retention, the state of heights [retainFrom, finalHeight] is kept
+	retentionEpochs uint64
+	retainFrom      uint64
+	prunedHeight    uint64
+
+	//lock
+	lock             sync.Mutex
+	wg               sync.WaitGroup
+	beaconInsertLock *sync.Mutex
+
+	//report
+	lastTriggerTime      time.Time
+	lastProcessingMode   string
+	status               int
+	lastError            string
+	lastProcessingHeight uint64
+	storage              uint64
+	nodes                uint64
+}
+
+func NewBeaconPruner(db incdb.Database, blockStorage *blockchain.BlockStorage, keepBlocks func() []common.Hash, shardHeight func() uint64) *BeaconPruner {
+	bp := &BeaconPruner{
+		db:           db,
+		blockStorage: blockStorage,
+		keepBlocks:   keepBlocks,
+		shardHeight:  shardHeight,
+	}
+	bp.restoreStatus()
+	if bp.lastProcessingHeight == 0 {
+		bp.lastProcessingHeight = 1
+	}
+	return bp
+}
+
+func (s *BeaconPruner) SetBloomSize(size uint64) {
+	s.bloomSize = size
+}
+
+// SetRetentionEpochs keeps the state of the last epochs when pruning, 0 keeps
+// the state of the views only
+func (s *BeaconPruner) SetRetentionEpochs(epochs uint64) {
+	s.retentionEpochs = epochs
+}
+
+func (s *BeaconPruner) Stop() {
+	s.status = IDLE
+}
+
+func (s *BeaconPruner) InitBloomState() error {
+	//restore beacon views from database
+	allViews := []*blockchain.BeaconBestState{}
+	views, err := rawdbv2.GetBeaconViews(s.db)
+	if err != nil {
+		Logger.log.Errorf("debug Cannot see beacon views %v", err)
+		return err
+	}
+	err = json.Unmarshal(views, &allViews)
+	if err != nil {
+		Logger.log.Errorf("debug Cannot unmarshall beacon views %v", err)
+		return err
+	}
+	//collect tree nodes want to keep, add them to state bloom
+	if len(allViews) > 0 {
+		s.finalHeight = allViews[0].BeaconHeight
+		s.bestView = allViews[len(allViews)-1]
+	} else {
+		return errors.New("Cannot retrieve all beacon views")
+	}
+	s.retainFrom, err = retentionStartHeight(s.finalHeight, allViews[0].Epoch, s.retentionEpochs, beaconEpochOf(s.blockStorage))
+	if err != nil {
+		return err
+	}
+	//shards process the beacon blocks after their beacon height, reading the beacon state of the previous height
+	if s.shardHeight != nil {
+		if h := s.shardHeight(); h > 0 && h < s.retainFrom {
+			s.retainFrom = h
+		}
+	}
+	for _, v := range allViews {
+		err = s.addViewToBloom(v)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, height := range epochBoundaryHeights(allViews[0].Epoch) {
+		if height >= s.retainFrom {
+			break
+		}
+		if err := s.addHeightToBloom(height); err != nil {
+			return err
+		}
+	}
+
+	if s.keepBlocks != nil {
+		for _, hash := range s.keepBlocks() {
+			bRH, err := blockchain.GetBeaconRootsHashByBlockHash(s.db, hash)
+			if err != nil {
+				return errors.Wrapf(err, "cannot get roots of committee from block %v", hash.String())
+			}
+			if err := s.addRootToBloom(bRH.ConsensusStateDBRootHash); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *BeaconPruner) Prune(byHash bool) error {
+	s.lock.Lock()
+	if s.status != IDLE {
+		s.lock.Unlock()
+		return fmt.Errorf("Beacon is not ready! State: %v", s.status)
+	}
+	s.status = INIT
+
+	s.lastTriggerTime = time.Now()
+
+	err := s.InitBloomState()
+	if err != nil {
+		s.lastError = errors.Wrap(err, "init bloom state fail").Error()
+		s.stateBloom = nil
+		s.status = IDLE
+		s.lock.Unlock()
+		return err
+	}
+	s.status = PRUNING
+	//heights below the retention window are going to lose their state
+	if s.retainFrom > s.prunedHeight+1 {
+		s.prunedHeight = s.retainFrom - 1
+	}
+	s.saveStatus()
+	s.lock.Unlock()
+
+	if byHash {
+		s.lastProcessingMode = "hash"
+		Logger.log.Infof("[state-prune beacon] Start prune by hash")
+		s.pruneByHash()
+	} else {
+		s.lastProcessingMode = "height"
+		Logger.log.Infof("[state-prune beacon] Start prune by height")
+		s.PruneByHeight()
+	}
+	s.saveStatus()
+	s.status = CHECKING
+	s.stateBloom = nil
+	s.CheckDataIntegrity()
+	s.status = IDLE
+	return nil
+}
+
+func (s *BeaconPruner) PruneByHeight() error {
+	if s.finalHeight <= 1 {
+		s.lastError = ""
+		return nil
+	}
+	for height := s.lastProcessingHeight; height < s.retainFrom; height++ {
+		if s.status != PRUNING {
+			return nil
+		}
+		err := func() error {
+			s.LockInsertBeaconBlock() //lock insert beacon block
+			defer s.UnlockInsertBeaconBlock()
+			s.wg.Wait() //wait for all insert bloom task (in case we have new view)
+
+			//recheck if there is error when handle new view
+			if s.status != PRUNING {
+				return nil
+			}
+
+			storage, node, err := pruneBeaconByHeight(s.db, s.blockStorage, s.stateBloom, height)
+			s.storage += storage
+			s.nodes += node
+			if err != nil {
+				return err
+			}
+
+			if height%1000 == 0 {
+				Logger.log.Infof("[state-prune beacon] Finish prune for height %v delete totalNodes %v with storage %v", height, s.nodes, s.storage)
+				s.saveStatus()
+			}
+			s.lastProcessingHeight = height
+			return nil
+		}()
+
+		if err != nil {
+			s.lastError = errors.Wrap(err, "prune by height fail").Error()
+		} else {
+			s.lastError = ""
+		}
+	}
+	return nil
+}
+
+func (s *BeaconPruner) pruneByHash() error {
+	iter := s.db.NewIteratorWithPrefixStart(rawdbv2.GetBeaconRootsHashPrefix(), nil)
+	defer func() {
+		iter.Release()
+	}()
+	count := 0
+
+	// retrieve all state tree by beacon root hash prefix
+	// delete all nodes which are not in state bloom
+	for iter.Next() {
+		if s.status != PRUNING {
+			return nil
+		}
+
+		err := func() error {
+			s.LockInsertBeaconBlock() //lock insert beacon block
+			defer s.UnlockInsertBeaconBlock()
+			s.wg.Wait() //wait for all handle new view task (in case we have new view)
+
+			//recheck if there is error when handle new view
+			if s.status != PRUNING {
+				return nil
+			}
+
+			key := iter.Key()
+			rootHash := &blockchain.BeaconRootHash{}
+			err := json.Unmarshal(iter.Value(), rootHash)
+			if err != nil {
+				return err
+			}
+			storage, node, err := pruneBeaconStateDBs(s.db, s.stateBloom, rootHash)
+			s.storage += storage
+			s.nodes += node
+			if err != nil {
+				return err
+			}
+
+			if count%1000 == 0 {
+				Logger.log.Infof("[state-prune beacon] Finish prune for key %v totalKeys %v delete totalNodes %v with storage %v", key, count, s.nodes, s.storage)
+				s.saveStatus()
+			}
+			count++
+			return nil
+		}()
+
+		if err != nil {
+			s.lastError = err.Error()
+			return err
+		} else {
+			s.lastError = ""
+		}
+	}
+	return nil
+}
+
+func (s *BeaconPruner) addViewToBloom(v *blockchain.BeaconBestState) error {
+	Logger.log.Infof("[state-prune beacon] Start retrieve view %s at height %v", v.BestBlockHash.String(), v.BeaconHeight)
+	bRH := &blockchain.BeaconRootHash{
+		ConsensusStateDBRootHash: v.ConsensusStateDBRootHash,
+		FeatureStateDBRootHash:   v.FeatureStateDBRootHash,
+		RewardStateDBRootHash:    v.RewardStateDBRootHash,
+		SlashStateDBRootHash:     v.SlashStateDBRootHash,
+	}
+	for _, root := range beaconStateRoots(bRH) {
+		if err := s.addRootToBloom(root); err != nil {
+			return err
+		}
+	}
+	Logger.log.Infof("[state-prune beacon] Finish retrieve view %s at height %v", v.BestBlockHash.String(), v.BeaconHeight)
+
+	//the final view also keeps the state of the retention window before it
+	if v.BeaconHeight == s.finalHeight && s.retainFrom < s.finalHeight {
+		return s.addRetentionToBloom()
+	}
+	return nil
+}
+
+// addRetentionToBloom adds the state of every finalized beacon block of the
+// retention window to the state bloom
+func (s *BeaconPruner) addRetentionToBloom() error {
+	Logger.log.Infof("[state-prune beacon] Start retrieve retention window from height %v to %v", s.retainFrom, s.finalHeight-1)
+	for height := s.retainFrom; height < s.finalHeight; height++ {
+		h, err := s.blockStorage.GetFinalizedBeaconBlock(height)
+		if err != nil {
+			return errors.Wrapf(err, "cannot get finalized block at height %v", height)
+		}
+		bRH, err := blockchain.GetBeaconRootsHashByBlockHash(s.db, *h)
+		if err != nil {
+			return errors.Wrapf(err, "cannot get roots of block %v", h.String())
+		}
+		for _, root := range beaconStateRoots(bRH) {
+			if err := s.addRootToBloom(root); err != nil {
+				return err
+			}
+		}
+	}
+	Logger.log.Infof("[state-prune beacon] Finish retrieve retention window")
+	return nil
+}
+
+// epochBoundaryHeights returns, in increasing order, the beacon heights whose
+// state is read by the reward code for every epoch up to finalEpoch: the share
+// prices of the beginning of the epoch and the committee of the epoch
+func epochBoundaryHeights(finalEpoch uint64) []uint64 {
+	heights := []uint64{}
+	seen := map[uint64]bool{}
+	for epoch := uint64(1); epoch <= finalEpoch; epoch++ {
+		epochHeights := []uint64{blockchain.BeaconSharePriceHeight(epoch)}
+		if last := blockchain.GetLastBeaconHeightInEpoch(epoch); last > 1 {
+			epochHeights = append(epochHeights, last-1)
+		}
+		for _, height := range epochHeights {
+			if height > 0 && !seen[height] {
+				seen[height] = true
+				heights = append(heights, height)
+			}
+		}
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights
+}
+
+// addHeightToBloom adds the state of the finalized beacon block at height to the
+// state bloom. A state already dropped by an older prune is skipped.
+func (s *BeaconPruner) addHeightToBloom(height uint64) error {
+	h, err := s.blockStorage.GetFinalizedBeaconBlock(height)
+	if err != nil {
+		return errors.Wrapf(err, "cannot get finalized block at height %v", height)
+	}
+	bRH, err := blockchain.GetBeaconRootsHashByBlockHash(s.db, *h)
+	if err != nil {
+		return errors.Wrapf(err, "cannot get roots of block %v", h.String())
+	}
+	for _, root := range beaconStateRoots(bRH) {
+		if err := s.addRootToBloom(root); err != nil {
+			Logger.log.Warnf("[state-prune beacon] State %v at height %v is not complete, skip it: %v", root.String(), height, err)
+		}
+	}
+	return nil
+}
+
+func (s *BeaconPruner) addRootToBloom(root common.Hash) error {
+	stateDB, err := statedb.NewWithPrefixTrie(root, statedb.NewDatabaseAccessWarper(s.db))
+	if err != nil {
+		return err
+	}
+	//Retrieve all state tree for this state
+	if s.stateBloom == nil {
+		s.stateBloom, _ = trie.NewStateBloomWithSize(s.bloomSize)
+		_, err = stateDB.Retrieve(true, false, s.stateBloom, true)
+	} else {
+		_, err = stateDB.Retrieve(true, false, s.stateBloom, false)
+	}
+	return err
+}
+
+func (s *BeaconPruner) CheckDataIntegrity() {
+	bRH := &blockchain.BeaconRootHash{
+		ConsensusStateDBRootHash: s.bestView.ConsensusStateDBRootHash,
+		FeatureStateDBRootHash:   s.bestView.FeatureStateDBRootHash,
+		RewardStateDBRootHash:    s.bestView.RewardStateDBRootHash,
+		SlashStateDBRootHash:     s.bestView.SlashStateDBRootHash,
+	}
+	for _, root := range beaconStateRoots(bRH) {
+		sDB, err := statedb.NewWithPrefixTrie(root, statedb.NewDatabaseAccessWarper(s.db))
+		if err != nil {
+			panic(fmt.Sprintf("Something wrong when init beacon stateDB %v", root.String()))
+		}
+		if err := sDB.Recheck(); err != nil {
+			Logger.log.Errorf("[state-prune beacon] Recheck beacon root hash %v at height %v failed", root.String(), s.bestView.BeaconHeight)
+			Logger.log.Infof("[state-prune beacon] Prune data error! %v", err)
+			panic("Prune data error! Beacon Database corrupt!")
+		}
+	}
+}
+
+func (s *BeaconPruner) LockInsertBeaconBlock() {
+	if s.beaconInsertLock != nil {
+		s.beaconInsertLock.Lock()
+	}
+}
+
+func (s *BeaconPruner) UnlockInsertBeaconBlock() {
+	if s.beaconInsertLock != nil {
+		s.beaconInsertLock.Unlock()
+	}
+}
+
+func (s *BeaconPruner) handleNewView(beaconBestState *blockchain.BeaconBestState) {
+	s.wg.Add(1)
+	s.bestView = beaconBestState
+	go func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		defer s.wg.Done()
+		if s.status == PRUNING {
+			err := s.addViewToBloom(beaconBestState)
+			if err != nil {
+				s.lastError = errors.Wrap(err, "handle new view fail").Error()
+				s.status = IDLE
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,101 @@
+package pruner
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	_ "github.com/levietcuong2602/incognito-chain/incdb/lvdb"
+	"github.com/stretchr/testify/assert"
+)
+
+// storeTestBeaconChain commits one beacon state per height, each height
+// overwrites the same key so the root of a height is only reachable from it,
+// and returns the roots by height
+func storeTestBeaconChain(t *testing.T, db incdb.Database, blockStorage *blockchain.BlockStorage, finalHeight uint64) map[uint64]common.Hash {
+	stateDB, err := statedb.NewWithPrefixTrie(common.EmptyRoot, statedb.NewDatabaseAccessWarper(db))
+	assert.Nil(t, err)
+	assert.Nil(t, stateDB.SetStateObject(statedb.TestObjectType, common.HashH([]byte("shared")), []byte("shared")))
+	roots := map[uint64]common.Hash{}
+	for height := uint64(1); height <= finalHeight; height++ {
+		value := []byte{byte(height)}
+		assert.Nil(t, stateDB.SetStateObject(statedb.TestObjectType, common.HashH([]byte("height")), value))
+		root, err := stateDB.Commit(true)
+		assert.Nil(t, err)
+		assert.Nil(t, stateDB.Database().TrieDB().Commit(root, false))
+		roots[height] = root
+
+		blockHash := common.HashH(value)
+		assert.Nil(t, blockStorage.StoreFinalizedBeaconBlock(height, blockHash))
+		bRH := blockchain.BeaconRootHash{
+			ConsensusStateDBRootHash: root,
+			FeatureStateDBRootHash:   root,
+			RewardStateDBRootHash:    root,
+			SlashStateDBRootHash:     root,
+		}
+		assert.Nil(t, rawdbv2.StoreBeaconRootsHash(db, blockHash, bRH))
+	}
+	return roots
+}
+
+func TestBeaconPruner_KeepConsensusState(t *testing.T) {
+	Logger.Init(common.NewBackend(nil).Logger("test", true))
+	config.AbortParam()
+	config.Param().EpochParam.NumberOfBlockInEpoch = 5
+	config.Param().EpochParam.EpochV2BreakPoint = 1000
+	dir, err := ioutil.TempDir("", "beaconpruner")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	db, err := incdb.Open("leveldb", path.Join(dir, "beacon"))
+	assert.Nil(t, err)
+	defer db.Close()
+	blockStorage := blockchain.NewBlockStorage(db, path.Join(dir, "blockstorage"), common.BeaconChainID, false)
+
+	finalHeight := uint64(20)
+	roots := storeTestBeaconChain(t, db, blockStorage, finalHeight)
+	view := blockchain.NewBeaconBestState()
+	view.BeaconHeight = finalHeight
+	view.Epoch = 4
+	view.ConsensusStateDBRootHash = roots[finalHeight]
+	view.FeatureStateDBRootHash = roots[finalHeight]
+	view.RewardStateDBRootHash = roots[finalHeight]
+	view.SlashStateDBRootHash = roots[finalHeight]
+	views, err := json.Marshal([]*blockchain.BeaconBestState{view})
+	assert.Nil(t, err)
+	assert.Nil(t, rawdbv2.StoreBeaconViews(db, views))
+
+	//the lowest shard view confirmed beacon height 17
+	pruner := NewBeaconPruner(db, blockStorage, nil, func() uint64 { return 17 })
+	pruner.SetBloomSize(1)
+	assert.Nil(t, pruner.Prune(false))
+
+	//share price (1, 6, 11, 16) and committee (4, 9, 14, 19) heights of epochs 1-4, and the heights after the shard views
+	kept := map[uint64]bool{1: true, 4: true, 6: true, 9: true, 11: true, 14: true, 16: true, 17: true, 18: true, 19: true, 20: true}
+	for height := uint64(1); height <= finalHeight; height++ {
+		stateDB, err := statedb.NewWithPrefixTrie(roots[height], statedb.NewDatabaseAccessWarper(db))
+		if kept[height] {
+			assert.Nil(t, err, "height %v", height)
+			assert.Nil(t, stateDB.Recheck(), "height %v", height)
+		} else {
+			assert.NotNil(t, err, "height %v should be pruned", height)
+		}
+	}
+
+	//a second prune skips the heights already pruned
+	pruner.lastProcessingHeight = 1
+	assert.Nil(t, pruner.Prune(false))
+	for height := range kept {
+		stateDB, err := statedb.NewWithPrefixTrie(roots[height], statedb.NewDatabaseAccessWarper(db))
+		assert.Nil(t, err, "height %v", height)
+		assert.Nil(t, stateDB.Recheck(), "height %v", height)
+	}
+}
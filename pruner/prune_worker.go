@@ -40,6 +40,50 @@ func pruneTxStateDB(db incdb.Database, stateBloom *trie.StateBloom, sRH *blockch
 	return storage, node, nil
 }
 
+func pruneBeaconByHeight(db incdb.Database, blockStorage *blockchain.BlockStorage, stateBloom *trie.StateBloom, height uint64) (uint64, uint64, error) {
+	h, err := blockStorage.GetFinalizedBeaconBlock(height)
+	if err != nil {
+		return 0, 0, err
+	}
+	bRH, err := blockchain.GetBeaconRootsHashByBlockHash(db, *h)
+	if err != nil {
+		return 0, 0, err
+	}
+	return pruneBeaconStateDBs(db, stateBloom, bRH)
+}
+
+// pruneBeaconStateDBs removes the nodes of the consensus, feature, reward and
+// slash tries of a beacon block which are not in the state bloom
+func pruneBeaconStateDBs(db incdb.Database, stateBloom *trie.StateBloom, bRH *blockchain.BeaconRootHash) (uint64, uint64, error) {
+	var storage, nodes uint64
+	for _, root := range beaconStateRoots(bRH) {
+		sDB, err := statedb.NewWithPrefixTrie(root, statedb.NewDatabaseAccessWarper(db))
+		if err != nil {
+			continue
+		}
+		keysShouldBeRemoved, err := sDB.Retrieve(false, true, stateBloom, false)
+		if err != nil {
+			return storage, nodes, err
+		}
+		s, n, err := removeNodes(db, keysShouldBeRemoved)
+		storage += s
+		nodes += n
+		if err != nil {
+			return storage, nodes, err
+		}
+	}
+	return storage, nodes, nil
+}
+
+func beaconStateRoots(bRH *blockchain.BeaconRootHash) []common.Hash {
+	return []common.Hash{
+		bRH.ConsensusStateDBRootHash,
+		bRH.FeatureStateDBRootHash,
+		bRH.RewardStateDBRootHash,
+		bRH.SlashStateDBRootHash,
+	}
+}
+
 // removeNodes after removeNodes keys map will be reset to empty value
 func removeNodes(db incdb.Database, keysShouldBeRemoved map[common.Hash]struct{}) (uint64, uint64, error) {
 	var storage, count uint64
@@ -12,6 +12,7 @@ import (
 	"github.com/levietcuong2602/incognito-chain/blockchain"
 	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
 	"github.com/levietcuong2602/incognito-chain/incdb"
 	"golang.org/x/sync/semaphore"
 )
@@ -21,8 +22,9 @@ type Config struct {
 }
 
 type PrunerManager struct {
-	ShardPruner map[int]*ShardPruner
-	JobRquest   map[int]*Config
+	ShardPruner  map[int]*ShardPruner
+	BeaconPruner *BeaconPruner
+	JobRquest    map[int]*Config //key is shardID or common.BeaconChainID
 }
 
 func NewPrunerManager(db map[int]incdb.Database) *PrunerManager {
@@ -33,10 +35,55 @@ func NewPrunerManager(db map[int]incdb.Database) *PrunerManager {
 	for sid := 0; sid < common.MaxShardNumber; sid++ {
 		prunerManager.ShardPruner[sid] = NewShardPruner(sid, db[sid], nil)
 	}
+	prunerManager.BeaconPruner = NewBeaconPruner(db[common.BeaconChainID], nil, prunerManager.committeeFromBlocks, prunerManager.lowestShardBeaconHeight)
 
 	return prunerManager
 }
 
+// shardViews returns the shard views stored in the shard databases
+func (s *PrunerManager) shardViews() []*blockchain.ShardBestState {
+	res := []*blockchain.ShardBestState{}
+	for sid := 0; sid < common.MaxShardNumber; sid++ {
+		views, err := rawdbv2.GetShardBestState(s.ShardPruner[sid].db, byte(sid))
+		if err != nil {
+			continue
+		}
+		allViews := []*blockchain.ShardBestState{}
+		if err := json.Unmarshal(views, &allViews); err != nil {
+			continue
+		}
+		res = append(res, allViews...)
+	}
+	return res
+}
+
+// committeeFromBlocks returns the beacon blocks the shard views read their
+// committee from, the beacon pruner keeps their consensus state
+func (s *PrunerManager) committeeFromBlocks() []common.Hash {
+	res := []common.Hash{}
+	for _, v := range s.shardViews() {
+		if v.BestBlock == nil {
+			continue
+		}
+		if hash := v.BestBlock.CommitteeFromBlock(); !hash.IsEqual(&common.Hash{}) {
+			res = append(res, hash)
+		}
+	}
+	return res
+}
+
+// lowestShardBeaconHeight returns the lowest beacon height confirmed by a shard
+// view, the shards still read the beacon state of every height from there
+func (s *PrunerManager) lowestShardBeaconHeight() uint64 {
+	res := uint64(0)
+	for _, v := range s.shardViews() {
+		if v.BeaconHeight > 0 && (res == 0 || v.BeaconHeight < res) {
+			res = v.BeaconHeight
+		}
+	}
+	return res
+}
+
 func (s *PrunerManager) Start() error {
 	for {
 		for sid, shardPruner := range s.ShardPruner {
@@ -47,11 +94,13 @@ func (s *PrunerManager) Start() error {
 					latest = true
 				}
 				//if auto prune, sync latest block, and bestview block > last processing block
-				if config.Config().EnableAutoPrune && latest && shardPruner.bestView.ShardHeight > shardPruner.lastProcessingHeight+config.Config().NumBlockTriggerPrune {
+				if config.Config().EnableAutoPrune && latest && shardPruner.bestView.ShardHeight > triggerHeight(shardPruner.lastProcessingHeight, shardPruner.finalHeight)+config.Config().NumBlockTriggerPrune {
 					shardPruner.SetBloomSize(config.Config().StateBloomSize)
+					shardPruner.SetRetentionEpochs(config.Config().StateRetentionEpochs)
 					shardPruner.Prune(false)
 				} else if req, ok := s.JobRquest[sid]; ok { //request for this shard from RPC
 					shardPruner.SetBloomSize(config.Config().StateBloomSize)
+					shardPruner.SetRetentionEpochs(config.Config().StateRetentionEpochs)
 					if req.ShouldPruneByHash {
 						shardPruner.Prune(true)
 					} else {
@@ -62,6 +111,23 @@ func (s *PrunerManager) Start() error {
 				}
 			}
 		}
+		beaconPruner := s.BeaconPruner
+		if beaconPruner.status == IDLE {
+			latest := false
+			if beaconPruner.bestView != nil && beaconPruner.bestView.CalculateTimeSlot(beaconPruner.bestView.BestBlock.GetProposeTime()) == beaconPruner.bestView.CalculateTimeSlot(time.Now().Unix()) {
+				latest = true
+			}
+			if config.Config().EnableBeaconPrune && config.Config().EnableAutoPrune && latest && beaconPruner.bestView.BeaconHeight > triggerHeight(beaconPruner.lastProcessingHeight, beaconPruner.finalHeight)+config.Config().NumBlockTriggerPrune {
+				beaconPruner.SetBloomSize(config.Config().StateBloomSize)
+				beaconPruner.SetRetentionEpochs(config.Config().StateRetentionEpochs)
+				beaconPruner.Prune(false)
+			} else if req, ok := s.JobRquest[common.BeaconChainID]; ok && config.Config().EnableBeaconPrune { //request for beacon from RPC
+				beaconPruner.SetBloomSize(config.Config().StateBloomSize)
+				beaconPruner.SetRetentionEpochs(config.Config().StateRetentionEpochs)
+				beaconPruner.Prune(req.ShouldPruneByHash)
+				delete(s.JobRquest, common.BeaconChainID)
+			}
+		}
 		time.Sleep(time.Second)
 	}
 }
@@ -95,6 +161,7 @@ func (s *PrunerManager) OfflinePrune() {
 						fmt.Println("ShardPrunter is not ready")
 					}
 					s.ShardPruner[shardID].SetBloomSize(stateBloomSize)
+					s.ShardPruner[shardID].SetRetentionEpochs(cfg.StateRetentionEpochs)
 					s.ShardPruner[shardID].Prune(false)
 					wg.Done()
 					sem.Release(1)
@@ -115,6 +182,17 @@ func (s *PrunerManager) OfflinePrune() {
 		ch <- i
 	}
 	wg.Wait()
+
+	if !cfg.EnableBeaconPrune {
+		return
+	}
+	ffPath := path.Join(cfg.DataDir, cfg.DatabaseDir, common.BeaconChainDatabaseDirectory, "blockstorage")
+	s.BeaconPruner.blockStorage = blockchain.NewBlockStorage(s.BeaconPruner.db, ffPath, common.BeaconChainID, false)
+	s.BeaconPruner.SetBloomSize(stateBloomSize)
+	s.BeaconPruner.SetRetentionEpochs(cfg.StateRetentionEpochs)
+	s.BeaconPruner.Prune(false)
+	b, _ := json.MarshalIndent(s.BeaconPruner.Report(), "", "\t")
+	Logger.log.Infof("Beacon finish prune %v", string(b))
 }
 
 func (p *PrunerManager) SetShardInsertLock(sid int, mutex *sync.Mutex) {
@@ -124,6 +202,17 @@ func (p *PrunerManager) SetShardBlockStorage(sid int, blockStorage *blockchain.B
 	p.ShardPruner[sid].blockStorage = blockStorage
 }
 
+func (p *PrunerManager) SetBeaconInsertLock(mutex *sync.Mutex) {
+	p.BeaconPruner.beaconInsertLock = mutex
+}
+func (p *PrunerManager) SetBeaconBlockStorage(blockStorage *blockchain.BlockStorage) {
+	p.BeaconPruner.blockStorage = blockStorage
+}
+
+func (p *PrunerManager) InsertNewBeaconView(beaconBestState *blockchain.BeaconBestState) {
+	p.BeaconPruner.handleNewView(beaconBestState)
+}
+
 func (p *PrunerManager) InsertNewView(shardBestState *blockchain.ShardBestState) {
 	sid := shardBestState.ShardID
 	p.ShardPruner[int(sid)].handleNewView(shardBestState)
@@ -134,5 +223,6 @@ func (s *PrunerManager) Report() map[int]ShardPrunerReport {
 	for sid := 0; sid < common.MaxShardNumber; sid++ {
 		res[sid] = s.ShardPruner[sid].Report()
 	}
+	res[common.BeaconChainID] = s.BeaconPruner.Report()
 	return res
 }
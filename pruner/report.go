@@ -2,6 +2,7 @@ package pruner
 
 import (
 	"encoding/json"
+	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
 	"time"
 )
@@ -16,21 +17,15 @@ type ShardPrunerReport struct {
 	LastProcessingMode   string
 	TotalNodePrune       uint64
 	TotalStoragePrune    uint64
+	FinalHeight          uint64
+	RetentionEpochs      uint64
+	PrunedHeight         uint64 //the state of heights <= PrunedHeight may be missing
 }
 
 func (s *ShardPruner) Report() ShardPrunerReport {
 	res := ShardPrunerReport{}
 	res.LastTriggerTime = s.lastTriggerTime
-	switch s.status {
-	case IDLE:
-		res.Status = "IDLE"
-	case INIT:
-		res.Status = "INIT"
-	case PRUNING:
-		res.Status = "PRUNING"
-	case CHECKING:
-		res.Status = "CHECKING"
-	}
+	res.Status = statusString(s.status)
 	res.ChainID = s.shardID
 	res.Error = s.lastError
 	res.LastProcessingHeight = s.lastProcessingHeight
@@ -38,6 +33,9 @@ func (s *ShardPruner) Report() ShardPrunerReport {
 	res.TotalNodePrune = s.nodes
 	res.TotalStoragePrune = s.storage
 	res.BloomSize = s.bloomSize
+	res.FinalHeight = s.finalHeight
+	res.RetentionEpochs = s.retentionEpochs
+	res.PrunedHeight = s.prunedHeight
 	return res
 }
 
@@ -62,4 +60,73 @@ func (s *ShardPruner) restoreStatus() {
 	s.lastProcessingHeight = report.LastProcessingHeight
 	s.storage = report.TotalStoragePrune
 	s.nodes = report.TotalNodePrune
+	s.finalHeight = report.FinalHeight
+	s.retentionEpochs = report.RetentionEpochs
+	s.prunedHeight = report.PrunedHeight
+}
+
+func (s *BeaconPruner) Report() ShardPrunerReport {
+	res := ShardPrunerReport{}
+	res.LastTriggerTime = s.lastTriggerTime
+	res.Status = statusString(s.status)
+	res.ChainID = common.BeaconChainID
+	res.Error = s.lastError
+	res.LastProcessingHeight = s.lastProcessingHeight
+	res.LastProcessingMode = s.lastProcessingMode
+	res.TotalNodePrune = s.nodes
+	res.TotalStoragePrune = s.storage
+	res.BloomSize = s.bloomSize
+	res.FinalHeight = s.finalHeight
+	res.RetentionEpochs = s.retentionEpochs
+	res.PrunedHeight = s.prunedHeight
+	return res
+}
+
+func (s *BeaconPruner) saveStatus() {
+	b, _ := json.Marshal(s.Report())
+	rawdbv2.StorePruneStatus(s.db, b)
+}
+
+func (s *BeaconPruner) restoreStatus() {
+	b, err := rawdbv2.GetPruneStatus(s.db)
+	if err != nil {
+		return
+	}
+	report := &ShardPrunerReport{}
+	err = json.Unmarshal(b, report)
+	if err != nil {
+		return
+	}
+	s.lastTriggerTime = report.LastTriggerTime
+	s.lastProcessingMode = report.LastProcessingMode
+	s.lastError = report.Error
+	s.lastProcessingHeight = report.LastProcessingHeight
+	s.storage = report.TotalStoragePrune
+	s.nodes = report.TotalNodePrune
+	s.finalHeight = report.FinalHeight
+	s.retentionEpochs = report.RetentionEpochs
+	s.prunedHeight = report.PrunedHeight
+}
+
+func statusString(status int) string {
+	switch status {
+	case IDLE:
+		return "IDLE"
+	case INIT:
+		return "INIT"
+	case PRUNING:
+		return "PRUNING"
+	case CHECKING:
+		return "CHECKING"
+	}
+	return ""
+}
+
+// triggerHeight is the height the last prune run reached, the next automatic
+// prune is triggered relatively to it
+func triggerHeight(lastProcessingHeight, finalHeight uint64) uint64 {
+	if finalHeight > lastProcessingHeight {
+		return finalHeight
+	}
+	return lastProcessingHeight
 }
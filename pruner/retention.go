@@ -0,0 +1,69 @@
+package pruner
+
+import (
+	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/pkg/errors"
+)
+
+// retentionStartHeight returns the lowest height whose block belongs to one of
+// the last retentionEpochs epochs (finalEpoch included), the state of every
+// height from there to the final height is kept. Without retention only the
+// views are kept. Epochs never decrease with the height so the start height is
+// found by bisection, epochOf returns the epoch of the finalized block at height.
+func retentionStartHeight(finalHeight, finalEpoch, retentionEpochs uint64, epochOf func(uint64) (uint64, error)) (uint64, error) {
+	if retentionEpochs == 0 || finalHeight <= 1 {
+		return finalHeight, nil
+	}
+	if finalEpoch < retentionEpochs {
+		return 1, nil
+	}
+	startEpoch := finalEpoch - retentionEpochs + 1
+	low, high := uint64(1), finalHeight
+	for low < high {
+		mid := low + (high-low)/2
+		epoch, err := epochOf(mid)
+		if err != nil {
+			return 0, errors.Wrapf(err, "cannot get epoch at height %v", mid)
+		}
+		if epoch < startEpoch {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+	return low, nil
+}
+
+func shardEpochOf(blockStorage *blockchain.BlockStorage) func(uint64) (uint64, error) {
+	return func(height uint64) (uint64, error) {
+		if blockStorage == nil {
+			return 0, errors.New("block storage is not set")
+		}
+		hash, err := blockStorage.GetFinalizedShardBlockHashByIndex(height)
+		if err != nil {
+			return 0, err
+		}
+		blk, _, err := blockStorage.GetBlock(*hash)
+		if err != nil {
+			return 0, err
+		}
+		return blk.GetCurrentEpoch(), nil
+	}
+}
+
+func beaconEpochOf(blockStorage *blockchain.BlockStorage) func(uint64) (uint64, error) {
+	return func(height uint64) (uint64, error) {
+		if blockStorage == nil {
+			return 0, errors.New("block storage is not set")
+		}
+		hash, err := blockStorage.GetFinalizedBeaconBlock(height)
+		if err != nil {
+			return 0, err
+		}
+		blk, _, err := blockStorage.GetBlock(*hash)
+		if err != nil {
+			return 0, err
+		}
+		return blk.GetCurrentEpoch(), nil
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
 	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
 	"github.com/levietcuong2602/incognito-chain/incdb"
@@ -25,6 +26,11 @@ type ShardPruner struct {
 	finalHeight  uint64
 	bestView     *blockchain.ShardBestState
 
+	//retention, the state of heights [retainFrom, finalHeight] is kept
+	retentionEpochs uint64
+	retainFrom      uint64
+	prunedHeight    uint64
+
 	//lock
 	lock            sync.Mutex
 	wg              sync.WaitGroup
@@ -58,6 +64,12 @@ func (s *ShardPruner) SetBloomSize(size uint64) {
 	s.bloomSize = size
 }
 
+// SetRetentionEpochs keeps the state of the last epochs when pruning, 0 keeps
+// the state of the views only
+func (s *ShardPruner) SetRetentionEpochs(epochs uint64) {
+	s.retentionEpochs = epochs
+}
+
 func (s *ShardPruner) Stop() {
 	s.status = IDLE
 }
@@ -82,6 +94,10 @@ func (s *ShardPruner) InitBloomState() error {
 	} else {
 		return errors.New("Cannot retrieve all shard views")
 	}
+	s.retainFrom, err = retentionStartHeight(s.finalHeight, allViews[0].Epoch, s.retentionEpochs, shardEpochOf(s.blockStorage))
+	if err != nil {
+		return err
+	}
 	for _, v := range allViews {
 		err = s.addViewToBloom(v)
 		if err != nil {
@@ -108,6 +124,11 @@ func (s *ShardPruner) Prune(byHash bool) error {
 		return err
 	}
 	s.status = PRUNING
+	//heights below the retention window are going to lose their state
+	if s.retainFrom > s.prunedHeight+1 {
+		s.prunedHeight = s.retainFrom - 1
+	}
+	s.saveStatus()
 	s.lock.Unlock()
 
 	if byHash {
@@ -133,7 +154,7 @@ func (s *ShardPruner) PruneByHeight() error {
 		s.lastError = ""
 		return nil
 	}
-	for height := s.lastProcessingHeight; height < s.finalHeight; height++ {
+	for height := s.lastProcessingHeight; height < s.retainFrom; height++ {
 		if s.status != PRUNING {
 			return nil
 		}
@@ -230,8 +251,42 @@ func (s *ShardPruner) pruneByHash() error {
 func (s *ShardPruner) addViewToBloom(v *blockchain.ShardBestState) error {
 	Logger.log.Infof("[state-prune %v] Start retrieve view %s at height %v hash %v ",
 		v.ShardID, v.BestBlockHash.String(), v.ShardHeight, v.TransactionStateDBRootHash.String())
-	var dbAccessWarper = statedb.NewDatabaseAccessWarper(s.db)
-	stateDB, err := statedb.NewWithPrefixTrie(v.TransactionStateDBRootHash, dbAccessWarper)
+	if err := s.addRootToBloom(v.TransactionStateDBRootHash); err != nil {
+		return err
+	}
+	Logger.log.Infof("[state-prune %v] Finish retrieve view %s at height %v",
+		v.ShardID, v.BestBlockHash.String(), v.ShardHeight)
+
+	//the final view also keeps the state of the retention window before it
+	if v.ShardHeight == s.finalHeight && s.retainFrom < s.finalHeight {
+		return s.addRetentionToBloom()
+	}
+	return nil
+}
+
+// addRetentionToBloom adds the transaction state of every finalized block of
+// the retention window to the state bloom
+func (s *ShardPruner) addRetentionToBloom() error {
+	Logger.log.Infof("[state-prune %v] Start retrieve retention window from height %v to %v", s.shardID, s.retainFrom, s.finalHeight-1)
+	for height := s.retainFrom; height < s.finalHeight; height++ {
+		h, err := s.blockStorage.GetFinalizedShardBlockHashByIndex(height)
+		if err != nil {
+			return errors.Wrapf(err, "cannot get finalized block at height %v", height)
+		}
+		sRH, err := blockchain.GetShardRootsHashByBlockHash(s.db, byte(s.shardID), *h)
+		if err != nil {
+			return errors.Wrapf(err, "cannot get roots of block %v", h.String())
+		}
+		if err := s.addRootToBloom(sRH.TransactionStateDBRootHash); err != nil {
+			return err
+		}
+	}
+	Logger.log.Infof("[state-prune %v] Finish retrieve retention window", s.shardID)
+	return nil
+}
+
+func (s *ShardPruner) addRootToBloom(root common.Hash) error {
+	stateDB, err := statedb.NewWithPrefixTrie(root, statedb.NewDatabaseAccessWarper(s.db))
 	if err != nil {
 		return err
 	}
@@ -242,14 +297,7 @@ func (s *ShardPruner) addViewToBloom(v *blockchain.ShardBestState) error {
 	} else {
 		_, err = stateDB.Retrieve(true, false, s.stateBloom, false)
 	}
-
-	if err != nil {
-		panic(err)
-		return err
-	}
-	Logger.log.Infof("[state-prune %v] Finish retrieve view %s at height %v",
-		v.ShardID, v.BestBlockHash.String(), v.ShardHeight)
-	return nil
+	return err
 }
 
 func (s *ShardPruner) CheckDataIntegrity() {
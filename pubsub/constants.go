@@ -18,6 +18,7 @@ const (
 	RequestBeaconBlockByHeightTopic = "requestbeaconblockbyheighttopic"
 	RequestBeaconBlockByHashTopic   = "requestbeaconblockbyhashtopic"
 	TestTopic                       = "testtopic"
+	TxLifecycleTopic                = "txlifecycletopic"
 )
 
 var Topics = []string{
@@ -35,6 +36,7 @@ var Topics = []string{
 	RequestShardBlockByHeightTopic,
 	RequestShardBlockByHashTopic,
 	ShardBeststateTopic,
+	TxLifecycleTopic,
 }
 
 type NodeRole struct {
@@ -0,0 +1,23 @@
+package pubsub
+
+// events of the lifecycle of a tx in the pool of its shard
+const (
+	TxEventReceived  = "received"
+	TxEventValidated = "validated"
+	TxEventRejected  = "rejected"
+	TxEventReplaced  = "replaced"
+	TxEventSelected  = "selected"
+	TxEventIncluded  = "included"
+	TxEventEvicted   = "evicted"
+)
+
+// TxEvent is published on TxLifecycleTopic at each step of a tx in the pool,
+// Reason is the error of a rejected or evicted tx, the tx replacing a double
+// spend or the block a tx is selected into
+type TxEvent struct {
+	TxHash  string
+	ShardID byte
+	Event   string
+	Reason  string `json:",omitempty"`
+	Time    int64
+}
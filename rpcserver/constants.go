@@ -9,6 +9,7 @@ const (
 	startProfiling = "startprofiling"
 	stopProfiling  = "stopprofiling"
 	exportMetrics  = "exportmetrics"
+	getDBStats     = "getdatabasestats"
 
 	getNetworkInfo       = "getnetworkinfo"
 	getConnectionCount   = "getconnectioncount"
@@ -56,12 +57,15 @@ const (
 	getBlockChainInfo           = "getblockchaininfo"
 	getBlockCount               = "getblockcount"
 	getBlockHash                = "getblockhash"
+	getStateDiff                = "getstatediff"
+	getStateProof               = "getstateproof"
 
 	listOutputCoins                            = "listoutputcoins"
 	listOutputCoinsFromCache                   = "listoutputcoinsfromcache"
 	listOutputTokens                           = "listoutputtokens"
 	createRawTransaction                       = "createtransaction"
 	sendRawTransaction                         = "sendtransaction"
+	simulateTransaction                        = "simulatetransaction"
 	createAndSendTransaction                   = "createandsendtransaction"
 	createConvertCoinVer1ToVer2Transaction     = "createconvertcoinver1tover2transaction"
 	createAndSendCustomTokenTransaction        = "createandsendcustomtokentransaction"
@@ -485,6 +489,7 @@ const (
 	subcribeBeaconBestStateFromMem              = "subcribebeaconbeststatefrommem"
 	subcribeBeaconPoolBeststate                 = "subcribebeaconpoolbeststate"
 	subcribeShardPoolBeststate                  = "subcribeshardpoolbeststate"
+	subcribeTxLifecycle                         = "subcribetxlifecycle"
 )
 
 // add method names when add new feature flags
@@ -201,17 +201,11 @@ func (httpServer *HttpServer) handleGetBeaconStakerInfo(params interface{}, clos
 	height := uint64(arrayParams[0].(float64))
 	stakerPubkey := arrayParams[1].(string)
 
-	beaconConsensusStateRootHash, err := httpServer.config.BlockChain.GetBeaconRootsHashFromBlockHeight(
-		height,
-	)
-	if err != nil {
-		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
-	}
-	stateDB, err := statedb.NewWithPrefixTrie(beaconConsensusStateRootHash.ConsensusStateDBRootHash,
-		statedb.NewDatabaseAccessWarper(httpServer.config.BlockChain.GetBeaconChainDatabase()))
-	if err != nil {
-		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
+	at, rpcErr := httpServer.getStateDBAt(common.BeaconChainID, blockchain.ConsensusStateDB, blockchain.StateQuery{Height: height})
+	if rpcErr != nil {
+		return nil, rpcErr
 	}
+	stateDB := at.StateDB
 	res, found, err := statedb.GetBeaconStakerInfo(stateDB, stakerPubkey)
 	if err != nil {
 		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
@@ -241,6 +235,9 @@ func (httpServer *HttpServer) handleGetShardStakerInfo(params interface{}, close
 	stakerPubkey := arrayParams[1].(string)
 	stateDB := httpServer.config.BlockChain.GetBeaconBestState().GetBeaconConsensusStateDB()
 	if height != 0 {
+		if rpcErr := httpServer.checkStatePruned(common.BeaconChainID, height); rpcErr != nil {
+			return nil, rpcErr
+		}
 		beaconConsensusStateRootHash, err := httpServer.config.BlockChain.GetBeaconRootsHashFromBlockHeight(
 			height,
 		)
@@ -278,6 +275,9 @@ func (httpServer *HttpServer) handleGetBeaconCommitteeState(params interface{},
 	if height == 0 {
 		return httpServer.config.BlockChain.GetBeaconBestState().GetBeaconCommitteeState().(*committeestate.BeaconCommitteeStateV4).DebugBeaconCommitteeState(), nil
 	}
+	if rpcErr := httpServer.checkStatePruned(common.BeaconChainID, height); rpcErr != nil {
+		return nil, rpcErr
+	}
 	beaconConsensusStateRootHash, err := httpServer.config.BlockChain.GetBeaconRootsHashFromBlockHeight(
 		height,
 	)
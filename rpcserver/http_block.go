@@ -405,3 +405,56 @@ func (httpServer *HttpServer) handleGetBlocksFromHeight(params interface{}, clos
 	}
 	return result, nil
 }
+
+// handleGetStateDiff returns the state objects added, removed and modified by
+// a block in one of its state databases. Params: block hash, db kind
+// (consensus, transaction, feature, reward or slash), optionally the hash of
+// the block to compare with, its previous block by default, and the maximum
+// number of objects, at most maxStateDiffLimit, 0 for maxStateDiffLimit.
+func (httpServer *HttpServer) handleGetStateDiff(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if len(arrayParams) < 2 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("param must be an array at least 2 elements"))
+	}
+	hashString, ok := arrayParams[0].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("hashString is invalid"))
+	}
+	hash, err := common.Hash{}.NewHashFromStr(hashString)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, err)
+	}
+	kind, ok := arrayParams[1].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("db kind is invalid"))
+	}
+	var fromHash *common.Hash
+	if len(arrayParams) > 2 {
+		fromHashString, ok := arrayParams[2].(string)
+		if !ok {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("from hashString is invalid"))
+		}
+		if fromHashString != "" {
+			if fromHash, err = (common.Hash{}).NewHashFromStr(fromHashString); err != nil {
+				return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, err)
+			}
+		}
+	}
+	limit := defaultStateDiffLimit
+	if len(arrayParams) > 3 {
+		limitParam, ok := arrayParams[3].(float64)
+		if !ok || limitParam < 0 {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("limit is invalid"))
+		}
+		limit = int(limitParam)
+		if limit == 0 || limit > maxStateDiffLimit {
+			limit = maxStateDiffLimit
+		}
+	}
+
+	result, err := httpServer.config.BlockChain.GetStateDiffByBlockHash(*hash, fromHash, kind, limit)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.StateDiffError, err)
+	}
+	return result, nil
+}
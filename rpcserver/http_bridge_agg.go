@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	rCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/levietcuong2602/incognito-chain/blockchain"
 	"github.com/levietcuong2602/incognito-chain/blockchain/bridgeagg"
 	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/common/base58"
@@ -28,11 +29,15 @@ func (httpServer *HttpServer) handleGetBridgeAggState(params interface{}, closeC
 	if !ok {
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Payload data is invalid"))
 	}
-	beaconHeight, ok := data["BeaconHeight"].(float64)
-	if !ok {
-		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Beacon height is invalid"))
+	query, rpcErr := parseBeaconStateQuery(data)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	at, rpcErr := httpServer.getStateDBAt(common.BeaconChainID, blockchain.FeatureStateDB, query)
+	if rpcErr != nil {
+		return nil, rpcErr
 	}
-	result, err := httpServer.blockService.GetBridgeAggState(uint64(beaconHeight))
+	result, err := httpServer.blockService.GetBridgeAggState(at)
 	if err != nil {
 		return nil, rpcservice.NewRPCError(rpcservice.GetBridgeAggStateError, err)
 	}
@@ -16,6 +16,9 @@ func (httpServer *HttpServer) handleGetDelegationDetail(params interface{}, clos
 	height := uint64(arrayParams[0].(float64))
 	stateDB := httpServer.config.BlockChain.GetBeaconBestState().GetBeaconConsensusStateDB()
 	if height != 0 {
+		if rpcErr := httpServer.checkStatePruned(common.BeaconChainID, height); rpcErr != nil {
+			return nil, rpcErr
+		}
 		beaconConsensusStateRootHash, err := httpServer.config.BlockChain.GetBeaconRootsHashFromBlockHeight(
 			height,
 		)
@@ -40,6 +43,9 @@ func (httpServer *HttpServer) handleGetDelegationRewardDetail(params interface{}
 	height := uint64(arrayParams[0].(float64))
 	stateDB := httpServer.config.BlockChain.GetBeaconBestState().GetBeaconConsensusStateDB()
 	if height != 0 {
+		if rpcErr := httpServer.checkStatePruned(common.BeaconChainID, height); rpcErr != nil {
+			return nil, rpcErr
+		}
 		beaconConsensusStateRootHash, err := httpServer.config.BlockChain.GetBeaconRootsHashFromBlockHeight(
 			height,
 		)
@@ -160,5 +160,8 @@ func (httpServer *HttpServer) handleEstimateFeeWithEstimator(params interface{},
 	}
 
 	result := jsonresult.NewEstimateFeeResult(estimateFeeCoinPerKb, 0, 0, minFeePerTx)
+	if estimate, err := httpServer.txService.EstimateFeeFromPool(shardIDSender, numblock, tokenId); err == nil {
+		result.FeePerKBPercentiles = estimate.FeePerKB
+	}
 	return result, nil
 }
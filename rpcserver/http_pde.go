@@ -695,6 +695,9 @@ func (httpServer *HttpServer) handleGetPDEState(params interface{}, closeChan <-
 	if !ok {
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Beacon height is invalid"))
 	}
+	if rpcErr := httpServer.checkStatePruned(common.BeaconChainID, uint64(beaconHeight)); rpcErr != nil {
+		return nil, rpcErr
+	}
 	beaconFeatureStateRootHash, err := httpServer.config.BlockChain.GetBeaconFeatureRootHash(httpServer.config.BlockChain.GetBeaconBestState(), uint64(beaconHeight))
 	if err != nil {
 		return nil, rpcservice.NewRPCError(rpcservice.GetPDEStateError, fmt.Errorf("Can't found ConsensusStateRootHash of beacon height %+v, error %+v", beaconHeight, err))
@@ -754,6 +757,9 @@ func (httpServer *HttpServer) handleConvertNativeTokenToPrivacyToken(params inte
 	if err != nil {
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Payload is invalid"))
 	}
+	if rpcErr := httpServer.checkStatePruned(common.BeaconChainID, uint64(beaconHeight)); rpcErr != nil {
+		return nil, rpcErr
+	}
 	beaconPdexStateDB, err := httpServer.config.BlockChain.GetBestStateBeaconFeatureStateDBByHeight(uint64(beaconHeight), httpServer.GetBeaconChainDatabase())
 	if err != nil {
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, err)
@@ -789,6 +795,9 @@ func (httpServer *HttpServer) handleConvertPrivacyTokenToNativeToken(params inte
 	if err != nil {
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Payload is invalid"))
 	}
+	if rpcErr := httpServer.checkStatePruned(common.BeaconChainID, uint64(beaconHeight)); rpcErr != nil {
+		return nil, rpcErr
+	}
 	beaconPdexStateDB, err := httpServer.config.BlockChain.GetBestStateBeaconFeatureStateDBByHeight(uint64(beaconHeight), httpServer.GetBeaconChainDatabase())
 	if err != nil {
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, err)
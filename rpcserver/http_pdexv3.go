@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/levietcuong2602/incognito-chain/blockchain"
 	"github.com/levietcuong2602/incognito-chain/blockchain/pdex"
 	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/common/base58"
@@ -30,15 +31,19 @@ func (httpServer *HttpServer) handleGetPdexv3State(params interface{}, closeChan
 	if !ok {
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Payload data is invalid"))
 	}
-	beaconHeight, ok := data["BeaconHeight"].(float64)
-	if !ok {
-		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Beacon height is invalid"))
+	query, rpcErr := parseBeaconStateQuery(data)
+	if rpcErr != nil {
+		return nil, rpcErr
 	}
 	filter, ok := data["Filter"].(map[string]interface{})
 	if !ok {
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Filter is invalid"))
 	}
-	result, err := httpServer.blockService.GetPdexv3State(filter, uint64(beaconHeight))
+	at, rpcErr := httpServer.getStateDBAt(common.BeaconChainID, blockchain.FeatureStateDB, query)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	result, err := httpServer.blockService.GetPdexv3State(filter, at)
 	if err != nil {
 		return nil, rpcservice.NewRPCError(rpcservice.GetPdexv3StateError, err)
 	}
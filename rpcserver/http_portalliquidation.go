@@ -49,6 +49,9 @@ func (httpServer *HttpServer) handleGetLiquidationExchangeRatesPool(params inter
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata TokenID is not support"))
 	}
 
+	if rpcErr := httpServer.checkStatePruned(common.BeaconChainID, uint64(beaconHeight)); rpcErr != nil {
+		return nil, rpcErr
+	}
 	featureStateRootHash, err := httpServer.config.BlockChain.GetBeaconFeatureRootHash(httpServer.config.BlockChain.GetBeaconBestState(), uint64(beaconHeight))
 	if err != nil {
 		return nil, rpcservice.NewRPCError(rpcservice.GetExchangeRatesLiquidationPoolError, fmt.Errorf("Can't found FeatureStateRootHash of beacon height %+v, error %+v", beaconHeight, err))
@@ -776,6 +779,9 @@ func (httpServer *HttpServer) handleGetTopupAmountForCustodianState(params inter
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("metadata CollateralTokenID is not supported"))
 	}
 
+	if rpcErr := httpServer.checkStatePruned(common.BeaconChainID, beaconHeight); rpcErr != nil {
+		return nil, rpcErr
+	}
 	featureStateRootHash, err := httpServer.config.BlockChain.GetBeaconFeatureRootHash(httpServer.config.BlockChain.GetBeaconBestState(), beaconHeight)
 	if err != nil {
 		return nil, rpcservice.NewRPCError(rpcservice.GetPortalStateError, fmt.Errorf("Can't found FeatureStateRootHash of beacon height %+v, error %+v", beaconHeight, err))
@@ -816,6 +822,9 @@ func (httpServer *HttpServer) handleGetAmountTopUpWaitingPorting(params interfac
 		beaconHeight = beaconHeightParam
 	}
 
+	if rpcErr := httpServer.checkStatePruned(common.BeaconChainID, beaconHeight); rpcErr != nil {
+		return nil, rpcErr
+	}
 	beaconFeatureStateRootHash, err := httpServer.config.BlockChain.GetBeaconFeatureRootHash(httpServer.config.BlockChain.GetBeaconBestState(), beaconHeight)
 	if err != nil {
 		return nil, rpcservice.NewRPCError(rpcservice.GetPortalRewardError, fmt.Errorf("Can't found FeatureStateRootHash of beacon height %+v, error %+v", beaconHeight, err))
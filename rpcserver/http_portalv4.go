@@ -38,6 +38,9 @@ func (httpServer *HttpServer) handleGetPortalV4State(params interface{}, closeCh
 	}
 
 	// get PortalStateDB
+	if rpcErr := httpServer.checkStatePruned(common.BeaconChainID, uint64(beaconHeight)); rpcErr != nil {
+		return nil, rpcErr
+	}
 	beaconFeatureStateRootHash, err := httpServer.config.BlockChain.GetBeaconFeatureRootHash(httpServer.config.BlockChain.GetBeaconBestState(), uint64(beaconHeight))
 	if err != nil {
 		return nil, rpcservice.NewRPCError(rpcservice.GetPortalV4StateError, fmt.Errorf("Can't found FeatureStateRootHash of beacon height %+v, error %+v", beaconHeight, err))
@@ -1,6 +1,11 @@
 package rpcserver
 
 import (
+	"errors"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/incdb"
 	"github.com/levietcuong2602/incognito-chain/metrics"
 	"github.com/levietcuong2602/incognito-chain/metrics/exp"
 	"github.com/levietcuong2602/incognito-chain/rpcserver/rpcservice"
@@ -29,3 +34,26 @@ func (httpServer *HttpServer) handleExportMetrics(params interface{}, closeChan
 	exporter := exp.NewExp(metrics.DefaultRegistry)
 	return exporter.Export(), nil
 }
+
+// handleGetDatabaseStats returns the operation metrics of the databases per key
+// family, optionally filtered by database name (block/beacon, block/shard0...)
+func (httpServer *HttpServer) handleGetDatabaseStats(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	if !config.Config().DatabaseMetrics {
+		return nil, rpcservice.NewRPCError(rpcservice.DatabaseStatsError, errors.New("Database metrics are disabled, set database_metrics to enable them"))
+	}
+	name := ""
+	arrayParams := common.InterfaceSlice(params)
+	if len(arrayParams) > 0 {
+		var ok bool
+		if name, ok = arrayParams[0].(string); !ok {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Database name is invalid"))
+		}
+	}
+	res := []incdb.OpStats{}
+	for _, stats := range incdb.MetricsSnapshot() {
+		if name == "" || stats.Database == name {
+			res = append(res, stats)
+		}
+	}
+	return res, nil
+}
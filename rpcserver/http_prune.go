@@ -8,6 +8,7 @@ import (
 
 	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/config"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
 	"github.com/levietcuong2602/incognito-chain/pruner"
 	"github.com/levietcuong2602/incognito-chain/rpcserver/rpcservice"
 )
@@ -21,7 +22,7 @@ func (httpServer *HttpServer) handlePrune(params interface{}, closeChan <-chan s
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Payload data is invalid"))
 	}
 	type Temp struct {
-		Config map[byte]pruner.Config `json:"Config"`
+		Config map[int]pruner.Config `json:"Config"` //key is shardID, or -1 for beacon
 	}
 	t := Temp{}
 	b, err := json.Marshal(arrayParams[0])
@@ -32,11 +33,16 @@ func (httpServer *HttpServer) handlePrune(params interface{}, closeChan <-chan s
 	if err != nil {
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, err)
 	}
-	for shardID, c := range t.Config {
-		if int(shardID) >= common.MaxShardNumber {
-			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, fmt.Errorf("shardID is %v is invalid", shardID))
+	for chainID := range t.Config {
+		if chainID < common.BeaconChainID || chainID >= common.MaxShardNumber {
+			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, fmt.Errorf("shardID is %v is invalid", chainID))
 		}
-		httpServer.Pruner.JobRquest[int(shardID)] = &pruner.Config{ShouldPruneByHash: c.ShouldPruneByHash}
+		if chainID == common.BeaconChainID && !config.Config().EnableBeaconPrune {
+			return nil, rpcservice.NewRPCError(rpcservice.PruneError, errors.New("Beacon prune is not enabled"))
+		}
+	}
+	for chainID, c := range t.Config {
+		httpServer.Pruner.JobRquest[chainID] = &pruner.Config{ShouldPruneByHash: c.ShouldPruneByHash}
 	}
 	type Result struct {
 		Message string `json:"Message"`
@@ -51,19 +57,47 @@ func (httpServer *HttpServer) getPruneState(params interface{}, closeChan <-chan
 
 func (httpServer *HttpServer) checkPruneData(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
 	res := map[int]bool{}
+	lock := sync.Mutex{}
 	wg := sync.WaitGroup{}
 	for i := 0; i < common.MaxShardNumber; i++ {
 		wg.Add(1)
 		go func(sid int) {
-			if err := httpServer.GetBlockchain().GetBestStateShard(byte(sid)).GetCopiedTransactionStateDB().Recheck(); err != nil {
-				res[sid] = false
-			} else {
-				res[sid] = true
-			}
+			ok := httpServer.GetBlockchain().GetBestStateShard(byte(sid)).GetCopiedTransactionStateDB().Recheck() == nil
+			lock.Lock()
+			res[sid] = ok
+			lock.Unlock()
 			wg.Done()
 		}(i)
 	}
+	wg.Add(1)
+	go func() {
+		beaconBestState := httpServer.GetBlockchain().GetBeaconBestState()
+		ok := true
+		for _, stateDB := range []*statedb.StateDB{
+			beaconBestState.GetBeaconConsensusStateDB(),
+			beaconBestState.GetBeaconFeatureStateDB(),
+			beaconBestState.GetBeaconRewardStateDB(),
+			beaconBestState.GetBeaconSlashStateDB(),
+		} {
+			if err := stateDB.Recheck(); err != nil {
+				ok = false
+			}
+		}
+		lock.Lock()
+		res[common.BeaconChainID] = ok
+		lock.Unlock()
+		wg.Done()
+	}()
 	wg.Wait()
 	fmt.Println("checkPruneData", res)
 	return res, nil
 }
+
+// checkStatePruned returns a StatePrunedError when the state of chain cid at
+// height is outside the retention window of the state pruner
+func (httpServer *HttpServer) checkStatePruned(cid int, height uint64) *rpcservice.RPCError {
+	if err := httpServer.config.BlockChain.CheckStateAvailable(cid, height); err != nil {
+		return rpcservice.NewRPCError(rpcservice.StatePrunedError, err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package rpcserver
+
+import (
+	"errors"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/rpcserver/rpcservice"
+)
+
+// handleSimulateTransaction dry runs a signed PRV or token tx against the best
+// views of its shard and of the beacon, it is neither added to the pool nor
+// broadcast. Params: [base58 check data of the tx, as for sendtransaction]
+func (httpServer *HttpServer) handleSimulateTransaction(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if len(arrayParams) < 1 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("param must be an array at least 1 element"))
+	}
+	base58CheckData, ok := arrayParams[0].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("base58 check data is invalid"))
+	}
+	return httpServer.txService.SimulateTransaction(base58CheckData)
+}
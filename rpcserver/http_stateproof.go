@@ -0,0 +1,66 @@
+package rpcserver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/rpcserver/jsonresult"
+	"github.com/levietcuong2602/incognito-chain/rpcserver/rpcservice"
+)
+
+// handleGetStateProof returns the Merkle proof of a statedb object against the
+// state root of a beacon or shard block, which stateproof.StateProof verifies.
+// Payload: {"ChainID": -1 for beacon, "DBKind": "consensus" | "transaction" |
+// "feature" | "reward" | "slash", "ObjectType": object type, "Key": object key,
+// "At": optional state query, see parseStateQuery}
+func (httpServer *HttpServer) handleGetStateProof(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
+	arrayParams := common.InterfaceSlice(params)
+	if len(arrayParams) != 1 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Payload data is invalid"))
+	}
+	data, ok := arrayParams[0].(map[string]interface{})
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Payload data is invalid"))
+	}
+	chainID, ok := data["ChainID"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("ChainID is invalid"))
+	}
+	kind, ok := data["DBKind"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("DBKind is invalid"))
+	}
+	objectType, ok := data["ObjectType"].(float64)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("ObjectType is invalid"))
+	}
+	keyString, ok := data["Key"].(string)
+	if !ok {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Key is invalid"))
+	}
+	key, err := common.Hash{}.NewHashFromStr(keyString)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, fmt.Errorf("key %v is invalid", keyString))
+	}
+	query, rpcErr := parseStateQuery(data["At"])
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	at, rpcErr := httpServer.getStateDBAt(int(chainID), kind, query)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	proof, err := at.StateDB.Prove(int(objectType), *key)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.StateProofError, err)
+	}
+	return jsonresult.StateProofResult{
+		ChainID:     at.ChainID,
+		BlockHash:   at.BlockHash,
+		BlockHeight: at.Height,
+		DBKind:      kind,
+		StateProof:  proof,
+	}, nil
+}
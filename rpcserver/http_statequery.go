@@ -0,0 +1,91 @@
+package rpcserver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/rpcserver/rpcservice"
+)
+
+// parseStateQuery reads the "at" parameter of the state reading RPCs, which
+// selects the block whose state is read: a block height, a block hash, or an
+// object {"Height": height} / {"BlockHash": hash}. A nil parameter, a zero
+// height or an empty hash read the best view.
+func parseStateQuery(param interface{}) (blockchain.StateQuery, *rpcservice.RPCError) {
+	query := blockchain.StateQuery{}
+	switch v := param.(type) {
+	case nil:
+		return query, nil
+	case float64:
+		if v < 0 {
+			return query, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, fmt.Errorf("height %v is invalid", v))
+		}
+		query.Height = uint64(v)
+		return query, nil
+	case string:
+		if v == "" {
+			return query, nil
+		}
+		hash, err := common.Hash{}.NewHashFromStr(v)
+		if err != nil {
+			return query, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, fmt.Errorf("block hash %v is invalid", v))
+		}
+		query.BlockHash = hash
+		return query, nil
+	case map[string]interface{}:
+		if height, ok := v["Height"]; ok {
+			h, ok := height.(float64)
+			if !ok || h < 0 {
+				return query, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Height is invalid"))
+			}
+			query.Height = uint64(h)
+		}
+		if hashParam, ok := v["BlockHash"]; ok {
+			hashString, ok := hashParam.(string)
+			if !ok {
+				return query, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("BlockHash is invalid"))
+			}
+			if hashString != "" {
+				hash, err := common.Hash{}.NewHashFromStr(hashString)
+				if err != nil {
+					return query, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, fmt.Errorf("block hash %v is invalid", hashString))
+				}
+				query.BlockHash = hash
+			}
+		}
+		return query, nil
+	}
+	return query, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("state query must be a height, a block hash or an object with Height or BlockHash"))
+}
+
+// parseBeaconStateQuery reads the state query of a payload with a
+// BeaconHeight, which is kept for compatibility, and an optional At
+func parseBeaconStateQuery(data map[string]interface{}) (blockchain.StateQuery, *rpcservice.RPCError) {
+	if at, ok := data["At"]; ok {
+		return parseStateQuery(at)
+	}
+	beaconHeight, ok := data["BeaconHeight"].(float64)
+	if !ok {
+		return blockchain.StateQuery{}, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Beacon height is invalid"))
+	}
+	return parseStateQuery(beaconHeight)
+}
+
+// getStateDBAt opens the kind state database of chain cid at the block
+// selected by query, a pruned state is returned as StatePrunedError
+func (httpServer *HttpServer) getStateDBAt(cid int, kind string, query blockchain.StateQuery) (*blockchain.StateAt, *rpcservice.RPCError) {
+	at, err := httpServer.config.BlockChain.GetStateDBAt(cid, kind, query)
+	if err != nil {
+		return nil, stateQueryError(err)
+	}
+	return at, nil
+}
+
+func stateQueryError(err error) *rpcservice.RPCError {
+	if bcErr, ok := err.(*blockchain.BlockChainError); ok && bcErr.Code == blockchain.ErrCodeMessage[blockchain.StatePrunedError].Code {
+		return rpcservice.NewRPCError(rpcservice.StatePrunedError, err)
+	}
+	return rpcservice.NewRPCError(rpcservice.StateQueryError, err)
+}
@@ -0,0 +1,43 @@
+package rpcserver
+
+import (
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStateQuery(t *testing.T) {
+	hash := common.HashH([]byte("block"))
+
+	query, err := parseStateQuery(nil)
+	assert.Nil(t, err)
+	assert.True(t, query.IsBest())
+
+	query, err = parseStateQuery(float64(100))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), query.Height)
+	assert.Nil(t, query.BlockHash)
+
+	query, err = parseStateQuery(hash.String())
+	assert.Nil(t, err)
+	assert.Equal(t, hash, *query.BlockHash)
+
+	query, err = parseStateQuery(map[string]interface{}{"Height": float64(7), "BlockHash": hash.String()})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), query.Height)
+	assert.Equal(t, hash, *query.BlockHash)
+
+	query, err = parseBeaconStateQuery(map[string]interface{}{"BeaconHeight": float64(0)})
+	assert.Nil(t, err)
+	assert.True(t, query.IsBest())
+
+	_, err = parseStateQuery("not-a-hash")
+	assert.NotNil(t, err)
+	_, err = parseStateQuery(float64(-1))
+	assert.NotNil(t, err)
+	_, err = parseStateQuery(true)
+	assert.NotNil(t, err)
+	_, err = parseBeaconStateQuery(map[string]interface{}{})
+	assert.NotNil(t, err)
+}
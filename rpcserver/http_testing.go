@@ -177,36 +177,24 @@ func (httpServer *HttpServer) handleGetCommitteeState(params interface{}, closeC
 	height := uint64(arrayParams[0].(float64))
 	tempHash := arrayParams[1].(string)
 
-	var beaconConsensusStateRootHash = &blockchain.BeaconRootHash{}
-	var err1 error = nil
-
-	if height == 0 || tempHash != "" {
-		hash, err := common.Hash{}.NewHashFromStr(tempHash)
-		if err != nil {
-			return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
-		}
-		beaconConsensusStateRootHash, err1 = blockchain.GetBeaconRootsHashByBlockHash(
-			httpServer.config.BlockChain.GetBeaconChainDatabase(),
-			*hash,
-		)
-		if err1 != nil {
-			return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err1)
-		}
-	} else {
-		beaconConsensusStateRootHash, err1 = httpServer.config.BlockChain.GetBeaconRootsHashFromBlockHeight(
-			height,
-		)
-		if err1 != nil {
-			return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err1)
-		}
+	query, rpcErr := parseStateQuery(tempHash)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if query.BlockHash == nil {
+		query.Height = height
+	}
+	at, rpcErr := httpServer.getStateDBAt(common.BeaconChainID, blockchain.ConsensusStateDB, query)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	root, err := blockchain.GetStateRootByBlockHash(httpServer.config.BlockChain.GetBeaconChainDatabase(), common.BeaconChainID, at.BlockHash, blockchain.ConsensusStateDB)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err)
 	}
 	shardIDs := []int{-1}
 	shardIDs = append(shardIDs, httpServer.config.BlockChain.GetShardIDs()...)
-	stateDB, err2 := statedb.NewWithPrefixTrie(beaconConsensusStateRootHash.ConsensusStateDBRootHash,
-		statedb.NewDatabaseAccessWarper(httpServer.config.BlockChain.GetBeaconChainDatabase()))
-	if err2 != nil {
-		return nil, rpcservice.NewRPCError(rpcservice.UnexpectedError, err2)
-	}
+	stateDB := at.StateDB
 
 	currentValidator, substituteValidator, nextEpochShardCandidate, currentEpochShardCandidate, _, _, syncingValidators, rewardReceivers, autoStaking, stakingTx, _ := statedb.GetAllCandidateSubstituteCommittee(stateDB, shardIDs)
 	currentValidatorStr := make(map[int][]string)
@@ -238,7 +226,7 @@ func (httpServer *HttpServer) handleGetCommitteeState(params interface{}, closeC
 		tempRewardReceiver[k] = paymentAddress
 	}
 	return map[string]interface{}{
-		"root":             beaconConsensusStateRootHash.ConsensusStateDBRootHash,
+		"root":             root,
 		"committee":        currentValidatorStr,
 		"substitute":       substituteValidatorStr,
 		"nextCandidate":    nextEpochShardCandidateStr,
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/levietcuong2602/incognito-chain/blockchain"
 	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/common/base58"
 	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
@@ -257,7 +258,7 @@ func (httpServer *HttpServer) handleRemoveAccount(params interface{}, closeChan
 func (httpServer *HttpServer) handleGetBalanceByPrivatekey(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
 	// all component
 	arrayParams := common.InterfaceSlice(params)
-	if arrayParams == nil || len(arrayParams) != 1 {
+	if arrayParams == nil || len(arrayParams) < 1 || len(arrayParams) > 2 {
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("param must be an array at least 1 element"))
 	}
 	// param #1: private key of sender
@@ -265,8 +266,24 @@ func (httpServer *HttpServer) handleGetBalanceByPrivatekey(params interface{}, c
 	if !ok {
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("invalid private key"))
 	}
+	if len(arrayParams) == 1 {
+		return httpServer.walletService.GetBalanceByPrivateKey(senderKeyParam)
+	}
 
-	return httpServer.walletService.GetBalanceByPrivateKey(senderKeyParam)
+	// param #2: optional state query, the balance at a past shard block
+	query, rpcErr := parseStateQuery(arrayParams[1])
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	_, shardID, err := rpcservice.GetKeySetFromPrivateKeyParams(senderKeyParam)
+	if err != nil {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, err)
+	}
+	at, rpcErr := httpServer.getStateDBAt(int(shardID), blockchain.TransactionStateDB, query)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return httpServer.walletService.GetBalanceByPrivateKeyAt(senderKeyParam, at)
 }
 
 // handleGetBalanceByPaymentAddress -  return balance of paymentaddress
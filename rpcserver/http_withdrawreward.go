@@ -3,6 +3,7 @@ package rpcserver
 import (
 	"errors"
 
+	"github.com/levietcuong2602/incognito-chain/blockchain"
 	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/metadata"
 	"github.com/levietcuong2602/incognito-chain/rpcserver/jsonresult"
@@ -140,13 +141,25 @@ func (httpServer *HttpServer) handleGetRewardAmount(params interface{}, closeCha
 		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("payment address is invalid"))
 	}
 	mode := 2 //0: committee reward, 1: delegate reward, 2: total reward
-	if len(arrayParams) == 2 {
+	if len(arrayParams) >= 2 {
 		modeInput, ok := arrayParams[1].(float64)
 		if !ok {
 			return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("mode is invalid"))
 		}
 		mode = int(modeInput)
 	}
+	// the optional state query selects a block of the shard of the payment
+	// address, the delegation reward is read at the beacon height it confirmed
+	query := blockchain.StateQuery{}
+	if len(arrayParams) >= 3 {
+		var rpcErr *rpcservice.RPCError
+		if query, rpcErr = parseStateQuery(arrayParams[2]); rpcErr != nil {
+			return nil, rpcErr
+		}
+	}
+	if !query.IsBest() {
+		return httpServer.getRewardAmountAt(paymentAddress, mode, query)
+	}
 	switch mode {
 
 	case 0:
@@ -186,6 +199,41 @@ func (httpServer *HttpServer) handleGetRewardAmount(params interface{}, closeCha
 	}
 }
 
+// getRewardAmountAt is handleGetRewardAmount at a past block of the shard of
+// the payment address
+func (httpServer *HttpServer) getRewardAmountAt(paymentAddress string, mode int, query blockchain.StateQuery) (interface{}, *rpcservice.RPCError) {
+	keyWallet, err := wallet.Base58CheckDeserialize(paymentAddress)
+	if err != nil || len(keyWallet.KeySet.PaymentAddress.Pk) == 0 {
+		return nil, rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("payment address is invalid"))
+	}
+	receiverAddr := keyWallet.KeySet.PaymentAddress
+	shardID := common.GetShardIDFromLastByte(receiverAddr.Pk[len(receiverAddr.Pk)-1])
+	rewardAt, rpcErr := httpServer.getStateDBAt(int(shardID), blockchain.RewardStateDB, query)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	rewardAmount := map[string]uint64{}
+	if mode != 1 {
+		rewardAmount, err = httpServer.blockService.GetRewardAmountAt(paymentAddress, rewardAt)
+		if err != nil {
+			return nil, rpcservice.NewRPCError(rpcservice.GetRewardAmountError, err)
+		}
+	}
+	if mode != 0 {
+		consensusAt, rpcErr := httpServer.getStateDBAt(common.BeaconChainID, blockchain.ConsensusStateDB, blockchain.StateQuery{Height: rewardAt.BeaconHeight})
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		rewardDAmount, err := httpServer.GetBlockchain().GetDelegationRewardAmount(consensusAt.StateDB, receiverAddr.Pk)
+		if err != nil {
+			return nil, rpcservice.NewRPCError(rpcservice.GetDelegationRewardAmountError, err)
+		}
+		rewardAmount["PRV"] += rewardDAmount
+	}
+	return rewardAmount, nil
+}
+
 // handleGetRewardAmount - Get the reward amount of a payment address with all existed token
 func (httpServer *HttpServer) handleGetRewardAmountByPublicKey(params interface{}, closeChan <-chan struct{}) (interface{}, *rpcservice.RPCError) {
 	arrayParams := common.InterfaceSlice(params)
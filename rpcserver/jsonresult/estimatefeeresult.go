@@ -5,6 +5,9 @@ type EstimateFeeResult struct {
 	EstimateTxSizeInKb   uint64
 	EstimateFee          uint64
 	MinFeePerTx          uint64
+	// FeePerKBPercentiles are the fees per KB by percentile of the txs
+	// confirmed within the target blocks, from the tx pool estimator
+	FeePerKBPercentiles map[int]uint64 `json:",omitempty"`
 }
 
 func NewEstimateFeeResult(estimateFeeCoinPerKb, estimateTxSizeInKb, estimateFee, minFeePerTx uint64) *EstimateFeeResult {
@@ -17,6 +17,9 @@ type GetMempoolInfo struct {
 	MempoolMinFee uint64             `json:"MempoolMinFee"`
 	MempoolMaxFee uint64             `json:"MempoolMaxFee"`
 	ListTxs       []GetMempoolInfoTx `json:"ListTxs"`
+	// UsageByType is the usage of the pool by metadata type, 1 for the txs
+	// without metadata
+	UsageByType map[int]txpool.PoolUsage `json:"UsageByType,omitempty"`
 }
 
 func NewGetMempoolInfoV2(info txpool.MempoolInfo) *GetMempoolInfo {
@@ -24,6 +27,7 @@ func NewGetMempoolInfoV2(info txpool.MempoolInfo) *GetMempoolInfo {
 		Size:          info.GetSize(),
 		Bytes:         info.GetBytes(),
 		MempoolMaxFee: info.GetMaxMempool(),
+		UsageByType:   info.GetUsageByType(),
 	}
 	// get list data from mempool
 	listTxs := info.GetListTxs()
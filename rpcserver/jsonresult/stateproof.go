@@ -0,0 +1,16 @@
+package jsonresult
+
+import (
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/stateproof"
+)
+
+// StateProofResult is a state proof with the block whose state root it is
+// checked against
+type StateProofResult struct {
+	ChainID     int         `json:"ChainID"`
+	BlockHash   common.Hash `json:"BlockHash"`
+	BlockHeight uint64      `json:"BlockHeight"`
+	DBKind      string      `json:"DBKind"`
+	*stateproof.StateProof
+}
@@ -14,6 +14,7 @@ var HttpHandler = map[string]httpHandler{
 	startProfiling: (*HttpServer).handleStartProfiling,
 	stopProfiling:  (*HttpServer).handleStopProfiling,
 	exportMetrics:  (*HttpServer).handleExportMetrics,
+	getDBStats:     (*HttpServer).handleGetDatabaseStats,
 
 	// node
 	getNodeRole:              (*HttpServer).handleGetNodeRole,
@@ -63,6 +64,8 @@ var HttpHandler = map[string]httpHandler{
 	getBlockHeader:              (*HttpServer).handleGetBlockHeader, // Current committee, next block committee and candidate is included in block header
 	getCrossShardBlock:          (*HttpServer).handleGetCrossShardBlock,
 	getBlocksFromHeight:         (*HttpServer).handleGetBlocksFromHeight,
+	getStateDiff:                (*HttpServer).handleGetStateDiff,
+	getStateProof:               (*HttpServer).handleGetStateProof,
 
 	// transaction
 	listOutputCoins:                         (*HttpServer).handleListOutputCoins,
@@ -70,6 +73,7 @@ var HttpHandler = map[string]httpHandler{
 	listOutputTokens:                        (*HttpServer).handleListOutputCoins,
 	createRawTransaction:                    (*HttpServer).handleCreateRawTransaction,
 	sendRawTransaction:                      (*HttpServer).handleSendRawTransaction,
+	simulateTransaction:                     (*HttpServer).handleSimulateTransaction,
 	createConvertCoinVer1ToVer2Transaction:  (*HttpServer).handleCreateConvertCoinVer1ToVer2Transaction,
 	createAndSendTransaction:                (*HttpServer).handleCreateAndSendTx,
 	getTransactionByHash:                    (*HttpServer).handleGetTransactionByHash,
@@ -483,4 +487,5 @@ var WsHandler = map[string]wsHandler{
 	subcribeBeaconBestStateFromMem:              (*WsServer).handleSubscribeBeaconBestStateFromMem,
 	subcribeBeaconPoolBeststate:                 (*WsServer).handleSubscribeBeaconPoolBestState,
 	subcribeShardPoolBeststate:                  (*WsServer).handleSubscribeShardPoolBeststate,
+	subcribeTxLifecycle:                         (*WsServer).handleSubscribeTxLifecycle,
 }
@@ -27,6 +27,8 @@ const (
 	rpcAuthTimeoutSeconds    = 60
 	rpcProcessTimeoutSeconds = 90
 	RpcServerVersion         = "1.0"
+	defaultStateDiffLimit    = 1000
+	maxStateDiffLimit        = 10000
 )
 
 // timeZeroVal is simply the zero value for a time.Time and is used to avoid
@@ -759,12 +759,34 @@ func (blockService BlockService) GetRewardAmount(paymentAddress string) (map[str
 		return rewardAmountResult, nil
 	}
 	shardID := common.GetShardIDFromLastByte(publicKey[len(publicKey)-1])
+	return blockService.getRewardAmount(publicKey, shardID, blockService.BlockChain.GetBestStateShard(shardID).GetShardRewardStateDB())
+}
+
+// GetRewardAmountAt returns the committee reward of a payment address on the
+// reward state of a past block of its shard, see BlockChain.GetStateDBAt
+func (blockService BlockService) GetRewardAmountAt(paymentAddress string, at *blockchain.StateAt) (map[string]uint64, error) {
+	keySet, _, err := GetKeySetFromPaymentAddressParam(paymentAddress)
+	if err != nil {
+		return nil, err
+	}
+	publicKey := keySet.PaymentAddress.Pk
+	if publicKey == nil {
+		return make(map[string]uint64), nil
+	}
+	shardID := common.GetShardIDFromLastByte(publicKey[len(publicKey)-1])
+	if int(shardID) != at.ChainID {
+		return nil, fmt.Errorf("payment address is of shard %v, state is of chain %v", shardID, at.ChainID)
+	}
+	return blockService.getRewardAmount(publicKey, shardID, at.StateDB)
+}
+
+func (blockService BlockService) getRewardAmount(publicKey []byte, shardID byte, committeeRewardStateDB *statedb.StateDB) (map[string]uint64, error) {
+	rewardAmountResult := make(map[string]uint64)
 	allCoinIDs, err := blockService.BlockChain.ListPrivacyTokenAndBridgeTokenAndPRVByShardID(shardID)
 	if err != nil {
 		return nil, err
 	}
 	for _, coinID := range allCoinIDs {
-		committeeRewardStateDB := blockService.BlockChain.GetBestStateShard(shardID).GetShardRewardStateDB()
 		tempPK := base58.Base58Check{}.Encode(publicKey, common.Base58Version)
 		amount, err := statedb.GetCommitteeReward(committeeRewardStateDB, tempPK, coinID)
 		if err != nil {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/levietcuong2602/incognito-chain/blockchain"
 	"github.com/levietcuong2602/incognito-chain/blockchain/bridgeagg"
 	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/config"
@@ -11,31 +12,12 @@ import (
 	"github.com/levietcuong2602/incognito-chain/rpcserver/jsonresult"
 )
 
+// GetBridgeAggState reads the bridge aggregator state from the beacon feature
+// state of at, see BlockChain.GetStateDBAt
 func (blockService BlockService) GetBridgeAggState(
-	beaconHeight uint64,
+	at *blockchain.StateAt,
 ) (interface{}, error) {
-	beaconBestView := blockService.BlockChain.GetBeaconBestState()
-	if beaconHeight == 0 {
-		beaconHeight = beaconBestView.BeaconHeight
-	}
-
-	beaconFeatureStateRootHash, err := blockService.BlockChain.GetBeaconFeatureRootHash(beaconBestView, uint64(beaconHeight))
-	if err != nil {
-		return nil, NewRPCError(GetBridgeAggStateError, fmt.Errorf("Can't found ConsensusStateRootHash of beacon height %+v, error %+v", beaconHeight, err))
-	}
-	beaconFeatureStateDB, err := statedb.NewWithPrefixTrie(beaconFeatureStateRootHash, statedb.NewDatabaseAccessWarper(blockService.BlockChain.GetBeaconChainDatabase()))
-	if err != nil {
-		return nil, NewRPCError(GetBridgeAggStateError, err)
-	}
-
-	beaconBlocks, err := blockService.BlockChain.GetBeaconBlockByHeight(uint64(beaconHeight))
-	if err != nil {
-		return nil, NewRPCError(GetBridgeAggStateError, err)
-	}
-	beaconBlock := beaconBlocks[0]
-	beaconTimeStamp := beaconBlock.Header.Timestamp
-
-	res, err := getBridgeAggState(beaconHeight, beaconTimeStamp, beaconFeatureStateDB)
+	res, err := getBridgeAggState(at.Height, at.Timestamp, at.StateDB)
 	if err != nil {
 		return nil, NewRPCError(GetBridgeAggStateError, err)
 	}
@@ -164,6 +164,22 @@ const (
 
 	// prune
 	PruneError
+	StatePrunedError
+
+	// database metrics
+	DatabaseStatsError
+
+	// state diff
+	StateDiffError
+
+	// historical state query
+	StateQueryError
+
+	// state proof
+	StateProofError
+
+	// tx simulation
+	SimulateTxError
 )
 
 // Standard JSON-RPC 2.0 errors.
@@ -316,7 +332,23 @@ var ErrCodeMessage = map[int]struct {
 	BridgeAggEstimateRewardError:              {-13001, "Bridge agg estimate reward error"},
 
 	// prune
-	PruneError: {-14000, "Prune error"},
+	PruneError:       {-14000, "Prune error"},
+	StatePrunedError: {-14001, "State pruned"},
+
+	// database metrics
+	DatabaseStatsError: {-15000, "Database stats error"},
+
+	// state diff
+	StateDiffError: {-16000, "State diff error"},
+
+	// historical state query
+	StateQueryError: {-17000, "State query error"},
+
+	// state proof
+	StateProofError: {-18000, "State proof error"},
+
+	// tx simulation
+	SimulateTxError: {-19000, "Simulate transaction error"},
 }
 
 // RPCError represents an error that is used as a part of a JSON-RPC JsonResponse
@@ -7,6 +7,7 @@ import (
 	"math/big"
 	"reflect"
 
+	"github.com/levietcuong2602/incognito-chain/blockchain"
 	"github.com/levietcuong2602/incognito-chain/blockchain/pdex"
 	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/config"
@@ -250,33 +251,19 @@ func (blockService BlockService) GetPdexv3WithdrawalStakingRewardStatus(reqTxID
 	return &status, nil
 }
 
+// GetPdexv3State reads the pDEX v3 state from the beacon feature state of at,
+// see BlockChain.GetStateDBAt
 func (blockService BlockService) GetPdexv3State(
 	filterParam map[string]interface{},
-	beaconHeight uint64,
+	at *blockchain.StateAt,
 ) (interface{}, error) {
 	beaconBestView := blockService.BlockChain.GetBeaconBestState()
-	if beaconHeight == 0 {
-		beaconHeight = beaconBestView.BeaconHeight
-	}
-	if uint64(beaconHeight) < config.Param().PDexParams.Pdexv3BreakPointHeight {
+	beaconHeight := at.Height
+	if beaconHeight < config.Param().PDexParams.Pdexv3BreakPointHeight {
 		return nil, NewRPCError(GetPdexv3StateError, fmt.Errorf("pDEX v3 is not available"))
 	}
-
-	beaconFeatureStateRootHash, err := blockService.BlockChain.GetBeaconFeatureRootHash(beaconBestView, uint64(beaconHeight))
-	if err != nil {
-		return nil, NewRPCError(GetPdexv3StateError, fmt.Errorf("Can't found ConsensusStateRootHash of beacon height %+v, error %+v", beaconHeight, err))
-	}
-	beaconFeatureStateDB, err := statedb.NewWithPrefixTrie(beaconFeatureStateRootHash, statedb.NewDatabaseAccessWarper(blockService.BlockChain.GetBeaconChainDatabase()))
-	if err != nil {
-		return nil, NewRPCError(GetPdexv3StateError, err)
-	}
-
-	beaconBlocks, err := blockService.BlockChain.GetBeaconBlockByHeight(uint64(beaconHeight))
-	if err != nil {
-		return nil, NewRPCError(GetPdexv3StateError, err)
-	}
-	beaconBlock := beaconBlocks[0]
-	beaconTimeStamp := beaconBlock.Header.Timestamp
+	beaconFeatureStateDB := at.StateDB
+	beaconTimeStamp := at.Timestamp
 
 	var res interface{}
 	type FilterParam struct {
@@ -27,6 +27,7 @@ import (
 	"github.com/levietcuong2602/incognito-chain/rpcserver/bean"
 	"github.com/levietcuong2602/incognito-chain/rpcserver/jsonresult"
 	"github.com/levietcuong2602/incognito-chain/transaction"
+	"github.com/levietcuong2602/incognito-chain/txpool"
 	"github.com/levietcuong2602/incognito-chain/wallet"
 	"github.com/levietcuong2602/incognito-chain/wire"
 )
@@ -559,7 +560,9 @@ func (txService TxService) EstimateFeeWithEstimator(defaultFee int64, shardID by
 	unitFee := uint64(1)
 	if defaultFee == -1 {
 		// estimate fee on the blocks before (in native token or in pToken)
-		if _, ok := txService.FeeEstimator[shardID]; ok {
+		if estimate, err := txService.EstimateFeeFromPool(shardID, numBlock, tokenId); err == nil {
+			unitFee = estimate.FeePerKB[50]
+		} else if _, ok := txService.FeeEstimator[shardID]; ok {
 			temp, _ := txService.FeeEstimator[shardID].EstimateFee(numBlock, tokenId)
 			if temp > 0 {
 				unitFee = temp
@@ -595,6 +598,28 @@ func (txService TxService) EstimateFeeWithEstimator(defaultFee int64, shardID by
 	}
 }
 
+// EstimateFeeFromPool returns the percentiles of the fee per KB paid in tokenId,
+// PRV when nil, by the txs of shardID confirmed within numBlock blocks as seen
+// by the tx pool
+func (txService TxService) EstimateFeeFromPool(shardID byte, numBlock uint64, tokenId *common.Hash) (*txpool.FeeEstimate, error) {
+	if !txService.BlockChain.UsingNewPool() {
+		return nil, errors.New("tx pool v2 is not in use")
+	}
+	pm := txService.BlockChain.GetPoolManager()
+	if pm == nil {
+		return nil, errors.New("PoolManager is nil")
+	}
+	tp, err := pm.GetShardTxsPool(shardID)
+	if err != nil {
+		return nil, err
+	}
+	token := common.PRVCoinID
+	if tokenId != nil {
+		token = *tokenId
+	}
+	return tp.GetFeeEstimator().EstimateFee(numBlock, token)
+}
+
 func (txService TxService) BuildConvertV1ToV2Transaction(params *bean.CreateRawTxSwitchVer1ToVer2Param) (metadata.Transaction, *RPCError) {
 	Logger.log.Infof("Convert V1 to V2 Transaction Params: \n %+v", params)
 	// get output coins to spend and real fee
@@ -688,6 +713,28 @@ func (txService TxService) CreateRawTransaction(params *bean.CreateRawTxParam, m
 	return tx.Hash(), txBytes, txShardID, nil
 }
 
+// SimulateTransaction runs the checks of the pool on the PRV or token tx in
+// base58 check without adding it to the pool, see BlockChain.SimulateTransaction
+func (txService TxService) SimulateTransaction(txB58Check string) (*blockchain.TxSimulation, *RPCError) {
+	rawTxBytes, _, err := base58.Base58Check{}.Decode(txB58Check)
+	if err != nil {
+		return nil, NewRPCError(Base58ChedkDataOfTxInvalid, err)
+	}
+	choice, err := transaction.DeserializeTransactionJSON(rawTxBytes)
+	if err != nil {
+		return nil, NewRPCError(JsonDataOfTxInvalid, err)
+	}
+	tx := choice.ToTx()
+	if tx == nil {
+		return nil, NewRPCError(JsonDataOfTxInvalid, errors.New("cannot parse tx"))
+	}
+	res, err := txService.BlockChain.SimulateTransaction(tx)
+	if err != nil {
+		return nil, NewRPCError(SimulateTxError, err)
+	}
+	return res, nil
+}
+
 func (txService TxService) SendRawTransaction(txB58Check string) (wire.Message, *common.Hash, byte, *RPCError) {
 	// Decode base58check data of tx
 	rawTxBytes, _, err := base58.Base58Check{}.Decode(txB58Check)
@@ -161,6 +161,33 @@ func (walletService WalletService) GetBalanceByPrivateKey(privateKey string) (ui
 	return balance, nil
 }
 
+// GetBalanceByPrivateKeyAt returns the PRV balance of a private key on the
+// transaction state of a past shard block, see BlockChain.GetStateDBAt
+func (walletService WalletService) GetBalanceByPrivateKeyAt(privateKey string, at *blockchain.StateAt) (uint64, *RPCError) {
+	keySet, shardIDSender, err := GetKeySetFromPrivateKeyParams(privateKey)
+	if err != nil {
+		return uint64(0), NewRPCError(RPCInvalidParamsError, err)
+	}
+	if keySet == nil {
+		return uint64(0), NewRPCError(InvalidSenderPrivateKeyError, err)
+	}
+	if int(shardIDSender) != at.ChainID {
+		return uint64(0), NewRPCError(RPCInvalidParamsError, fmt.Errorf("private key is of shard %v, state is of chain %v", shardIDSender, at.ChainID))
+	}
+
+	outcoins, _, _, err := walletService.BlockChain.GetListDecryptedOutputCoinsByKeysetAt(keySet, shardIDSender, &common.PRVCoinID, at)
+	if err != nil {
+		return uint64(0), NewRPCError(UnexpectedError, err)
+	}
+
+	balance := uint64(0)
+	for _, out := range outcoins {
+		balance += out.GetValue()
+	}
+
+	return balance, nil
+}
+
 func (walletService WalletService) GetBalanceByPaymentAddress(paymentAddress string) (uint64, *RPCError) {
 	keySet, shardIDSender, err := GetKeySetFromPaymentAddressParam(paymentAddress)
 	if err != nil {
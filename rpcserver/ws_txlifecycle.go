@@ -0,0 +1,70 @@
+package rpcserver
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/pubsub"
+	"github.com/levietcuong2602/incognito-chain/rpcserver/jsonresult"
+	"github.com/levietcuong2602/incognito-chain/rpcserver/rpcservice"
+)
+
+// handleSubscribeTxLifecycle streams the events of the txs in the pools of the
+// node: received, validated, rejected, replaced, selected, included, evicted.
+// Params: [tx hash] to follow one tx, or [] for all of them
+func (wsServer *WsServer) handleSubscribeTxLifecycle(params interface{}, subcription string, cResult chan RpcSubResult, closeChan <-chan struct{}) {
+	arrayParams := common.InterfaceSlice(params)
+	txHash := ""
+	if len(arrayParams) > 1 {
+		err := rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Methods should only contain at most 1 param"))
+		cResult <- RpcSubResult{Error: err}
+		return
+	}
+	if len(arrayParams) == 1 {
+		txHashTemp, ok := arrayParams[0].(string)
+		if !ok {
+			err := rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, errors.New("Invalid Tx Hash"))
+			cResult <- RpcSubResult{Error: err}
+			return
+		}
+		h, err := common.Hash{}.NewHashFromStr(txHashTemp)
+		if err != nil {
+			cResult <- RpcSubResult{Error: rpcservice.NewRPCError(rpcservice.RPCInvalidParamsError, err)}
+			return
+		}
+		txHash = h.String()
+	}
+	subId, subChan, err := wsServer.config.PubSubManager.RegisterNewSubscriber(pubsub.TxLifecycleTopic)
+	if err != nil {
+		err := rpcservice.NewRPCError(rpcservice.SubcribeError, err)
+		cResult <- RpcSubResult{Error: err}
+		return
+	}
+	defer func() {
+		Logger.log.Info("Finish Subscribe Tx Lifecycle ", txHash)
+		wsServer.config.PubSubManager.Unsubscribe(pubsub.TxLifecycleTopic, subId)
+		close(cResult)
+	}()
+	for {
+		select {
+		case msg := <-subChan:
+			{
+				event, ok := msg.Value.(*pubsub.TxEvent)
+				if !ok {
+					Logger.log.Errorf("Wrong Message Type from Pubsub Manager, wanted *pubsub.TxEvent, have %+v", reflect.TypeOf(msg.Value))
+					continue
+				}
+				if txHash != "" && event.TxHash != txHash {
+					continue
+				}
+				cResult <- RpcSubResult{Result: event, Error: nil}
+			}
+		case <-closeChan:
+			{
+				cResult <- RpcSubResult{Result: jsonresult.UnsubcribeResult{Message: "Unsubscribe Tx Lifecycle " + txHash}}
+				return
+			}
+		}
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
 	"github.com/levietcuong2602/incognito-chain/databasemp"
 	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/incdb/pebbledb"
 	"github.com/levietcuong2602/incognito-chain/incognitokey"
 	"github.com/levietcuong2602/incognito-chain/memcache"
 	"github.com/levietcuong2602/incognito-chain/mempool"
@@ -285,10 +286,11 @@ func (serverObj *Server) NewServer(
 			OnAddr:           serverObj.OnAddr,
 
 			//mubft
-			OnBFTMsg:     serverObj.OnBFTMsg,
-			OnPeerState:  serverObj.OnPeerState,
-			OnFinishSync: serverObj.OnFinishSync,
-			OnFeatureMsg: serverObj.OnFeatureMsg,
+			OnBFTMsg:          serverObj.OnBFTMsg,
+			OnPeerState:       serverObj.OnPeerState,
+			OnFinishSync:      serverObj.OnFinishSync,
+			OnFeatureMsg:      serverObj.OnFeatureMsg,
+			OnEquivocationMsg: serverObj.OnEquivocationMsg,
 		},
 		BC: serverObj.blockChain,
 	}
@@ -312,6 +314,33 @@ func (serverObj *Server) NewServer(
 		serverObj.pusubManager,
 		time.Duration(cfg.TxPoolTTL)*time.Second,
 	)
+	poolManager.RestoreFeeEstimators(serverObj.dataBase)
+	typeQuota, err := txpool.ParseTypeQuota(cfg.TxPoolTypeQuota)
+	if err != nil {
+		Logger.log.Error("could not parse tx pool type quota")
+		return err
+	}
+	poolManager.SetLimits(txpool.TxsPoolLimits{
+		MaxTx:     cfg.TxPoolMaxTx,
+		MaxSize:   cfg.TxPoolMaxSize,
+		TypeQuota: typeQuota,
+	})
+	if cfg.TxPoolPersist {
+		txPoolDBPath := filepath.Join(cfg.DataDir, config.DefaultTxPoolDirname)
+		txPoolDB, err := incdb.Open(pebbledb.DetectDriver(txPoolDBPath, cfg.DatabaseDriver), txPoolDBPath)
+		if err != nil {
+			Logger.log.Error("could not open tx pool database")
+			return err
+		}
+		maxTx, maxSize := cfg.TxPoolPersistMaxTx, cfg.TxPoolPersistMaxSize
+		if maxTx == 0 {
+			maxTx = config.DefaultTxPoolPersistMaxTx
+		}
+		if maxSize == 0 {
+			maxSize = config.DefaultTxPoolPersistMaxSize
+		}
+		poolManager.EnablePersistence(txPoolDB, maxTx, maxSize)
+	}
 	err = serverObj.blockChain.Init(&blockchain.Config{
 		BTCChain:      btcChain,
 		BNBChainState: bnbChainState,
@@ -351,6 +380,8 @@ func (serverObj *Server) NewServer(
 		serverObj.Pruner.SetShardInsertLock(sid, serverObj.blockChain.ShardChain[sid].GetInsertLock())
 		serverObj.Pruner.SetShardBlockStorage(sid, serverObj.blockChain.ShardChain[sid].BlockStorage)
 	}
+	serverObj.Pruner.SetBeaconInsertLock(serverObj.blockChain.BeaconChain.GetInsertLock())
+	serverObj.Pruner.SetBeaconBlockStorage(serverObj.blockChain.BeaconChain.BlockStorage)
 	go serverObj.Pruner.Start()
 
 	// or if it cannot be loaded, create a new one.
@@ -637,6 +668,10 @@ func (serverObj *Server) Stop() error {
 		}
 	}
 
+	if pm := serverObj.blockChain.GetPoolManager(); pm != nil {
+		pm.SaveFeeEstimators(serverObj.dataBase)
+	}
+
 	err := serverObj.consensusEngine.Stop()
 	if err != nil {
 		Logger.log.Error(err)
@@ -998,6 +1033,13 @@ func (serverObj *Server) OnFeatureMsg(p *peer.PeerConn, msg *wire.MessageFeature
 	blockchain.DefaultFeatureStat.ReceiveMsg(msg)
 }
 
+// OnEquivocationMsg handle equivocation evidence message
+func (serverObj *Server) OnEquivocationMsg(p *peer.PeerConn, msg *wire.MessageEquivocation) {
+	if err := serverObj.blockChain.AddEquivocationEvidence(msg.Evidence); err != nil {
+		Logger.log.Error("Invalid equivocation evidence", err)
+	}
+}
+
 // OnBlock is invoked when a peer receives a block message.  It
 // blocks until the coin block has been fully processed.
 func (serverObj *Server) OnBlockShard(p *peer.PeerConn,
@@ -2187,3 +2229,7 @@ func (serverObj *Server) GetSelfPeerID() libp2p.ID {
 func (serverObj *Server) InsertNewShardView(newView *blockchain.ShardBestState) {
 	serverObj.Pruner.InsertNewView(newView)
 }
+
+func (serverObj *Server) InsertNewBeaconView(newView *blockchain.BeaconBestState) {
+	serverObj.Pruner.InsertNewBeaconView(newView)
+}
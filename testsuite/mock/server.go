@@ -21,6 +21,10 @@ func (s *Server) InsertNewShardView(state *blockchain.ShardBestState) {
 	return
 }
 
+func (s *Server) InsertNewBeaconView(state *blockchain.BeaconBestState) {
+	return
+}
+
 func (s *Server) PushBlockToAll(block types.BlockInterface, previousValidationData string, isBeacon bool) error {
 	return nil
 }
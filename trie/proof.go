@@ -3,6 +3,7 @@ package trie
 import (
 	"bytes"
 	"fmt"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/levietcuong2602/incognito-chain/common"
@@ -16,7 +17,7 @@ import (
 // If the trie does not contain a value for key, the returned proof contains all
 // nodes of the longest existing prefix of the key (at least the root node), ending
 // with the node that proves the absence of the key.
-func (t *Trie) Prove(key []byte, fromLevel uint, proofDb incdb.Database) error {
+func (t *Trie) Prove(key []byte, fromLevel uint, proofDb incdb.KeyValueWriter) error {
 	// Collect all nodes on the path to key.
 	key = keybytesToHex(key)
 	var nodes []node
@@ -79,7 +80,7 @@ func (t *Trie) Prove(key []byte, fromLevel uint, proofDb incdb.Database) error {
 // If the trie does not contain a value for key, the returned proof contains all
 // nodes of the longest existing prefix of the key (at least the root node), ending
 // with the node that proves the absence of the key.
-func (t *SecureTrie) Prove(key []byte, fromLevel uint, proofDb incdb.Database) error {
+func (t *SecureTrie) Prove(key []byte, fromLevel uint, proofDb incdb.KeyValueWriter) error {
 	return t.trie.Prove(key, fromLevel, proofDb)
 }
 
@@ -90,14 +91,14 @@ func (t *SecureTrie) Prove(key []byte, fromLevel uint, proofDb incdb.Database) e
 // If the trie does not contain a value for key, the returned proof contains all
 // nodes of the longest existing prefix of the key (at least the root node), ending
 // with the node that proves the absence of the key.
-func (t *PrefixTrie) Prove(key []byte, fromLevel uint, proofDb incdb.Database) error {
+func (t *PrefixTrie) Prove(key []byte, fromLevel uint, proofDb incdb.KeyValueWriter) error {
 	return t.trie.Prove(key, fromLevel, proofDb)
 }
 
 // VerifyProof checks merkle proofs. The given proof must contain the value for
 // key in a trie with the given root hash. VerifyProof returns an error if the
 // proof contains invalid trie nodes or the wrong value.
-func VerifyProof(rootHash common.Hash, key []byte, proofDb incdb.Database) (value []byte, nodes int, err error) {
+func VerifyProof(rootHash common.Hash, key []byte, proofDb incdb.KeyValueReader) (value []byte, nodes int, err error) {
 	key = keybytesToHex(key)
 	wantHash := rootHash
 	for i := 0; ; i++ {
@@ -146,3 +147,54 @@ func get(tn node, key []byte) ([]byte, node) {
 		}
 	}
 }
+
+// ProofSet is an in-memory set of proof nodes keyed by their hash. Prove
+// writes a proof into it and VerifyProof reads a proof from it.
+type ProofSet map[string][]byte
+
+// NewProofSet returns the proof set of the encoded nodes of a proof
+func NewProofSet(nodes [][]byte) ProofSet {
+	hasher := newHasher(nil)
+	defer returnHasherToPool(hasher)
+	set := make(ProofSet, len(nodes))
+	for _, enc := range nodes {
+		set[string(hasher.makeHashNode(enc))] = common.CopyBytes(enc)
+	}
+	return set
+}
+
+func (set ProofSet) Put(key []byte, value []byte) error {
+	set[string(key)] = common.CopyBytes(value)
+	return nil
+}
+
+func (set ProofSet) Delete(key []byte) error {
+	delete(set, string(key))
+	return nil
+}
+
+func (set ProofSet) Has(key []byte) (bool, error) {
+	_, ok := set[string(key)]
+	return ok, nil
+}
+
+func (set ProofSet) Get(key []byte) ([]byte, error) {
+	if value, ok := set[string(key)]; ok {
+		return value, nil
+	}
+	return nil, fmt.Errorf("proof node %x not found", key)
+}
+
+// Nodes returns the encoded nodes of the set, ordered by hash
+func (set ProofSet) Nodes() [][]byte {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	nodes := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		nodes = append(nodes, set[key])
+	}
+	return nodes
+}
@@ -0,0 +1,210 @@
+package txpool
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain/types"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/metadata"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultFeeEstimatorDepth is the number of shard blocks the fees of the
+	// confirmed txs are kept for
+	DefaultFeeEstimatorDepth = 200
+	// maxObservedTxs bounds the txs of the pool waiting for a block
+	maxObservedTxs = 100000
+)
+
+// FeePercentiles are the percentiles of fee per KB returned by the estimator
+var FeePercentiles = []int{10, 25, 50, 75, 90}
+
+// feeSample is the fee per KB a tx paid in its fee token and the number of
+// blocks it waited for, 1 when it was confirmed by the next block
+type feeSample struct {
+	FeePerKB uint64
+	Blocks   uint64
+}
+
+type feeBlock struct {
+	Height  uint64
+	Samples map[string][]feeSample //by fee token
+}
+
+// PoolFeeEstimator estimates the fee per KB a tx of a shard should pay in a
+// token to be confirmed within a number of blocks. The pool tells when each tx
+// is first seen and the confirmed shard blocks when it is confirmed.
+type PoolFeeEstimator struct {
+	lock       sync.RWMutex
+	depth      uint64
+	lastHeight uint64
+	observed   map[string]uint64 //height of the shard when the tx was seen
+	blocks     []feeBlock
+}
+
+// FeeEstimate is the fee per KB in TokenID by percentile paid by the Samples
+// txs confirmed within Target blocks
+type FeeEstimate struct {
+	TokenID  string
+	Target   uint64
+	Samples  int
+	FeePerKB map[int]uint64
+}
+
+type poolFeeEstimatorState struct {
+	LastHeight uint64
+	Observed   map[string]uint64
+	Blocks     []feeBlock
+}
+
+func NewPoolFeeEstimator(depth uint64) *PoolFeeEstimator {
+	return &PoolFeeEstimator{
+		depth:    depth,
+		observed: map[string]uint64{},
+		blocks:   []feeBlock{},
+	}
+}
+
+// feeTokenOf returns the token tx pays its fee in and the fee
+func feeTokenOf(tx metadata.Transaction) (common.Hash, uint64) {
+	if feeToken := tx.GetTxFeeToken(); feeToken > 0 {
+		return *tx.GetTokenID(), feeToken
+	}
+	return common.PRVCoinID, tx.GetTxFee()
+}
+
+// ObserveTx records that the tx txHash entered the pool
+func (e *PoolFeeEstimator) ObserveTx(txHash string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if _, ok := e.observed[txHash]; ok || len(e.observed) >= maxObservedTxs {
+		return
+	}
+	e.observed[txHash] = e.lastHeight
+}
+
+// RegisterBlock records the fees of the txs of the pool confirmed by block. A
+// block at a height already registered replaces the blocks from this height.
+func (e *PoolFeeEstimator) RegisterBlock(block *types.ShardBlock) {
+	height := block.Header.Height
+	fb := feeBlock{Height: height, Samples: map[string][]feeSample{}}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, tx := range block.Body.Transactions {
+		if !isTxForUser(tx) {
+			continue
+		}
+		// the txs the pool did not see, or saw before it knew the height of
+		// the shard, waited for an unknown number of blocks
+		txHash := tx.Hash().String()
+		seen, ok := e.observed[txHash]
+		if !ok {
+			continue
+		}
+		delete(e.observed, txHash)
+		if seen == 0 || seen >= height {
+			continue
+		}
+		blocks := height - seen
+		tokenID, fee := feeTokenOf(tx)
+		if fee == 0 {
+			continue
+		}
+		token := tokenID.String()
+		fb.Samples[token] = append(fb.Samples[token], feeSample{
+			FeePerKB: feePerKB(fee, tx.GetTxActualSize()),
+			Blocks:   blocks,
+		})
+	}
+	i := len(e.blocks)
+	for i > 0 && e.blocks[i-1].Height >= height {
+		i--
+	}
+	e.blocks = append(e.blocks[:i], fb)
+	if uint64(len(e.blocks)) > e.depth {
+		e.blocks = e.blocks[uint64(len(e.blocks))-e.depth:]
+	}
+	e.lastHeight = height
+	for txHash, seen := range e.observed {
+		if seen+e.depth < height {
+			delete(e.observed, txHash)
+		}
+	}
+}
+
+// EstimateFee returns the percentiles of the fee per KB in tokenID of the txs
+// confirmed within target blocks
+func (e *PoolFeeEstimator) EstimateFee(target uint64, tokenID common.Hash) (*FeeEstimate, error) {
+	if target == 0 || target > e.depth {
+		return nil, errors.Errorf("target %v is not between 1 and %v blocks", target, e.depth)
+	}
+	token := tokenID.String()
+	fees := []uint64{}
+	e.lock.RLock()
+	for _, fb := range e.blocks {
+		for _, s := range fb.Samples[token] {
+			if s.Blocks <= target {
+				fees = append(fees, s.FeePerKB)
+			}
+		}
+	}
+	e.lock.RUnlock()
+	if len(fees) == 0 {
+		return nil, errors.Errorf("no tx paying fee in token %v confirmed within %v blocks", token, target)
+	}
+	sort.Slice(fees, func(i, j int) bool { return fees[i] < fees[j] })
+	res := &FeeEstimate{
+		TokenID:  token,
+		Target:   target,
+		Samples:  len(fees),
+		FeePerKB: map[int]uint64{},
+	}
+	for _, p := range FeePercentiles {
+		// nearest rank
+		rank := (p*len(fees) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		res.FeePerKB[p] = fees[rank-1]
+	}
+	return res, nil
+}
+
+// Save returns the history of the estimator to restore it after a restart
+func (e *PoolFeeEstimator) Save() ([]byte, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return json.Marshal(poolFeeEstimatorState{
+		LastHeight: e.lastHeight,
+		Observed:   e.observed,
+		Blocks:     e.blocks,
+	})
+}
+
+// Restore replaces the history of the estimator by the saved one
+func (e *PoolFeeEstimator) Restore(data []byte) error {
+	state := poolFeeEstimatorState{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if state.Observed == nil {
+		state.Observed = map[string]uint64{}
+	}
+	if uint64(len(state.Blocks)) > e.depth {
+		state.Blocks = state.Blocks[uint64(len(state.Blocks))-e.depth:]
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.lastHeight = state.LastHeight
+	e.observed = state.Observed
+	e.blocks = state.Blocks
+	return nil
+}
+
+// GetFeeEstimator returns the fee estimator fed by the pool
+func (tp *TxsPool) GetFeeEstimator() *PoolFeeEstimator {
+	return tp.feeEstimator
+}
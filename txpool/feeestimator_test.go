@@ -0,0 +1,108 @@
+package txpool
+
+import (
+	"testing"
+
+	"github.com/levietcuong2602/incognito-chain/blockchain/types"
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestShardBlock(height uint64, txs ...metadata.Transaction) *types.ShardBlock {
+	block := types.NewShardBlock()
+	block.Header.Height = height
+	block.Body.Transactions = txs
+	return block
+}
+
+// newTestFeeEstimator registers ten txs paying 1 to 10 times a base fee per
+// KB, the five cheapest waited for 3 blocks, the others for 1 block, and a
+// tx the pool did not see paying much more
+func newTestFeeEstimator() (*PoolFeeEstimator, uint64) {
+	e := NewPoolFeeEstimator(10)
+	e.RegisterBlock(newTestShardBlock(1))
+	txs := []metadata.Transaction{}
+	for i := 1; i <= 10; i++ {
+		tx := newTestTx(int64(i), uint64(i)*1000)
+		e.ObserveTx(tx.Hash().String())
+		txs = append(txs, tx)
+	}
+	e.RegisterBlock(newTestShardBlock(2, txs[5:]...))
+	e.RegisterBlock(newTestShardBlock(3))
+	unseen := newTestTx(11, 1000000)
+	e.RegisterBlock(newTestShardBlock(4, append(txs[:5], unseen)...))
+	return e, txs[0].GetTxActualSize()
+}
+
+func TestPoolFeeEstimator_EstimateFee(t *testing.T) {
+	e, size := newTestFeeEstimator()
+	fee := func(i uint64) uint64 { return feePerKB(i*1000, size) }
+	tests := []struct {
+		name     string
+		target   uint64
+		samples  int
+		feePerKB map[int]uint64
+		wantErr  bool
+	}{
+		{name: "next block", target: 1, samples: 5, feePerKB: map[int]uint64{10: fee(6), 25: fee(7), 50: fee(8), 75: fee(9), 90: fee(10)}},
+		{name: "two blocks", target: 2, samples: 5, feePerKB: map[int]uint64{10: fee(6), 25: fee(7), 50: fee(8), 75: fee(9), 90: fee(10)}},
+		{name: "three blocks", target: 3, samples: 10, feePerKB: map[int]uint64{10: fee(1), 25: fee(3), 50: fee(5), 75: fee(8), 90: fee(9)}},
+		{name: "zero target", target: 0, wantErr: true},
+		{name: "over the depth", target: 11, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := e.EstimateFee(tt.target, common.PRVCoinID)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.samples, res.Samples)
+			assert.Equal(t, tt.feePerKB, res.FeePerKB)
+		})
+	}
+
+	//no tx paid its fee in another token
+	_, err := e.EstimateFee(3, common.ConfidentialAssetID)
+	assert.NotNil(t, err)
+}
+
+func TestPoolFeeEstimator_SaveRestore(t *testing.T) {
+	e, _ := newTestFeeEstimator()
+	pending := newTestTx(12, 1000)
+	e.ObserveTx(pending.Hash().String())
+	data, err := e.Save()
+	assert.Nil(t, err)
+
+	restored := NewPoolFeeEstimator(10)
+	assert.Nil(t, restored.Restore(data))
+	for target := uint64(1); target <= 3; target++ {
+		want, err := e.EstimateFee(target, common.PRVCoinID)
+		assert.Nil(t, err)
+		got, err := restored.EstimateFee(target, common.PRVCoinID)
+		assert.Nil(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	//the txs seen before the restart keep their height
+	restored.RegisterBlock(newTestShardBlock(6, pending))
+	res, err := restored.EstimateFee(1, common.PRVCoinID)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, res.Samples)
+	res, err = restored.EstimateFee(2, common.PRVCoinID)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, res.Samples)
+	res, err = restored.EstimateFee(3, common.PRVCoinID)
+	assert.Nil(t, err)
+	assert.Equal(t, 11, res.Samples)
+
+	//a shorter estimator keeps the last blocks only
+	short := NewPoolFeeEstimator(2)
+	assert.Nil(t, short.Restore(data))
+	res, err = short.EstimateFee(2, common.PRVCoinID)
+	assert.NotNil(t, err)
+	assert.Nil(t, res)
+	assert.NotNil(t, short.Restore([]byte("garbage")))
+}
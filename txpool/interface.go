@@ -45,6 +45,7 @@ type PrefetchInterface interface {
 }
 type TxPool interface {
 	UpdateTxVerifier(tv TxVerifier)
+	UpdateViewGetter(vg ViewGetter)
 	Start()
 	Stop()
 	GetInbox() chan metadata.Transaction
@@ -79,6 +80,7 @@ type TxPool interface {
 	snapshotPoolOutCoin() map[common.Hash]interface{}
 	getTxByHash(txID string) metadata.Transaction
 	RemoveTx(txHash string)
+	GetFeeEstimator() *PoolFeeEstimator
 }
 
 type BlockTxsVerifier interface {
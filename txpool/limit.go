@@ -0,0 +1,175 @@
+package txpool
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/levietcuong2602/incognito-chain/metadata"
+	"github.com/pkg/errors"
+)
+
+// AnyMetaType is the key of the quota of the metadata types without their own
+const AnyMetaType = -1
+
+// PoolUsage is the number and the total size in KB of txs of the pool
+type PoolUsage struct {
+	Count uint64
+	Size  uint64
+}
+
+// TxsPoolLimits bounds the pool of a shard to MaxTx txs of MaxSize KB, 0 for
+// no limit. TypeQuota is the share in percent of both limits the txs of a
+// metadata type may take, AnyMetaType for the types not listed. The txs
+// without metadata have no quota.
+type TxsPoolLimits struct {
+	MaxTx     uint64
+	MaxSize   uint64
+	TypeQuota map[int]uint64
+}
+
+// ParseTypeQuota parses quotas written as type:percent separated by commas,
+// * for the metadata types not listed, e.g. "*:30,285:10"
+func ParseTypeQuota(s string) (map[int]uint64, error) {
+	res := map[int]uint64{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.Split(item, ":")
+		if len(kv) != 2 {
+			return nil, errors.Errorf("quota %v is not type:percent", item)
+		}
+		metaType := AnyMetaType
+		if kv[0] != "*" {
+			t, err := strconv.Atoi(kv[0])
+			if err != nil || t <= metadata.InvalidMeta {
+				return nil, errors.Errorf("metadata type of quota %v is invalid", item)
+			}
+			metaType = t
+		}
+		percent, err := strconv.ParseUint(kv[1], 10, 64)
+		if err != nil || percent == 0 || percent > 100 {
+			return nil, errors.Errorf("percent of quota %v is invalid", item)
+		}
+		res[metaType] = percent
+	}
+	return res, nil
+}
+
+// quotaOf returns the most txs and KB the txs of metaType may take, 0 for no
+// limit
+func (l TxsPoolLimits) quotaOf(metaType int) (uint64, uint64) {
+	if metaType == metadata.InvalidMeta {
+		return 0, 0
+	}
+	percent, ok := l.TypeQuota[metaType]
+	if !ok {
+		percent = l.TypeQuota[AnyMetaType]
+	}
+	if percent == 0 {
+		return 0, 0
+	}
+	return l.MaxTx * percent / 100, l.MaxSize * percent / 100
+}
+
+func fits(u PoolUsage, info TxInfo, maxTx, maxSize uint64) bool {
+	return (maxTx == 0 || u.Count+1 <= maxTx) && (maxSize == 0 || u.Size+info.Size <= maxSize)
+}
+
+// evictedBefore tells whether the tx a is evicted before the tx b: the lowest
+// fee per KB first, then the oldest, then the highest hash
+func evictedBefore(a TxInfo, aHash string, b TxInfo, bHash string) bool {
+	if a.FeePerKB != b.FeePerKB {
+		return a.FeePerKB < b.FeePerKB
+	}
+	if !a.Added.Equal(b.Added) {
+		return a.Added.Before(b.Added)
+	}
+	return aHash > bHash
+}
+
+// worstTx returns the next tx to evict among the txs of metaType, of any type
+// for AnyMetaType, skipping the ones in excluded
+func (tp *TxsPool) worstTx(metaType int, excluded map[string]bool) (string, TxInfo, bool) {
+	worstHash, worst, found := "", TxInfo{}, false
+	for txHash, info := range tp.Data.TxInfos {
+		if excluded[txHash] {
+			continue
+		}
+		if tx, ok := tp.Data.TxByHash[txHash]; !ok || tx == nil || (metaType != AnyMetaType && tx.GetMetadataType() != metaType) {
+			continue
+		}
+		if !found || evictedBefore(info, txHash, worst, worstHash) {
+			worstHash, worst, found = txHash, info, true
+		}
+	}
+	return worstHash, worst, found
+}
+
+// txEviction is a tx evicted from the pool to make room for another one
+type txEviction struct {
+	txHash string
+	reason string
+}
+
+// evictionsFor returns the txs to evict, in order, for tx to fit in the pool
+// once the tx replaced is removed. It fails when the txs to evict do not pay
+// less per KB than tx, the pool is left untouched.
+func (tp *TxsPool) evictionsFor(tx metadata.Transaction, info TxInfo, replaced string) ([]txEviction, error) {
+	res := []txEviction{}
+	excluded := map[string]bool{}
+	total := PoolUsage{Count: uint64(len(tp.Data.TxInfos))}
+	for _, u := range tp.usage {
+		total.Size += u.Size
+	}
+	metaType := tx.GetMetadataType()
+	typeUsage := *tp.usageOf(metaType)
+	evict := func(txHash string, evicted TxInfo, reason string) {
+		excluded[txHash] = true
+		total.Count--
+		total.Size -= evicted.Size
+		if t, ok := tp.Data.TxByHash[txHash]; ok && t.GetMetadataType() == metaType {
+			typeUsage.Count--
+			typeUsage.Size -= evicted.Size
+		}
+		if reason != "" {
+			res = append(res, txEviction{txHash: txHash, reason: reason})
+		}
+	}
+	if replacedInfo, ok := tp.Data.TxInfos[replaced]; ok {
+		evict(replaced, replacedInfo, "")
+	}
+
+	maxTx, maxSize := tp.limits.quotaOf(metaType)
+	for !fits(typeUsage, info, maxTx, maxSize) {
+		txHash, worst, ok := tp.worstTx(metaType, excluded)
+		if !ok || worst.FeePerKB >= info.FeePerKB {
+			return nil, errors.Errorf("quota of metadata type %v in pool is full", metaType)
+		}
+		evict(txHash, worst, "quota of metadata type full")
+	}
+	for !fits(total, info, tp.limits.MaxTx, tp.limits.MaxSize) {
+		txHash, worst, ok := tp.worstTx(AnyMetaType, excluded)
+		if !ok || worst.FeePerKB >= info.FeePerKB {
+			return nil, errors.New("pool is full")
+		}
+		evict(txHash, worst, "pool full")
+	}
+	return res, nil
+}
+
+func (tp *TxsPool) usageOf(metaType int) *PoolUsage {
+	u, ok := tp.usage[metaType]
+	if !ok {
+		u = &PoolUsage{}
+		tp.usage[metaType] = u
+	}
+	return u
+}
+
+// SetLimits bounds the pool, the txs already in it are only evicted to make
+// room for the next ones
+func (tp *TxsPool) SetLimits(limits TxsPoolLimits) {
+	tp.limits = limits
+}
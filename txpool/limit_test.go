@@ -0,0 +1,223 @@
+package txpool
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/metadata"
+	"github.com/levietcuong2602/incognito-chain/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTypeQuota(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    map[int]uint64
+		wantErr bool
+	}{
+		{name: "empty", s: "", want: map[int]uint64{}},
+		{name: "types and the others", s: "*:30,285:10", want: map[int]uint64{AnyMetaType: 30, 285: 10}},
+		{name: "spaces and trailing comma", s: " 91:50 , ", want: map[int]uint64{91: 50}},
+		{name: "no percent", s: "91", wantErr: true},
+		{name: "too many colons", s: "91:50:1", wantErr: true},
+		{name: "type not a number", s: "trade:50", wantErr: true},
+		{name: "invalid type", s: "1:50", wantErr: true},
+		{name: "percent not a number", s: "*:half", wantErr: true},
+		{name: "zero percent", s: "91:0", wantErr: true},
+		{name: "over 100 percent", s: "91:101", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTypeQuota(tt.s)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type testPoolTx struct {
+	metaType int
+	fee      uint64
+	age      time.Duration
+}
+
+// newTestMetaTx returns a tx of 1 KB of metaType paying fee, id tells the txs
+// apart
+func newTestMetaTx(id int64, metaType int, fee uint64) metadata.Transaction {
+	tx := newTestTx(id, fee).(*transaction.TxVersion1)
+	if metaType != metadata.InvalidMeta {
+		tx.Metadata = &metadata.PDETradeRequest{MetadataBase: *metadata.NewMetadataBase(metaType)}
+	}
+	return tx
+}
+
+func TestTxsPool_EvictionsFor(t *testing.T) {
+	Logger.Init(common.NewBackend(nil).Logger("test", true))
+	trade := metadata.PDETradeRequestMeta
+	noMeta := metadata.InvalidMeta
+	tests := []struct {
+		name     string
+		limits   TxsPoolLimits
+		pool     []testPoolTx
+		tx       testPoolTx
+		replaced int //index in pool of the tx replaced, -1 for none
+		evicted  []int
+		reasons  []string
+		wantErr  string
+	}{
+		{
+			name:     "room left",
+			limits:   TxsPoolLimits{MaxTx: 3},
+			pool:     []testPoolTx{{noMeta, 1, 0}, {noMeta, 2, 0}},
+			tx:       testPoolTx{noMeta, 1, 0},
+			replaced: -1,
+		},
+		{
+			name:     "pool full evicts the lowest fee per KB",
+			limits:   TxsPoolLimits{MaxTx: 3},
+			pool:     []testPoolTx{{noMeta, 30, 0}, {noMeta, 10, 0}, {noMeta, 20, 0}},
+			tx:       testPoolTx{noMeta, 25, 0},
+			replaced: -1,
+			evicted:  []int{1},
+			reasons:  []string{"pool full"},
+		},
+		{
+			name:     "pool full evicts the oldest of the same fee per KB",
+			limits:   TxsPoolLimits{MaxTx: 3},
+			pool:     []testPoolTx{{noMeta, 10, time.Minute}, {noMeta, 10, time.Hour}, {noMeta, 20, 0}},
+			tx:       testPoolTx{noMeta, 25, 0},
+			replaced: -1,
+			evicted:  []int{1},
+			reasons:  []string{"pool full"},
+		},
+		{
+			name:     "lowered limits evict from the lowest fee per KB",
+			limits:   TxsPoolLimits{MaxTx: 2},
+			pool:     []testPoolTx{{noMeta, 1, 0}, {noMeta, 3, 0}, {noMeta, 2, 0}, {noMeta, 9, 0}},
+			tx:       testPoolTx{noMeta, 5, 0},
+			replaced: -1,
+			evicted:  []int{0, 2, 1},
+			reasons:  []string{"pool full", "pool full", "pool full"},
+		},
+		{
+			name:     "pool full of better txs",
+			limits:   TxsPoolLimits{MaxTx: 3},
+			pool:     []testPoolTx{{noMeta, 10, 0}, {noMeta, 20, 0}, {noMeta, 30, 0}},
+			tx:       testPoolTx{noMeta, 10, 0},
+			replaced: -1,
+			wantErr:  "pool is full",
+		},
+		{
+			name:     "pool full of larger txs",
+			limits:   TxsPoolLimits{MaxSize: 2},
+			pool:     []testPoolTx{{noMeta, 10, 0}, {noMeta, 20, 0}},
+			tx:       testPoolTx{noMeta, 5, 0},
+			replaced: -1,
+			wantErr:  "pool is full",
+		},
+		{
+			name:     "the replaced tx makes room",
+			limits:   TxsPoolLimits{MaxTx: 3},
+			pool:     []testPoolTx{{noMeta, 10, 0}, {noMeta, 20, 0}, {noMeta, 30, 0}},
+			tx:       testPoolTx{noMeta, 5, 0},
+			replaced: 2,
+		},
+		{
+			name:     "quota full evicts a tx of the same type",
+			limits:   TxsPoolLimits{MaxTx: 4, TypeQuota: map[int]uint64{trade: 50}},
+			pool:     []testPoolTx{{trade, 5, 0}, {trade, 8, 0}, {noMeta, 1, 0}},
+			tx:       testPoolTx{trade, 9, 0},
+			replaced: -1,
+			evicted:  []int{0},
+			reasons:  []string{"quota of metadata type full"},
+		},
+		{
+			name:     "quota of the other types",
+			limits:   TxsPoolLimits{MaxTx: 4, TypeQuota: map[int]uint64{AnyMetaType: 25}},
+			pool:     []testPoolTx{{trade, 5, 0}, {noMeta, 1, 0}},
+			tx:       testPoolTx{trade, 9, 0},
+			replaced: -1,
+			evicted:  []int{0},
+			reasons:  []string{"quota of metadata type full"},
+		},
+		{
+			name:     "quota then pool full",
+			limits:   TxsPoolLimits{MaxTx: 3, TypeQuota: map[int]uint64{trade: 34}},
+			pool:     []testPoolTx{{noMeta, 1, 0}, {trade, 5, 0}, {noMeta, 2, 0}, {noMeta, 7, 0}},
+			tx:       testPoolTx{trade, 9, 0},
+			replaced: -1,
+			evicted:  []int{1, 0},
+			reasons:  []string{"quota of metadata type full", "pool full"},
+		},
+		{
+			name:     "quota full of better txs",
+			limits:   TxsPoolLimits{MaxTx: 4, TypeQuota: map[int]uint64{trade: 50}},
+			pool:     []testPoolTx{{trade, 5, 0}, {trade, 8, 0}, {noMeta, 1, 0}},
+			tx:       testPoolTx{trade, 4, 0},
+			replaced: -1,
+			wantErr:  "quota of metadata type 91 in pool is full",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := NewTxsPool(&testVerifier{}, make(chan metadata.Transaction), time.Hour)
+			hashes := []string{}
+			for i, ptx := range tt.pool {
+				tx := newTestMetaTx(int64(i), ptx.metaType, ptx.fee)
+				tp.addValidTx(txInfoTemp{tx: tx, added: time.Now().Add(-ptx.age)})
+				hashes = append(hashes, tx.Hash().String())
+			}
+			tp.SetLimits(tt.limits)
+			tx := newTestMetaTx(int64(len(tt.pool)), tt.tx.metaType, tt.tx.fee)
+			replaced := ""
+			if tt.replaced >= 0 {
+				replaced = hashes[tt.replaced]
+			}
+			got, err := tp.evictionsFor(tx, tp.newTxInfo(txInfoTemp{tx: tx, added: time.Now()}), replaced)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, got)
+			} else {
+				assert.Nil(t, err)
+				want := []txEviction{}
+				for i, idx := range tt.evicted {
+					want = append(want, txEviction{txHash: hashes[idx], reason: tt.reasons[i]})
+				}
+				assert.Equal(t, want, got)
+			}
+
+			//the tx is added with the evictions only
+			if tt.replaced >= 0 {
+				return
+			}
+			tp.addValidTx(txInfoTemp{tx: tx})
+			want := []string{}
+			if tt.wantErr == "" {
+				want = append(want, tx.Hash().String())
+			}
+			for i, txHash := range hashes {
+				evicted := false
+				for _, idx := range tt.evicted {
+					evicted = evicted || idx == i
+				}
+				if !evicted {
+					want = append(want, txHash)
+				}
+			}
+			inPool := []string{}
+			for txHash := range tp.Data.TxByHash {
+				inPool = append(inPool, txHash)
+			}
+			sort.Strings(want)
+			sort.Strings(inPool)
+			assert.Equal(t, want, inPool)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package txpool
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	"github.com/levietcuong2602/incognito-chain/metadata"
+	"github.com/levietcuong2602/incognito-chain/transaction"
+	"github.com/pkg/errors"
+)
+
+// ViewGetter returns the best views of the chain the txs of a pool are
+// validated with
+type ViewGetter func() (
+	metadata.ChainRetriever,
+	metadata.ShardViewRetriever,
+	metadata.BeaconViewRetriever,
+	error,
+)
+
+// persistedTx is a tx of the pool as stored on disk, AddedTime is when it
+// entered the pool so that it expires as it would have without a restart
+type persistedTx struct {
+	TxType    string
+	Tx        json.RawMessage
+	AddedTime time.Time
+}
+
+// poolPersistence writes the txs of the pool of a shard through to db, up to
+// maxTx txs of maxSize KB in total. The txs over the limits are only kept in
+// memory.
+type poolPersistence struct {
+	db      incdb.Database
+	shardID byte
+	maxTx   uint64
+	maxSize uint64
+	loaded  sync.Once
+
+	lock   sync.Mutex
+	stored map[string]uint64 //size of the stored txs by hash
+	size   uint64
+}
+
+func newPoolPersistence(db incdb.Database, shardID byte, maxTx, maxSize uint64) *poolPersistence {
+	return &poolPersistence{
+		db:      db,
+		shardID: shardID,
+		maxTx:   maxTx,
+		maxSize: maxSize,
+		stored:  map[string]uint64{},
+	}
+}
+
+func (p *poolPersistence) store(tx metadata.Transaction, size uint64, added time.Time) {
+	txHash := tx.Hash()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if _, ok := p.stored[txHash.String()]; ok {
+		return
+	}
+	if uint64(len(p.stored)) >= p.maxTx || p.size+size > p.maxSize {
+		Logger.Debugf("[txTracing] Tx pool storage of shard %v is full, tx %v is kept in memory only", p.shardID, txHash.String())
+		return
+	}
+	txBytes, err := json.Marshal(tx)
+	if err != nil {
+		Logger.Errorf("Cannot marshal tx %v to store it: %v", txHash.String(), err)
+		return
+	}
+	value, err := json.Marshal(persistedTx{TxType: tx.GetType(), Tx: txBytes, AddedTime: added})
+	if err != nil {
+		Logger.Errorf("Cannot marshal tx %v to store it: %v", txHash.String(), err)
+		return
+	}
+	if err := rawdbv2.StoreTxPoolTx(p.db, p.shardID, *txHash, value); err != nil {
+		Logger.Errorf("Cannot store tx %v of shard %v: %v", txHash.String(), p.shardID, err)
+		return
+	}
+	p.stored[txHash.String()] = size
+	p.size += size
+}
+
+func (p *poolPersistence) remove(txHash string) {
+	h, err := common.Hash{}.NewHashFromStr(txHash)
+	if err != nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if err := rawdbv2.DeleteTxPoolTx(p.db, p.shardID, *h); err != nil {
+		Logger.Errorf("Cannot delete stored tx %v of shard %v: %v", txHash, p.shardID, err)
+		return
+	}
+	p.size -= p.stored[txHash]
+	delete(p.stored, txHash)
+}
+
+func decodePersistedTx(value []byte) (metadata.Transaction, time.Time, error) {
+	stored := persistedTx{}
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, time.Time{}, err
+	}
+	switch stored.TxType {
+	case common.TxNormalType, common.TxConversionType:
+		tx, err := transaction.NewTransactionFromJsonBytes(stored.Tx)
+		return tx, stored.AddedTime, err
+	case common.TxCustomTokenPrivacyType, common.TxTokenConversionType:
+		tx, err := transaction.NewTransactionTokenFromJsonBytes(stored.Tx)
+		return tx, stored.AddedTime, err
+	}
+	return nil, time.Time{}, errors.Errorf("unknown tx type %v", stored.TxType)
+}
+
+// EnablePersistence stores the txs of the pool of shardID in db, the stored
+// txs are loaded back the first time the pool starts
+func (tp *TxsPool) EnablePersistence(db incdb.Database, shardID byte, maxTx, maxSize uint64) {
+	tp.persistence = newPoolPersistence(db, shardID, maxTx, maxSize)
+}
+
+// UpdateViewGetter sets the views the txs loaded from disk are filtered with
+func (tp *TxsPool) UpdateViewGetter(vg ViewGetter) {
+	tp.viewGetter = vg
+}
+
+// loadPersistedTxs validates the stored txs again and puts the valid ones back
+// into the pool with what remains of their TTL, the expired and invalid ones
+// are deleted. The pool is then filtered with the best view of the chain.
+func (tp *TxsPool) loadPersistedTxs() {
+	p := tp.persistence
+	txs, err := rawdbv2.GetTxPoolTxs(p.db, p.shardID)
+	if err != nil {
+		Logger.Errorf("Cannot load the stored txs of shard %v: %v", p.shardID, err)
+		return
+	}
+	validTxs := []txInfoTemp{}
+	dropped := 0
+	for txHash, value := range txs {
+		if !tp.IsRunning() {
+			return
+		}
+		tx, added, err := decodePersistedTx(value)
+		if err != nil || tx.Hash().String() != txHash.String() {
+			Logger.Errorf("Cannot decode stored tx %v of shard %v: %v", txHash.String(), p.shardID, err)
+			p.remove(txHash.String())
+			dropped++
+			continue
+		}
+		remaining := tp.ttl - time.Since(added)
+		if remaining <= 0 {
+			p.remove(txHash.String())
+			dropped++
+			continue
+		}
+		ok, err, vTime := tp.ValidateNewTx(tx)
+		if err != nil {
+			Logger.Errorf("[txTracing] Stored tx %v of shard %v is invalid: %v", txHash.String(), p.shardID, err)
+			p.remove(txHash.String())
+			dropped++
+			continue
+		}
+		if !ok {
+			p.remove(txHash.String())
+			dropped++
+			continue
+		}
+		tp.Cacher.Set(txHash.String(), nil, remaining)
+		validTxs = append(validTxs, txInfoTemp{tx: tx, vt: vTime, added: added})
+	}
+	Logger.Infof("Load %v stored txs of shard %v, drop %v", len(validTxs), p.shardID, dropped)
+	if len(validTxs) == 0 {
+		return
+	}
+	// the txs are added in a single action so that a pool stopped during the
+	// validation does not leave this goroutine blocked on a send per tx
+	if !tp.IsRunning() {
+		return
+	}
+	tp.action <- func(tpTemp *TxsPool) {
+		for _, validTx := range validTxs {
+			tpTemp.addValidTx(validTx)
+		}
+	}
+	if tp.viewGetter == nil {
+		return
+	}
+	cView, sView, bcView, err := tp.viewGetter()
+	if err != nil {
+		Logger.Errorf("Cannot get the views to filter the stored txs of shard %v: %v", p.shardID, err)
+		return
+	}
+	tp.FilterWithNewView(cView, sView, bcView)
+}
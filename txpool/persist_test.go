@@ -0,0 +1,104 @@
+package txpool
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+	"github.com/levietcuong2602/incognito-chain/incdb"
+	_ "github.com/levietcuong2602/incognito-chain/incdb/lvdb"
+	"github.com/levietcuong2602/incognito-chain/metadata"
+	"github.com/levietcuong2602/incognito-chain/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+// testVerifier accepts every tx but the ones of invalid
+type testVerifier struct {
+	invalid map[string]bool
+}
+
+func (v *testVerifier) ValidateWithoutChainstate(tx metadata.Transaction) (bool, error) {
+	return !v.invalid[tx.Hash().String()], nil
+}
+
+func (v *testVerifier) ValidateWithChainState(metadata.Transaction, metadata.ChainRetriever, metadata.ShardViewRetriever, metadata.BeaconViewRetriever, uint64) (bool, error) {
+	return true, nil
+}
+
+func (v *testVerifier) FullValidateTransactions(metadata.ChainRetriever, metadata.ShardViewRetriever, metadata.BeaconViewRetriever, []metadata.Transaction) (bool, error) {
+	return true, nil
+}
+
+func (v *testVerifier) LoadCommitment(metadata.Transaction, metadata.ShardViewRetriever) (bool, error) {
+	return true, nil
+}
+
+func (v *testVerifier) PrepareDataForTxs([]metadata.Transaction, []metadata.Transaction, metadata.ShardViewRetriever) (bool, error) {
+	return true, nil
+}
+
+func (v *testVerifier) UpdateTransactionStateDB(*statedb.StateDB) {}
+
+func (v *testVerifier) UpdateFeeEstimator(FeeEstimator) {}
+
+func newTestTx(lockTime int64, fee uint64) metadata.Transaction {
+	tx := &transaction.TxVersion1{}
+	tx.Version = 1
+	tx.Type = common.TxNormalType
+	tx.LockTime = lockTime
+	tx.Fee = fee
+	return tx
+}
+
+func TestTxsPool_LoadPersistedTxs(t *testing.T) {
+	Logger.Init(common.NewBackend(nil).Logger("test", true))
+	dir, err := ioutil.TempDir("", "txpoolpersist")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	db, err := incdb.Open("leveldb", path.Join(dir, "txpool"))
+	assert.Nil(t, err)
+	defer db.Close()
+
+	ttl := time.Hour
+	valid := newTestTx(1, 10)
+	expired := newTestTx(2, 10)
+	invalid := newTestTx(3, 10)
+	p := newPoolPersistence(db, 0, 10, 1000)
+	p.store(valid, 1, time.Now().Add(-ttl/2))
+	p.store(expired, 1, time.Now().Add(-2*ttl))
+	p.store(invalid, 1, time.Now())
+	assert.Nil(t, rawdbv2.StoreTxPoolTx(db, 0, common.HashH([]byte("garbage")), []byte("garbage")))
+
+	tp := NewTxsPool(&testVerifier{invalid: map[string]bool{invalid.Hash().String(): true}}, make(chan metadata.Transaction), ttl)
+	tp.EnablePersistence(db, 0, 10, 1000)
+	go tp.Start()
+	defer tp.Stop()
+	for i := 0; i < 100 && !tp.IsRunning(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	//the valid tx is back in the pool with what remains of its ttl
+	inPool := false
+	for i := 0; i < 100 && !inPool; i++ {
+		time.Sleep(10 * time.Millisecond)
+		tp.CData.locker.RLock()
+		_, inPool = tp.Data.TxByHash[valid.Hash().String()]
+		tp.CData.locker.RUnlock()
+	}
+	assert.True(t, inPool)
+	_, expiration, ok := tp.Cacher.GetWithExpiration(valid.Hash().String())
+	assert.True(t, ok)
+	assert.True(t, time.Until(expiration) <= ttl/2)
+
+	//the expired, invalid and undecodable txs are deleted
+	stored, err := rawdbv2.GetTxPoolTxs(db, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stored))
+	_, ok = stored[*valid.Hash()]
+	assert.True(t, ok)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/levietcuong2602/incognito-chain/common"
 	"github.com/levietcuong2602/incognito-chain/metadata"
 	"github.com/levietcuong2602/incognito-chain/privacy"
+	"github.com/levietcuong2602/incognito-chain/pubsub"
 	"github.com/levietcuong2602/incognito-chain/transaction"
 	"github.com/levietcuong2602/incognito-chain/transaction/tx_generic"
 	"github.com/patrickmn/go-cache"
@@ -16,9 +17,11 @@ import (
 )
 
 type TxInfo struct {
-	Fee   uint64
-	Size  uint64
-	VTime time.Duration
+	Fee      uint64
+	Size     uint64
+	VTime    time.Duration
+	FeePerKB uint64    //fee per KB in PRV when the tx entered the pool
+	Added    time.Time //when the tx entered the pool
 }
 
 type validateResult struct {
@@ -28,11 +31,12 @@ type validateResult struct {
 }
 
 type TxInfoDetail struct {
-	Hash  string
-	Fee   uint64
-	Size  uint64
-	VTime time.Duration
-	Tx    metadata.Transaction
+	Hash     string
+	Fee      uint64
+	FeePerKB uint64 //fee per KB in PRV, the token fee converted
+	Size     uint64
+	VTime    time.Duration
+	Tx       metadata.Transaction
 }
 
 type TxsData struct {
@@ -47,8 +51,9 @@ type CoinsData struct {
 }
 
 type txInfoTemp struct {
-	tx metadata.Transaction
-	vt time.Duration
+	tx    metadata.Transaction
+	vt    time.Duration
+	added time.Time //when the tx entered the pool, now when zero
 }
 
 type TxsPool struct {
@@ -63,6 +68,15 @@ type TxsPool struct {
 	better    func(txA, txB metadata.Transaction) bool
 	ttl       time.Duration
 	CData     CoinsData
+
+	persistence  *poolPersistence
+	viewGetter   ViewGetter
+	ps           *pubsub.PubSubManager
+	shardID      byte
+	limits       TxsPoolLimits
+	usage        map[int]*PoolUsage           //by metadata type
+	feeView      metadata.BeaconViewRetriever //converts the token fees of new txs
+	feeEstimator *PoolFeeEstimator
 }
 
 func NewTxsPool(
@@ -95,11 +109,13 @@ func NewTxsPool(
 			TxHashByCoin:  map[string]string{},
 			CoinsByTxHash: map[string][]string{},
 		},
+		usage:        map[int]*PoolUsage{},
+		feeEstimator: NewPoolFeeEstimator(DefaultFeeEstimatorDepth),
 	}
 	removeTx := func(txHash string, arg interface{}) {
 		go func(txPool *TxsPool, target string) {
 			if txPool.IsRunning() {
-				tp.RemoveTx(target)
+				tp.removeTxs([]string{target}, pubsub.TxEventEvicted, map[string]string{target: "expired"})
 			}
 			txPool.CData.locker.Lock()
 			if listCoins, ok := txPool.CData.CoinsByTxHash[txHash]; ok {
@@ -142,6 +158,11 @@ func (tp *TxsPool) Start() {
 	cValidTxs := make(chan txInfoTemp, 1024)
 	stopGetTxs := make(chan interface{})
 	go tp.getTxs(stopGetTxs, cValidTxs)
+	if tp.persistence != nil {
+		tp.persistence.loaded.Do(func() {
+			go tp.loadPersistedTxs()
+		})
+	}
 	total := 0
 	for {
 		select {
@@ -168,21 +189,60 @@ func (tp *TxsPool) Start() {
 			f(tp)
 			Logger.Debugf("Total txs in pool %v after func\n", len(tp.Data.TxInfos))
 		case validTx := <-cValidTxs:
-			isDoubleSpend, needToRemove, txToRemove, listKeyCoin := tp.CheckDoubleSpendWithCurMem(validTx.tx)
-			if isDoubleSpend {
-				if needToRemove {
-					tp.removeDoubleSpendTx(txToRemove)
-					tp.addTx(validTx, listKeyCoin)
-				}
-			} else {
-				tp.addTx(validTx, listKeyCoin)
-			}
+			tp.addValidTx(validTx)
 			total++
 
 		}
 	}
 }
 
+// addValidTx adds a validated tx unless the pool has a better tx spending
+// the same coins, which it replaces otherwise. When the pool or the quota of
+// the metadata type of the tx is full, the txs paying the least per KB are
+// evicted for it if they pay less than it, it is rejected otherwise.
+func (tp *TxsPool) addValidTx(validTx txInfoTemp) {
+	txH := validTx.tx.Hash().String()
+	isDoubleSpend, needToRemove, txToRemove, listKeyCoin := tp.CheckDoubleSpendWithCurMem(validTx.tx)
+	if isDoubleSpend && !needToRemove {
+		tp.publishTxEvent(txH, pubsub.TxEventRejected, fmt.Sprintf("double spend with tx %v in pool", txToRemove))
+		return
+	}
+	if !isDoubleSpend {
+		txToRemove = ""
+	}
+	if validTx.added.IsZero() {
+		validTx.added = time.Now()
+	}
+	info := tp.newTxInfo(validTx)
+	evictions, err := tp.evictionsFor(validTx.tx, info, txToRemove)
+	if err != nil {
+		Logger.Infof("[txTracing] Reject tx %v: %v", txH, err)
+		tp.publishTxEvent(txH, pubsub.TxEventRejected, err.Error())
+		return
+	}
+	if txToRemove != "" {
+		tp.removeDoubleSpendTx(txToRemove)
+		tp.publishTxEvent(txToRemove, pubsub.TxEventReplaced, txH)
+	}
+	for _, e := range evictions {
+		Logger.Infof("[txTracing] Evict tx %v for tx %v: %v", e.txHash, txH, e.reason)
+		tp.removeDoubleSpendTx(e.txHash)
+		tp.publishTxEvent(e.txHash, pubsub.TxEventEvicted, e.reason)
+	}
+	tp.addTx(validTx, info, listKeyCoin)
+}
+
+func (tp *TxsPool) newTxInfo(validTx txInfoTemp) TxInfo {
+	size := validTx.tx.GetTxActualSize()
+	return TxInfo{
+		Fee:      validTx.tx.GetTxFee(),
+		Size:     size,
+		VTime:    validTx.vt,
+		FeePerKB: feePerKB(feeInNativeToken(validTx.tx, tp.feeView), size),
+		Added:    validTx.added,
+	}
+}
+
 func (tp *TxsPool) CheckDoubleSpendWithCurMem(target metadata.Transaction) (bool, bool, string, []string) {
 	tp.CData.locker.RLock()
 	defer tp.CData.locker.RUnlock()
@@ -264,27 +324,45 @@ func (tp *TxsPool) CheckDoubleSpendWithCurMem(target metadata.Transaction) (bool
 	return isDoubleSpend, neededToReplace, txHash, listkey
 }
 
-func (tp *TxsPool) addTx(validTx txInfoTemp, listCoinKey []string) {
+func (tp *TxsPool) addTx(validTx txInfoTemp, info TxInfo, listCoinKey []string) {
 	tp.CData.locker.Lock()
 	defer tp.CData.locker.Unlock()
 	txH := validTx.tx.Hash().String()
 	tp.Data.TxByHash[txH] = validTx.tx
-	tp.Data.TxInfos[txH] = TxInfo{
-		Fee:   validTx.tx.GetTxFee(),
-		Size:  validTx.tx.GetTxActualSize(),
-		VTime: validTx.vt,
-	}
+	tp.Data.TxInfos[txH] = info
+	u := tp.usageOf(validTx.tx.GetMetadataType())
+	u.Count++
+	u.Size += info.Size
 	tp.CData.CoinsByTxHash[validTx.tx.Hash().String()] = listCoinKey
 	for _, v := range listCoinKey {
 		tp.CData.TxHashByCoin[v] = validTx.tx.Hash().String()
 	}
+	if tp.persistence != nil {
+		tp.persistence.store(validTx.tx, info.Size, info.Added)
+	}
+	tp.feeEstimator.ObserveTx(txH)
+}
+
+// dropTx deletes the tx txH from the pool, it returns whether it was there
+func (tp *TxsPool) dropTx(txH string) bool {
+	tx, inPool := tp.Data.TxByHash[txH]
+	if info, ok := tp.Data.TxInfos[txH]; ok && inPool && tx != nil {
+		u := tp.usageOf(tx.GetMetadataType())
+		u.Count--
+		u.Size -= info.Size
+	}
+	delete(tp.Data.TxByHash, txH)
+	delete(tp.Data.TxInfos, txH)
+	if tp.persistence != nil {
+		tp.persistence.remove(txH)
+	}
+	return inPool
 }
 
 func (tp *TxsPool) removeDoubleSpendTx(txH string) {
 	tp.CData.locker.Lock()
 	defer tp.CData.locker.Unlock()
-	delete(tp.Data.TxByHash, txH)
-	delete(tp.Data.TxInfos, txH)
+	tp.dropTx(txH)
 	if keyList, ok := tp.CData.CoinsByTxHash[txH]; ok {
 		for _, key := range keyList {
 			delete(tp.CData.TxHashByCoin, key)
@@ -300,18 +378,23 @@ func (tp *TxsPool) Stop() {
 }
 
 func (tp *TxsPool) RemoveTx(txHash string) {
-	tp.action <- func(tpTemp *TxsPool) {
-		Logger.Debugf("Removing tx %v at %v", txHash, time.Now())
-		delete(tpTemp.Data.TxByHash, txHash)
-		delete(tpTemp.Data.TxInfos, txHash)
-	}
+	Logger.Debugf("Removing tx %v at %v", txHash, time.Now())
+	tp.removeTxs([]string{txHash}, pubsub.TxEventEvicted, map[string]string{txHash: "removed"})
 }
 
+// RemoveTxs removes the txs included in a new block
 func (tp *TxsPool) RemoveTxs(txHashes []string) {
+	tp.removeTxs(txHashes, pubsub.TxEventIncluded, nil)
+}
+
+// removeTxs removes txHashes from the pool, event is published with the reason
+// of each tx for the ones that were in the pool
+func (tp *TxsPool) removeTxs(txHashes []string, event string, reasons map[string]string) {
 	tp.action <- func(tpTemp *TxsPool) {
 		for _, tx := range txHashes {
-			delete(tpTemp.Data.TxByHash, tx)
-			delete(tpTemp.Data.TxInfos, tx)
+			if tpTemp.dropTx(tx) {
+				tpTemp.publishTxEvent(tx, event, reasons[tx])
+			}
 		}
 	}
 }
@@ -384,9 +467,13 @@ func (tp *TxsPool) FilterWithNewView(
 	if !tp.IsRunning() {
 		return
 	}
+	tp.action <- func(tpTemp *TxsPool) {
+		tpTemp.feeView = bcView
+	}
 	sDB := sView.GetCopiedTransactionStateDB()
 	txsData := tp.snapshotPool()
 	txsToRemove := []string{}
+	reasons := map[string]string{}
 	txsValid := []metadata.Transaction{}
 	defer func() {
 		Logger.Infof("SHARD %v | Filter mempool with bview %v, sview %v; del %v txs, remaining %v \n", sView.GetShardID(), bcView.GetHeight(), sView.GetHeight(), len(txsToRemove), len(txsValid))
@@ -399,6 +486,7 @@ func (tp *TxsPool) FilterWithNewView(
 		if err := tx.CheckData(sDB); err != nil {
 			Logger.Errorf("[txTracing] Validate tx %v return error %v with sView %v\n", txHash, err, sView.GetHeight())
 			txsToRemove = append(txsToRemove, txHash)
+			reasons[txHash] = err.Error()
 			continue
 		}
 		ok, err := tp.Verifier.ValidateWithChainState(
@@ -411,15 +499,23 @@ func (tp *TxsPool) FilterWithNewView(
 		if !ok || err != nil {
 			Logger.Errorf("[txTracing] Validate tx %v return error %v with sView %v\n", txHash, err, sView.GetHeight())
 			txsToRemove = append(txsToRemove, txHash)
+			if err != nil {
+				reasons[txHash] = err.Error()
+			} else {
+				reasons[txHash] = "invalid with chain state"
+			}
 			continue
 		}
 		isDoubleSpend, needToReplace, _, removeIdx := tp.CheckDoubleSpend(mapForChkDbSpend, tx, &txsValid)
 		if isDoubleSpend && !needToReplace {
 			txsToRemove = append(txsToRemove, txHash)
+			reasons[txHash] = "double spend with a better tx in pool"
 			continue
 		}
 		for k := range removeIdx {
-			txsToRemove = append(txsToRemove, txsValid[k].Hash().String())
+			removedHash := txsValid[k].Hash().String()
+			txsToRemove = append(txsToRemove, removedHash)
+			reasons[removedHash] = fmt.Sprintf("double spend with tx %v", txHash)
 			txsValid[k] = nil
 		}
 		if info, ok := txsData.TxInfos[txHash]; ok {
@@ -436,10 +532,11 @@ func (tp *TxsPool) FilterWithNewView(
 			)
 		} else {
 			txsToRemove = append(txsToRemove, txHash)
+			reasons[txHash] = "no tx info in pool"
 		}
 	}
 	if tp.IsRunning() {
-		tp.RemoveTxs(txsToRemove)
+		tp.removeTxs(txsToRemove, pubsub.TxEventEvicted, reasons)
 	}
 	if len(txsToRemove) > 0 {
 		Logger.Infof("Remove %+v txs when validate with new sView %v bView %v", txsToRemove, sView.GetHeight(), bcView.GetHeight())
@@ -456,7 +553,20 @@ func (tp *TxsPool) GetTxsTranferForNewBlock(
 	res := []metadata.Transaction{}
 	txDetailCh := make(chan *TxInfoDetail, 1024)
 	stopCh := make(chan interface{})
-	go tp.getTxsFromPool(txDetailCh, stopCh)
+	// the fees in PRV are kept for the streams of the pool after the first
+	feeLock := &sync.Mutex{}
+	fees := map[string]uint64{}
+	feeOf := func(txDetails *TxInfoDetail) uint64 {
+		feeLock.Lock()
+		defer feeLock.Unlock()
+		if fee, ok := fees[txDetails.Hash]; ok {
+			return fee
+		}
+		fee := feeInNativeToken(txDetails.Tx, bcView)
+		fees[txDetails.Hash] = fee
+		return fee
+	}
+	go tp.getTxsFromPool(txDetailCh, stopCh, feeOf)
 	curSize := uint64(0)
 	curTime := 0 * time.Millisecond
 	mapForChkDbSpend := map[[privacy.Ed25519KeySize]byte]struct {
@@ -467,12 +577,19 @@ func (tp *TxsPool) GetTxsTranferForNewBlock(
 	maxSize := ctx.GetMaxSize()
 	mapForChkDbStake := map[string]interface{}{}
 	collectedTx := map[string]bool{}
+	// the highest fee per KB of the txs left out for lack of room
+	cutoffFeePerKB := uint64(0)
 	defer func() {
-		Logger.Infof("Return list txs #res %v cursize %v curtime %v maxsize %v for shard %v \n", len(res), curSize, curTime, maxSize, sView.GetShardID())
+		Logger.Infof("Return list txs #res %v cursize %v curtime %v maxsize %v cutoff fee per KB %v for shard %v \n", len(res), curSize, curTime, maxSize, cutoffFeePerKB, sView.GetShardID())
+		cutoffFeePerKBGauge.Update(int64(cutoffFeePerKB))
 		if stopCh != nil {
 			close(stopCh)
 		}
 		removeNilTx(&res)
+		reason := fmt.Sprintf("shard height %v", sView.GetHeight()+1)
+		for _, tx := range res {
+			tp.publishTxEvent(tx.Hash().String(), pubsub.TxEventSelected, reason)
+		}
 	}()
 	limitTxAction := map[int]int{}
 	for {
@@ -492,7 +609,7 @@ func (tp *TxsPool) GetTxsTranferForNewBlock(
 				txDetailCh = make(chan *TxInfoDetail, 1024)
 				go func() {
 					time.Sleep(time.Millisecond * 100)
-					tp.getTxsFromPool(txDetailCh, stopCh)
+					tp.getTxsFromPool(txDetailCh, stopCh, feeOf)
 				}()
 				continue
 			}
@@ -502,10 +619,10 @@ func (tp *TxsPool) GetTxsTranferForNewBlock(
 			}
 
 			Logger.Debugf("[txTracing] Validate new tx %v with chainstate\n", txDetails.Tx.Hash().String())
-			if curSize+txDetails.Size > maxSize {
-				continue
-			}
 			if (curSize+txDetails.Size > maxSize) || (curTime+txDetails.VTime > maxTime) {
+				if txDetails.FeePerKB > cutoffFeePerKB {
+					cutoffFeePerKB = txDetails.FeePerKB
+				}
 				continue
 			}
 			if ok := checkTxAction(limitTxAction, txDetails.Tx); !ok {
@@ -546,6 +663,14 @@ func (tp *TxsPool) GetTxsTranferForNewBlock(
 			collectedTx[txDetails.Tx.Hash().String()] = true
 			ctx.DecreaseNumTXRemain()
 			if ctx.GetNumTxRemain() <= 0 {
+				// the txs are streamed by fee, the next one is the first left out
+				select {
+				case next := <-txDetailCh:
+					if next != nil && !collectedTx[next.Hash] && next.FeePerKB > cutoffFeePerKB {
+						cutoffFeePerKB = next.FeePerKB
+					}
+				default:
+				}
 				return res
 			}
 
@@ -785,17 +910,21 @@ func (tp *TxsPool) getTxs(quit <-chan interface{}, cValidTxs chan txInfoTemp) {
 			txHah := msg.Hash().String()
 			workerID := len(nWorkers)
 			Logger.Debugf("[txTracing] Received new tx %v, send to worker %v", txHah, workerID)
+			tp.publishTxEvent(txHah, pubsub.TxEventReceived, "")
 			nWorkers <- 1
 			go func() {
 				isValid, err, vTime := tp.ValidateNewTx(msg)
 				<-nWorkers
 				if err != nil {
 					Logger.Errorf("Validate tx %v return error %v:\n", msg.Hash().String(), err)
+					tp.publishTxEvent(txHah, pubsub.TxEventRejected, err.Error())
+				} else if isValid {
+					tp.publishTxEvent(txHah, pubsub.TxEventValidated, "")
 				}
 				if (isValid) && (cValidTxs != nil) {
 					cValidTxs <- txInfoTemp{
-						msg,
-						vTime,
+						tx: msg,
+						vt: vTime,
 					}
 				}
 			}()
@@ -851,46 +980,59 @@ func (tp *TxsPool) getTxByHash(txID string) metadata.Transaction {
 	return <-cData
 }
 
+// getTxsFromPool streams the txs of the pool from the highest fee per KB,
+// feeOf gives the fee in PRV of a tx. The pool is only held to copy its txs.
 func (tp *TxsPool) getTxsFromPool(
 	txCh chan *TxInfoDetail,
 	stopC <-chan interface{},
+	feeOf func(txDetails *TxInfoDetail) uint64,
 ) {
+	defer func() {
+		if txCh != nil {
+			close(txCh)
+		}
+		Logger.Debug("tx channel is closed")
+	}()
+	cTxs := make(chan []*TxInfoDetail)
 	tp.action <- func(tpTemp *TxsPool) {
-		defer func() {
-			if txCh != nil {
-				close(txCh)
-			}
-			Logger.Debug("tx channel is closed")
-		}()
+		txs := make([]*TxInfoDetail, 0, len(tpTemp.Data.TxByHash))
 		for k, v := range tpTemp.Data.TxByHash {
+			info, ok := tpTemp.Data.TxInfos[k]
+			if !ok || v == nil {
+				continue
+			}
+			txs = append(txs, &TxInfoDetail{
+				Hash:  k,
+				Fee:   info.Fee,
+				Size:  info.Size,
+				VTime: info.VTime,
+				Tx:    v,
+			})
+		}
+		cTxs <- txs
+	}
+	txs := <-cTxs
+	for _, txDetails := range txs {
+		txDetails.FeePerKB = feePerKB(feeOf(txDetails), txDetails.Size)
+	}
+	sortByFeePerKB(txs)
+	for _, txDetails := range txs {
+		select {
+		case <-stopC:
+			return
+		default:
+		}
+		Logger.Debugf("[debugperformance] Got %v fee per KB %v, send to channel\n", txDetails.Hash, txDetails.FeePerKB)
+		if txCh != nil {
 			select {
+			case txCh <- txDetails:
 			case <-stopC:
 				return
-			default:
-				txDetails := &TxInfoDetail{}
-				if info, ok := tpTemp.Data.TxInfos[k]; ok {
-					txDetails.Hash = k
-					txDetails.Fee = info.Fee
-					txDetails.Size = info.Size
-					txDetails.VTime = info.VTime
-				} else {
-					continue
-				}
-				if v != nil {
-					txDetails.Tx = v
-					Logger.Debugf("[debugperformance] Got %v, send to channel\n", txDetails.Hash)
-					if txCh != nil {
-						select {
-						case txCh <- txDetails:
-						case <-time.NewTimer(time.Second * 10).C:
-							return
-						}
-					}
-				}
+			case <-time.NewTimer(time.Second * 10).C:
+				return
 			}
 		}
 	}
-
 }
 
 func checkTxAction(
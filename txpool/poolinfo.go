@@ -25,6 +25,7 @@ type GetMempoolInfo struct {
 	MempoolMinFee uint64
 	MempoolMaxFee uint64
 	ListTxs       []MempoolInfoTx
+	UsageByType   map[int]PoolUsage //by metadata type, InvalidMeta for no metadata
 }
 
 func (info *GetMempoolInfo) GetSize() int {
@@ -49,6 +50,9 @@ func (info *GetMempoolInfo) GetMempoolMaxFee() uint64 {
 func (info *GetMempoolInfo) GetListTxs() []MempoolInfoTx {
 	return info.ListTxs
 }
+func (info *GetMempoolInfo) GetUsageByType() map[int]PoolUsage {
+	return info.UsageByType
+}
 
 type MempoolInfo interface {
 	GetSize() int
@@ -58,4 +62,5 @@ type MempoolInfo interface {
 	GetMempoolMinFee() uint64
 	GetMempoolMaxFee() uint64
 	GetListTxs() []MempoolInfoTx
+	GetUsageByType() map[int]PoolUsage
 }
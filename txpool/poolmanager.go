@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/rawdbv2"
+	"github.com/levietcuong2602/incognito-chain/incdb"
 	"github.com/levietcuong2602/incognito-chain/metadata"
 	"github.com/levietcuong2602/incognito-chain/privacy"
 	"github.com/levietcuong2602/incognito-chain/pubsub"
@@ -28,12 +30,69 @@ func NewPoolManager(
 		ps: ps,
 	}
 	for i := 0; i < activeShards; i++ {
-		res.ShardTxsPool = append(res.ShardTxsPool, NewTxsPool(nil, make(chan metadata.Transaction, 128), ttl))
+		tp := NewTxsPool(nil, make(chan metadata.Transaction, 128), ttl)
+		tp.UpdatePubSub(ps, byte(i))
+		res.ShardTxsPool = append(res.ShardTxsPool, tp)
 	}
 
 	return res, nil
 }
 
+// EnablePersistence stores the txs of the shard pools in db, each shard up
+// to maxTx txs of maxSize KB
+func (pm *PoolManager) EnablePersistence(db incdb.Database, maxTx, maxSize uint64) {
+	for sID, txPool := range pm.ShardTxsPool {
+		if tp, ok := txPool.(*TxsPool); ok {
+			tp.EnablePersistence(db, byte(sID), maxTx, maxSize)
+		}
+	}
+}
+
+// RestoreFeeEstimators restores the fee history of each shard pool from the
+// database of its shard
+func (pm *PoolManager) RestoreFeeEstimators(dbs map[int]incdb.Database) {
+	for sID, txPool := range pm.ShardTxsPool {
+		db, ok := dbs[sID]
+		if !ok {
+			continue
+		}
+		data, err := rawdbv2.GetTxPoolFeeEstimator(db, byte(sID))
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		if err := txPool.GetFeeEstimator().Restore(data); err != nil {
+			Logger.Errorf("Cannot restore fee estimator of shard %v: %v", sID, err)
+		}
+	}
+}
+
+// SaveFeeEstimators stores the fee history of each shard pool in the database
+// of its shard
+func (pm *PoolManager) SaveFeeEstimators(dbs map[int]incdb.Database) {
+	for sID, txPool := range pm.ShardTxsPool {
+		db, ok := dbs[sID]
+		if !ok {
+			continue
+		}
+		data, err := txPool.GetFeeEstimator().Save()
+		if err == nil {
+			err = rawdbv2.StoreTxPoolFeeEstimator(db, byte(sID), data)
+		}
+		if err != nil {
+			Logger.Errorf("Cannot save fee estimator of shard %v: %v", sID, err)
+		}
+	}
+}
+
+// SetLimits bounds each shard pool by limits
+func (pm *PoolManager) SetLimits(limits TxsPoolLimits) {
+	for _, txPool := range pm.ShardTxsPool {
+		if tp, ok := txPool.(*TxsPool); ok {
+			tp.SetLimits(limits)
+		}
+	}
+}
+
 func (pm *PoolManager) Start(relayShards []byte) error {
 	_, newRoleECh, err := pm.ps.RegisterNewSubscriber(pubsub.NodeRoleDetailTopic)
 	if err != nil {
@@ -112,6 +171,7 @@ func (pm *PoolManager) GetMempoolInfo() MempoolInfo {
 		MempoolMinFee: 1000000,
 		MempoolMaxFee: 0,
 		ListTxs:       []MempoolInfoTx{},
+		UsageByType:   map[int]PoolUsage{},
 	}
 
 	allTxsData := []TxsData{}
@@ -128,6 +188,10 @@ func (pm *PoolManager) GetMempoolInfo() MempoolInfo {
 				LockTime: tx.GetLockTime(),
 			})
 			if txInfo, ok := txsData.TxInfos[txHash]; ok {
+				usage := res.UsageByType[tx.GetMetadataType()]
+				usage.Count++
+				usage.Size += txInfo.Size
+				res.UsageByType[tx.GetMetadataType()] = usage
 				res.Bytes += txInfo.Size
 				if res.MempoolMinFee > txInfo.Fee {
 					res.MempoolMinFee = txInfo.Fee
@@ -0,0 +1,53 @@
+package txpool
+
+import (
+	"math"
+	"sort"
+
+	"github.com/levietcuong2602/incognito-chain/metadata"
+	"github.com/levietcuong2602/incognito-chain/metrics"
+)
+
+// cutoffFeePerKBGauge is the highest fee per KB in PRV of the transactions
+// left out of the last block for lack of room, 0 when every one fit
+var cutoffFeePerKBGauge = metrics.NewRegisteredGauge("txpool/selection/cutofffeeperkb", nil)
+
+// feeInNativeToken returns the fee of tx in PRV, its fee in token converted by
+// the PDE rate of the beacon view as the fee check of the verifier does. A
+// token fee without rate counts for nothing, the verifier rejects the tx.
+func feeInNativeToken(tx metadata.Transaction, bcView metadata.BeaconViewRetriever) uint64 {
+	fee := tx.GetTxFee()
+	feePToken := tx.GetTxFeeToken()
+	if feePToken == 0 || bcView == nil {
+		return fee
+	}
+	feePTokenToNativeToken, err := metadata.ConvertPrivacyTokenToNativeToken(feePToken, tx.GetTokenID(), int64(bcView.GetHeight()), bcView.GetBeaconFeatureStateDB())
+	if err != nil {
+		Logger.Debugf("[txTracing] Fee %v of tx %v in token %v has no rate: %v", feePToken, tx.Hash().String(), tx.GetTokenID().String(), err)
+		return fee
+	}
+	return fee + uint64(math.Ceil(feePTokenToNativeToken))
+}
+
+// feePerKB is the fee of a tx of size KB, the size of a tx is at least 1 KB
+// for the fee rules
+func feePerKB(fee uint64, size uint64) uint64 {
+	if size == 0 {
+		size = 1
+	}
+	return fee / size
+}
+
+// sortByFeePerKB sorts txs from the highest fee per KB, ties go to the
+// highest fee then to the hash so that every node picks the same order
+func sortByFeePerKB(txs []*TxInfoDetail) {
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].FeePerKB != txs[j].FeePerKB {
+			return txs[i].FeePerKB > txs[j].FeePerKB
+		}
+		if txs[i].Fee != txs[j].Fee {
+			return txs[i].Fee > txs[j].Fee
+		}
+		return txs[i].Hash < txs[j].Hash
+	})
+}
@@ -0,0 +1,125 @@
+package txpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/statedb"
+	"github.com/levietcuong2602/incognito-chain/incognitokey"
+	"github.com/levietcuong2602/incognito-chain/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortByFeePerKB(t *testing.T) {
+	tests := []struct {
+		name string
+		txs  []*TxInfoDetail
+		want []string
+	}{
+		{
+			name: "highest fee per KB first",
+			txs:  []*TxInfoDetail{{Hash: "a", FeePerKB: 1, Fee: 10}, {Hash: "b", FeePerKB: 3, Fee: 3}, {Hash: "c", FeePerKB: 2, Fee: 2}},
+			want: []string{"b", "c", "a"},
+		},
+		{
+			name: "same fee per KB, highest fee first",
+			txs:  []*TxInfoDetail{{Hash: "a", FeePerKB: 2, Fee: 2}, {Hash: "b", FeePerKB: 2, Fee: 4}, {Hash: "c", FeePerKB: 1, Fee: 8}},
+			want: []string{"b", "a", "c"},
+		},
+		{
+			name: "same fee per KB and fee, lowest hash first",
+			txs:  []*TxInfoDetail{{Hash: "c", FeePerKB: 2, Fee: 2}, {Hash: "a", FeePerKB: 2, Fee: 2}, {Hash: "b", FeePerKB: 2, Fee: 2}},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "empty",
+			txs:  []*TxInfoDetail{},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortByFeePerKB(tt.txs)
+			got := []string{}
+			for _, tx := range tt.txs {
+				got = append(got, tx.Hash)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// testPrefetch fills a block of maxSize KB
+type testPrefetch struct {
+	context.Context
+	maxSize uint64
+}
+
+func (p *testPrefetch) DecreaseNumTXRemain()      {}
+func (p *testPrefetch) GetMaxTime() time.Duration { return time.Minute }
+func (p *testPrefetch) GetMaxSize() uint64        { return p.maxSize }
+func (p *testPrefetch) IsRunning() bool           { return true }
+func (p *testPrefetch) GetNumTxRemain() int64     { return 1000 }
+
+// testShardView is the view of shard 0 at height 1
+type testShardView struct{}
+
+func (testShardView) GetEpoch() uint64                                            { return 1 }
+func (testShardView) GetBeaconHeight() uint64                                     { return 1 }
+func (testShardView) GetShardID() byte                                            { return 0 }
+func (testShardView) GetStakingTx() map[string]string                             { return nil }
+func (testShardView) ListShardPrivacyTokenAndPRV() []common.Hash                  { return nil }
+func (testShardView) GetShardRewardStateDB() *statedb.StateDB                     { return nil }
+func (testShardView) GetCopiedFeatureStateDB() *statedb.StateDB                   { return nil }
+func (testShardView) GetCopiedTransactionStateDB() *statedb.StateDB               { return nil }
+func (testShardView) GetHeight() uint64                                           { return 1 }
+func (testShardView) GetBlockVersion() int                                        { return 1 }
+func (testShardView) GetTriggeredFeature() map[string]uint64                      { return nil }
+func (testShardView) GetShardCommittee() []incognitokey.CommitteePublicKey        { return nil }
+func (testShardView) GetShardPendingValidator() []incognitokey.CommitteePublicKey { return nil }
+
+// addTestTxs adds txs to the running pool tp
+func addTestTxs(tp *TxsPool, txs ...metadata.Transaction) {
+	done := make(chan struct{})
+	tp.action <- func(tpTemp *TxsPool) {
+		for _, tx := range txs {
+			tpTemp.addValidTx(txInfoTemp{tx: tx})
+		}
+		close(done)
+	}
+	<-done
+}
+
+func TestTxsPool_CutoffFeePerKB(t *testing.T) {
+	Logger.Init(common.NewBackend(nil).Logger("test", true))
+	txs := []metadata.Transaction{newTestTx(1, 3000), newTestTx(2, 1000), newTestTx(3, 2000)}
+	size := txs[0].GetTxActualSize()
+	tests := []struct {
+		name    string
+		maxSize uint64
+		want    []metadata.Transaction
+		cutoff  uint64
+	}{
+		{name: "every tx fits", maxSize: 3 * size, want: []metadata.Transaction{txs[0], txs[2], txs[1]}, cutoff: 0},
+		{name: "lowest tx left out", maxSize: 2 * size, want: []metadata.Transaction{txs[0], txs[2]}, cutoff: feePerKB(1000, size)},
+		{name: "only the best tx fits", maxSize: size, want: []metadata.Transaction{txs[0]}, cutoff: feePerKB(2000, size)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := NewTxsPool(&testVerifier{}, make(chan metadata.Transaction), time.Hour)
+			go tp.Start()
+			defer tp.Stop()
+			for !tp.IsRunning() {
+				time.Sleep(10 * time.Millisecond)
+			}
+			addTestTxs(tp, txs...)
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+			defer cancel()
+			got := tp.GetTxsTranferForNewBlock(nil, testShardView{}, nil, &testPrefetch{Context: ctx, maxSize: tt.maxSize})
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, int64(tt.cutoff), cutoffFeePerKBGauge.Value())
+		})
+	}
+}
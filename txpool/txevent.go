@@ -0,0 +1,27 @@
+package txpool
+
+import (
+	"time"
+
+	"github.com/levietcuong2602/incognito-chain/pubsub"
+)
+
+// UpdatePubSub publishes the lifecycle events of the txs of the pool of
+// shardID to ps
+func (tp *TxsPool) UpdatePubSub(ps *pubsub.PubSubManager, shardID byte) {
+	tp.ps = ps
+	tp.shardID = shardID
+}
+
+func (tp *TxsPool) publishTxEvent(txHash, event, reason string) {
+	if tp.ps == nil {
+		return
+	}
+	tp.ps.PublishMessage(pubsub.NewMessage(pubsub.TxLifecycleTopic, &pubsub.TxEvent{
+		TxHash:  txHash,
+		ShardID: tp.shardID,
+		Event:   event,
+		Reason:  reason,
+		Time:    time.Now().Unix(),
+	}))
+}
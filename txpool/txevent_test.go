@@ -0,0 +1,125 @@
+package txpool
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/levietcuong2602/incognito-chain/common"
+	"github.com/levietcuong2602/incognito-chain/metadata"
+	"github.com/levietcuong2602/incognito-chain/privacy"
+	"github.com/levietcuong2602/incognito-chain/privacy/coin"
+	"github.com/levietcuong2602/incognito-chain/privacy/operation"
+	"github.com/levietcuong2602/incognito-chain/pubsub"
+	"github.com/levietcuong2602/incognito-chain/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestSpendTx returns a tx paying fee that spends the coin of key image
+// keyImage, the txs of the same keyImage are double spends
+func newTestSpendTx(id int64, fee uint64, keyImage byte) metadata.Transaction {
+	pc := new(coin.PlainCoinV1).Init()
+	pc.SetKeyImage(operation.HashToPoint([]byte{keyImage}))
+	proof := &privacy.ProofV1{}
+	proof.Init()
+	proof.SetInputCoins([]coin.PlainCoin{pc})
+	tx := newTestTx(id, fee).(*transaction.TxVersion1)
+	tx.Proof = proof
+	return tx
+}
+
+// collectTxEvents returns the events received on events until none comes for
+// a while, sorted by tx and event
+func collectTxEvents(events pubsub.EventChannel) []pubsub.TxEvent {
+	res := []pubsub.TxEvent{}
+	for {
+		select {
+		case msg := <-events:
+			event := *msg.Value.(*pubsub.TxEvent)
+			event.Time = 0
+			res = append(res, event)
+		case <-time.After(200 * time.Millisecond):
+			sort.Slice(res, func(i, j int) bool {
+				if res[i].TxHash != res[j].TxHash {
+					return res[i].TxHash < res[j].TxHash
+				}
+				return res[i].Event < res[j].Event
+			})
+			return res
+		}
+	}
+}
+
+func TestTxsPool_TxEvents(t *testing.T) {
+	Logger.Init(common.NewBackend(nil).Logger("test", true))
+	ps := pubsub.NewPubSubManager()
+	go ps.Start()
+	inPool := newTestSpendTx(1, 10, 1)
+	other := newTestSpendTx(2, 15, 2)
+	better := newTestSpendTx(3, 20, 1)
+	worse := newTestSpendTx(4, 5, 1)
+	richer := newTestSpendTx(5, 20, 5)
+	poorer := newTestSpendTx(6, 5, 6)
+	hash := func(tx metadata.Transaction) string { return tx.Hash().String() }
+	event := func(tx metadata.Transaction, e, reason string) pubsub.TxEvent {
+		return pubsub.TxEvent{TxHash: hash(tx), ShardID: 1, Event: e, Reason: reason}
+	}
+	tests := []struct {
+		name   string
+		limits TxsPoolLimits
+		action func(tp *TxsPool)
+		want   []pubsub.TxEvent
+	}{
+		{
+			name:   "included in a block",
+			action: func(tp *TxsPool) { tp.RemoveTxs([]string{hash(inPool), hash(better)}) },
+			want:   []pubsub.TxEvent{event(inPool, pubsub.TxEventIncluded, "")},
+		},
+		{
+			name:   "removed",
+			action: func(tp *TxsPool) { tp.RemoveTx(hash(other)) },
+			want:   []pubsub.TxEvent{event(other, pubsub.TxEventEvicted, "removed")},
+		},
+		{
+			name:   "replaced by a double spend paying more",
+			action: func(tp *TxsPool) { addTestTxs(tp, better) },
+			want:   []pubsub.TxEvent{event(inPool, pubsub.TxEventReplaced, hash(better))},
+		},
+		{
+			name:   "double spend paying less",
+			action: func(tp *TxsPool) { addTestTxs(tp, worse) },
+			want:   []pubsub.TxEvent{event(worse, pubsub.TxEventRejected, "double spend with tx "+hash(inPool)+" in pool")},
+		},
+		{
+			name:   "evicted from a full pool",
+			limits: TxsPoolLimits{MaxTx: 2},
+			action: func(tp *TxsPool) { addTestTxs(tp, richer) },
+			want:   []pubsub.TxEvent{event(inPool, pubsub.TxEventEvicted, "pool full")},
+		},
+		{
+			name:   "rejected by a full pool",
+			limits: TxsPoolLimits{MaxTx: 2},
+			action: func(tp *TxsPool) { addTestTxs(tp, poorer) },
+			want:   []pubsub.TxEvent{event(poorer, pubsub.TxEventRejected, "pool is full")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := NewTxsPool(&testVerifier{}, make(chan metadata.Transaction), time.Hour)
+			tp.addValidTx(txInfoTemp{tx: other})
+			tp.addValidTx(txInfoTemp{tx: inPool})
+			tp.SetLimits(tt.limits)
+			tp.UpdatePubSub(ps, 1)
+			subID, events, err := ps.RegisterNewSubscriber(pubsub.TxLifecycleTopic)
+			assert.Nil(t, err)
+			defer ps.Unsubscribe(pubsub.TxLifecycleTopic, subID)
+			go tp.Start()
+			defer tp.Stop()
+			for !tp.IsRunning() {
+				time.Sleep(10 * time.Millisecond)
+			}
+			tt.action(tp)
+			assert.Equal(t, tt.want, collectTxEvents(events))
+		})
+	}
+}
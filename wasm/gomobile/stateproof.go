@@ -0,0 +1,35 @@
+package gomobile
+
+import (
+	"encoding/json"
+
+	"github.com/levietcuong2602/incognito-chain/dataaccessobject/stateproof"
+)
+
+// VerifyStateProof verifies a proof returned by the getstateproof RPC against
+// the trusted state root root, hex encoded, which is required. It returns the
+// proved object as JSON with the encoded object in Value, Exists is false when
+// the proof shows the object is absent.
+func VerifyStateProof(proofJSON string, root string) (string, error) {
+	proof, err := stateproof.VerifyJSON([]byte(proofJSON), root)
+	if err != nil {
+		return "", err
+	}
+	result, err := json.Marshal(struct {
+		Root       string
+		ObjectType int
+		Key        string
+		Exists     bool
+		Value      []byte
+	}{
+		Root:       proof.Root.String(),
+		ObjectType: proof.ObjectType,
+		Key:        proof.Key.String(),
+		Exists:     proof.Exists(),
+		Value:      proof.Value,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
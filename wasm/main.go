@@ -211,6 +211,15 @@ func verifySign(_ js.Value, args []js.Value) interface{} {
 	return result
 }
 
+func verifyStateProof(_ js.Value, args []js.Value) interface{} {
+	result, err := gomobile.VerifyStateProof(args[0].String(), args[1].String())
+	if err != nil {
+		return nil
+	}
+
+	return result
+}
+
 func main() {
 	c := make(chan struct{}, 0)
 	println("Hello WASM")
@@ -243,5 +252,7 @@ func main() {
 	js.Global().Set("signPoolWithdraw", js.FuncOf(signPoolWithdraw))
 	js.Global().Set("verifySign", js.FuncOf(verifySign))
 
+	js.Global().Set("verifyStateProof", js.FuncOf(verifyStateProof))
+
 	<-c
 }